@@ -0,0 +1,59 @@
+package apperror
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Error represents typed application error
+type Error struct {
+	Code       string
+	HTTPStatus int
+	Message    string
+	Err        error
+	stack      []uintptr
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause of the error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by code so sentinel app errors work with errors.Is
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code != "" && e.Code == t.Code
+}
+
+// Stack returns the formatted stack trace captured at creation
+func (e *Error) Stack() string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// Config represents error handler configuration
+type Config struct {
+	// LogStack logs 5xx errors with stack traces
+	LogStack bool
+	// StatusByKind overrides HTTP status for database error kinds
+	StatusByKind map[string]int
+}
@@ -0,0 +1,91 @@
+package apperror
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/response"
+	"github.com/rikiihsan/nest/validator"
+)
+
+var defaultStatusByKind = map[database.ErrorKind]int{
+	database.KindNotFound:            http.StatusNotFound,
+	database.KindUniqueViolation:     http.StatusConflict,
+	database.KindForeignKeyViolation: http.StatusConflict,
+	database.KindNotNullViolation:    http.StatusBadRequest,
+	database.KindCheckViolation:      http.StatusBadRequest,
+	database.KindTimeout:             http.StatusGatewayTimeout,
+	database.KindConnection:          http.StatusServiceUnavailable,
+}
+
+// ErrorHandler returns fiber error handler writing the standard response envelope
+func ErrorHandler(configs ...Config) fiber.ErrorHandler {
+	cfg := Config{LogStack: true}
+	if len(configs) > 0 {
+		cfg = configs[0]
+	}
+
+	return func(c *fiber.Ctx, err error) error {
+		// Validation errors
+		var validationErrs validator.Errors
+		if errors.As(err, &validationErrs) {
+			return response.ValidationFailed(c, validationErrs)
+		}
+
+		status := statusOf(err, cfg)
+		if status >= http.StatusInternalServerError {
+			logError(c, err, cfg)
+		}
+
+		// Only expose messages of errors meant for clients
+		message := http.StatusText(status)
+		var fiberErr *fiber.Error
+		if appErr, ok := As(err); ok {
+			message = appErr.Message
+		} else if errors.As(err, &fiberErr) {
+			message = fiberErr.Message
+		}
+		return response.Error(c, status, errors.New(message))
+	}
+}
+
+func statusOf(err error, cfg Config) int {
+	if appErr, ok := As(err); ok {
+		return appErr.HTTPStatus
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+
+	kind := database.Classify(err)
+	if status, ok := cfg.StatusByKind[kind.String()]; ok {
+		return status
+	}
+	if status, ok := defaultStatusByKind[kind]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+func logError(c *fiber.Ctx, err error, cfg Config) {
+	attrs := []any{
+		"method", c.Method(),
+		"path", c.Path(),
+		"error", err.Error(),
+	}
+	if cfg.LogStack {
+		if appErr, ok := As(err); ok {
+			attrs = append(attrs, "stack", appErr.Stack())
+		} else {
+			attrs = append(attrs, "stack", string(debug.Stack()))
+		}
+	}
+	slog.ErrorContext(c.UserContext(), "request failed", attrs...)
+}
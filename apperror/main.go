@@ -0,0 +1,104 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"runtime"
+)
+
+// Common application errors
+var (
+	ErrBadRequest   = New("bad_request", http.StatusBadRequest, "bad request")
+	ErrUnauthorized = New("unauthorized", http.StatusUnauthorized, "unauthorized")
+	ErrForbidden    = New("forbidden", http.StatusForbidden, "forbidden")
+	ErrNotFound     = New("not_found", http.StatusNotFound, "resource not found")
+	ErrConflict     = New("conflict", http.StatusConflict, "resource conflict")
+	ErrInternal     = New("internal", http.StatusInternalServerError, "internal server error")
+)
+
+func callers() []uintptr {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// New creates application error
+func New(code string, httpStatus int, message string) *Error {
+	return &Error{
+		Code:       code,
+		HTTPStatus: httpStatus,
+		Message:    message,
+		stack:      callers(),
+	}
+}
+
+// Wrap wraps err as application error
+func Wrap(err error, code string, httpStatus int, message string) *Error {
+	return &Error{
+		Code:       code,
+		HTTPStatus: httpStatus,
+		Message:    message,
+		Err:        err,
+		stack:      callers(),
+	}
+}
+
+// Wrap wraps err keeping code and status of e
+func (e *Error) Wrap(err error) *Error {
+	return &Error{
+		Code:       e.Code,
+		HTTPStatus: e.HTTPStatus,
+		Message:    e.Message,
+		Err:        err,
+		stack:      callers(),
+	}
+}
+
+// WithMessage returns copy of e with a different message
+func (e *Error) WithMessage(message string) *Error {
+	return &Error{
+		Code:       e.Code,
+		HTTPStatus: e.HTTPStatus,
+		Message:    message,
+		Err:        e.Err,
+		stack:      callers(),
+	}
+}
+
+// As returns the outermost application error in the chain
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// Cause returns the innermost error in the chain
+func Cause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// Chain returns all errors in the chain from outermost to innermost
+func Chain(err error) []error {
+	var chain []error
+	for err != nil {
+		chain = append(chain, err)
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
+
+// StatusOf returns HTTP status of err, 500 if err is not an application error
+func StatusOf(err error) int {
+	if appErr, ok := As(err); ok {
+		return appErr.HTTPStatus
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,47 @@
+package argon2id
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+)
+
+var ErrShortSalt = errors.New("argon2id : salt must be at least 16 bytes")
+
+// GenerateSalt returns random salt of params.SaltLength bytes to store next to encrypted data
+func GenerateSalt(params *Params) ([]byte, error) {
+	return generateRandByte(params.SaltLength)
+}
+
+// DeriveKey returns raw argon2id key material of keyLen bytes, e.g. an AES-256 key for keyLen 32
+func DeriveKey(password string, salt []byte, params *Params, keyLen uint32) ([]byte, error) {
+	if len(salt) < 16 {
+		return nil, ErrShortSalt
+	}
+	return argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, keyLen), nil
+}
+
+// SubKey expands master key into keyLen bytes bound to info with HKDF-SHA256
+func SubKey(master []byte, info string, keyLen int) ([]byte, error) {
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, master, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// SubKeys expands master key into one keyLen sub-key per info, e.g. separate encryption and MAC keys
+func SubKeys(master []byte, keyLen int, infos ...string) ([][]byte, error) {
+	keys := make([][]byte, len(infos))
+	for i, info := range infos {
+		key, err := SubKey(master, info, keyLen)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
@@ -0,0 +1,20 @@
+package argon2id
+
+// DummyHash is verified for unknown users, it uses DefaultParams so the computation costs the same as a real
+// verification. Applications hashing with other params should replace it with CreateHash of their params
+var DummyHash = "$argon2id$ver=19$memo=65536,it=1,pll=2$Tt6mzLI4F/DE7V0pxJ0ibw$bfz5j22WcqIS572wtuQYn/MX+UyMt2vJVOSHfAkEiE0"
+
+// VerifyOrDummy compares password with hash, when hash is nil or empty (unknown user) it verifies against
+// DummyHash and returns false, so login latency doesn't reveal whether the account exists
+func VerifyOrDummy(password string, hash *string) (bool, error) {
+	return verifyOrDummy([]byte(password), hash)
+}
+
+func verifyOrDummy(password []byte, hash *string) (bool, error) {
+	if hash == nil || *hash == "" {
+		_, _, err := checkHash(password, DummyHash)
+		return false, err
+	}
+	match, _, err := checkHash(password, *hash)
+	return match, err
+}
@@ -23,6 +23,8 @@ type Params struct {
 	Parallelism uint8
 	SaltLength  uint32
 	KeyLength   uint32
+	// Profile is recorded in hash metadata as profile=<name>, see Profile
+	Profile string
 }
 
 var DefaultParams = &Params{
@@ -43,14 +45,23 @@ func generateRandByte(n uint32) ([]byte, error) {
 }
 
 func CreateHash(password string, params *Params) (hash string, err error) {
+	return createHash([]byte(password), params)
+}
+
+// createHash hashes password without copying it, so callers can zero it
+func createHash(password []byte, params *Params) (hash string, err error) {
 	salt, err := generateRandByte(params.SaltLength)
 	if err != nil {
 		return "", err
 	}
-	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	key := argon2.IDKey(password, salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
 	base64salt := base64.RawStdEncoding.EncodeToString(salt)
 	base64key := base64.RawStdEncoding.EncodeToString(key)
-	hash = fmt.Sprintf("$argon2id$ver=%d$memo=%d,it=%d,pll=%d$%s$%s", argon2.Version, params.Memory, params.Iterations, params.Parallelism, base64salt, base64key)
+	profile := ""
+	if params.Profile != "" {
+		profile = ",profile=" + params.Profile
+	}
+	hash = fmt.Sprintf("$argon2id$ver=%d$memo=%d,it=%d,pll=%d%s$%s$%s", argon2.Version, params.Memory, params.Iterations, params.Parallelism, profile, base64salt, base64key)
 	return hash, nil
 }
 
@@ -60,12 +71,17 @@ func ComparePassAndHash(password, hash string) (match bool, err error) {
 }
 
 func CheckHash(password, hash string) (match bool, params *Params, err error) {
+	return checkHash([]byte(password), hash)
+}
+
+// checkHash compares password with hash without copying it, so callers can zero it
+func checkHash(password []byte, hash string) (match bool, params *Params, err error) {
 	params, salt, key, err := DecodeHash(hash)
 	if err != nil {
 		return false, nil, err
 	}
 
-	otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	otherKey := argon2.IDKey(password, salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
 
 	keyLen := int32(len(key))
 	otherKeyLen := int32(len(otherKey))
@@ -103,6 +119,11 @@ func DecodeHash(hash string) (params *Params, salt, key []byte, err error) {
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	for _, param := range strings.Split(vals[3], ",") {
+		if name, ok := strings.CutPrefix(param, "profile="); ok {
+			params.Profile = name
+		}
+	}
 
 	salt, err = base64.RawStdEncoding.Strict().DecodeString(vals[4])
 	if err != nil {
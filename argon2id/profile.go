@@ -0,0 +1,65 @@
+package argon2id
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ProfileEnv selects profile of ProfileFromEnv
+const ProfileEnv = "NEST_ARGON2ID_PROFILE"
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]Params{
+		// OWASP minimum for login: 19 MiB, 2 iterations, 1 lane
+		"interactive": {Memory: 19 * 1024, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32, Profile: "interactive"},
+		// RFC 9106 second recommended option: 64 MiB, 3 iterations, 4 lanes
+		"moderate": {Memory: 64 * 1024, Iterations: 3, Parallelism: 4, SaltLength: 16, KeyLength: 32, Profile: "moderate"},
+		// For rarely used secrets such as key derivation of encrypted backups: 256 MiB, 4 iterations, 4 lanes
+		"sensitive": {Memory: 256 * 1024, Iterations: 4, Parallelism: 4, SaltLength: 16, KeyLength: 32, Profile: "sensitive"},
+	}
+)
+
+// RegisterProfile adds or replaces named params profile
+func RegisterProfile(name string, params Params) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	params.Profile = name
+	profiles[name] = params
+}
+
+// Profile returns copy of named params, hashes created with it record the profile name
+func Profile(name string) (*Params, error) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	params, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("argon2id : unknown profile %q", name)
+	}
+	return &params, nil
+}
+
+// ProfileFromEnv returns profile named by NEST_ARGON2ID_PROFILE, DefaultParams when unset
+func ProfileFromEnv() (*Params, error) {
+	name := os.Getenv(ProfileEnv)
+	if name == "" {
+		return DefaultParams, nil
+	}
+	return Profile(name)
+}
+
+// ProfileNames returns registered profile names
+func ProfileNames() []string {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
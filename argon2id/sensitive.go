@@ -0,0 +1,15 @@
+package argon2id
+
+import "github.com/rikiihsan/nest/secure"
+
+// CreateHashAndZero hashes password and zeroes it afterwards
+func CreateHashAndZero(password secure.Sensitive, params *Params) (string, error) {
+	defer password.Zero()
+	return createHash(password.Bytes(), params)
+}
+
+// CompareAndZero compares password with hash and zeroes it afterwards, nil or empty hash verifies against DummyHash
+func CompareAndZero(password secure.Sensitive, hash *string) (bool, error) {
+	defer password.Zero()
+	return verifyOrDummy(password.Bytes(), hash)
+}
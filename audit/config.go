@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/uptrace/bun"
+)
+
+// Entry represents persisted audit record
+type Entry struct {
+	bun.BaseModel `bun:"table:audit_logs,alias:al"`
+
+	ID         int64     `bun:",pk,autoincrement" json:"id"`
+	Actor      string    `bun:",nullzero" json:"actor,omitempty"`
+	Action     string    `bun:",notnull" json:"action"`
+	Resource   string    `bun:",notnull" json:"resource"`
+	ResourceID string    `bun:",nullzero" json:"resource_id,omitempty"`
+	Before     string    `bun:",nullzero" json:"before,omitempty"`
+	After      string    `bun:",nullzero" json:"after,omitempty"`
+	Diff       string    `bun:",nullzero" json:"diff,omitempty"`
+	IP         string    `bun:",nullzero" json:"ip,omitempty"`
+	RequestID  string    `bun:",nullzero" json:"request_id,omitempty"`
+	Metadata   string    `bun:",nullzero" json:"metadata,omitempty"`
+	CreatedAt  time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// Event describes auditable change, Before and After are encoded as JSON
+type Event struct {
+	Actor      string
+	Action     string
+	Resource   string
+	ResourceID string
+	Before     interface{}
+	After      interface{}
+	Metadata   map[string]interface{}
+}
+
+// Change represents single field difference
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Sink persists audit entries
+type Sink interface {
+	Write(ctx context.Context, entry *Entry) error
+}
+
+// SinkFunc adapts function to Sink
+type SinkFunc func(ctx context.Context, entry *Entry) error
+
+func (f SinkFunc) Write(ctx context.Context, entry *Entry) error {
+	return f(ctx, entry)
+}
+
+// Config represents audit configuration
+type Config struct {
+	Sink Sink
+	// Actor resolves acting user of request
+	Actor func(c *fiber.Ctx) string
+	// Methods captured by middleware, defaults to POST, PUT, PATCH and DELETE
+	Methods []string
+	// SkipPaths are not captured by middleware
+	SkipPaths []string
+	// RequestIDLocal is fiber locals key holding request ID
+	RequestIDLocal string
+	// OnError is called when sink fails, defaults to logging
+	OnError func(err error)
+}
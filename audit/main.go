@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/queue"
+	"github.com/rikiihsan/nest/requestctx"
+	"github.com/uptrace/bun"
+)
+
+// JobType is queue job type used by QueueSink
+const JobType = "audit:write"
+
+var ErrNoSink = errors.New("audit : sink is not configured")
+
+var config = Config{
+	Methods:        []string{"POST", "PUT", "PATCH", "DELETE"},
+	RequestIDLocal: requestctx.RequestID.Name(),
+}
+
+// Init sets audit configuration
+func Init(cfg Config) {
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = config.Methods
+	}
+	if cfg.RequestIDLocal == "" {
+		cfg.RequestIDLocal = config.RequestIDLocal
+	}
+	config = cfg
+}
+
+// CreateTable creates audit table if it does not exist
+func CreateTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().Model((*Entry)(nil)).IfNotExists().Exec(ctx)
+	return err
+}
+
+// DBSink writes entries to audit table of session
+func DBSink(session string) Sink {
+	return SinkFunc(func(ctx context.Context, entry *Entry) error {
+		db, err := database.GetDB(session)
+		if err != nil {
+			return err
+		}
+		_, err = db.NewInsert().Model(entry).Exec(ctx)
+		return err
+	})
+}
+
+// QueueSink enqueues entries and registers handler writing them to target sink
+func QueueSink(target Sink, opts ...queue.Option) Sink {
+	queue.Register(JobType, func(ctx context.Context, job *queue.Job) error {
+		var entry Entry
+		if err := job.Bind(&entry); err != nil {
+			return err
+		}
+		return target.Write(ctx, &entry)
+	})
+
+	return SinkFunc(func(ctx context.Context, entry *Entry) error {
+		_, err := queue.Enqueue(ctx, JobType, entry, opts...)
+		return err
+	})
+}
+
+// Log records event, request metadata is taken from ctx when present
+func Log(ctx context.Context, event Event) error {
+	if config.Sink == nil {
+		return ErrNoSink
+	}
+
+	entry := &Entry{
+		Actor:      event.Actor,
+		Action:     event.Action,
+		Resource:   event.Resource,
+		ResourceID: event.ResourceID,
+		CreatedAt:  time.Now(),
+	}
+
+	if meta, ok := requestFromContext(ctx); ok {
+		if entry.Actor == "" {
+			entry.Actor = meta.Actor
+		}
+		entry.IP = meta.IP
+		entry.RequestID = meta.RequestID
+	}
+
+	var err error
+	if entry.Before, err = encode(event.Before); err != nil {
+		return err
+	}
+	if entry.After, err = encode(event.After); err != nil {
+		return err
+	}
+	if entry.Metadata, err = encode(event.Metadata); err != nil {
+		return err
+	}
+	if event.Before != nil || event.After != nil {
+		changes, err := Diff(event.Before, event.After)
+		if err != nil {
+			return err
+		}
+		if entry.Diff, err = encode(changes); err != nil {
+			return err
+		}
+	}
+
+	return config.Sink.Write(ctx, entry)
+}
+
+func encode(v interface{}) (string, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Map && reflect.ValueOf(v).Len() == 0) {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit value: %w", err)
+	}
+	return string(data), nil
+}
+
+// toMap converts value to map of top-level JSON fields
+func toMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return map[string]interface{}{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("audit value must encode to JSON object: %w", err)
+	}
+	return out, nil
+}
+
+// Diff returns top-level JSON fields that differ between before and after
+func Diff(before, after interface{}) (map[string]Change, error) {
+	from, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for key, value := range from {
+		if other, ok := to[key]; !ok || !reflect.DeepEqual(value, other) {
+			changes[key] = Change{From: value, To: to[key]}
+		}
+	}
+	for key, value := range to {
+		if _, ok := from[key]; !ok {
+			changes[key] = Change{To: value}
+		}
+	}
+	return changes, nil
+}
+
+func reportError(err error) {
+	if config.OnError != nil {
+		config.OnError(err)
+		return
+	}
+	slog.Error("failed to write audit entry", "error", err)
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type contextKey struct{}
+
+const changeLocal = "audit_change"
+
+// requestMeta holds request data attached to audit entries
+type requestMeta struct {
+	Actor     string
+	IP        string
+	RequestID string
+}
+
+func requestFromContext(ctx context.Context) (requestMeta, bool) {
+	meta, ok := ctx.Value(contextKey{}).(requestMeta)
+	return meta, ok
+}
+
+// SetChange attaches before/after state to request captured by Middleware
+func SetChange(c *fiber.Ctx, resourceID string, before, after interface{}) {
+	c.Locals(changeLocal, &Event{ResourceID: resourceID, Before: before, After: after})
+}
+
+// Middleware captures mutating requests and exposes request metadata to Log via UserContext
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, path := range config.SkipPaths {
+			if strings.HasPrefix(c.Path(), path) {
+				return c.Next()
+			}
+		}
+
+		meta := requestMeta{IP: c.IP()}
+		if id, ok := c.Locals(config.RequestIDLocal).(string); ok {
+			meta.RequestID = id
+		}
+		if config.Actor != nil {
+			meta.Actor = config.Actor(c)
+		}
+		c.SetUserContext(context.WithValue(c.UserContext(), contextKey{}, meta))
+
+		err := c.Next()
+
+		if !captured(c.Method()) || err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest {
+			return err
+		}
+
+		// Actor may be resolved by auth middleware running after this one
+		if config.Actor != nil && meta.Actor == "" {
+			meta.Actor = config.Actor(c)
+			c.SetUserContext(context.WithValue(c.UserContext(), contextKey{}, meta))
+		}
+
+		event := Event{
+			Action:     c.Method(),
+			Resource:   c.Route().Path,
+			ResourceID: c.Params("id"),
+		}
+		if change, ok := c.Locals(changeLocal).(*Event); ok {
+			if change.ResourceID != "" {
+				event.ResourceID = change.ResourceID
+			}
+			event.Before = change.Before
+			event.After = change.After
+		}
+
+		if logErr := Log(c.UserContext(), event); logErr != nil {
+			reportError(logErr)
+		}
+		return nil
+	}
+}
+
+func captured(method string) bool {
+	for _, m := range config.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package authz
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/uptrace/bun"
+)
+
+// Subject represents entity permissions are checked for
+type Subject interface {
+	SubjectID() string
+}
+
+// Policy decides permission in code, e.g. ownership checks
+type Policy func(ctx context.Context, user Subject) (bool, error)
+
+// Role represents named set of permissions
+type Role struct {
+	bun.BaseModel `bun:"table:authz_roles,alias:r"`
+
+	ID          int64         `bun:",pk,autoincrement" json:"id"`
+	Name        string        `bun:",notnull,unique" json:"name"`
+	Permissions []*Permission `bun:"m2m:authz_role_permissions,join:Role=Permission" json:"permissions,omitempty"`
+	CreatedAt   time.Time     `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// Permission represents "resource:action" permission
+type Permission struct {
+	bun.BaseModel `bun:"table:authz_permissions,alias:p"`
+
+	ID   int64  `bun:",pk,autoincrement" json:"id"`
+	Name string `bun:",notnull,unique" json:"name"`
+}
+
+// RolePermission links roles and permissions
+type RolePermission struct {
+	bun.BaseModel `bun:"table:authz_role_permissions,alias:rp"`
+
+	RoleID       int64       `bun:",pk"`
+	Role         *Role       `bun:"rel:belongs-to,join:role_id=id"`
+	PermissionID int64       `bun:",pk"`
+	Permission   *Permission `bun:"rel:belongs-to,join:permission_id=id"`
+}
+
+// UserRole links subjects and roles
+type UserRole struct {
+	bun.BaseModel `bun:"table:authz_user_roles,alias:ur"`
+
+	SubjectID string `bun:",pk"`
+	RoleID    int64  `bun:",pk"`
+}
+
+// Config represents authorization configuration
+type Config struct {
+	// Session is database session storing roles
+	Session string
+	// CacheTTL of Redis cached permission lookups, zero disables cache
+	CacheTTL time.Duration
+	// Subject resolves authenticated subject of request
+	Subject func(c *fiber.Ctx) (Subject, bool)
+}
@@ -0,0 +1,346 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+)
+
+const cachePrefix = "nest:authz:"
+
+var (
+	config = Config{Session: "default"}
+
+	mu       sync.RWMutex
+	policies = make(map[string]Policy)
+	before   []Policy
+)
+
+func init() {
+	// Needed for m2m relation queries
+	database.RegisterModel((*RolePermission)(nil))
+}
+
+// Init sets authorization configuration
+func Init(cfg Config) {
+	if cfg.Session == "" {
+		cfg.Session = "default"
+	}
+	config = cfg
+}
+
+// Define registers code policy consulted when roles do not grant permission
+func Define(permission string, policy Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	policies[permission] = policy
+}
+
+// Before registers policy granting every permission when it returns true, e.g. super admins
+func Before(policy Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	before = append(before, policy)
+}
+
+func db() (*bun.DB, error) {
+	return database.GetDB(config.Session)
+}
+
+// CreateTables creates authorization tables if they do not exist
+func CreateTables(ctx context.Context, db bun.IDB) error {
+	models := []interface{}{(*Role)(nil), (*Permission)(nil), (*RolePermission)(nil), (*UserRole)(nil)}
+	for _, model := range models {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateRole creates role if missing and grants permissions
+func CreateRole(ctx context.Context, name string, permissions ...string) (*Role, error) {
+	d, err := db()
+	if err != nil {
+		return nil, err
+	}
+
+	role := &Role{Name: name}
+	err = d.NewSelect().Model(role).Where("name = ?", name).Scan(ctx)
+	if database.IsNotFound(err) {
+		_, err = d.NewInsert().Model(role).Exec(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(permissions) > 0 {
+		if err := GrantPermission(ctx, name, permissions...); err != nil {
+			return nil, err
+		}
+	}
+	return role, nil
+}
+
+func findRole(ctx context.Context, d bun.IDB, name string) (*Role, error) {
+	role := new(Role)
+	if err := d.NewSelect().Model(role).Where("name = ?", name).Scan(ctx); err != nil {
+		if database.IsNotFound(err) {
+			return nil, fmt.Errorf("authz : role '%s' not found", name)
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// GrantPermission grants permissions to role
+func GrantPermission(ctx context.Context, roleName string, permissions ...string) error {
+	err := database.WithTransaction(ctx, config.Session, func(tx bun.Tx) error {
+		role, err := findRole(ctx, tx, roleName)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range permissions {
+			perm := &Permission{Name: name}
+			err := tx.NewSelect().Model(perm).Where("name = ?", name).Scan(ctx)
+			if database.IsNotFound(err) {
+				_, err = tx.NewInsert().Model(perm).Exec(ctx)
+			}
+			if err != nil {
+				return err
+			}
+
+			exists, err := tx.NewSelect().Model((*RolePermission)(nil)).
+				Where("role_id = ? AND permission_id = ?", role.ID, perm.ID).
+				Exists(ctx)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				link := &RolePermission{RoleID: role.ID, PermissionID: perm.ID}
+				if _, err := tx.NewInsert().Model(link).Exec(ctx); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return invalidateAll(ctx)
+}
+
+// RevokePermission revokes permissions from role
+func RevokePermission(ctx context.Context, roleName string, permissions ...string) error {
+	d, err := db()
+	if err != nil {
+		return err
+	}
+	role, err := findRole(ctx, d, roleName)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.NewDelete().Model((*RolePermission)(nil)).
+		Where("role_id = ?", role.ID).
+		Where("permission_id IN (?)", d.NewSelect().Model((*Permission)(nil)).Column("id").Where("name IN (?)", bun.In(permissions))).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return invalidateAll(ctx)
+}
+
+// AssignRole assigns role to subject
+func AssignRole(ctx context.Context, subjectID, roleName string) error {
+	d, err := db()
+	if err != nil {
+		return err
+	}
+	role, err := findRole(ctx, d, roleName)
+	if err != nil {
+		return err
+	}
+
+	exists, err := d.NewSelect().Model((*UserRole)(nil)).
+		Where("subject_id = ? AND role_id = ?", subjectID, role.ID).
+		Exists(ctx)
+	if err != nil || exists {
+		return err
+	}
+	if _, err := d.NewInsert().Model(&UserRole{SubjectID: subjectID, RoleID: role.ID}).Exec(ctx); err != nil {
+		return err
+	}
+	return Invalidate(ctx, subjectID)
+}
+
+// RemoveRole removes role from subject
+func RemoveRole(ctx context.Context, subjectID, roleName string) error {
+	d, err := db()
+	if err != nil {
+		return err
+	}
+	role, err := findRole(ctx, d, roleName)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.NewDelete().Model((*UserRole)(nil)).
+		Where("subject_id = ? AND role_id = ?", subjectID, role.ID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return Invalidate(ctx, subjectID)
+}
+
+// Roles returns role names of subject
+func Roles(ctx context.Context, subjectID string) ([]string, error) {
+	d, err := db()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	err = d.NewSelect().
+		Model((*Role)(nil)).
+		Column("r.name").
+		Join("JOIN authz_user_roles AS ur ON ur.role_id = r.id").
+		Where("ur.subject_id = ?", subjectID).
+		Scan(ctx, &names)
+	return names, err
+}
+
+// Permissions returns permission names granted to subject through roles
+func Permissions(ctx context.Context, subjectID string) ([]string, error) {
+	// Key is resolved once before the query, a revocation bumping the version meanwhile then
+	// leaves the loaded permissions under the old version instead of caching them under the new one
+	key, cacheable := cacheKey(ctx, subjectID)
+	if cacheable {
+		if perms, ok := cached(ctx, key); ok {
+			return perms, nil
+		}
+	}
+
+	d, err := db()
+	if err != nil {
+		return nil, err
+	}
+
+	var perms []string
+	err = d.NewSelect().
+		Model((*Permission)(nil)).
+		ColumnExpr("DISTINCT p.name").
+		Join("JOIN authz_role_permissions AS rp ON rp.permission_id = p.id").
+		Join("JOIN authz_user_roles AS ur ON ur.role_id = rp.role_id").
+		Where("ur.subject_id = ?", subjectID).
+		Scan(ctx, &perms)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		store(ctx, key, perms)
+	}
+	return perms, nil
+}
+
+// Can reports whether user has permission through roles, before hooks or defined policy
+func Can(ctx context.Context, user Subject, permission string) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	mu.RLock()
+	hooks := append([]Policy(nil), before...)
+	policy, hasPolicy := policies[permission]
+	mu.RUnlock()
+
+	for _, hook := range hooks {
+		allowed, err := hook(ctx, user)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+
+	perms, err := Permissions(ctx, user.SubjectID())
+	if err != nil {
+		return false, err
+	}
+	for _, granted := range perms {
+		if Match(granted, permission) {
+			return true, nil
+		}
+	}
+
+	if hasPolicy {
+		return policy(ctx, user)
+	}
+	return false, nil
+}
+
+// Match reports whether granted permission covers required, supporting "*" and "resource:*"
+func Match(granted, required string) bool {
+	if granted == "*" || granted == required {
+		return true
+	}
+	if strings.HasSuffix(granted, ":*") {
+		return strings.HasPrefix(required, strings.TrimSuffix(granted, "*"))
+	}
+	return false
+}
+
+// cacheKey includes global version so role permission changes invalidate every subject
+func cacheKey(ctx context.Context, subjectID string) (string, bool) {
+	if database.RedisClient == nil || config.CacheTTL <= 0 {
+		return "", false
+	}
+	version, err := database.RedisClient.Get(ctx, cachePrefix+"version").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", false
+	}
+	return fmt.Sprintf("%sv%s:perms:%s", cachePrefix, version, subjectID), true
+}
+
+func cached(ctx context.Context, key string) ([]string, bool) {
+	data, err := database.RedisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	var perms []string
+	if err := json.Unmarshal(data, &perms); err != nil {
+		return nil, false
+	}
+	return perms, true
+}
+
+func store(ctx context.Context, key string, perms []string) {
+	if data, err := json.Marshal(perms); err == nil {
+		database.RedisClient.Set(ctx, key, data, config.CacheTTL)
+	}
+}
+
+// Invalidate drops cached permissions of subject
+func Invalidate(ctx context.Context, subjectID string) error {
+	key, ok := cacheKey(ctx, subjectID)
+	if !ok {
+		return nil
+	}
+	return database.RedisClient.Del(ctx, key).Err()
+}
+
+func invalidateAll(ctx context.Context) error {
+	if database.RedisClient == nil || config.CacheTTL <= 0 {
+		return nil
+	}
+	return database.RedisClient.Incr(ctx, cachePrefix+"version").Err()
+}
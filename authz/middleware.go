@@ -0,0 +1,38 @@
+package authz
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/apperror"
+	"github.com/rikiihsan/nest/requestctx"
+)
+
+// subjectFromCtx resolves subject using configured resolver or requestctx.User
+func subjectFromCtx(c *fiber.Ctx) (Subject, bool) {
+	if config.Subject != nil {
+		return config.Subject(c)
+	}
+	user, _ := requestctx.User.From(c)
+	subject, ok := user.(Subject)
+	return subject, ok
+}
+
+// Require returns middleware allowing request only when subject has all permissions
+func Require(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := subjectFromCtx(c)
+		if !ok || user == nil {
+			return apperror.ErrUnauthorized
+		}
+
+		for _, permission := range permissions {
+			allowed, err := Can(c.UserContext(), user, permission)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return apperror.ErrForbidden.WithMessage("missing permission " + permission)
+			}
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,161 @@
+package bloom
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+)
+
+var ErrNoRedis = errors.New("bloom : redis client is not initialized")
+
+// Prefix is prepended to all filter keys
+var Prefix = "nest:bloom:"
+
+// Defaults of filters created by package functions
+var (
+	DefaultCapacity  int64   = 1_000_000
+	DefaultErrorRate float64 = 0.01
+)
+
+// native is 0 when unknown, 1 when RedisBloom is available and -1 when the bitset fallback is used
+var native atomic.Int32
+
+// setScript sets all bit positions of ARGV on KEYS[1]
+var setScript = redis.NewScript(`
+for i = 1, #ARGV do
+	redis.call("SETBIT", KEYS[1], ARGV[i], 1)
+end
+return 1
+`)
+
+// testScript returns 1 when all bit positions of ARGV are set on KEYS[1]
+var testScript = redis.NewScript(`
+for i = 1, #ARGV do
+	if redis.call("GETBIT", KEYS[1], ARGV[i]) == 0 then
+		return 0
+	end
+end
+return 1
+`)
+
+// Filter represents Bloom filter sized for Capacity items at ErrorRate false positives
+type Filter struct {
+	Key       string
+	Capacity  int64
+	ErrorRate float64
+}
+
+// New creates filter on key
+func New(key string, capacity int64, errorRate float64) *Filter {
+	return &Filter{Key: key, Capacity: capacity, ErrorRate: errorRate}
+}
+
+// Add adds items to filter with default sizing
+func Add(ctx context.Context, key string, items ...string) error {
+	return New(key, DefaultCapacity, DefaultErrorRate).Add(ctx, items...)
+}
+
+// MightContain reports whether item may have been added to filter with default sizing
+func MightContain(ctx context.Context, key string, item string) (bool, error) {
+	return New(key, DefaultCapacity, DefaultErrorRate).MightContain(ctx, item)
+}
+
+// Add adds items, using BF.RESERVE/BF.MADD when RedisBloom is loaded and a bitset otherwise
+func (f *Filter) Add(ctx context.Context, items ...string) error {
+	if database.RedisClient == nil {
+		return ErrNoRedis
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	if native.Load() >= 0 {
+		err := f.reserve(ctx)
+		if err == nil {
+			args := []interface{}{"BF.MADD", Prefix + f.Key}
+			for _, item := range items {
+				args = append(args, item)
+			}
+			err = database.RedisClient.Do(ctx, args...).Err()
+		}
+		if !unknownCommand(err) {
+			native.Store(1)
+			return err
+		}
+		native.Store(-1)
+	}
+
+	var positions []interface{}
+	for _, item := range items {
+		positions = append(positions, f.positions(item)...)
+	}
+	return setScript.Run(ctx, database.RedisClient, []string{Prefix + f.Key + ":bits"}, positions...).Err()
+}
+
+// MightContain reports whether item may have been added, false means it was definitely not
+func (f *Filter) MightContain(ctx context.Context, item string) (bool, error) {
+	if database.RedisClient == nil {
+		return false, ErrNoRedis
+	}
+
+	if native.Load() >= 0 {
+		n, err := database.RedisClient.Do(ctx, "BF.EXISTS", Prefix+f.Key, item).Int()
+		if !unknownCommand(err) {
+			native.Store(1)
+			return n == 1, err
+		}
+		native.Store(-1)
+	}
+
+	n, err := testScript.Run(ctx, database.RedisClient, []string{Prefix + f.Key + ":bits"}, f.positions(item)...).Int()
+	return n == 1, err
+}
+
+// reserve creates native filter with sizing, existing filters are kept
+func (f *Filter) reserve(ctx context.Context) error {
+	err := database.RedisClient.Do(ctx, "BF.RESERVE", Prefix+f.Key, f.ErrorRate, f.Capacity).Err()
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "exists") {
+		return nil
+	}
+	return err
+}
+
+// size returns bit count and hash count of the fallback filter
+func (f *Filter) size() (uint64, int) {
+	n := float64(max(f.Capacity, 1))
+	p := f.ErrorRate
+	if p <= 0 || p >= 1 {
+		p = DefaultErrorRate
+	}
+	m := math.Ceil(-n * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := int(math.Max(1, math.Round(m/n*math.Ln2)))
+	return uint64(m), k
+}
+
+// positions returns k bit positions of item using double hashing
+func (f *Filter) positions(item string) []interface{} {
+	m, k := f.size()
+
+	h := fnv.New64a()
+	h.Write([]byte(item))
+	h1 := h.Sum64()
+	h.Write([]byte{0})
+	h2 := h.Sum64() | 1
+
+	positions := make([]interface{}, k)
+	for i := 0; i < k; i++ {
+		positions[i] = (h1 + uint64(i)*h2) % m
+	}
+	return positions
+}
+
+// unknownCommand reports whether err means RedisBloom module is not loaded
+func unknownCommand(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "unknown command")
+}
@@ -0,0 +1,55 @@
+package cache
+
+import "time"
+
+// Config represents cache configuration
+type Config struct {
+	// Prefix is prepended to all cache keys
+	Prefix string
+	// DefaultTTL is used when Set is called with zero TTL
+	DefaultTTL time.Duration
+	// Name labels hit/miss metrics
+	Name string
+}
+
+// DefaultConfig is used until Init is called
+var DefaultConfig = Config{
+	Prefix:     "nest:cache:",
+	DefaultTTL: 5 * time.Minute,
+	Name:       "redis",
+}
+
+var config = DefaultConfig
+
+// Init sets global cache configuration, empty fields fall back to DefaultConfig
+func Init(cfg Config) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultConfig.Prefix
+	}
+	if cfg.DefaultTTL <= 0 {
+		cfg.DefaultTTL = DefaultConfig.DefaultTTL
+	}
+	if cfg.Name == "" {
+		cfg.Name = DefaultConfig.Name
+	}
+	config = cfg
+}
+
+// GetConfig returns current cache configuration
+func GetConfig() Config {
+	return config
+}
+
+// Option configures Set
+type Option func(*options)
+
+type options struct {
+	tags []string
+}
+
+// WithTags associates entry with tags flushed together by InvalidateTags
+func WithTags(tags ...string) Option {
+	return func(o *options) {
+		o.tags = append(o.tags, tags...)
+	}
+}
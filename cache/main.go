@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/logger"
+	"github.com/rikiihsan/nest/metrics"
+)
+
+var (
+	ErrNoRedis = errors.New("cache : redis client is not initialized")
+	ErrMiss    = errors.New("cache : key not found")
+)
+
+// invalidateScript deletes members of tag sets and the sets, returning deleted entries
+var invalidateScript = redis.NewScript(`
+local deleted = 0
+for _, tag in ipairs(KEYS) do
+	local keys = redis.call("SMEMBERS", tag)
+	for i = 1, #keys, 500 do
+		deleted = deleted + redis.call("DEL", unpack(keys, i, math.min(i + 499, #keys)))
+	end
+	redis.call("DEL", tag)
+end
+return deleted
+`)
+
+func client() (*redis.Client, error) {
+	if database.RedisClient == nil {
+		return nil, ErrNoRedis
+	}
+	return database.RedisClient, nil
+}
+
+// Key returns prefixed redis key of cache entry
+func Key(key string) string {
+	return config.Prefix + key
+}
+
+// tagKey returns redis key of tag set
+func tagKey(tag string) string {
+	return config.Prefix + "tag:" + tag
+}
+
+// Get decodes cached value of key into dst, returns ErrMiss when absent
+func Get(ctx context.Context, key string, dst interface{}) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+
+	raw, err := rdb.Get(ctx, Key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		metrics.CacheMiss(config.Name)
+		return ErrMiss
+	}
+	if err != nil {
+		return err
+	}
+	metrics.CacheHit(config.Name)
+	return json.Unmarshal(raw, dst)
+}
+
+// Set stores JSON encoded value for ttl, zero ttl uses Config.DefaultTTL
+func Set(ctx context.Context, key string, value interface{}, ttl time.Duration, opts ...Option) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		ttl = config.DefaultTTL
+	}
+
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value: %w", err)
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.Set(ctx, Key(key), raw, ttl)
+	for _, tag := range o.tags {
+		// Tag set lives as long as its longest entry
+		pipe.SAdd(ctx, tagKey(tag), Key(key))
+		pipe.ExpireNX(ctx, tagKey(tag), ttl)
+		pipe.ExpireGT(ctx, tagKey(tag), ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Delete removes cached keys
+func Delete(ctx context.Context, keys ...string) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = Key(key)
+	}
+	return rdb.Del(ctx, prefixed...).Err()
+}
+
+// InvalidateTags removes all entries stored with any of tags, returning deleted entry count
+func InvalidateTags(ctx context.Context, tags ...string) (int64, error) {
+	rdb, err := client()
+	if err != nil {
+		return 0, err
+	}
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagKey(tag)
+	}
+	return invalidateScript.Run(ctx, rdb, keys).Int64()
+}
+
+// Remember returns cached value of key, otherwise stores and returns result of fn. Cache errors, e.g.
+// a Redis outage, are logged and treated as misses so only errors of fn are returned
+func Remember[T any](ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	var value T
+	err := Get(ctx, key, &value)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, ErrMiss) {
+		logger.Get().WarnContext(ctx, "cache read failed", "key", key, "error", err)
+	}
+
+	value, err = fn(ctx)
+	if err != nil {
+		return value, err
+	}
+	if err := Set(ctx, key, value, ttl, opts...); err != nil {
+		logger.Get().WarnContext(ctx, "cache write failed", "key", key, "error", err)
+	}
+	return value, nil
+}
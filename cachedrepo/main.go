@@ -0,0 +1,167 @@
+package cachedrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/rikiihsan/nest/cache"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/database/repository"
+	"github.com/rikiihsan/nest/logger"
+	"github.com/rikiihsan/nest/metrics"
+)
+
+// Cache stores entries of Repository, Get returns cache.ErrMiss for absent keys
+type Cache interface {
+	Get(ctx context.Context, key string, dst interface{}) error
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Redis is Cache backed by the cache package
+var Redis Cache = redisCache{}
+
+type redisCache struct{}
+
+func (redisCache) Get(ctx context.Context, key string, dst interface{}) error {
+	return cache.Get(ctx, key, dst)
+}
+
+func (redisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return cache.Set(ctx, key, value, ttl)
+}
+
+func (redisCache) Delete(ctx context.Context, keys ...string) error {
+	return cache.Delete(ctx, keys...)
+}
+
+// entry is cached lookup, missing rows are cached too so Exists stays cheap
+type entry[T any] struct {
+	Found bool `json:"found"`
+	Value *T   `json:"value,omitempty"`
+}
+
+// Repository caches primary key lookups of wrapped repository and invalidates them on its writes,
+// including writes made through the wrapped repository directly, e.g. by nest.Service.
+// Other methods go straight to the database through the embedded repository
+type Repository[T any] struct {
+	*repository.Repository[T]
+
+	cache     Cache
+	ttl       time.Duration
+	namespace string
+}
+
+// Wrap returns caching repository, keys are namespaced by the Go type of T. It registers a write
+// hook on repo, so call it before repo is used
+func Wrap[T any](repo *repository.Repository[T], c Cache, ttl time.Duration) *Repository[T] {
+	r := &Repository[T]{
+		Repository: repo,
+		cache:      c,
+		ttl:        ttl,
+		namespace:  reflect.TypeOf((*T)(nil)).Elem().String(),
+	}
+	repo.OnWrite(func(ctx context.Context, ids []interface{}) {
+		if err := r.Forget(ctx, ids...); err != nil {
+			logger.Get().WarnContext(ctx, "repository cache invalidation failed", "error", err)
+		}
+	})
+	return r
+}
+
+// key returns cache key of primary key value
+func (r *Repository[T]) key(id interface{}) string {
+	return "repo:" + r.namespace + ":" + fmt.Sprint(id)
+}
+
+// lookup returns cached entry of id, loading it on miss. Transactions bypass the cache
+func (r *Repository[T]) lookup(ctx context.Context, id interface{}) (entry[T], error) {
+	if _, ok := database.TxFromContext(ctx, r.Session); ok {
+		return r.load(ctx, id)
+	}
+
+	key := r.key(id)
+	var cached entry[T]
+	err := r.cache.Get(ctx, key, &cached)
+	if err == nil {
+		metrics.CacheHit("repo:" + r.namespace)
+		return cached, nil
+	}
+	metrics.CacheMiss("repo:" + r.namespace)
+	if !errors.Is(err, cache.ErrMiss) {
+		logger.Get().WarnContext(ctx, "repository cache lookup failed", "key", key, "error", err)
+	}
+
+	loaded, err := r.load(ctx, id)
+	if err != nil {
+		return loaded, err
+	}
+	if err := r.cache.Set(ctx, key, loaded, r.ttl); err != nil {
+		logger.Get().WarnContext(ctx, "repository cache store failed", "key", key, "error", err)
+	}
+	return loaded, nil
+}
+
+// load reads entity from transaction of ctx or primary, a lagging replica could otherwise put
+// a row back into the cache right after Forget and keep it there for the TTL
+func (r *Repository[T]) load(ctx context.Context, id interface{}) (entry[T], error) {
+	db, err := r.DB(ctx)
+	if err != nil {
+		return entry[T]{}, err
+	}
+	entity := new(T)
+	err = db.NewSelect().Model(entity).Where("?PKs = ?", id).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entry[T]{}, nil
+	}
+	if err != nil {
+		return entry[T]{}, err
+	}
+	return entry[T]{Found: true, Value: entity}, nil
+}
+
+// Find returns entity by primary key from cache, sql.ErrNoRows when missing
+func (r *Repository[T]) Find(ctx context.Context, id interface{}) (*T, error) {
+	cached, err := r.lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !cached.Found {
+		return nil, sql.ErrNoRows
+	}
+	return cached.Value, nil
+}
+
+// ExistsByID reports whether entity with primary key exists
+func (r *Repository[T]) ExistsByID(ctx context.Context, id interface{}) (bool, error) {
+	cached, err := r.lookup(ctx, id)
+	return cached.Found, err
+}
+
+// Forget invalidates cache entries of primary keys now and again after commit of the
+// transaction in ctx, so values cached by concurrent readers meanwhile do not survive
+func (r *Repository[T]) Forget(ctx context.Context, ids ...interface{}) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.key(id)
+	}
+
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	if _, ok := database.TxFromContext(ctx, r.Session); ok {
+		database.AfterCommit(ctx, func(ctx context.Context) {
+			if err := r.cache.Delete(ctx, keys...); err != nil {
+				logger.Get().WarnContext(ctx, "repository cache invalidation failed", "error", err)
+			}
+		})
+	}
+	return nil
+}
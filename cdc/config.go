@@ -0,0 +1,99 @@
+package cdc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rikiihsan/nest/events"
+	"github.com/uptrace/bun"
+)
+
+// Operations captured by triggers
+const (
+	OpInsert = "INSERT"
+	OpUpdate = "UPDATE"
+	OpDelete = "DELETE"
+)
+
+// Mode selects how captured changes reach the events bus
+type Mode string
+
+const (
+	// ModeNotify wakes Consumer with LISTEN/NOTIFY, polling as fallback
+	ModeNotify Mode = "notify"
+	// ModeOutbox also writes changes to outbox_events, delivered by outbox.Relay with Publisher
+	ModeOutbox Mode = "outbox"
+)
+
+// Change is row written by the capture trigger for every insert, update and delete
+type Change struct {
+	bun.BaseModel `bun:"table:cdc_changes,alias:cdc"`
+
+	ID          int64           `bun:",pk,autoincrement" json:"id"`
+	Table       string          `bun:"table_name,notnull" json:"table_name"`
+	Op          string          `bun:",notnull" json:"op"`
+	Old         json.RawMessage `bun:"old_data,type:jsonb" json:"old_data,omitempty"`
+	New         json.RawMessage `bun:"new_data,type:jsonb" json:"new_data,omitempty"`
+	CreatedAt   time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	ProcessedAt bun.NullTime    `json:"processed_at"`
+}
+
+// DecodeOld decodes row before update or delete into v
+func (c Change) DecodeOld(v interface{}) error {
+	return json.Unmarshal(c.Old, v)
+}
+
+// DecodeNew decodes row after insert or update into v
+func (c Change) DecodeNew(v interface{}) error {
+	return json.Unmarshal(c.New, v)
+}
+
+// Config represents capture and consumer configuration
+type Config struct {
+	Session string
+	Mode    Mode
+	// Channel is LISTEN/NOTIFY channel of ModeNotify
+	Channel string
+	// TopicPrefix prefixes event topics, changes publish as <prefix><table>.<insert|update|delete>
+	TopicPrefix  string
+	PollInterval time.Duration
+	BatchSize    int
+	// Bus receives changes, defaults to events.Default
+	Bus *events.Bus
+}
+
+// DefaultConfig is used by Install and NewConsumer for empty fields
+var DefaultConfig = Config{
+	Session:      "default",
+	Mode:         ModeNotify,
+	Channel:      "nest_cdc",
+	TopicPrefix:  "cdc.",
+	PollInterval: 5 * time.Second,
+	BatchSize:    100,
+}
+
+// withDefaults fills empty fields from DefaultConfig
+func (cfg Config) withDefaults() Config {
+	if cfg.Session == "" {
+		cfg.Session = DefaultConfig.Session
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = DefaultConfig.Mode
+	}
+	if cfg.Channel == "" {
+		cfg.Channel = DefaultConfig.Channel
+	}
+	if cfg.TopicPrefix == "" {
+		cfg.TopicPrefix = DefaultConfig.TopicPrefix
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultConfig.PollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultConfig.BatchSize
+	}
+	if cfg.Bus == nil {
+		cfg.Bus = events.Default
+	}
+	return cfg
+}
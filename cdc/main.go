@@ -0,0 +1,375 @@
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/events"
+	"github.com/rikiihsan/nest/outbox"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+var (
+	ErrUnsupported = errors.New("cdc : change capture requires PostgreSQL")
+	// ErrOutboxMode is returned by Consumer.Start, ModeOutbox changes are delivered by outbox.Relay with Publisher
+	ErrOutboxMode = errors.New("cdc : consumer is not used in outbox mode")
+	// ErrNoListen is returned by Listen when the session driver cannot hold a LISTEN connection
+	ErrNoListen = errors.New("cdc : session driver does not support LISTEN")
+
+	errNoSession = errors.New("cdc : session not found")
+)
+
+// triggerName is name of the capture trigger on every table
+const triggerName = "nest_cdc"
+
+// captureFunction writes the change, then notifies Channel (TG_ARGV[0]) and writes outbox event
+// with topic prefix TG_ARGV[1] when they are not empty. Changes sent through the outbox are stored processed
+const captureFunction = `CREATE OR REPLACE FUNCTION nest_cdc_capture() RETURNS trigger AS $$
+DECLARE
+	change_id bigint;
+BEGIN
+	INSERT INTO cdc_changes (table_name, op, old_data, new_data, processed_at)
+	VALUES (
+		TG_TABLE_NAME,
+		TG_OP,
+		CASE WHEN TG_OP IN ('UPDATE', 'DELETE') THEN to_jsonb(OLD) END,
+		CASE WHEN TG_OP IN ('INSERT', 'UPDATE') THEN to_jsonb(NEW) END,
+		CASE WHEN TG_ARGV[1] <> '' THEN now() END
+	)
+	RETURNING id INTO change_id;
+
+	IF TG_ARGV[0] <> '' THEN
+		PERFORM pg_notify(TG_ARGV[0], change_id::text);
+	END IF;
+	IF TG_ARGV[1] <> '' THEN
+		INSERT INTO outbox_events (topic, dedupe_key, payload, created_at, available_at)
+		SELECT TG_ARGV[1] || TG_TABLE_NAME || '.' || lower(TG_OP), 'cdc:' || change_id, row_to_json(c)::text, now(), now()
+		FROM cdc_changes c WHERE c.id = change_id;
+	END IF;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql`
+
+// Statements returns SQL installing capture triggers on tables, for use in migrations.
+// The cdc_changes table is created by Install or CreateTable
+func Statements(cfg Config, tables ...string) []string {
+	cfg = cfg.withDefaults()
+	channel, prefix := cfg.Channel, ""
+	if cfg.Mode == ModeOutbox {
+		channel, prefix = "", cfg.TopicPrefix
+	}
+
+	stmts := []string{captureFunction}
+	for _, table := range tables {
+		ident := quoteIdent(table)
+		stmts = append(stmts,
+			"DROP TRIGGER IF EXISTS "+triggerName+" ON "+ident,
+			"CREATE TRIGGER "+triggerName+" AFTER INSERT OR UPDATE OR DELETE ON "+ident+
+				" FOR EACH ROW EXECUTE FUNCTION nest_cdc_capture("+quoteLiteral(channel)+", "+quoteLiteral(prefix)+")",
+		)
+	}
+	return stmts
+}
+
+// DropStatements returns SQL removing capture triggers from tables
+func DropStatements(tables ...string) []string {
+	stmts := make([]string, 0, len(tables))
+	for _, table := range tables {
+		stmts = append(stmts, "DROP TRIGGER IF EXISTS "+triggerName+" ON "+quoteIdent(table))
+	}
+	return stmts
+}
+
+// CreateTable creates cdc_changes table if it does not exist
+func CreateTable(ctx context.Context, db bun.IDB) error {
+	if db.Dialect().Name() != dialect.PG {
+		return ErrUnsupported
+	}
+	if _, err := db.NewCreateTable().Model((*Change)(nil)).IfNotExists().Exec(ctx); err != nil {
+		return err
+	}
+	_, err := db.NewCreateIndex().Model((*Change)(nil)).Index("cdc_changes_pending_idx").
+		IfNotExists().Column("id").Where("processed_at IS NULL").Exec(ctx)
+	return err
+}
+
+// Install creates cdc_changes, the outbox table in ModeOutbox, and capture triggers on tables in one transaction
+func Install(ctx context.Context, cfg Config, tables ...string) error {
+	cfg = cfg.withDefaults()
+	return database.RunInTx(ctx, cfg.Session, func(ctx context.Context) error {
+		db, err := database.IDB(ctx, cfg.Session)
+		if err != nil {
+			return err
+		}
+		if err := CreateTable(ctx, db); err != nil {
+			return err
+		}
+		if cfg.Mode == ModeOutbox {
+			if err := outbox.CreateTable(ctx, db); err != nil {
+				return err
+			}
+		}
+		for _, stmt := range Statements(cfg, tables...) {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("cdc : install: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Uninstall removes capture triggers from tables, captured changes are kept
+func Uninstall(ctx context.Context, cfg Config, tables ...string) error {
+	cfg = cfg.withDefaults()
+	db, err := database.IDB(ctx, cfg.Session)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range DropStatements(tables...) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Topic returns event topic of change
+func Topic(prefix, table, op string) string {
+	return prefix + table + "." + strings.ToLower(op)
+}
+
+// Subscribe registers handler for changes of table on Bus of cfg under its TopicPrefix, pass the Config
+// of the consumer or Install. op is OpInsert, OpUpdate, OpDelete or "*", table may be "*" too.
+// The returned func removes the subscription
+func Subscribe(cfg Config, table, op string, handler func(ctx context.Context, change Change) error) func() {
+	cfg = cfg.withDefaults()
+	if op != "*" {
+		op = strings.ToLower(op)
+	}
+	return cfg.Bus.Subscribe(cfg.TopicPrefix+table+"."+op, func(ctx context.Context, event events.Event) error {
+		change, ok := event.Payload.(Change)
+		if !ok {
+			return fmt.Errorf("cdc : unexpected payload %T on %s", event.Payload, event.Topic)
+		}
+		return handler(ctx, change)
+	})
+}
+
+// Publisher delivers outbox events written by ModeOutbox triggers to the default events bus
+var Publisher = outbox.PublisherFunc(func(ctx context.Context, event *outbox.Event) error {
+	var change Change
+	if err := json.Unmarshal([]byte(event.Payload), &change); err != nil {
+		return fmt.Errorf("cdc : decode change: %w", err)
+	}
+	return events.Publish(ctx, event.Topic, change)
+})
+
+// Consumer publishes pending changes of ModeNotify triggers to the events bus at least once
+type Consumer struct {
+	config Config
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewConsumer creates consumer, see Start
+func NewConsumer(cfg Config) *Consumer {
+	return &Consumer{config: cfg.withDefaults(), wake: make(chan struct{}, 1)}
+}
+
+// Start listens for notifications and processes changes in background, polling every PollInterval
+// so changes are delivered even when notifications are missed
+func (c *Consumer) Start(ctx context.Context) error {
+	if c.config.Mode == ModeOutbox {
+		return ErrOutboxMode
+	}
+	ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		c.listen(ctx)
+	}()
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			for {
+				n, err := c.Process(ctx)
+				if err != nil && ctx.Err() == nil {
+					slog.Error("cdc consumer failed", "error", err)
+				}
+				if err != nil || n < c.config.BatchSize {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-c.wake:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops consumer and waits for the current batch
+func (c *Consumer) Stop(ctx context.Context) error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cdc consumer did not drain: %w", ctx.Err())
+	}
+}
+
+// Process publishes one batch of pending changes in id order and marks them processed.
+// Rows are locked with SKIP LOCKED so consumers of several instances share the work
+func (c *Consumer) Process(ctx context.Context) (int, error) {
+	processed := 0
+	err := database.RunInTx(ctx, c.config.Session, func(ctx context.Context) error {
+		db, err := database.IDB(ctx, c.config.Session)
+		if err != nil {
+			return err
+		}
+		if db.Dialect().Name() != dialect.PG {
+			return ErrUnsupported
+		}
+
+		var changes []Change
+		err = db.NewSelect().
+			Model(&changes).
+			Where("processed_at IS NULL").
+			Order("id ASC").
+			Limit(c.config.BatchSize).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load changes: %w", err)
+		}
+
+		for _, change := range changes {
+			if err := c.config.Bus.Publish(ctx, Topic(c.config.TopicPrefix, change.Table, change.Op), change); err != nil {
+				// Earlier changes of the batch stay processed, this one is retried
+				slog.WarnContext(ctx, "cdc change handler failed", "id", change.ID, "table", change.Table, "error", err)
+				break
+			}
+			_, err := db.NewUpdate().
+				Model((*Change)(nil)).
+				Set("processed_at = ?", time.Now()).
+				Where("id = ?", change.ID).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to mark change %d processed: %w", change.ID, err)
+			}
+			processed++
+		}
+		return nil
+	})
+	return processed, err
+}
+
+// listen holds a LISTEN connection waking Process on notifications, reconnecting after failures
+func (c *Consumer) listen(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := c.Listen(ctx)
+		if errors.Is(err, ErrNoListen) || errors.Is(err, errNoSession) {
+			slog.Warn("cdc consumer polling only", "session", c.config.Session, "error", err)
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("cdc listen failed", "channel", c.config.Channel, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(c.config.PollInterval):
+		}
+	}
+}
+
+// Listen blocks receiving notifications of Channel on a dedicated pgx connection until ctx is done
+func (c *Consumer) Listen(ctx context.Context) error {
+	session, ok := database.GetSession(c.config.Session)
+	if !ok {
+		return fmt.Errorf("%w: %s", errNoSession, c.config.Session)
+	}
+	conn, err := session.SqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		pgConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return ErrNoListen
+		}
+		if _, err := pgConn.Conn().Exec(ctx, "LISTEN "+quoteIdent(c.config.Channel)); err != nil {
+			return err
+		}
+		// The connection returns to the pool, so it must not keep listening
+		defer pgConn.Conn().Exec(context.Background(), "UNLISTEN *")
+
+		for {
+			if _, err := pgConn.Conn().WaitForNotification(ctx); err != nil {
+				return err
+			}
+			select {
+			case c.wake <- struct{}{}:
+			default:
+			}
+		}
+	})
+}
+
+// Purge deletes processed changes older than age
+func Purge(ctx context.Context, db bun.IDB, age time.Duration) (int64, error) {
+	res, err := db.NewDelete().
+		Model((*Change)(nil)).
+		Where("processed_at IS NOT NULL").
+		Where("processed_at < ?", time.Now().Add(-age)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// quoteIdent quotes every part of schema qualified name
+func quoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
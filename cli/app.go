@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest"
+	"github.com/rikiihsan/nest/env"
+	"github.com/rikiihsan/nest/queue"
+	"github.com/rikiihsan/nest/routing"
+	"github.com/spf13/cobra"
+)
+
+func routesCommand(app *nest.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "routes",
+		Short: "List registered HTTP routes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "METHOD\tPATH\tNAME\tVERSION\tHANDLER")
+			for _, route := range app.Fiber.GetRoutes(true) {
+				version := "-"
+				if info, ok := routing.VersionOf(route.Path); ok {
+					version = info.Name
+					if info.Deprecated {
+						version += " (deprecated)"
+					}
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Name, version, handlerName(route.Handlers))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// handlerName returns function name of the last handler of route
+func handlerName(handlers []fiber.Handler) string {
+	if len(handlers) == 0 {
+		return "-"
+	}
+	fn := runtime.FuncForPC(reflect.ValueOf(handlers[len(handlers)-1]).Pointer())
+	if fn == nil {
+		return "-"
+	}
+	return fn.Name()
+}
+
+func envCheckCommand(app *nest.App) *cobra.Command {
+	var example string
+
+	cmd := &cobra.Command{
+		Use:   "env:check",
+		Short: "Verify required environment variables are set",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := app.Config.EnvFiles
+			if len(files) == 0 {
+				if _, err := os.Stat(".env"); err == nil {
+					files = []string{".env"}
+				}
+			}
+			if len(files) > 0 {
+				if err := env.Load(files...); err != nil {
+					return err
+				}
+			}
+
+			var problems []string
+			if app.Config.Bind != nil {
+				if err := env.Bind(app.Config.Bind); err != nil {
+					problems = append(problems, err.Error())
+				}
+			}
+
+			missing, err := missingKeys(example)
+			if err != nil {
+				return err
+			}
+			for _, key := range missing {
+				problems = append(problems, fmt.Sprintf("env: %s from %s is not set", key, example))
+			}
+
+			if len(problems) > 0 {
+				for _, p := range problems {
+					cmd.PrintErrln(p)
+				}
+				return fmt.Errorf("%d environment problem(s) found", len(problems))
+			}
+			cmd.Println("environment OK")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&example, "example", ".env.example", "file listing expected keys")
+	return cmd
+}
+
+// missingKeys returns keys from example file absent in environment
+func missingKeys(example string) ([]string, error) {
+	file, err := os.Open(example)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var missing []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key := strings.SplitN(line, "=", 2)[0]
+		if _, ok := os.LookupEnv(key); !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing, scanner.Err()
+}
+
+func queueWorkCommand(app *nest.App) *cobra.Command {
+	var queues []string
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:   "queue:work",
+		Short: "Process queued jobs until interrupted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				// Flags override the queue config of the application, e.g. set by its Setup
+				cfg := queue.GetConfig()
+				if len(queues) > 0 {
+					cfg.Queues = queues
+				}
+				if concurrency > 0 {
+					cfg.Concurrency = concurrency
+				}
+				if len(queues) > 0 || concurrency > 0 {
+					queue.Init(cfg)
+				}
+
+				cmd.Printf("processing queues %s\n", strings.Join(cfg.Queues, ", "))
+				return queue.Run(ctx)
+			})
+		},
+	}
+	cmd.Flags().StringSliceVar(&queues, "queues", nil, "queues to process")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 0, "number of concurrent jobs")
+	return cmd
+}
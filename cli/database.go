@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rikiihsan/nest"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/database/anonymize"
+	"github.com/rikiihsan/nest/database/migrate"
+	"github.com/rikiihsan/nest/database/schema"
+	"github.com/rikiihsan/nest/database/seed"
+	"github.com/spf13/cobra"
+)
+
+func migrateCommand(app *nest.App) *cobra.Command {
+	var session, dir string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Run database migrations",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				return nil
+			}
+			if err := migrate.Discover(os.DirFS(dir)); err != nil {
+				return fmt.Errorf("failed to discover migrations in %s: %w", dir, err)
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVar(&session, "session", "default", "database session name")
+	cmd.PersistentFlags().StringVar(&dir, "dir", "", "directory with SQL migrations")
+
+	var dryRun bool
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				if dryRun {
+					plans, err := migrate.Plan(ctx, session)
+					if err != nil {
+						return err
+					}
+					printPlan(cmd, plans, "no new migrations to run")
+					return nil
+				}
+
+				group, err := migrate.Up(ctx, session)
+				if err != nil {
+					return err
+				}
+				if group.IsZero() {
+					cmd.Println("no new migrations to run")
+					return nil
+				}
+				cmd.Printf("migrated to %s\n", group)
+				return nil
+			})
+		},
+	}
+	up.Flags().BoolVar(&dryRun, "dry-run", false, "print SQL of pending migrations without applying")
+	cmd.AddCommand(up)
+
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the last migration group",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				if dryRun {
+					plans, err := migrate.PlanDown(ctx, session)
+					if err != nil {
+						return err
+					}
+					printPlan(cmd, plans, "no groups to roll back")
+					return nil
+				}
+
+				group, err := migrate.Down(ctx, session)
+				if err != nil {
+					return err
+				}
+				if group.IsZero() {
+					cmd.Println("no groups to roll back")
+					return nil
+				}
+				cmd.Printf("rolled back %s\n", group)
+				return nil
+			})
+		},
+	}
+	down.Flags().BoolVar(&dryRun, "dry-run", false, "print SQL of the rollback without applying")
+	cmd.AddCommand(down)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show migration status",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				migrations, err := migrate.Status(ctx, session)
+				if err != nil {
+					return err
+				}
+				for _, m := range migrations {
+					status := "pending"
+					if m.IsApplied() {
+						status = fmt.Sprintf("applied (group %d)", m.GroupID)
+					}
+					cmd.Printf("%-40s %s\n", m.Name, status)
+				}
+				return nil
+			})
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "create <name>",
+		Short: "Create up/down SQL migration files",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				files, err := migrate.Create(ctx, session, args[0])
+				if err != nil {
+					return err
+				}
+				for _, f := range files {
+					cmd.Printf("created %s\n", f.Path)
+				}
+				return nil
+			})
+		},
+	})
+
+	return cmd
+}
+
+func schemaDiffCommand(app *nest.App) *cobra.Command {
+	var session string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "schema:diff",
+		Short: "Compare live schema with models registered via database.RegisterModel",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				report, err := schema.Diff(ctx, session, database.RegisteredModels()...)
+				if err != nil {
+					return err
+				}
+
+				if asJSON {
+					encoder := json.NewEncoder(cmd.OutOrStdout())
+					encoder.SetIndent("", "  ")
+					if err := encoder.Encode(report); err != nil {
+						return err
+					}
+				} else if !report.OK() {
+					cmd.Println(report.String())
+				}
+
+				if !report.OK() {
+					return fmt.Errorf("schema drift detected: %d issues", len(report.Issues))
+				}
+				if !asJSON {
+					cmd.Println("schema matches models")
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&session, "session", "default", "database session name")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print report as JSON")
+	return cmd
+}
+
+// printPlan prints planned statements per migration as SQL script
+func printPlan(cmd *cobra.Command, plans []migrate.PlannedMigration, empty string) {
+	if len(plans) == 0 {
+		cmd.Println(empty)
+		return
+	}
+	for _, plan := range plans {
+		cmd.Printf("-- migration: %s\n", plan.Name)
+		if len(plan.Statements) == 0 {
+			cmd.Println("-- no statements")
+		}
+		for _, stmt := range plan.Statements {
+			cmd.Printf("%s;\n", strings.TrimSuffix(stmt, ";"))
+		}
+		cmd.Println()
+	}
+}
+
+func seedCommand(app *nest.App) *cobra.Command {
+	var session string
+
+	cmd := &cobra.Command{
+		Use:   "seed [names...]",
+		Short: "Run registered seeders, all when no names given",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				if err := seed.Run(ctx, session, args...); err != nil {
+					return err
+				}
+				cmd.Println("seeding completed")
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&session, "session", "default", "database session name")
+	return cmd
+}
+
+func anonymizeCommand(app *nest.App) *cobra.Command {
+	var session string
+	var batch int
+
+	cmd := &cobra.Command{
+		Use:   "anonymize [tables...]",
+		Short: "Scrub data with registered anonymize rules, all tables when none given",
+		Long: "Scrub data with registered anonymize rules, all tables when none given.\n" +
+			"Hashed values are keyed with ANONYMIZE_KEY, or a random key per run when it is unset.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				options := anonymize.Options{
+					BatchSize: batch,
+					Key:       []byte(os.Getenv("ANONYMIZE_KEY")),
+					OnProgress: func(p anonymize.Progress) {
+						cmd.Printf("%s: %d/%d\n", p.Table, p.Rows, p.Total)
+					},
+				}
+				if err := anonymize.Run(ctx, session, options, args...); err != nil {
+					return err
+				}
+				cmd.Println("anonymization completed")
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&session, "session", "default", "database session name")
+	cmd.Flags().IntVar(&batch, "batch", 500, "rows per batch")
+	return cmd
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rikiihsan/nest/env"
+	"github.com/spf13/cobra"
+)
+
+func envEncryptCommand() *cobra.Command {
+	var key, out string
+	var generate bool
+
+	cmd := &cobra.Command{
+		Use:   "env:encrypt <file>",
+		Short: "Encrypt env file with AES-256-GCM",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if generate {
+				generated, err := env.GenerateKey()
+				if err != nil {
+					return err
+				}
+				key = generated
+				cmd.PrintErrf("generated key, store it as %s:\n%s\n", env.KeyEnv, key)
+			}
+			key, err := envKey(key)
+			if err != nil {
+				return err
+			}
+
+			plaintext, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			encrypted, err := env.Encrypt(plaintext, key)
+			if err != nil {
+				return err
+			}
+
+			if out == "" {
+				out = args[0] + ".enc"
+			}
+			if err := os.WriteFile(out, encrypted, 0o644); err != nil {
+				return err
+			}
+			cmd.Printf("encrypted %s to %s\n", args[0], out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&key, "key", "", "base64 key, defaults to "+env.KeyEnv)
+	cmd.Flags().StringVar(&out, "out", "", "output file, defaults to <file>.enc")
+	cmd.Flags().BoolVar(&generate, "generate-key", false, "generate a new key and print it")
+	return cmd
+}
+
+func envDecryptCommand() *cobra.Command {
+	var key, out string
+
+	cmd := &cobra.Command{
+		Use:   "env:decrypt <file>",
+		Short: "Decrypt env file encrypted by env:encrypt",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := envKey(key)
+			if err != nil {
+				return err
+			}
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			plaintext, err := env.Decrypt(data, key)
+			if err != nil {
+				return err
+			}
+
+			if out == "" {
+				_, err = cmd.OutOrStdout().Write(plaintext)
+				return err
+			}
+			return os.WriteFile(out, plaintext, 0o600)
+		},
+	}
+	cmd.Flags().StringVar(&key, "key", "", "base64 key, defaults to "+env.KeyEnv)
+	cmd.Flags().StringVar(&out, "out", "", "output file, defaults to stdout")
+	return cmd
+}
+
+// envKey returns key flag or NEST_ENV_KEY
+func envKey(key string) (string, error) {
+	if key == "" {
+		key = strings.TrimSpace(os.Getenv(env.KeyEnv))
+	}
+	if key == "" {
+		return "", fmt.Errorf("--key or %s is required", env.KeyEnv)
+	}
+	return key, nil
+}
@@ -0,0 +1,395 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// validateMarker annotates structs in their doc comment, optionally followed by body or query
+const validateMarker = "nest:validate"
+
+// genField represents struct field of an annotated request struct
+type genField struct {
+	GoName string
+	Name   string
+	Kind   string
+	Tag    string
+	// Type is the identifier of struct kind fields
+	Type string
+	// Nested is set for untagged package struct fields, Generated when that struct is annotated too
+	Nested    bool
+	Generated bool
+}
+
+// genStruct represents annotated request struct
+type genStruct struct {
+	Name   string
+	Source string
+	Fields []genField
+}
+
+func genCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate code",
+	}
+	cmd.AddCommand(genValidatorsCommand())
+	return cmd
+}
+
+func genValidatorsCommand() *cobra.Command {
+	var dir, out string
+
+	cmd := &cobra.Command{
+		Use:   "validators",
+		Short: "Generate binding and validation code for structs annotated with //nest:validate",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pkg, structs, err := scanValidateStructs(dir, out)
+			if err != nil {
+				return err
+			}
+			if len(structs) == 0 {
+				return fmt.Errorf("no structs annotated with //%s found in %s", validateMarker, dir)
+			}
+
+			src, err := generateValidators(pkg, structs)
+			if err != nil {
+				return err
+			}
+			file := filepath.Join(dir, out)
+			if err := os.WriteFile(file, src, 0o644); err != nil {
+				return err
+			}
+			cmd.Printf("generated %s for %d struct(s)\n", file, len(structs))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", ".", "package directory to scan")
+	cmd.Flags().StringVar(&out, "out", "validators_gen.go", "output file name inside dir")
+	return cmd
+}
+
+// scanValidateStructs parses package in dir and returns annotated structs
+func scanValidateStructs(dir, out string) (string, []genStruct, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		name := info.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != out
+	}, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var pkgName string
+	var structs []genStruct
+	local := make(map[string]bool)
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					st, ok := typeSpec.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					local[typeSpec.Name.Name] = true
+
+					doc := typeSpec.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					source, ok := validateAnnotation(doc)
+					if !ok {
+						continue
+					}
+					structs = append(structs, genStruct{
+						Name:   typeSpec.Name.Name,
+						Source: source,
+						Fields: structFields(st, source),
+					})
+				}
+			}
+		}
+	}
+
+	annotated := make(map[string]bool)
+	for _, st := range structs {
+		annotated[st.Name] = true
+	}
+	// Untagged fields of package struct types are validated recursively like go-playground does
+	for i := range structs {
+		for j := range structs[i].Fields {
+			field := &structs[i].Fields[j]
+			field.Nested = field.Kind == "struct" && field.Tag == "" && local[field.Type]
+			field.Generated = field.Nested && annotated[field.Type]
+		}
+	}
+
+	sort.Slice(structs, func(i, j int) bool {
+		return structs[i].Name < structs[j].Name
+	})
+	return pkgName, structs, nil
+}
+
+// validateAnnotation returns source of //nest:validate [body|query] annotation
+func validateAnnotation(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, comment := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if rest, ok := strings.CutPrefix(text, validateMarker); ok {
+			source := strings.TrimSpace(rest)
+			if source == "" {
+				source = "body"
+			}
+			return source, true
+		}
+	}
+	return "", false
+}
+
+// structFields returns exported named fields with their validation tags, named by source tag
+func structFields(st *ast.StructType, source string) []genField {
+	sourceTag := "json"
+	if source == "query" {
+		sourceTag = "query"
+	}
+
+	var fields []genField
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(raw)
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			tagName := strings.SplitN(tag.Get(sourceTag), ",", 2)[0]
+			if tagName == "" || tagName == "-" {
+				tagName = name.Name
+			}
+			kind, typ := fieldKind(field.Type)
+			fields = append(fields, genField{
+				GoName: name.Name,
+				Name:   tagName,
+				Kind:   kind,
+				Tag:    tag.Get("validate"),
+				Type:   typ,
+			})
+		}
+	}
+	return fields
+}
+
+// fieldKind classifies field type for generated checks
+func fieldKind(expr ast.Expr) (string, string) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string", ""
+		case "int", "int8", "int16", "int32", "int64", "rune":
+			return "int", ""
+		case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+			return "uint", ""
+		case "float32", "float64":
+			return "float", ""
+		case "bool":
+			return "bool", ""
+		}
+		if t.Obj != nil {
+			if spec, ok := t.Obj.Decl.(*ast.TypeSpec); ok {
+				if _, ok := spec.Type.(*ast.StructType); ok {
+					return "struct", t.Name
+				}
+			}
+		}
+		return "other", ""
+	case *ast.StarExpr:
+		return "pointer", ""
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "slice", ""
+		}
+	case *ast.MapType:
+		return "map", ""
+	}
+	return "other", ""
+}
+
+// generateValidators renders generated file source
+func generateValidators(pkg string, structs []genStruct) ([]byte, error) {
+	var b bytes.Buffer
+	for _, st := range structs {
+		fmt.Fprintf(&b, "\n// Validate checks validation rules of %s, reflection is only used to build error messages\n", st.Name)
+		fmt.Fprintf(&b, "func (r *%s) Validate() []validator.ValidatorError {\n\tvar errs []validator.ValidatorError\n", st.Name)
+		for _, field := range st.Fields {
+			writeFieldChecks(&b, field, st.Source)
+		}
+		b.WriteString("\treturn errs\n}\n")
+
+		parser := "BodyParser"
+		if st.Source == "query" {
+			parser = "QueryParser"
+		}
+		fmt.Fprintf(&b, "\n// ParseAndValidate%s parses request %s into %s and validates it\n", st.Name, st.Source, st.Name)
+		fmt.Fprintf(&b, "func ParseAndValidate%s(c *fiber.Ctx) (*%s, error) {\n", st.Name, st.Name)
+		fmt.Fprintf(&b, "\treq := new(%s)\n\tif err := c.%s(req); err != nil {\n\t\treturn nil, fiber.NewError(fiber.StatusBadRequest, err.Error())\n\t}\n", st.Name, parser)
+		b.WriteString("\tif errs := req.Validate(); len(errs) > 0 {\n\t\treturn nil, validator.Errors(errs)\n\t}\n\treturn req, nil\n}\n")
+	}
+
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "// Code generated by nest gen validators. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	if bytes.Contains(b.Bytes(), []byte("utf8.")) {
+		header.WriteString("\t\"unicode/utf8\"\n\n")
+	}
+	header.WriteString("\t\"github.com/gofiber/fiber/v2\"\n\t\"github.com/rikiihsan/nest/validator\"\n)\n")
+	header.Write(b.Bytes())
+
+	return format.Source(header.Bytes())
+}
+
+// writeFieldChecks renders checks of one field, the first failing tag is reported like go-playground does
+func writeFieldChecks(b *bytes.Buffer, field genField, source string) {
+	value := "r." + field.GoName
+
+	if field.Nested {
+		call := value + ".Validate()"
+		if !field.Generated {
+			call = fmt.Sprintf("validator.Validate(&%s, %q)", value, source)
+		}
+		fmt.Fprintf(b, "\tfor _, e := range %s {\n\t\te.FailedField = %q + e.FailedField\n\t\terrs = append(errs, e)\n\t}\n", call, field.Name+".")
+		return
+	}
+	if field.Tag == "" || field.Tag == "-" {
+		return
+	}
+
+	var cases []string
+	omitempty := false
+	for _, tag := range strings.Split(field.Tag, ",") {
+		if tag == "omitempty" {
+			omitempty = true
+			continue
+		}
+		cond, ok := tagCondition(field.Kind, value, tag)
+		if !ok {
+			// Unsupported tags validate the whole field with reflection
+			fmt.Fprintf(b, "\tif e := validator.ValidateField(%q, %s, %q); e != nil {\n\t\terrs = append(errs, *e)\n\t}\n", field.Name, value, field.Tag)
+			return
+		}
+		cases = append(cases, fmt.Sprintf("\tcase %s:\n\t\terrs = append(errs, validator.FieldError(%q, %s, %q))\n", cond, field.Name, value, tag))
+	}
+	if len(cases) == 0 {
+		return
+	}
+
+	if omitempty {
+		fmt.Fprintf(b, "\tif !(%s) {\n", zeroCondition(field.Kind, value))
+	}
+	b.WriteString("\tswitch {\n")
+	for _, c := range cases {
+		b.WriteString(c)
+	}
+	b.WriteString("\t}\n")
+	if omitempty {
+		b.WriteString("\t}\n")
+	}
+}
+
+// zeroCondition returns expression true when value is empty
+func zeroCondition(kind, value string) string {
+	switch kind {
+	case "string":
+		return value + ` == ""`
+	case "int", "uint", "float":
+		return value + " == 0"
+	case "bool":
+		return "!" + value
+	case "slice", "map":
+		return "len(" + value + ") == 0"
+	case "pointer":
+		return value + " == nil"
+	}
+	return "false"
+}
+
+// tagCondition returns expression true when value fails tag, false when tag needs reflection
+func tagCondition(kind, value, tag string) (string, bool) {
+	name, param, _ := strings.Cut(tag, "=")
+	if strings.Contains(tag, "|") {
+		return "", false
+	}
+
+	if name == "required" {
+		switch kind {
+		case "string", "int", "uint", "float", "bool":
+			return zeroCondition(kind, value), true
+		case "slice", "map", "pointer":
+			return value + " == nil", true
+		}
+		return "", false
+	}
+
+	operators := map[string]string{"min": "<", "gte": "<", "max": ">", "lte": ">", "gt": "<=", "lt": ">=", "len": "!="}
+	switch kind {
+	case "string", "slice", "map":
+		op, ok := operators[name]
+		if ok {
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				return "", false
+			}
+			length := "len(" + value + ")"
+			if kind == "string" {
+				length = "utf8.RuneCountInString(" + value + ")"
+			}
+			return fmt.Sprintf("%s %s %d", length, op, n), true
+		}
+		if name == "oneof" && kind == "string" {
+			var parts []string
+			for _, option := range strings.Fields(param) {
+				parts = append(parts, fmt.Sprintf("%s != %q", value, strings.Trim(option, "'")))
+			}
+			return strings.Join(parts, " && "), len(parts) > 0
+		}
+	case "int", "uint", "float":
+		if op, ok := operators[name]; ok {
+			if _, err := strconv.ParseFloat(param, 64); err != nil || (kind != "float" && strings.ContainsAny(param, ".eE")) {
+				return "", false
+			}
+			return fmt.Sprintf("%s %s %s", value, op, param), true
+		}
+		if name == "oneof" {
+			var parts []string
+			for _, option := range strings.Fields(param) {
+				if _, err := strconv.ParseFloat(option, 64); err != nil {
+					return "", false
+				}
+				parts = append(parts, fmt.Sprintf("%s != %s", value, option))
+			}
+			return strings.Join(parts, " && "), len(parts) > 0
+		}
+	}
+	return "", false
+}
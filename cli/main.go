@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rikiihsan/nest"
+	"github.com/rikiihsan/nest/database"
+	"github.com/spf13/cobra"
+)
+
+// New creates root command with all nest commands bound to app
+func New(app *nest.App) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "nest",
+		Short:         "Operational commands for nest applications",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	root.AddCommand(
+		migrateCommand(app),
+		seedCommand(app),
+		anonymizeCommand(app),
+		schemaDiffCommand(app),
+		makeCommand("model", modelTemplate),
+		makeCommand("handler", handlerTemplate),
+		makeCommand("validator", validatorTemplate),
+		makeCommand("service", serviceTemplate),
+		genCommand(),
+		routesCommand(app),
+		envCheckCommand(app),
+		envEncryptCommand(),
+		envDecryptCommand(),
+		queueWorkCommand(app),
+		redisAnalyzeCommand(app),
+		downCommand(app),
+		upCommand(app),
+	)
+	return root
+}
+
+// Execute runs root command and exits with non-zero status on error
+func Execute(app *nest.App) {
+	if err := New(app).Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// signalContext returns context cancelled on SIGINT/SIGTERM
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// withApp initializes subsystems, runs fn and closes connections
+func withApp(app *nest.App, fn func(ctx context.Context) error) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	if err := app.Init(ctx); err != nil {
+		return err
+	}
+	defer database.CloseAll()
+
+	return fn(ctx)
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rikiihsan/nest"
+	"github.com/rikiihsan/nest/maintenance"
+	"github.com/spf13/cobra"
+)
+
+func downCommand(app *nest.App) *cobra.Command {
+	var state maintenance.State
+	var data string
+
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Put application into maintenance mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if data != "" {
+				if !json.Valid([]byte(data)) {
+					return fmt.Errorf("--data must be valid JSON")
+				}
+				state.Data = json.RawMessage(data)
+			}
+			return withApp(app, func(ctx context.Context) error {
+				if err := maintenance.Enable(ctx, state); err != nil {
+					return err
+				}
+				cmd.Println("application is now in maintenance mode")
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&state.Message, "message", "", "message returned to clients")
+	cmd.Flags().IntVar(&state.RetryAfter, "retry", 0, "Retry-After header in seconds")
+	cmd.Flags().StringVar(&state.Secret, "secret", "", "secret letting requests bypass maintenance mode")
+	cmd.Flags().StringVar(&data, "data", "", "JSON payload returned as envelope data")
+	return cmd
+}
+
+func upCommand(app *nest.App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "up",
+		Short: "Bring application out of maintenance mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				if err := maintenance.Disable(ctx); err != nil {
+					return err
+				}
+				cmd.Println("application is now live")
+				return nil
+			})
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+const modelTemplate = `package {{.Package}}
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// {{.Name}} represents {{.Table}} table
+type {{.Name}} struct {
+	bun.BaseModel ` + "`bun:\"table:{{.Table}}\"`" + `
+
+	ID        int64     ` + "`bun:\",pk,autoincrement\" json:\"id\"`" + `
+	CreatedAt time.Time ` + "`bun:\",nullzero,notnull,default:current_timestamp\" json:\"created_at\"`" + `
+	UpdatedAt time.Time ` + "`bun:\",nullzero,notnull,default:current_timestamp\" json:\"updated_at\"`" + `
+}
+`
+
+const handlerTemplate = `package {{.Package}}
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/response"
+)
+
+// {{.Name}}Handler handles {{.Snake}} endpoints
+type {{.Name}}Handler struct{}
+
+// Register registers {{.Snake}} routes
+func (h *{{.Name}}Handler) Register(router fiber.Router) {
+	router.Get("/{{.Table}}", h.Index)
+	router.Get("/{{.Table}}/:id", h.Show)
+}
+
+// Index lists {{.Table}}
+func (h *{{.Name}}Handler) Index(c *fiber.Ctx) error {
+	return response.Success(c, []interface{}{}, nil)
+}
+
+// Show returns single {{.Snake}}
+func (h *{{.Name}}Handler) Show(c *fiber.Ctx) error {
+	return response.Success(c, fiber.Map{"id": c.Params("id")}, nil)
+}
+`
+
+const validatorTemplate = `package {{.Package}}
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// {{.Name}}Request represents {{.Snake}} request body
+type {{.Name}}Request struct {
+	Name string ` + "`json:\"name\" validate:\"required\"`" + `
+}
+
+// Parse{{.Name}}Request parses and validates request body
+func Parse{{.Name}}Request(c *fiber.Ctx) (*{{.Name}}Request, error) {
+	req := new({{.Name}}Request)
+	if err := c.BodyParser(req); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if errs := validator.Validate(req, "json"); len(errs) > 0 {
+		return nil, validator.Errors(errs)
+	}
+	return req, nil
+}
+`
+
+const serviceTemplate = `package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/rikiihsan/nest"
+	"{{.Models}}"
+)
+
+// {{.Name}}Service handles {{.Snake}} business logic
+type {{.Name}}Service struct {
+	*nest.Service[{{.ModelsPackage}}.{{.Name}}]
+}
+
+// New{{.Name}}Service creates {{.Snake}} service on database session
+func New{{.Name}}Service(session string) *{{.Name}}Service {
+	s := &{{.Name}}Service{Service: nest.NewService[{{.ModelsPackage}}.{{.Name}}](session, "{{.Snake}}")}
+	s.BeforeCreate = s.beforeCreate
+	s.BeforeUpdate = s.beforeUpdate
+	return s
+}
+
+// beforeCreate runs inside the create transaction
+func (s *{{.Name}}Service) beforeCreate(ctx context.Context, entity *{{.ModelsPackage}}.{{.Name}}) error {
+	return nil
+}
+
+// beforeUpdate runs inside the update transaction
+func (s *{{.Name}}Service) beforeUpdate(ctx context.Context, entity *{{.ModelsPackage}}.{{.Name}}) error {
+	return nil
+}
+`
+
+type scaffold struct {
+	Package       string
+	Name          string
+	Snake         string
+	Table         string
+	Models        string
+	ModelsPackage string
+}
+
+func makeCommand(kind, tmpl string) *cobra.Command {
+	var dir, pkg, models string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("make:%s <Name>", kind),
+		Short: fmt.Sprintf("Generate %s file", kind),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				dir = kind + "s"
+			}
+			if pkg == "" {
+				pkg = filepath.Base(dir)
+			}
+			if kind == "service" && models == "" {
+				return fmt.Errorf("--models is required for make:service")
+			}
+
+			name := exportedName(args[0])
+			data := scaffold{
+				Package: pkg,
+				Name:    name,
+				Snake:   snakeCase(name),
+				Table:   snakeCase(name) + "s",
+				Models:  models,
+			}
+			data.ModelsPackage = path.Base(models)
+
+			file := filepath.Join(dir, data.Snake+".go")
+			if _, err := os.Stat(file); err == nil && !force {
+				return fmt.Errorf("%s already exists, use --force to overwrite", file)
+			}
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			if err := template.Must(template.New(kind).Parse(tmpl)).Execute(&buf, data); err != nil {
+				return err
+			}
+			// Sorts imports since model import path is user provided
+			src, err := format.Source(buf.Bytes())
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(file, src, 0o644); err != nil {
+				return err
+			}
+			cmd.Printf("created %s\n", file)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "", "output directory")
+	cmd.Flags().StringVar(&pkg, "package", "", "package name, defaults to directory name")
+	cmd.Flags().StringVar(&models, "models", "", "import path of models package, required by make:service")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite existing file")
+	return cmd
+}
+
+// exportedName converts name to exported Go identifier
+func exportedName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' '
+	})
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// snakeCase converts CamelCase identifier to snake_case
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rikiihsan/nest"
+	"github.com/rikiihsan/nest/database"
+	"github.com/spf13/cobra"
+)
+
+func redisAnalyzeCommand(app *nest.App) *cobra.Command {
+	var opts database.RedisAnalyzeOptions
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "redis:analyze",
+		Short: "Sample Redis keys and report count, memory and TTLs per namespace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withApp(app, func(ctx context.Context) error {
+				report, err := database.AnalyzeRedis(ctx, opts)
+				if err != nil {
+					return err
+				}
+				if asJSON {
+					encoder := json.NewEncoder(cmd.OutOrStdout())
+					encoder.SetIndent("", "  ")
+					return encoder.Encode(report)
+				}
+
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "PREFIX\tKEYS\tMEMORY\tTTLS\tTYPES")
+				for _, ns := range report.Namespaces {
+					fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", ns.Prefix, ns.Keys, formatBytes(ns.Memory), formatCounts(ns.TTLs), formatCounts(ns.Types))
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+
+				cmd.Printf("\n%d keys sampled, %s, %d without TTL\n", report.Scanned, formatBytes(report.Memory), report.NoTTL)
+				for _, ns := range report.Namespaces {
+					for _, key := range ns.NoTTL {
+						cmd.Printf("  no TTL: %s\n", key)
+					}
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&opts.Match, "match", "*", "SCAN pattern")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 10000, "maximum keys sampled")
+	cmd.Flags().StringVar(&opts.Separator, "separator", ":", "namespace separator")
+	cmd.Flags().IntVar(&opts.Depth, "depth", 2, "namespace segments")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print report as JSON")
+	return cmd
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
+
+// formatCounts renders counts as name=count sorted by name
+func formatCounts(counts map[string]int64) string {
+	parts := make([]string, 0, len(counts))
+	for name, count := range counts {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, count))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/rikiihsan/nest"
+	"github.com/rikiihsan/nest/cli"
+	"github.com/rikiihsan/nest/database"
+
+	_ "github.com/rikiihsan/nest/database/drivers/mssql"
+	_ "github.com/rikiihsan/nest/database/drivers/mysql"
+	_ "github.com/rikiihsan/nest/database/drivers/postgres"
+	_ "github.com/rikiihsan/nest/database/drivers/sqlite"
+)
+
+// Config represents environment used by the standalone CLI
+type Config struct {
+	DBDriver      string `env:"DB_DRIVER" default:"pgx"`
+	DBDsn         string `env:"DB_DSN"`
+	RedisAddr     string `env:"REDIS_ADDR"`
+	RedisPassword string `env:"REDIS_PASSWORD"`
+	RedisDB       int    `env:"REDIS_DB" default:"0"`
+}
+
+func main() {
+	cfg := &Config{}
+	app := nest.New(nest.Config{
+		Bind: cfg,
+		Setup: func(app *nest.App) error {
+			if cfg.DBDsn != "" {
+				app.Config.Databases = append(app.Config.Databases, database.Config{
+					Name:   "default",
+					Driver: cfg.DBDriver,
+					Dsn:    cfg.DBDsn,
+				})
+			}
+			if cfg.RedisAddr != "" {
+				app.Config.Redis = &database.RedisConfig{
+					Addr:     cfg.RedisAddr,
+					Password: cfg.RedisPassword,
+					DB:       cfg.RedisDB,
+				}
+			}
+			return nil
+		},
+	})
+
+	cli.Execute(app)
+}
@@ -0,0 +1,35 @@
+package nest
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/i18n"
+	"github.com/rikiihsan/nest/logger"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// Config represents application bootstrap configuration
+type Config struct {
+	// EnvFiles are loaded first, missing default .env is ignored
+	EnvFiles []string
+	// Bind is pointer to struct populated with env.Bind after env loading
+	Bind interface{}
+	// Setup runs after env binding so configs below can be derived from Bind
+	Setup func(app *App) error
+
+	Logger      logger.Config
+	Databases   []database.Config
+	Redis       *database.RedisConfig
+	Translators []validator.Translator
+	// FreezeValidator locks validator registrations once Run starts serving, see validator.Freeze.
+	// Leave it off when registering validations or publishing i18n validator messages at runtime
+	FreezeValidator bool
+	// I18n loads message bundles and localizes response envelopes when set
+	I18n *i18n.Config
+
+	Fiber           fiber.Config
+	Addr            string
+	ShutdownTimeout time.Duration
+}
@@ -0,0 +1,113 @@
+package coordination
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/lock"
+)
+
+var ErrLeadershipLost = errors.New("coordination : leadership lost")
+
+// Options represents leader election settings
+type Options struct {
+	// TTL is lease duration, renewed every TTL/3, defaults to 15s
+	TTL time.Duration
+	// RetryInterval between campaigns of followers, defaults to 2s
+	RetryInterval time.Duration
+}
+
+// DefaultOptions are used for zero fields
+var DefaultOptions = Options{
+	TTL:           15 * time.Second,
+	RetryInterval: 2 * time.Second,
+}
+
+// FencePrefix is prepended to fencing token counters
+var FencePrefix = "nest:fence:"
+
+type fenceKey struct{}
+
+// FencingToken returns token of the leadership term running fn, tokens increase with every term
+// so storage can reject writes of a stale leader carrying a lower token
+func FencingToken(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(fenceKey{}).(int64)
+	return token, ok
+}
+
+// RunWhenLeader campaigns for leadership of key and runs fn while leader. fn ctx is cancelled when the
+// lease cannot be renewed, after which the instance campaigns again. Returns when ctx is done or fn
+// returns while leadership is held
+func RunWhenLeader(ctx context.Context, key string, fn func(ctx context.Context) error, opts ...Options) error {
+	o := DefaultOptions
+	if len(opts) > 0 {
+		o = opts[0]
+		if o.TTL <= 0 {
+			o.TTL = DefaultOptions.TTL
+		}
+		if o.RetryInterval <= 0 {
+			o.RetryInterval = DefaultOptions.RetryInterval
+		}
+	}
+
+	for {
+		l, err := lock.AcquireWait(ctx, "leader:"+key, o.TTL, o.RetryInterval)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		err = lead(ctx, l, key, o, fn)
+		if !errors.Is(err, ErrLeadershipLost) {
+			return err
+		}
+		slog.WarnContext(ctx, "leadership lost", "key", key)
+	}
+}
+
+// lead runs fn renewing the lease until fn returns or renewal fails
+func lead(ctx context.Context, l *lock.Lock, key string, o Options, fn func(ctx context.Context) error) error {
+	defer l.Release(context.WithoutCancel(ctx))
+
+	token, err := database.RedisClient.Incr(ctx, FencePrefix+key).Result()
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "leadership acquired", "key", key, "fencing_token", token)
+
+	leaderCtx, cancel := context.WithCancelCause(context.WithValue(ctx, fenceKey{}, token))
+	defer cancel(nil)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(o.TTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			if context.Cause(leaderCtx) == ErrLeadershipLost {
+				return ErrLeadershipLost
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		case <-ticker.C:
+			if err := l.Refresh(ctx, o.TTL); err != nil && ctx.Err() == nil {
+				// Stop fn before the lease can be taken over, then wait for it to return
+				cancel(ErrLeadershipLost)
+				<-done
+				return ErrLeadershipLost
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package counter
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+)
+
+var ErrNoRedis = errors.New("counter : redis client is not initialized")
+
+// Prefix is prepended to all counter keys
+var Prefix = "nest:counter:"
+
+// Retention is number of windows a bucket is kept before expiring
+var Retention = 60
+
+// Bucket represents counter value of one window
+type Bucket struct {
+	Start time.Time `json:"start"`
+	Value int64     `json:"value"`
+}
+
+func client() (*redis.Client, error) {
+	if database.RedisClient == nil {
+		return nil, ErrNoRedis
+	}
+	return database.RedisClient, nil
+}
+
+// bucketKey returns key of window bucket starting at start
+func bucketKey(key string, window time.Duration, start time.Time) string {
+	return Prefix + key + ":" + window.String() + ":" + strconv.FormatInt(start.Unix(), 10)
+}
+
+// Incr atomically adds delta to counter without expiry
+func Incr(ctx context.Context, key string, delta int64) (int64, error) {
+	rdb, err := client()
+	if err != nil {
+		return 0, err
+	}
+	return rdb.IncrBy(ctx, Prefix+key, delta).Result()
+}
+
+// Get returns counter value, zero when absent
+func Get(ctx context.Context, key string) (int64, error) {
+	rdb, err := client()
+	if err != nil {
+		return 0, err
+	}
+	n, err := rdb.Get(ctx, Prefix+key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Reset deletes counter
+func Reset(ctx context.Context, key string) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	return rdb.Del(ctx, Prefix+key).Err()
+}
+
+// IncrWindow atomically adds delta to the current window bucket of counter, returning bucket value.
+// Buckets expire after Retention windows
+func IncrWindow(ctx context.Context, key string, delta int64, window time.Duration) (int64, error) {
+	rdb, err := client()
+	if err != nil {
+		return 0, err
+	}
+
+	bucket := bucketKey(key, window, time.Now().Truncate(window))
+	pipe := rdb.TxPipeline()
+	incr := pipe.IncrBy(ctx, bucket, delta)
+	pipe.ExpireNX(ctx, bucket, window*time.Duration(Retention))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Buckets returns last n window buckets of counter, oldest first
+func Buckets(ctx context.Context, key string, window time.Duration, n int) ([]Bucket, error) {
+	rdb, err := client()
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	current := time.Now().Truncate(window)
+	buckets := make([]Bucket, n)
+	keys := make([]string, n)
+	for i := range buckets {
+		buckets[i].Start = current.Add(-time.Duration(n-1-i) * window)
+		keys[i] = bucketKey(key, window, buckets[i].Start)
+	}
+
+	values, err := rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			buckets[i].Value, _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return buckets, nil
+}
+
+// Rollup returns sum of last n window buckets, e.g. Rollup(ctx, "signups", time.Minute, 60) for the last hour
+func Rollup(ctx context.Context, key string, window time.Duration, n int) (int64, error) {
+	buckets, err := Buckets(ctx, key, window, n)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, bucket := range buckets {
+		total += bucket.Value
+	}
+	return total, nil
+}
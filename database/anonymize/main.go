@@ -0,0 +1,218 @@
+package anonymize
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// Rule scrubs columns of a table
+type Rule struct {
+	Table string
+	// Key is unique sortable column used for batching and updates, defaults to "id"
+	Key string
+	// Where optionally limits scrubbed rows with SQL without placeholders, e.g. "role <> 'admin'"
+	Where   string
+	Columns map[string]Strategy
+}
+
+// Progress is reported after every batch
+type Progress struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+	Total int64  `json:"total"`
+}
+
+// Options represents run configuration
+type Options struct {
+	// BatchSize is rows read and updated per transaction, defaults to 500
+	BatchSize int
+	// OnProgress is called after every batch
+	OnProgress func(Progress)
+	// Key is HMAC key of FakeEmail and Hash, values stay consistent across runs sharing it. Defaults to a
+	// random key per process, keep it secret since it allows checking guesses of original values
+	Key []byte
+}
+
+var (
+	mu    sync.Mutex
+	rules []Rule
+)
+
+// Register registers rule, rules run in registration order
+func Register(rule Rule) {
+	if rule.Key == "" {
+		rule.Key = "id"
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	rules = append(rules, rule)
+}
+
+// Rules returns registered rules
+func Rules() []Rule {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Rule(nil), rules...)
+}
+
+// Run scrubs tables of registered rules (all when tables is empty) on session in batches,
+// every batch commits separately so interrupted runs can be restarted
+func Run(ctx context.Context, sessionName string, options Options, tables ...string) error {
+	if options.BatchSize <= 0 {
+		options.BatchSize = 500
+	}
+	if len(options.Key) > 0 {
+		digestKey.Store(&options.Key)
+	}
+	db, err := database.GetDB(sessionName)
+	if err != nil {
+		return err
+	}
+
+	selected := Rules()
+	if len(tables) > 0 {
+		byTable := make(map[string]Rule, len(selected))
+		for _, rule := range selected {
+			byTable[rule.Table] = rule
+		}
+		selected = selected[:0]
+		for _, table := range tables {
+			rule, ok := byTable[table]
+			if !ok {
+				return fmt.Errorf("anonymize rule for table '%s' not found", table)
+			}
+			selected = append(selected, rule)
+		}
+	}
+
+	for _, rule := range selected {
+		if err := run(ctx, db, sessionName, rule, options); err != nil {
+			return fmt.Errorf("anonymize '%s' failed: %w", rule.Table, err)
+		}
+	}
+	return nil
+}
+
+func run(ctx context.Context, db *bun.DB, sessionName string, rule Rule, options Options) error {
+	columns := make([]string, 0, len(rule.Columns))
+	for column := range rule.Columns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	where := "1 = 1"
+	if rule.Where != "" {
+		where = "(" + rule.Where + ")"
+	}
+
+	progress := Progress{Table: rule.Table}
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM ? WHERE "+where, bun.Ident(rule.Table)).Scan(&progress.Total); err != nil {
+		return err
+	}
+
+	var last interface{}
+	for {
+		batch, err := readBatch(ctx, db, rule, columns, where, last, options.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err = database.WithTransaction(ctx, sessionName, func(tx bun.Tx) error {
+			for _, row := range batch {
+				if err := update(ctx, tx, rule, columns, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		last = batch[len(batch)-1][rule.Key]
+		progress.Rows += int64(len(batch))
+		if options.OnProgress != nil {
+			options.OnProgress(progress)
+		}
+		if len(batch) < options.BatchSize {
+			return nil
+		}
+	}
+}
+
+// readBatch reads next rows after last key ordered by key
+func readBatch(ctx context.Context, db *bun.DB, rule Rule, columns []string, where string, last interface{}, size int) ([]map[string]interface{}, error) {
+	selected := append([]string{rule.Key}, columns...)
+	args := make([]interface{}, 0, len(selected)+4)
+	for _, column := range selected {
+		args = append(args, bun.Ident(column))
+	}
+	args = append(args, bun.Ident(rule.Table))
+
+	query := "SELECT " + strings.TrimSuffix(strings.Repeat("?, ", len(selected)), ", ") + " FROM ? WHERE " + where
+	if last != nil {
+		query += " AND ? > ?"
+		args = append(args, bun.Ident(rule.Key), last)
+	}
+	if db.Dialect().Name() == dialect.MSSQL {
+		query += " ORDER BY ? OFFSET 0 ROWS FETCH NEXT ? ROWS ONLY"
+	} else {
+		query += " ORDER BY ? LIMIT ?"
+	}
+	args = append(args, bun.Ident(rule.Key), size)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(selected))
+	dest := make([]interface{}, len(selected))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var batch []map[string]interface{}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(selected))
+		for i, column := range selected {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		batch = append(batch, row)
+	}
+	return batch, rows.Err()
+}
+
+// update writes strategy results of row
+func update(ctx context.Context, tx bun.Tx, rule Rule, columns []string, row map[string]interface{}) error {
+	sets := make([]string, len(columns))
+	args := make([]interface{}, 0, len(columns)*2+3)
+	args = append(args, bun.Ident(rule.Table))
+	for i, column := range columns {
+		sets[i] = "? = ?"
+		args = append(args, bun.Ident(column), rule.Columns[column](row[column], row))
+	}
+	args = append(args, bun.Ident(rule.Key), row[rule.Key])
+
+	_, err := tx.ExecContext(ctx, "UPDATE ? SET "+strings.Join(sets, ", ")+" WHERE ? = ?", args...)
+	return err
+}
@@ -0,0 +1,97 @@
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// Strategy returns replacement of value, row holds key and all rule columns as read from the database.
+// NULL values are passed as nil, []byte values as string
+type Strategy func(value interface{}, row map[string]interface{}) interface{}
+
+// digestKey is HMAC key of digest, random per process unless Options.Key is set
+var digestKey atomic.Pointer[[]byte]
+
+func init() {
+	key := make([]byte, 32)
+	rand.Read(key)
+	digestKey.Store(&key)
+}
+
+// digest returns hex HMAC-SHA256 of value, equal values anonymize equally across tables and runs
+// sharing the key while the originals can't be recovered by hashing candidates
+func digest(value interface{}, n int) string {
+	mac := hmac.New(sha256.New, *digestKey.Load())
+	mac.Write([]byte(fmt.Sprint(value)))
+	return hex.EncodeToString(mac.Sum(nil))[:n]
+}
+
+// FakeEmail replaces emails with user-<hash>@domain, keeping them unique. Domain defaults to example.invalid
+func FakeEmail(domain ...string) Strategy {
+	host := "example.invalid"
+	if len(domain) > 0 {
+		host = domain[0]
+	}
+	return func(value interface{}, _ map[string]interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		return "user-" + digest(strings.ToLower(fmt.Sprint(value)), 12) + "@" + host
+	}
+}
+
+// MaskName keeps the first letter of every word, e.g. "Jane Doe" becomes "J*** D***"
+func MaskName() Strategy {
+	return func(value interface{}, _ map[string]interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		words := strings.Fields(fmt.Sprint(value))
+		for i, word := range words {
+			first, _ := utf8.DecodeRuneInString(word)
+			words[i] = string(first) + "***"
+		}
+		return strings.Join(words, " ")
+	}
+}
+
+// Hash replaces values with the first 16 hex characters of their keyed SHA-256, see Options.Key
+func Hash() Strategy {
+	return func(value interface{}, _ map[string]interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		return digest(value, 16)
+	}
+}
+
+// Null clears values, e.g. tokens and secrets
+func Null() Strategy {
+	return func(interface{}, map[string]interface{}) interface{} {
+		return nil
+	}
+}
+
+// Fixed replaces values with v
+func Fixed(v interface{}) Strategy {
+	return func(interface{}, map[string]interface{}) interface{} {
+		return v
+	}
+}
+
+// Format replaces values with format applied to the row key, e.g. Format("User %v")
+func Format(format string, key ...string) Strategy {
+	column := "id"
+	if len(key) > 0 {
+		column = key[0]
+	}
+	return func(_ interface{}, row map[string]interface{}) interface{} {
+		return fmt.Sprintf(format, row[column])
+	}
+}
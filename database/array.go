@@ -0,0 +1,215 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// StringArray is stored as native TEXT[] on PostgreSQL and as JSON elsewhere.
+// Register models with RegisterModel so their columns get the dialect type
+type StringArray []string
+
+// IntArray is stored as native BIGINT[] on PostgreSQL and as JSON elsewhere
+type IntArray []int64
+
+// AppendQuery formats array literal for the dialect of query
+func (a StringArray) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if a == nil {
+		return dialect.AppendNull(b), nil
+	}
+	if fmter.Dialect().Name() != dialect.PG {
+		return appendJSONArray(fmter, b, []string(a))
+	}
+
+	var lit strings.Builder
+	lit.WriteByte('{')
+	for i, s := range a {
+		if i > 0 {
+			lit.WriteByte(',')
+		}
+		lit.WriteByte('"')
+		lit.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s))
+		lit.WriteByte('"')
+	}
+	lit.WriteByte('}')
+	return fmter.Dialect().AppendString(b, lit.String()), nil
+}
+
+// Value encodes array as JSON for queries not built by bun
+func (a StringArray) Value() (driver.Value, error) {
+	return jsonArrayValue(a == nil, []string(a))
+}
+
+// Scan decodes PostgreSQL array literal or JSON array
+func (a *StringArray) Scan(src interface{}) error {
+	values, err := scanArray(src)
+	if err != nil || values == nil {
+		*a = nil
+		return err
+	}
+	*a = StringArray(values)
+	return nil
+}
+
+func (StringArray) columnType(name dialect.Name) string {
+	if name == dialect.PG {
+		return "TEXT[]"
+	}
+	return JSONType(name)
+}
+
+// AppendQuery formats array literal for the dialect of query
+func (a IntArray) AppendQuery(fmter schema.Formatter, b []byte) ([]byte, error) {
+	if a == nil {
+		return dialect.AppendNull(b), nil
+	}
+	if fmter.Dialect().Name() != dialect.PG {
+		return appendJSONArray(fmter, b, []int64(a))
+	}
+
+	var lit strings.Builder
+	lit.WriteByte('{')
+	for i, n := range a {
+		if i > 0 {
+			lit.WriteByte(',')
+		}
+		lit.WriteString(strconv.FormatInt(n, 10))
+	}
+	lit.WriteByte('}')
+	return fmter.Dialect().AppendString(b, lit.String()), nil
+}
+
+// Value encodes array as JSON for queries not built by bun
+func (a IntArray) Value() (driver.Value, error) {
+	return jsonArrayValue(a == nil, []int64(a))
+}
+
+// Scan decodes PostgreSQL array literal or JSON array
+func (a *IntArray) Scan(src interface{}) error {
+	values, err := scanArray(src)
+	if err != nil || values == nil {
+		*a = nil
+		return err
+	}
+	ints := make(IntArray, len(values))
+	for i, value := range values {
+		if ints[i], err = strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("database : invalid IntArray element %q", value)
+		}
+	}
+	*a = ints
+	return nil
+}
+
+func (IntArray) columnType(name dialect.Name) string {
+	if name == dialect.PG {
+		return "BIGINT[]"
+	}
+	return JSONType(name)
+}
+
+func appendJSONArray(fmter schema.Formatter, b []byte, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return fmter.Dialect().AppendString(b, string(data)), nil
+}
+
+func jsonArrayValue(null bool, v interface{}) (driver.Value, error) {
+	if null {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// scanArray returns elements of PostgreSQL array literal or JSON array as strings, nil for NULL
+func scanArray(src interface{}) ([]string, error) {
+	var s string
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		s = string(src)
+	case string:
+		s = src
+	default:
+		return nil, fmt.Errorf("database : cannot scan %T into array", src)
+	}
+
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return nil, nil
+	case strings.HasPrefix(s, "{"):
+		return parsePGArray(s)
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, err
+	}
+	values := make([]string, len(raw))
+	for i, item := range raw {
+		var str string
+		if err := json.Unmarshal(item, &str); err == nil {
+			values[i] = str
+		} else {
+			values[i] = string(item)
+		}
+	}
+	return values, nil
+}
+
+// parsePGArray parses one-dimensional array literal like {a,"b c",NULL}, NULL elements become ""
+func parsePGArray(s string) ([]string, error) {
+	if len(s) < 2 || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("database : invalid array literal %q", s)
+	}
+	s = s[1 : len(s)-1]
+	values := []string{}
+	if s == "" {
+		return values, nil
+	}
+
+	for i := 0; i <= len(s); {
+		if i < len(s) && s[i] == '"' {
+			var elem strings.Builder
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				elem.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("database : unterminated array element")
+			}
+			values = append(values, elem.String())
+			i += 2 // closing quote and comma
+			continue
+		}
+
+		end := strings.IndexByte(s[i:], ',')
+		if end < 0 {
+			end = len(s) - i
+		}
+		elem := s[i : i+end]
+		if elem == "NULL" {
+			elem = ""
+		}
+		values = append(values, elem)
+		i += end + 1
+	}
+	return values, nil
+}
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Autotune represents bounds of adaptive pool sizing
+type Autotune struct {
+	// MinOpenConns and MaxOpenConns bound MaxOpenConns adjustments. MinOpenConns defaults to 1 and
+	// MaxOpenConns to Config.MaxOpenConns, or 4 per CPU when that is unlimited
+	MinOpenConns int
+	MaxOpenConns int
+	// Interval between samples of sql.DBStats, defaults to 30s
+	Interval time.Duration
+	// WaitThreshold is average wait per sample above which the pool grows, defaults to 10ms
+	WaitThreshold time.Duration
+	// Step is connections added or removed per decision, defaults to 2
+	Step int
+	// IdleRatio sets MaxIdleConns relative to MaxOpenConns, defaults to 0.5
+	IdleRatio float64
+}
+
+// PoolDecision represents a single pool size adjustment
+type PoolDecision struct {
+	Time         time.Time     `json:"time"`
+	From         int           `json:"from"`
+	To           int           `json:"to"`
+	Reason       string        `json:"reason"`
+	WaitCount    int64         `json:"wait_count"`
+	WaitDuration time.Duration `json:"wait_duration"`
+	InUse        int           `json:"in_use"`
+}
+
+// PoolTuning represents autotune state of a session
+type PoolTuning struct {
+	MaxOpenConns int            `json:"max_open_conns"`
+	MaxIdleConns int            `json:"max_idle_conns"`
+	Decisions    []PoolDecision `json:"decisions"`
+}
+
+// MaxPoolDecisions limits decisions kept per session
+var MaxPoolDecisions = 50
+
+// poolTuner adjusts pool size of a sql.DB from wait statistics
+type poolTuner struct {
+	name   string
+	db     *sql.DB
+	config Autotune
+
+	mu        sync.Mutex
+	open      int
+	idle      int
+	last      sql.DBStats
+	decisions []PoolDecision
+	stop      chan struct{}
+	once      sync.Once
+}
+
+// newPoolTuner starts tuner at current MaxOpenConns clamped to bounds, maxOpen is the configured pool size
+func newPoolTuner(name string, db *sql.DB, config Autotune, maxOpen int) *poolTuner {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	if config.WaitThreshold <= 0 {
+		config.WaitThreshold = 10 * time.Millisecond
+	}
+	if config.Step <= 0 {
+		config.Step = 2
+	}
+	if config.IdleRatio <= 0 {
+		config.IdleRatio = 0.5
+	}
+	if config.MinOpenConns <= 0 {
+		config.MinOpenConns = 1
+	}
+	if config.MaxOpenConns <= 0 {
+		config.MaxOpenConns = maxOpen
+		if maxOpen <= 0 {
+			config.MaxOpenConns = 4 * runtime.NumCPU()
+		}
+	}
+	if config.MaxOpenConns < config.MinOpenConns {
+		config.MaxOpenConns = config.MinOpenConns
+	}
+
+	t := &poolTuner{name: name, db: db, config: config, stop: make(chan struct{})}
+	open := db.Stats().MaxOpenConnections
+	if open <= 0 || open > config.MaxOpenConns {
+		open = config.MaxOpenConns
+	}
+	if open < config.MinOpenConns {
+		open = config.MinOpenConns
+	}
+	t.apply(open)
+	t.last = db.Stats()
+
+	go t.run()
+	return t
+}
+
+func (t *poolTuner) run() {
+	ticker := time.NewTicker(t.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.sample()
+		}
+	}
+}
+
+// sample grows pool when callers waited, shrinks it when a sample had no waits and low usage
+func (t *poolTuner) sample() {
+	stats := t.db.Stats()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	waitCount := stats.WaitCount - t.last.WaitCount
+	waitDuration := stats.WaitDuration - t.last.WaitDuration
+	t.last = stats
+
+	open := t.open
+	reason := ""
+	switch {
+	case waitCount > 0 && waitDuration/time.Duration(waitCount) >= t.config.WaitThreshold:
+		open = min(t.open+t.config.Step, t.config.MaxOpenConns)
+		reason = "wait above threshold"
+	case waitCount == 0 && stats.InUse*2 < t.open:
+		open = max(t.open-t.config.Step, t.config.MinOpenConns)
+		reason = "low utilization"
+	}
+	if open == t.open {
+		return
+	}
+
+	decision := PoolDecision{
+		Time:         time.Now(),
+		From:         t.open,
+		To:           open,
+		Reason:       reason,
+		WaitCount:    waitCount,
+		WaitDuration: waitDuration,
+		InUse:        stats.InUse,
+	}
+	t.apply(open)
+
+	t.decisions = append(t.decisions, decision)
+	if len(t.decisions) > MaxPoolDecisions {
+		t.decisions = t.decisions[len(t.decisions)-MaxPoolDecisions:]
+	}
+	slog.Info("pool resized", "session", t.name, "from", decision.From, "to", decision.To, "reason", reason)
+}
+
+// apply sets MaxOpenConns and MaxIdleConns, caller must hold mu except on construction
+func (t *poolTuner) apply(open int) {
+	t.open = open
+	t.idle = max(int(float64(open)*t.config.IdleRatio), 1)
+	t.db.SetMaxOpenConns(t.open)
+	t.db.SetMaxIdleConns(t.idle)
+}
+
+func (t *poolTuner) close() {
+	t.once.Do(func() { close(t.stop) })
+}
+
+// PoolTuning returns autotune state, false if Config.Autotune is not set
+func (s *Session) PoolTuning() (PoolTuning, bool) {
+	if s.tuner == nil {
+		return PoolTuning{}, false
+	}
+
+	s.tuner.mu.Lock()
+	defer s.tuner.mu.Unlock()
+	return PoolTuning{
+		MaxOpenConns: s.tuner.open,
+		MaxIdleConns: s.tuner.idle,
+		Decisions:    append([]PoolDecision(nil), s.tuner.decisions...),
+	}, true
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/uptrace/bun/dialect"
+)
+
+// Backuper is implemented by drivers with an online backup API, e.g. SQLite
+type Backuper interface {
+	Backup(ctx context.Context, sqlDB *sql.DB, path string) error
+	Restore(ctx context.Context, sqlDB *sql.DB, path string) error
+}
+
+// BackupTo copies SQLite database of session to path while it stays in use. Drivers implementing Backuper
+// use the online backup API, others VACUUM INTO, which needs path not to exist
+func (s *Session) BackupTo(ctx context.Context, path string) error {
+	if s.DB.Dialect().Name() != dialect.SQLite {
+		return ErrUnsupported(s.Name, "backups")
+	}
+	if backuper, ok := Manager.drivers[s.Config.Driver].(Backuper); ok {
+		return backuper.Backup(ctx, s.SqlDB, path)
+	}
+	_, err := s.DB.ExecContext(ctx, "VACUUM INTO ?", path)
+	return classified(err)
+}
+
+// RestoreFrom replaces SQLite database of session with backup at path, requires a Backuper driver
+func (s *Session) RestoreFrom(ctx context.Context, path string) error {
+	backuper, ok := Manager.drivers[s.Config.Driver].(Backuper)
+	if !ok || s.DB.Dialect().Name() != dialect.SQLite {
+		return ErrUnsupported(s.Name, "restores")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	return backuper.Restore(ctx, s.SqlDB, path)
+}
+
+// BackupJob returns scheduler job writing <dir>/<session>-<timestamp>.db and keeping the newest keep backups,
+// zero keeps all, e.g. scheduler.SQLiteBackup("0 3 * * *", "default", "backups", 7)
+func BackupJob(sessionName, dir string, keep int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		session, ok := GetSession(sessionName)
+		if !ok {
+			return ErrSessionNotFound(sessionName)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.db", sessionName, time.Now().UTC().Format("20060102T150405Z")))
+		if err := session.BackupTo(ctx, path); err != nil {
+			return err
+		}
+		if keep <= 0 {
+			return nil
+		}
+
+		backups, err := filepath.Glob(filepath.Join(dir, sessionName+"-[0-9]*Z.db"))
+		if err != nil {
+			return err
+		}
+		// timestamps sort lexically, oldest first
+		sort.Strings(backups)
+		for _, old := range backups[:max(len(backups)-keep, 0)] {
+			if err := os.Remove(old); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strings"
+)
+
+// ErrorKind represents classification of database errors
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindNotFound
+	KindUniqueViolation
+	KindForeignKeyViolation
+	KindNotNullViolation
+	KindCheckViolation
+	KindTimeout
+	KindConnection
+)
+
+// String returns readable error kind name
+func (k ErrorKind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindUniqueViolation:
+		return "unique_violation"
+	case KindForeignKeyViolation:
+		return "foreign_key_violation"
+	case KindNotNullViolation:
+		return "not_null_violation"
+	case KindCheckViolation:
+		return "check_violation"
+	case KindTimeout:
+		return "timeout"
+	case KindConnection:
+		return "connection"
+	default:
+		return "unknown"
+	}
+}
+
+// sqlStateError is implemented by pgx errors
+type sqlStateError interface {
+	SQLState() string
+}
+
+// sqlErrorNumber is implemented by mssql errors
+type sqlErrorNumber interface {
+	SQLErrorNumber() int32
+}
+
+// Classify returns the kind of database error regardless of driver
+func Classify(err error) ErrorKind {
+	if err == nil {
+		return KindUnknown
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return KindNotFound
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	if errors.Is(err, sql.ErrConnDone) {
+		return KindConnection
+	}
+
+	// PostgreSQL SQLSTATE codes
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		switch code := stateErr.SQLState(); {
+		case code == "23505":
+			return KindUniqueViolation
+		case code == "23503":
+			return KindForeignKeyViolation
+		case code == "23502":
+			return KindNotNullViolation
+		case code == "23514":
+			return KindCheckViolation
+		case code == "57014":
+			return KindTimeout
+		case strings.HasPrefix(code, "08"):
+			return KindConnection
+		}
+	}
+
+	// MSSQL error numbers
+	var numberErr sqlErrorNumber
+	if errors.As(err, &numberErr) {
+		switch numberErr.SQLErrorNumber() {
+		case 2601, 2627:
+			return KindUniqueViolation
+		case 547:
+			return KindForeignKeyViolation
+		case 515:
+			return KindNotNullViolation
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return KindTimeout
+		}
+		return KindConnection
+	}
+
+	// Fallback to message matching for MySQL and SQLite
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "error 1062"),
+		strings.Contains(message, "unique constraint failed"),
+		strings.Contains(message, "duplicate key"):
+		return KindUniqueViolation
+	case strings.Contains(message, "error 1451"),
+		strings.Contains(message, "error 1452"),
+		strings.Contains(message, "foreign key constraint failed"):
+		return KindForeignKeyViolation
+	case strings.Contains(message, "error 1048"),
+		strings.Contains(message, "not null constraint failed"):
+		return KindNotNullViolation
+	case strings.Contains(message, "check constraint failed"):
+		return KindCheckViolation
+	case strings.Contains(message, "bad connection"),
+		strings.Contains(message, "connection refused"):
+		return KindConnection
+	}
+
+	return KindUnknown
+}
+
+// IsNotFound reports whether err means no rows were found
+func IsNotFound(err error) bool {
+	return Classify(err) == KindNotFound
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation
+func IsUniqueViolation(err error) bool {
+	return Classify(err) == KindUniqueViolation
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -26,6 +27,19 @@ type Config struct {
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
 	Debug           bool
+	// Redaction masks sensitive values of Debug query logs with slog instead of bundebug,
+	// its MaxLength and SampleRate also apply to slow query logs
+	Redaction *Redaction
+	// SlowQueryThreshold logs queries slower than threshold and aggregates them in QueryReport
+	SlowQueryThreshold time.Duration
+	// Replicas are DSNs of read replicas opened with the same driver, see Reader
+	Replicas []string
+	// StickyWindow routes reads to primary for this long after a write, see WithStickiness and MarkWrite
+	StickyWindow time.Duration
+	// Autotune adjusts MaxOpenConns/MaxIdleConns of the primary from pool wait statistics, see PoolTuning
+	Autotune *Autotune
+	// RLS sets PostgreSQL settings from request values at the start of every transaction, e.g. &DefaultRLS
+	RLS *RLS
 }
 
 // RedisConfig represents Redis configuration
@@ -42,16 +56,22 @@ type RedisConfig struct {
 
 // Session holds database connection info
 type Session struct {
-	Name   string
-	DB     *bun.DB
-	SqlDB  *sql.DB
-	Config Config
+	Name     string
+	DB       *bun.DB
+	SqlDB    *sql.DB
+	Config   Config
+	Replicas []*bun.DB
+
+	next  atomic.Uint64
+	tuner *poolTuner
 }
 
 // ConnectionManager manages all database connections
 type ConnectionManager struct {
 	sessions map[string]*Session
 	drivers  map[string]DatabaseDriver
+	models   []interface{}
+	hooks    []bun.QueryHook
 }
 
 // Global instances
@@ -73,6 +93,36 @@ func RegisterDriver(name string, driver DatabaseDriver) {
 	Manager.drivers[name] = driver
 }
 
+// RegisterModel registers bun models (e.g. m2m join models) on all current and future sessions,
+// typing their JSONColumn fields for the session dialect
+func RegisterModel(models ...interface{}) {
+	Manager.models = append(Manager.models, models...)
+	for _, session := range Manager.sessions {
+		registerModels(session.DB, models...)
+		for _, replica := range session.Replicas {
+			registerModels(replica, models...)
+		}
+	}
+}
+
+// AddQueryHook adds bun query hooks to primaries and replicas of all current and future sessions
+func AddQueryHook(hooks ...bun.QueryHook) {
+	Manager.hooks = append(Manager.hooks, hooks...)
+	for _, session := range Manager.sessions {
+		for _, hook := range hooks {
+			session.DB.AddQueryHook(hook)
+			for _, replica := range session.Replicas {
+				replica.AddQueryHook(hook)
+			}
+		}
+	}
+}
+
+// RegisteredModels returns models registered with RegisterModel
+func RegisteredModels() []interface{} {
+	return append([]interface{}(nil), Manager.models...)
+}
+
 // GetSession returns database session by name
 func GetSession(name string) (*Session, bool) {
 	session, exists := Manager.sessions[name]
@@ -95,6 +145,12 @@ func GetAllSessions() map[string]*Session {
 
 // Close closes specific database connection
 func (s *Session) Close() error {
+	if s.tuner != nil {
+		s.tuner.close()
+	}
+	for _, replica := range s.Replicas {
+		replica.Close()
+	}
 	if s.SqlDB != nil {
 		return s.SqlDB.Close()
 	}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// CopyBatchSize is rows per multi-row INSERT of CopyFrom fallback, at most 1000 on MSSQL
+var CopyBatchSize = 1000
+
+// CopySource yields rows of CopyFrom, pgx.CopyFromSource values such as pgx.CopyFromRows satisfy it
+type CopySource interface {
+	Next() bool
+	Values() ([]interface{}, error)
+	Err() error
+}
+
+// BulkCopier is implemented by drivers with native bulk loading, e.g. COPY on PostgreSQL
+type BulkCopier interface {
+	CopyFrom(ctx context.Context, sqlDB *sql.DB, table string, columns []string, src CopySource) (int64, error)
+}
+
+// CopyRows returns CopySource over rows already in memory
+func CopyRows(rows [][]interface{}) CopySource {
+	return &copyRows{rows: rows, index: -1}
+}
+
+type copyRows struct {
+	rows  [][]interface{}
+	index int
+}
+
+func (r *copyRows) Next() bool {
+	r.index++
+	return r.index < len(r.rows)
+}
+
+func (r *copyRows) Values() ([]interface{}, error) {
+	return r.rows[r.index], nil
+}
+
+func (r *copyRows) Err() error {
+	return nil
+}
+
+// CopyFrom bulk loads rows of src into columns of table and returns inserted count. Drivers implementing
+// BulkCopier load natively, others and transactions of ctx use chunked multi-row INSERTs of CopyBatchSize rows
+func (s *Session) CopyFrom(ctx context.Context, table string, columns []string, src CopySource) (int64, error) {
+	if !Supports(s.Name, CapWrites) {
+		return 0, ErrUnsupported(s.Name, "writes")
+	}
+
+	if _, inTx := TxFromContext(ctx, s.Name); !inTx {
+		if copier, ok := Manager.drivers[s.Config.Driver].(BulkCopier); ok {
+			count, err := copier.CopyFrom(ctx, s.SqlDB, table, columns, src)
+			return count, classified(err)
+		}
+	}
+
+	db, err := IDB(ctx, s.Name)
+	if err != nil {
+		return 0, err
+	}
+	return copyInserts(ctx, db, table, columns, src)
+}
+
+// copyInserts loads src with multi-row INSERTs
+func copyInserts(ctx context.Context, db bun.IDB, table string, columns []string, src CopySource) (int64, error) {
+	if len(columns) == 0 {
+		return 0, &DatabaseError{Message: "copy requires columns"}
+	}
+	batchSize := CopyBatchSize
+	if db.Dialect().Name() == dialect.MSSQL && batchSize > 1000 {
+		// MSSQL rejects VALUES lists of more than 1000 rows
+		batchSize = 1000
+	}
+
+	head := make([]interface{}, 0, len(columns)+1)
+	head = append(head, bun.Ident(table))
+	for _, column := range columns {
+		head = append(head, bun.Ident(column))
+	}
+	prefix := "INSERT INTO ? (" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ") VALUES "
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	var count int64
+	var batch [][]interface{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		var query strings.Builder
+		query.WriteString(prefix)
+		args := append([]interface{}{}, head...)
+		for i, row := range batch {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(tuple)
+			args = append(args, row...)
+		}
+		if _, err := db.ExecContext(ctx, query.String(), args...); err != nil {
+			return classified(err)
+		}
+		count += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	for src.Next() {
+		values, err := src.Values()
+		if err != nil {
+			return count, err
+		}
+		if len(values) != len(columns) {
+			return count, &DatabaseError{Message: "copy row does not match columns"}
+		}
+		batch = append(batch, values)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := src.Err(); err != nil {
+		return count, err
+	}
+	return count, flush()
+}
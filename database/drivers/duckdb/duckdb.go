@@ -0,0 +1,46 @@
+// Package drivers registers the "duckdb" database driver. It is a separate module, tagged
+// database/drivers/duckdb/vX.Y.Z after the root module, so its cgo bindings stay out of
+// applications not using it. Depend on it next to the root module:
+//
+//	go get github.com/rikiihsan/nest/database/drivers/duckdb
+//
+// and import it for its side effect:
+//
+//	import _ "github.com/rikiihsan/nest/database/drivers/duckdb"
+//
+// To work on it against a local checkout of the root module, use an uncommitted workspace:
+//
+//	go work init . ./database/drivers/duckdb
+package drivers
+
+import (
+	"database/sql"
+
+	"github.com/rikiihsan/nest/database"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+// DuckDBDriver opens embedded DuckDB databases, empty DSN is in-memory.
+// bun has no DuckDB dialect, PostgreSQL dialect is used since DuckDB follows its SQL syntax.
+// DuckDB returns native integer widths, scan INTEGER into int32 fields or cast to BIGINT
+type DuckDBDriver struct{}
+
+func (d *DuckDBDriver) Open(dsn string) (*sql.DB, error) {
+	return sql.Open("duckdb", dsn)
+}
+
+func (d *DuckDBDriver) CreateBunDB(sqlDB *sql.DB) *bun.DB {
+	return bun.NewDB(sqlDB, pgdialect.New())
+}
+
+func (d *DuckDBDriver) GetDriverName() string {
+	return "duckdb"
+}
+
+// Register DuckDB driver
+func init() {
+	database.RegisterDriver("duckdb", &DuckDBDriver{})
+}
@@ -1,19 +1,71 @@
 package mssql
 
 import (
+	"context"
 	"database/sql"
+	"sync"
+	"time"
 
 	"github.com/rikiihsan/nest/database"
 
-	_ "github.com/microsoft/go-mssqldb"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/mssqldialect"
 )
 
-type MSSQLDriver struct{}
+// TokenProvider returns Azure AD access token for https://database.windows.net/ and its expiry,
+// e.g. from azidentity managed identity or workload federation credentials
+type TokenProvider func(ctx context.Context) (token string, expiresOn time.Time, err error)
+
+type MSSQLDriver struct {
+	// TokenProvider enables Azure AD token authentication instead of DSN user/password
+	TokenProvider TokenProvider
+	// RefreshBefore renews cached token this long before expiry, defaults to 5m
+	RefreshBefore time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresOn time.Time
+}
 
 func (d *MSSQLDriver) Open(dsn string) (*sql.DB, error) {
-	return sql.Open("sqlserver", dsn)
+	if d.TokenProvider == nil {
+		return sql.Open("sqlserver", dsn)
+	}
+
+	config, err := msdsn.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := mssql.NewSecurityTokenConnector(config, d.accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// accessToken returns cached token, renewing it when within RefreshBefore of expiry.
+// Called on every new physical connection, so pooled connections always log in with a valid token
+func (d *MSSQLDriver) accessToken(ctx context.Context) (string, error) {
+	refreshBefore := d.RefreshBefore
+	if refreshBefore <= 0 {
+		refreshBefore = 5 * time.Minute
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.token != "" && time.Until(d.expiresOn) > refreshBefore {
+		return d.token, nil
+	}
+
+	token, expiresOn, err := d.TokenProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	d.token, d.expiresOn = token, expiresOn
+	return token, nil
 }
 
 func (d *MSSQLDriver) CreateBunDB(sqlDB *sql.DB) *bun.DB {
@@ -24,6 +76,11 @@ func (d *MSSQLDriver) GetDriverName() string {
 	return "sqlserver"
 }
 
+// RegisterAzureAD registers MSSQL driver under name authenticating with Azure AD tokens from provider
+func RegisterAzureAD(name string, provider TokenProvider) {
+	database.RegisterDriver(name, &MSSQLDriver{TokenProvider: provider})
+}
+
 // Register MSSQL driver
 func init() {
 	database.RegisterDriver("sqlserver", &MSSQLDriver{})
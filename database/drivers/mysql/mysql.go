@@ -2,17 +2,89 @@ package drivers
 
 import (
 	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
 	"github.com/rikiihsan/nest/database"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/mysqldialect"
 )
 
-type MySQLDriver struct{}
+type MySQLDriver struct {
+	// Loc is location of time.Time values for DSNs without loc, defaults to UTC
+	Loc *time.Location
+	// Strict returns an error for DSNs missing parseTime, loc or utf8mb4 instead of fixing them with a warning
+	Strict bool
+}
 
 func (d *MySQLDriver) Open(dsn string) (*sql.DB, error) {
-	return sql.Open("mysql", dsn)
+	normalized, err := d.Normalize(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.Open("mysql", normalized)
+}
+
+// Normalize ensures parseTime=true, loc and utf8mb4 charset are set on dsn, an explicit loc is kept
+func (d *MySQLDriver) Normalize(dsn string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	loc := d.Loc
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var fixes []string
+	if !cfg.ParseTime {
+		cfg.ParseTime = true
+		fixes = append(fixes, "parseTime=true")
+	}
+	if _, ok := dsnParam(dsn, "loc"); !ok && cfg.Loc.String() != loc.String() {
+		cfg.Loc = loc
+		fixes = append(fixes, "loc="+loc.String())
+	}
+	if !strings.HasPrefix(cfg.Collation, "utf8mb4") || hasNonUTF8MB4Charset(dsn) {
+		if err := cfg.Apply(mysql.Charset("utf8mb4", "utf8mb4_unicode_ci")); err != nil {
+			return "", err
+		}
+		fixes = append(fixes, "charset=utf8mb4")
+	}
+
+	if len(fixes) == 0 {
+		return dsn, nil
+	}
+	if d.Strict {
+		return "", fmt.Errorf("mysql : dsn must set %s", strings.Join(fixes, ", "))
+	}
+	slog.Warn("mysql dsn normalized", "set", fixes)
+	return cfg.FormatDSN(), nil
+}
+
+// hasNonUTF8MB4Charset reports whether dsn sets charset param other than utf8mb4
+func hasNonUTF8MB4Charset(dsn string) bool {
+	value, ok := dsnParam(dsn, "charset")
+	return ok && value != "utf8mb4"
+}
+
+// dsnParam returns value of param name of dsn
+func dsnParam(dsn, name string) (string, bool) {
+	_, query, ok := strings.Cut(dsn, "?")
+	if !ok {
+		return "", false
+	}
+	for _, param := range strings.Split(query, "&") {
+		if value, ok := strings.CutPrefix(param, name+"="); ok {
+			return value, true
+		}
+	}
+	return "", false
 }
 
 func (d *MySQLDriver) CreateBunDB(sqlDB *sql.DB) *bun.DB {
@@ -1,18 +1,38 @@
 package drivers
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+
 	"github.com/rikiihsan/nest/database"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 )
 
-type PostgreSQLDriver struct{}
+type PostgreSQLDriver struct {
+	// PgBouncer uses simple protocol without statement caches, so sessions work behind
+	// transaction pooling pgbouncer where prepared statements don't survive between transactions
+	PgBouncer bool
+}
 
 func (d *PostgreSQLDriver) Open(dsn string) (*sql.DB, error) {
-	return sql.Open("pgx", dsn)
+	if !d.PgBouncer {
+		return sql.Open("pgx", dsn)
+	}
+
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	config.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	config.StatementCacheCapacity = 0
+	config.DescriptionCacheCapacity = 0
+	return stdlib.OpenDB(*config), nil
 }
 
 func (d *PostgreSQLDriver) CreateBunDB(sqlDB *sql.DB) *bun.DB {
@@ -23,7 +43,28 @@ func (d *PostgreSQLDriver) GetDriverName() string {
 	return "pgx"
 }
 
-// Register PostgreSQL driver
+// CopyFrom loads src into table with COPY on a pooled connection
+func (d *PostgreSQLDriver) CopyFrom(ctx context.Context, sqlDB *sql.DB, table string, columns []string, src database.CopySource) (int64, error) {
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var count int64
+	err = conn.Raw(func(driverConn interface{}) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("postgres : COPY needs pgx connection, got %T", driverConn)
+		}
+		count, err = pgxConn.Conn().CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, src)
+		return err
+	})
+	return count, err
+}
+
+// Register PostgreSQL drivers, "pgbouncer" is pgx in pgbouncer compatibility mode
 func init() {
 	database.RegisterDriver("pgx", &PostgreSQLDriver{})
+	database.RegisterDriver("pgbouncer", &PostgreSQLDriver{PgBouncer: true})
 }
@@ -1,18 +1,85 @@
 package drivers
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/rikiihsan/nest/database"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/sqlitedialect"
 )
 
-type SQLiteDriver struct{}
+// Options represents PRAGMAs applied on every new connection
+type Options struct {
+	// JournalMode e.g. WAL, DELETE, MEMORY, empty keeps database default
+	JournalMode string
+	// BusyTimeout waits for locks instead of failing with SQLITE_BUSY
+	BusyTimeout time.Duration
+	// ForeignKeys enables foreign key enforcement
+	ForeignKeys bool
+	// CacheSize in pages, negative values are KiB as in PRAGMA cache_size
+	CacheSize int
+	// InitSQL runs after PRAGMAs
+	InitSQL []string
+}
+
+// DefaultOptions are used by the "sqlite" driver
+var DefaultOptions = Options{
+	JournalMode: "WAL",
+	BusyTimeout: 5 * time.Second,
+}
+
+// statements returns SQL run on connect
+func (o Options) statements() []string {
+	var stmts []string
+	if o.JournalMode != "" {
+		stmts = append(stmts, "PRAGMA journal_mode = "+o.JournalMode)
+	}
+	if o.BusyTimeout > 0 {
+		stmts = append(stmts, fmt.Sprintf("PRAGMA busy_timeout = %d", o.BusyTimeout.Milliseconds()))
+	}
+	if o.ForeignKeys {
+		stmts = append(stmts, "PRAGMA foreign_keys = ON")
+	}
+	if o.CacheSize != 0 {
+		stmts = append(stmts, fmt.Sprintf("PRAGMA cache_size = %d", o.CacheSize))
+	}
+	return append(stmts, o.InitSQL...)
+}
+
+// registered counts sql drivers registered for option sets
+var registered atomic.Int64
+
+type SQLiteDriver struct {
+	Options Options
+
+	once    sync.Once
+	sqlName string
+}
 
 func (d *SQLiteDriver) Open(dsn string) (*sql.DB, error) {
-	return sql.Open("sqlite3", dsn)
+	// database/sql drivers are global, so every option set gets its own connect hook driver
+	d.once.Do(func() {
+		d.sqlName = fmt.Sprintf("sqlite3_nest_%d", registered.Add(1))
+		stmts := d.Options.statements()
+		sql.Register(d.sqlName, &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				for _, stmt := range stmts {
+					if _, err := conn.Exec(stmt, nil); err != nil {
+						return fmt.Errorf("sqlite : %s: %w", stmt, err)
+					}
+				}
+				return nil
+			},
+		})
+	})
+	return sql.Open(d.sqlName, dsn)
 }
 
 func (d *SQLiteDriver) CreateBunDB(sqlDB *sql.DB) *bun.DB {
@@ -23,7 +90,71 @@ func (d *SQLiteDriver) GetDriverName() string {
 	return "sqlite"
 }
 
+// BackupPages is pages copied per backup step, locks are released between steps so writers keep going
+var BackupPages = 1024
+
+// Backup copies database of sqlDB to path with the online backup API
+func (d *SQLiteDriver) Backup(ctx context.Context, sqlDB *sql.DB, path string) error {
+	target, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+	return copyDatabase(ctx, target, sqlDB)
+}
+
+// Restore overwrites database of sqlDB with backup at path, visible to all pooled connections
+func (d *SQLiteDriver) Restore(ctx context.Context, sqlDB *sql.DB, path string) error {
+	source, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	return copyDatabase(ctx, sqlDB, source)
+}
+
+// copyDatabase copies main database of source into target in steps of BackupPages
+func copyDatabase(ctx context.Context, target, source *sql.DB) error {
+	targetConn, err := target.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer targetConn.Close()
+	sourceConn, err := source.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer sourceConn.Close()
+
+	return targetConn.Raw(func(targetRaw interface{}) error {
+		return sourceConn.Raw(func(sourceRaw interface{}) error {
+			backup, err := targetRaw.(*sqlite3.SQLiteConn).Backup("main", sourceRaw.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := backup.Step(BackupPages)
+				if err != nil || done {
+					if finishErr := backup.Finish(); err == nil {
+						err = finishErr
+					}
+					return err
+				}
+				if err := ctx.Err(); err != nil {
+					backup.Finish()
+					return err
+				}
+			}
+		})
+	})
+}
+
+// RegisterWithOptions registers SQLite driver under name applying options on every connection
+func RegisterWithOptions(name string, options Options) {
+	database.RegisterDriver(name, &SQLiteDriver{Options: options})
+}
+
 // Register SQLite driver
 func init() {
-	database.RegisterDriver("sqlite", &SQLiteDriver{})
+	RegisterWithOptions("sqlite", DefaultOptions)
 }
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// Plan represents EXPLAIN output of a query
+type Plan struct {
+	Dialect  string     `json:"dialect"`
+	Query    string     `json:"query"`
+	Analyzed bool       `json:"analyzed"`
+	Nodes    []PlanNode `json:"nodes"`
+	// PlanningMs and ExecutionMs are reported by PostgreSQL
+	PlanningMs  float64 `json:"planning_ms,omitempty"`
+	ExecutionMs float64 `json:"execution_ms,omitempty"`
+	// Raw is unparsed output, JSON on PostgreSQL and text elsewhere
+	Raw string `json:"raw"`
+}
+
+// PlanNode represents step of a query plan, estimates and actuals are filled where the dialect reports them
+type PlanNode struct {
+	Operation    string     `json:"operation"`
+	Relation     string     `json:"relation,omitempty"`
+	Index        string     `json:"index,omitempty"`
+	Detail       string     `json:"detail,omitempty"`
+	Cost         float64    `json:"cost,omitempty"`
+	Rows         float64    `json:"rows,omitempty"`
+	ActualRows   float64    `json:"actual_rows,omitempty"`
+	ActualTimeMs float64    `json:"actual_time_ms,omitempty"`
+	Children     []PlanNode `json:"children,omitempty"`
+}
+
+// Statement is named query registered for explaining by debug endpoints
+type Statement struct {
+	Name    string `json:"name"`
+	Session string `json:"session"`
+	Query   string `json:"query"`
+}
+
+var (
+	statementsMu sync.RWMutex
+	statements   = map[string]Statement{}
+)
+
+// RegisterStatement registers query under name so database/explain endpoints can explain it, session defaults to "default"
+func RegisterStatement(name, query string, session ...string) {
+	sessionName := "default"
+	if len(session) > 0 {
+		sessionName = session[0]
+	}
+
+	statementsMu.Lock()
+	defer statementsMu.Unlock()
+	statements[name] = Statement{Name: name, Session: sessionName, Query: query}
+}
+
+// GetStatement returns statement registered under name
+func GetStatement(name string) (Statement, bool) {
+	statementsMu.RLock()
+	defer statementsMu.RUnlock()
+	statement, ok := statements[name]
+	return statement, ok
+}
+
+// Statements returns registered statements ordered by name
+func Statements() []Statement {
+	statementsMu.RLock()
+	defer statementsMu.RUnlock()
+
+	list := make([]Statement, 0, len(statements))
+	for _, statement := range statements {
+		list = append(list, statement)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Explain returns estimated plan of query on primary without running it. Supported on PostgreSQL, MySQL and SQLite
+func (s *Session) Explain(ctx context.Context, query string, args ...interface{}) (*Plan, error) {
+	return s.explain(ctx, s.DB, false, query, args)
+}
+
+// ExplainAnalyze runs query to report actual rows and timings, inside a transaction that is always rolled back
+// so writes are discarded. Supported on PostgreSQL and MySQL 8.0.18+
+func (s *Session) ExplainAnalyze(ctx context.Context, query string, args ...interface{}) (*Plan, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, classified(err)
+	}
+	defer tx.Rollback()
+	return s.explain(ctx, tx, true, query, args)
+}
+
+func (s *Session) explain(ctx context.Context, db bun.IDB, analyze bool, query string, args []interface{}) (*Plan, error) {
+	plan := &Plan{Dialect: db.Dialect().Name().String(), Query: query, Analyzed: analyze}
+
+	var err error
+	switch db.Dialect().Name() {
+	case dialect.PG:
+		err = explainPG(ctx, db, plan, args)
+	case dialect.MySQL:
+		err = explainMySQL(ctx, db, plan, args)
+	case dialect.SQLite:
+		if analyze {
+			return nil, ErrUnsupported(s.Name, "EXPLAIN ANALYZE")
+		}
+		err = explainSQLite(ctx, db, plan, args)
+	default:
+		return nil, ErrUnsupported(s.Name, "EXPLAIN")
+	}
+	if err != nil {
+		return nil, classified(err)
+	}
+	return plan, nil
+}
+
+func explainPG(ctx context.Context, db bun.IDB, plan *Plan, args []interface{}) error {
+	options := "FORMAT JSON"
+	if plan.Analyzed {
+		options = "ANALYZE, BUFFERS, FORMAT JSON"
+	}
+	if err := db.QueryRowContext(ctx, "EXPLAIN ("+options+") "+plan.Query, args...).Scan(&plan.Raw); err != nil {
+		return err
+	}
+
+	var output []struct {
+		Plan          pgPlanNode `json:"Plan"`
+		PlanningTime  float64    `json:"Planning Time"`
+		ExecutionTime float64    `json:"Execution Time"`
+	}
+	if err := json.Unmarshal([]byte(plan.Raw), &output); err != nil {
+		return err
+	}
+	for _, item := range output {
+		plan.Nodes = append(plan.Nodes, item.Plan.node())
+		plan.PlanningMs = item.PlanningTime
+		plan.ExecutionMs = item.ExecutionTime
+	}
+	return nil
+}
+
+// pgPlanNode is node of PostgreSQL JSON plans
+type pgPlanNode struct {
+	NodeType   string       `json:"Node Type"`
+	Relation   string       `json:"Relation Name"`
+	Index      string       `json:"Index Name"`
+	Filter     string       `json:"Filter"`
+	IndexCond  string       `json:"Index Cond"`
+	TotalCost  float64      `json:"Total Cost"`
+	PlanRows   float64      `json:"Plan Rows"`
+	ActualRows float64      `json:"Actual Rows"`
+	ActualTime float64      `json:"Actual Total Time"`
+	Plans      []pgPlanNode `json:"Plans"`
+}
+
+func (n pgPlanNode) node() PlanNode {
+	node := PlanNode{
+		Operation:    n.NodeType,
+		Relation:     n.Relation,
+		Index:        n.Index,
+		Detail:       strings.TrimSpace(n.IndexCond + " " + n.Filter),
+		Cost:         n.TotalCost,
+		Rows:         n.PlanRows,
+		ActualRows:   n.ActualRows,
+		ActualTimeMs: n.ActualTime,
+	}
+	for _, child := range n.Plans {
+		node.Children = append(node.Children, child.node())
+	}
+	return node
+}
+
+func explainMySQL(ctx context.Context, db bun.IDB, plan *Plan, args []interface{}) error {
+	if plan.Analyzed {
+		// EXPLAIN ANALYZE returns the iterator tree as a single text column
+		return db.QueryRowContext(ctx, "EXPLAIN ANALYZE "+plan.Query, args...).Scan(&plan.Raw)
+	}
+
+	rows, err := db.QueryContext(ctx, "EXPLAIN "+plan.Query, args...)
+	if err != nil {
+		return err
+	}
+	records, err := scanRecords(rows)
+	if err != nil {
+		return err
+	}
+
+	var raw strings.Builder
+	for _, record := range records {
+		rowCount, _ := strconv.ParseFloat(record["rows"], 64)
+		plan.Nodes = append(plan.Nodes, PlanNode{
+			Operation: strings.TrimSpace(record["select_type"] + " " + record["type"]),
+			Relation:  record["table"],
+			Index:     record["key"],
+			Detail:    record["Extra"],
+			Rows:      rowCount,
+		})
+		fmt.Fprintf(&raw, "%s\t%s\t%s\t%s\t%s\t%s\n", record["id"], record["select_type"], record["table"], record["type"], record["key"], record["Extra"])
+	}
+	plan.Raw = raw.String()
+	return nil
+}
+
+func explainSQLite(ctx context.Context, db bun.IDB, plan *Plan, args []interface{}) error {
+	rows, err := db.QueryContext(ctx, "EXPLAIN QUERY PLAN "+plan.Query, args...)
+	if err != nil {
+		return err
+	}
+	records, err := scanRecords(rows)
+	if err != nil {
+		return err
+	}
+
+	// rows reference parent ids, build the tree bottom-up from the last row
+	children := map[string][]PlanNode{}
+	var raw strings.Builder
+	for _, record := range records {
+		fmt.Fprintf(&raw, "%s\t%s\t%s\n", record["id"], record["parent"], record["detail"])
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		node := PlanNode{Operation: record["detail"], Children: children[record["id"]]}
+		children[record["parent"]] = append([]PlanNode{node}, children[record["parent"]]...)
+	}
+	plan.Nodes = children["0"]
+	plan.Raw = raw.String()
+	return nil
+}
+
+// scanRecords reads rows as column name to text maps and closes them
+func scanRecords(rows *sql.Rows) ([]map[string]string, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var records []map[string]string
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		record := make(map[string]string, len(columns))
+		for i, column := range columns {
+			record[column] = values[i].String
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,66 @@
+package explain
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/authz"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/response"
+)
+
+// Permission is required by Register endpoints through authz.Require
+var Permission = "database:explain"
+
+// ErrUnknownStatement is returned for names not registered with database.RegisterStatement
+var ErrUnknownStatement = errors.New("explain : statement is not registered")
+
+// Request is body of POST /explain/:name
+type Request struct {
+	Args    []interface{} `json:"args"`
+	Analyze bool          `json:"analyze"`
+}
+
+// Register mounts debug endpoints explaining statements registered with database.RegisterStatement,
+// arbitrary SQL is never accepted. Every route requires Permission
+//
+//	GET  /explain
+//	POST /explain/:name   body {"args": [...], "analyze": false}
+func Register(router fiber.Router) {
+	group := router.Group("/explain", authz.Require(Permission))
+	group.Get("/", listHandler)
+	group.Post("/:name", explainHandler)
+}
+
+func listHandler(c *fiber.Ctx) error {
+	return response.Success(c, database.Statements(), nil)
+}
+
+func explainHandler(c *fiber.Ctx) error {
+	statement, ok := database.GetStatement(c.Params("name"))
+	if !ok {
+		return response.Error(c, fiber.StatusNotFound, ErrUnknownStatement)
+	}
+
+	var req Request
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, err)
+		}
+	}
+
+	session, ok := database.GetSession(statement.Session)
+	if !ok {
+		return response.Error(c, fiber.StatusInternalServerError, database.ErrSessionNotFound(statement.Session))
+	}
+
+	explain := session.Explain
+	if req.Analyze {
+		explain = session.ExplainAnalyze
+	}
+	plan, err := explain(c.UserContext(), statement.Query, req.Args...)
+	if err != nil {
+		return response.Error(c, fiber.StatusUnprocessableEntity, err)
+	}
+	return response.Success(c, plan, nil)
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// QueryToCSV streams query rows to w as CSV with a header row, reading from a replica when configured
+func (s *Session) QueryToCSV(ctx context.Context, w io.Writer, query string, args ...interface{}) (int64, error) {
+	rows, err := s.exportRows(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return 0, err
+	}
+
+	// RawBytes are reused by driver between rows, record strings are written before next Scan
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, err
+		}
+		for i, value := range values {
+			record[i] = string(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+// QueryToJSONLines streams query rows to w as one JSON object per line, reading from a replica when configured
+func (s *Session) QueryToJSONLines(ctx context.Context, w io.Writer, query string, args ...interface{}) (int64, error) {
+	rows, err := s.exportRows(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	// Column keys are encoded once and reused for every row
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		key, _ := json.Marshal(column)
+		keys[i] = append(key, ':')
+	}
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	buf := bufio.NewWriter(w)
+	line := make([]byte, 0, 512)
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, err
+		}
+
+		line = append(line[:0], '{')
+		for i, value := range values {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			line = append(line, keys[i]...)
+			if line, err = appendJSONValue(line, value); err != nil {
+				return count, err
+			}
+		}
+		line = append(line, '}', '\n')
+
+		if _, err := buf.Write(line); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, buf.Flush()
+}
+
+// exportRows runs query on Reader of session
+func (s *Session) exportRows(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db, err := Reader(ctx, s.Name)
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, query, args...)
+}
+
+// appendJSONValue appends driver value as JSON, avoiding reflection for common types
+func appendJSONValue(dst []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(dst, "null"...), nil
+	case int64:
+		return strconv.AppendInt(dst, v, 10), nil
+	case float64:
+		return strconv.AppendFloat(dst, v, 'g', -1, 64), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case []byte:
+		return appendJSONString(dst, string(v))
+	case string:
+		return appendJSONString(dst, v)
+	case time.Time:
+		dst = append(dst, '"')
+		dst = v.AppendFormat(dst, time.RFC3339Nano)
+		return append(dst, '"'), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return dst, err
+		}
+		return append(dst, encoded...), nil
+	}
+}
+
+// appendJSONString appends s as JSON string
+func appendJSONString(dst []byte, s string) ([]byte, error) {
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, encoded...), nil
+}
@@ -0,0 +1,173 @@
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is directory of fixture files used by Load
+var Dir = "testdata/fixtures"
+
+// funcs are available in fixture templates, e.g. {{ now }} or {{ uuid }}
+var funcs = template.FuncMap{
+	"now": func() string {
+		return time.Now().UTC().Format(time.RFC3339Nano)
+	},
+	"uuid": uuid.NewString,
+	"daysAgo": func(days int) string {
+		return time.Now().UTC().AddDate(0, 0, -days).Format(time.RFC3339Nano)
+	},
+}
+
+// Funcs adds template functions available in fixture files
+func Funcs(fm template.FuncMap) {
+	for name, fn := range fm {
+		funcs[name] = fn
+	}
+}
+
+// table holds rows of one table in file order
+type table struct {
+	name string
+	rows []map[string]interface{}
+}
+
+// Load truncates tables of fixture files in Dir and inserts their rows, failing the test on error
+func Load(t testing.TB, session string, files ...string) {
+	t.Helper()
+	if err := LoadFS(context.Background(), session, os.DirFS(Dir), files...); err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+}
+
+// LoadFS loads YAML or JSON fixture files from fsys in one transaction.
+// Files map table names to rows, tables are inserted in file order and truncated in reverse order
+func LoadFS(ctx context.Context, session string, fsys fs.FS, files ...string) error {
+	var tables []table
+	for _, file := range files {
+		parsed, err := parse(fsys, file)
+		if err != nil {
+			return err
+		}
+		tables = append(tables, parsed...)
+	}
+
+	return database.RunInTx(ctx, session, func(ctx context.Context) error {
+		db, err := database.IDB(ctx, session)
+		if err != nil {
+			return err
+		}
+
+		if err := deferConstraints(ctx, db); err != nil {
+			return err
+		}
+		if err := truncate(ctx, db, tables); err != nil {
+			return err
+		}
+		for _, t := range tables {
+			for i, row := range t.rows {
+				if _, err := db.NewInsert().Model(&row).TableExpr("?", bun.Ident(t.name)).Exec(ctx); err != nil {
+					return fmt.Errorf("failed to insert row %d of %s: %w", i, t.name, err)
+				}
+			}
+		}
+		return restoreConstraints(ctx, db)
+	})
+}
+
+// parse renders file template and decodes tables keeping their order
+func parse(fsys fs.FS, file string) ([]table, error) {
+	raw, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", file, err)
+	}
+
+	tmpl, err := template.New(file).Funcs(funcs).Parse(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", file, err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, nil); err != nil {
+		return nil, fmt.Errorf("failed to render fixture %s: %w", file, err)
+	}
+
+	// JSON is valid YAML so one decoder handles both
+	var doc yaml.Node
+	if err := yaml.Unmarshal(rendered.Bytes(), &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture %s: %w", file, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("fixture %s must map table names to rows", file)
+	}
+
+	var tables []table
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		t := table{name: root.Content[i].Value}
+		if err := root.Content[i+1].Decode(&t.rows); err != nil {
+			return nil, fmt.Errorf("failed to decode rows of %s in %s: %w", t.name, file, err)
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// truncate removes rows of tables in reverse order so children go before parents
+func truncate(ctx context.Context, db bun.IDB, tables []table) error {
+	if db.Dialect().Name() == dialect.PG && len(tables) > 0 {
+		names := make([]interface{}, len(tables))
+		for i, t := range tables {
+			names[i] = bun.Ident(t.name)
+		}
+		_, err := db.ExecContext(ctx, "TRUNCATE TABLE ? RESTART IDENTITY CASCADE", bun.In(names))
+		return err
+	}
+
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, err := db.ExecContext(ctx, "DELETE FROM ?", bun.Ident(tables[i].name)); err != nil {
+			return fmt.Errorf("failed to truncate %s: %w", tables[i].name, err)
+		}
+	}
+	return nil
+}
+
+// deferConstraints postpones foreign key checks until commit where supported
+func deferConstraints(ctx context.Context, db bun.IDB) error {
+	var query string
+	switch db.Dialect().Name() {
+	case dialect.PG:
+		query = "SET CONSTRAINTS ALL DEFERRED"
+	case dialect.MySQL:
+		query = "SET FOREIGN_KEY_CHECKS = 0"
+	case dialect.SQLite:
+		query = "PRAGMA defer_foreign_keys = ON"
+	default:
+		return nil
+	}
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+// restoreConstraints re-enables checks disabled for the connection
+func restoreConstraints(ctx context.Context, db bun.IDB) error {
+	if db.Dialect().Name() != dialect.MySQL {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+	return err
+}
@@ -0,0 +1,137 @@
+package fixtures
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rikiihsan/nest/database"
+	_ "github.com/rikiihsan/nest/database/drivers/sqlite"
+)
+
+func TestParse(t *testing.T) {
+	fsys := fstest.MapFS{
+		"users.yaml": {Data: []byte(`
+users:
+  - id: 1
+    name: '{{ upper "ann" }}'
+posts:
+  - id: 1
+    user_id: 1
+    token: "{{ uuid }}"
+`)},
+		"tags.json":  {Data: []byte(`{"tags": [{"id": 1, "name": "go"}, {"id": 2, "name": "sql"}]}`)},
+		"empty.yaml": {Data: []byte("")},
+		"list.yaml":  {Data: []byte("- id: 1\n")},
+	}
+	Funcs(map[string]interface{}{"upper": strings.ToUpper})
+
+	tables, err := parse(fsys, "users.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[0].name != "users" || tables[1].name != "posts" {
+		t.Fatalf("tables not in file order: %+v", tables)
+	}
+	if name := tables[0].rows[0]["name"]; name != "ANN" {
+		t.Errorf("custom func not rendered, got %v", name)
+	}
+	if token, _ := tables[1].rows[0]["token"].(string); len(token) != 36 {
+		t.Errorf("uuid not rendered, got %q", token)
+	}
+
+	tables, err = parse(fsys, "tags.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || len(tables[0].rows) != 2 || tables[0].rows[1]["name"] != "sql" {
+		t.Errorf("unexpected JSON tables: %+v", tables)
+	}
+
+	if tables, err := parse(fsys, "empty.yaml"); err != nil || len(tables) != 0 {
+		t.Errorf("expected no tables for empty file, got %+v, %v", tables, err)
+	}
+	if _, err := parse(fsys, "list.yaml"); err == nil {
+		t.Error("expected error for file not mapping tables")
+	}
+	if _, err := parse(fsys, "missing.yaml"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	session := "fixtures_test"
+	if err := database.Init(database.Config{
+		Name:   session,
+		Driver: "sqlite",
+		Dsn:    "file:" + filepath.Join(t.TempDir(), "fixtures.db"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.CloseAll() })
+
+	ctx := context.Background()
+	db, err := database.GetDB(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users (id), title TEXT)",
+		"INSERT INTO users (id, name) VALUES (9, 'stale')",
+	} {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Children come first to check foreign keys are deferred until commit
+	fsys := fstest.MapFS{
+		"blog.yaml": {Data: []byte(`
+posts:
+  - id: 1
+    user_id: 1
+    title: hello
+users:
+  - id: 1
+    name: ann
+  - id: 2
+    name: bob
+`)},
+		"broken.yaml": {Data: []byte("users:\n  - id: 1\n    missing: x\n")},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := LoadFS(ctx, session, fsys, "blog.yaml"); err != nil {
+			t.Fatalf("load %d: %v", i, err)
+		}
+	}
+
+	var names []string
+	if err := db.NewSelect().Table("users").Column("name").Order("id").Scan(ctx, &names); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "ann,bob" {
+		t.Errorf("users not replaced by fixtures, got %v", names)
+	}
+	posts, err := db.NewSelect().Table("posts").Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts != 1 {
+		t.Errorf("expected 1 post, got %d", posts)
+	}
+
+	if err := LoadFS(ctx, session, fsys, "broken.yaml"); err == nil {
+		t.Fatal("expected error for unknown column")
+	}
+	count, err := db.NewSelect().Table("users").Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("failed load was not rolled back, got %d users", count)
+	}
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// Capability represents features a driver supports
+type Capability uint
+
+const (
+	CapTransactions Capability = 1 << iota
+	CapMigrations
+	CapWrites
+
+	// CapAll is assumed for drivers not implementing CapabilityProvider
+	CapAll = CapTransactions | CapMigrations | CapWrites
+)
+
+// CapabilityProvider is implemented by drivers supporting only some capabilities
+type CapabilityProvider interface {
+	Capabilities() Capability
+}
+
+// GenericDriver adapts any registered database/sql driver, e.g. Trino or Snowflake
+type GenericDriver struct {
+	sqlDriverName string
+	dialect       schema.Dialect
+	capabilities  Capability
+}
+
+// NewGenericDriver creates driver opening sqlDriverName connections with dialect.
+// Capabilities default to CapAll, query engines without transactions should declare e.g. CapWrites only
+func NewGenericDriver(sqlDriverName string, dialect schema.Dialect, capabilities ...Capability) *GenericDriver {
+	d := &GenericDriver{sqlDriverName: sqlDriverName, dialect: dialect, capabilities: CapAll}
+	if len(capabilities) > 0 {
+		d.capabilities = 0
+		for _, capability := range capabilities {
+			d.capabilities |= capability
+		}
+	}
+	return d
+}
+
+func (d *GenericDriver) Open(dsn string) (*sql.DB, error) {
+	return sql.Open(d.sqlDriverName, dsn)
+}
+
+func (d *GenericDriver) CreateBunDB(sqlDB *sql.DB) *bun.DB {
+	return bun.NewDB(sqlDB, d.dialect)
+}
+
+func (d *GenericDriver) GetDriverName() string {
+	return d.sqlDriverName
+}
+
+func (d *GenericDriver) Capabilities() Capability {
+	return d.capabilities
+}
+
+// Supports reports whether driver of session supports capability
+func Supports(sessionName string, capability Capability) bool {
+	session, exists := Manager.sessions[sessionName]
+	if !exists {
+		return false
+	}
+	provider, ok := Manager.drivers[session.Config.Driver].(CapabilityProvider)
+	if !ok {
+		return true
+	}
+	return provider.Capabilities()&capability == capability
+}
+
+// ErrUnsupported returns error for capability missing on driver of session
+func ErrUnsupported(sessionName string, capability string) error {
+	return &DatabaseError{Message: fmt.Sprintf("session '%s' does not support %s", sessionName, capability)}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// IterateBatchSize is rows fetched per round trip by Iterate
+var IterateBatchSize = 1000
+
+var cursorSeq atomic.Uint64
+
+// Iterate runs raw SQL on Reader of session and calls fn for every row scanned into T without loading
+// all rows in memory. PostgreSQL fetches batches from a server-side cursor in a read-only transaction,
+// or in the transaction of ctx. Other dialects page with LIMIT/OFFSET, so query needs a stable ORDER BY,
+// on MSSQL it must end with ORDER BY. Error of fn stops iteration and is returned as is
+func Iterate[T any](ctx context.Context, sessionName string, query string, fn func(T) error, args ...interface{}) error {
+	db, err := Reader(ctx, sessionName)
+	if err != nil {
+		return err
+	}
+
+	if db.Dialect().Name() == dialect.PG {
+		return iterateCursor(ctx, db, query, fn, args)
+	}
+	return iteratePages(ctx, db, query, fn, args)
+}
+
+// iterateCursor fetches batches from a PostgreSQL cursor declared for query
+func iterateCursor[T any](ctx context.Context, db bun.IDB, query string, fn func(T) error, args []interface{}) error {
+	tx, ok := db.(bun.Tx)
+	if !ok {
+		var err error
+		if tx, err = db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true}); err != nil {
+			return classified(err)
+		}
+		defer tx.Rollback()
+	}
+
+	cursor := fmt.Sprintf("nest_iterate_%d", cursorSeq.Add(1))
+	if _, err := tx.NewRaw("DECLARE ? NO SCROLL CURSOR FOR "+query, append([]interface{}{bun.Ident(cursor)}, args...)...).Exec(ctx); err != nil {
+		return classified(err)
+	}
+	defer tx.ExecContext(context.WithoutCancel(ctx), "CLOSE ?", bun.Ident(cursor))
+
+	for {
+		var batch []T
+		if err := tx.NewRaw("FETCH FORWARD ? FROM ?", IterateBatchSize, bun.Ident(cursor)).Scan(ctx, &batch); err != nil {
+			return classified(err)
+		}
+		for _, row := range batch {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(batch) < IterateBatchSize {
+			return nil
+		}
+	}
+}
+
+// iteratePages runs query once per page of IterateBatchSize rows
+func iteratePages[T any](ctx context.Context, db bun.IDB, query string, fn func(T) error, args []interface{}) error {
+	paged := "SELECT * FROM (" + query + ") AS nest_iterate LIMIT ? OFFSET ?"
+	if db.Dialect().Name() == dialect.MSSQL {
+		paged = query + " OFFSET ? ROWS FETCH NEXT ? ROWS ONLY"
+	}
+
+	for offset := 0; ; offset += IterateBatchSize {
+		pageArgs := append(append([]interface{}{}, args...), IterateBatchSize, offset)
+		if db.Dialect().Name() == dialect.MSSQL {
+			pageArgs = append(append([]interface{}{}, args...), offset, IterateBatchSize)
+		}
+
+		var batch []T
+		if err := db.NewRaw(paged, pageArgs...).Scan(ctx, &batch); err != nil {
+			return classified(err)
+		}
+		for _, row := range batch {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(batch) < IterateBatchSize {
+			return nil
+		}
+	}
+}
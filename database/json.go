@@ -0,0 +1,119 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// JSONColumn stores V as JSON, models use it for typed JSON fields, e.g. Settings JSONColumn[Prefs].
+// Columns of models registered with RegisterModel are created as jsonb on PostgreSQL, JSON on MySQL,
+// NVARCHAR(MAX) on MSSQL and TEXT on SQLite, see JSONType
+type JSONColumn[T any] struct {
+	V T
+}
+
+// NewJSONColumn wraps v
+func NewJSONColumn[T any](v T) JSONColumn[T] {
+	return JSONColumn[T]{V: v}
+}
+
+// Value encodes V as JSON text, accepted by json, jsonb and text columns alike
+func (j JSONColumn[T]) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.V)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan decodes JSON text or bytes into V, NULL leaves the zero value
+func (j *JSONColumn[T]) Scan(src interface{}) error {
+	var zero T
+	j.V = zero
+
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		if len(src) == 0 {
+			return nil
+		}
+		return json.Unmarshal(src, &j.V)
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &j.V)
+	default:
+		return fmt.Errorf("database : cannot scan %T into JSONColumn", src)
+	}
+}
+
+// MarshalJSON encodes V so API responses show the plain value
+func (j JSONColumn[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.V)
+}
+
+// UnmarshalJSON decodes into V
+func (j *JSONColumn[T]) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &j.V)
+}
+
+func (JSONColumn[T]) columnType(name dialect.Name) string {
+	return JSONType(name)
+}
+
+// columnTyper is implemented by column helpers whose type depends on the dialect
+type columnTyper interface {
+	columnType(name dialect.Name) string
+}
+
+var columnTyperType = reflect.TypeOf((*columnTyper)(nil)).Elem()
+
+// JSONType returns column type of JSON values for dialect
+func JSONType(name dialect.Name) string {
+	switch name {
+	case dialect.PG:
+		return "JSONB"
+	case dialect.MySQL:
+		return "JSON"
+	case dialect.MSSQL:
+		return "NVARCHAR(MAX)"
+	default:
+		return "TEXT"
+	}
+}
+
+// registerModels registers models on db and sets dialect column types of their JSONColumn and array fields without type tag
+func registerModels(db *bun.DB, models ...interface{}) {
+	db.RegisterModel(models...)
+
+	for _, model := range models {
+		typ := reflect.TypeOf(model)
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			continue
+		}
+		setColumnTypes(db.Dialect().Tables().Get(typ), db.Dialect().Name())
+	}
+}
+
+func setColumnTypes(table *schema.Table, name dialect.Name) {
+	for _, field := range table.Fields {
+		if _, tagged := field.Tag.Options["type"]; tagged || !field.IndirectType.Implements(columnTyperType) {
+			continue
+		}
+		sqlType := reflect.Zero(field.IndirectType).Interface().(columnTyper).columnType(name)
+		field.DiscoveredSQLType = sqlType
+		field.UserSQLType = sqlType
+		field.CreateTableSQLType = sqlType
+	}
+}
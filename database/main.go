@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -59,30 +60,14 @@ func (cm *ConnectionManager) createSession(config Config) error {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Configure connection pool
-	if config.MaxOpenConns > 0 {
-		sqlDB.SetMaxOpenConns(config.MaxOpenConns)
-	}
-	if config.MaxIdleConns > 0 {
-		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
-	}
-	if config.ConnMaxLifetime > 0 {
-		sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
-	}
-	if config.ConnMaxIdleTime > 0 {
-		sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
-	}
+	configurePool(sqlDB, config)
 
 	// Create Bun DB instance
 	bunDB := driver.CreateBunDB(sqlDB)
-
-	// Add debug hook if debug mode is enabled
-	if config.Debug {
-		bunDB.AddQueryHook(bundebug.NewQueryHook(
-			bundebug.WithVerbose(true),
-			bundebug.FromEnv("BUNDEBUG"),
-		))
+	if len(cm.models) > 0 {
+		registerModels(bunDB, cm.models...)
 	}
+	cm.addQueryHooks(bunDB, config)
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -93,17 +78,89 @@ func (cm *ConnectionManager) createSession(config Config) error {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	// Open read replicas
+	var replicas []*bun.DB
+	for i, dsn := range config.Replicas {
+		replicaSQL, err := driver.Open(dsn)
+		if err == nil {
+			err = replicaSQL.PingContext(ctx)
+		}
+		if err != nil {
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			sqlDB.Close()
+			return fmt.Errorf("failed to open replica %d: %w", i, err)
+		}
+		configurePool(replicaSQL, config)
+
+		replica := driver.CreateBunDB(replicaSQL)
+		if len(cm.models) > 0 {
+			registerModels(replica, cm.models...)
+		}
+		cm.addQueryHooks(replica, config)
+		replicas = append(replicas, replica)
+	}
+
 	// Store session
-	cm.sessions[config.Name] = &Session{
-		Name:   config.Name,
-		DB:     bunDB,
-		SqlDB:  sqlDB,
-		Config: config,
+	session := &Session{
+		Name:     config.Name,
+		DB:       bunDB,
+		SqlDB:    sqlDB,
+		Config:   config,
+		Replicas: replicas,
 	}
+	if config.Autotune != nil {
+		session.tuner = newPoolTuner(config.Name, sqlDB, *config.Autotune, config.MaxOpenConns)
+	}
+	cm.sessions[config.Name] = session
 
 	return nil
 }
 
+// addQueryHooks adds stickiness, debug, registered and slow query hooks of config to db,
+// used for primary and replicas alike
+func (cm *ConnectionManager) addQueryHooks(db *bun.DB, config Config) {
+	db.AddQueryHook(&stickyHook{session: config.Name})
+
+	// Add debug hook if debug mode is enabled
+	if config.Debug && config.Redaction != nil {
+		db.AddQueryHook(&queryLogHook{session: config.Name, redaction: config.Redaction.withDefaults()})
+	} else if config.Debug {
+		db.AddQueryHook(bundebug.NewQueryHook(
+			bundebug.WithVerbose(true),
+			bundebug.FromEnv("BUNDEBUG"),
+		))
+	}
+
+	for _, hook := range cm.hooks {
+		db.AddQueryHook(hook)
+	}
+	if config.SlowQueryThreshold > 0 {
+		hook := &slowQueryHook{session: config.Name, threshold: config.SlowQueryThreshold, redaction: DefaultRedaction.withDefaults()}
+		if config.Redaction != nil {
+			hook.redaction = config.Redaction.withDefaults()
+		}
+		db.AddQueryHook(hook)
+	}
+}
+
+// configurePool applies connection pool settings
+func configurePool(sqlDB *sql.DB, config Config) {
+	if config.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+	if config.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	}
+}
+
 // InitRedis initializes Redis connection
 func InitRedis(cfg RedisConfig) error {
 	RedisClient = redis.NewClient(&redis.Options{
@@ -206,6 +263,9 @@ func WithTransaction(ctx context.Context, sessionName string, fn func(tx bun.Tx)
 	}
 
 	return session.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := ApplyRLS(ctx, tx, sessionName); err != nil {
+			return err
+		}
 		return fn(tx)
 	})
 }
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// ErrUnknownView is returned by RefreshNow for views not registered with RegisterMaterializedView
+var ErrUnknownView = errors.New("database : materialized view is not registered")
+
+// MaterializedViewPrefix is prepended to Redis keys holding last refresh times shared by instances
+var MaterializedViewPrefix = "nest:matview:"
+
+// ViewStatus represents refresh state of a materialized view
+type ViewStatus struct {
+	Name         string        `json:"name"`
+	Session      string        `json:"session"`
+	Interval     time.Duration `json:"interval"`
+	Concurrent   bool          `json:"concurrent"`
+	LastRefresh  time.Time     `json:"last_refresh"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+	Refreshes    int64         `json:"refreshes"`
+	Failures     int64         `json:"failures"`
+}
+
+// Staleness returns time since the last successful refresh, zero before the first one
+func (s ViewStatus) Staleness() time.Duration {
+	if s.LastRefresh.IsZero() {
+		return 0
+	}
+	return time.Since(s.LastRefresh)
+}
+
+// materializedView serializes refreshes of a view within the process
+type materializedView struct {
+	refresh sync.Mutex
+	mu      sync.Mutex
+	status  ViewStatus
+}
+
+var (
+	viewsMu sync.RWMutex
+	views   = map[string]*materializedView{}
+)
+
+// RegisterMaterializedView registers PostgreSQL materialized view refreshed by RefreshDue every refreshInterval.
+// Concurrent refreshes keep the view readable and need a unique index on it. Session defaults to "default"
+func RegisterMaterializedView(name string, refreshInterval time.Duration, concurrent bool, session ...string) {
+	sessionName := "default"
+	if len(session) > 0 {
+		sessionName = session[0]
+	}
+
+	viewsMu.Lock()
+	defer viewsMu.Unlock()
+	views[name] = &materializedView{status: ViewStatus{
+		Name:       name,
+		Session:    sessionName,
+		Interval:   refreshInterval,
+		Concurrent: concurrent,
+	}}
+}
+
+// RefreshNow refreshes view immediately, waiting for a refresh already running
+func RefreshNow(ctx context.Context, name string) error {
+	viewsMu.RLock()
+	view, ok := views[name]
+	viewsMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownView, name)
+	}
+	return view.run(ctx)
+}
+
+// RefreshDue refreshes views whose interval elapsed since their last refresh by any instance, views
+// never refreshed are due. Schedule it, e.g. scheduler.Every("1m").Singleton().Do(database.RefreshDue)
+func RefreshDue(ctx context.Context) error {
+	var errs []error
+	for _, status := range MaterializedViews(ctx) {
+		if !status.LastRefresh.IsZero() && time.Since(status.LastRefresh) < status.Interval {
+			continue
+		}
+		if err := RefreshNow(ctx, status.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MaterializedViews returns status of registered views ordered by name. LastRefresh is the latest refresh
+// of any instance when Redis is initialized, the other fields are of this process
+func MaterializedViews(ctx context.Context) []ViewStatus {
+	viewsMu.RLock()
+	statuses := make([]ViewStatus, 0, len(views))
+	for _, view := range views {
+		view.mu.Lock()
+		statuses = append(statuses, view.status)
+		view.mu.Unlock()
+	}
+	viewsMu.RUnlock()
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	if RedisClient == nil || len(statuses) == 0 {
+		return statuses
+	}
+	keys := make([]string, len(statuses))
+	for i, status := range statuses {
+		keys[i] = viewKey(status)
+	}
+	shared, err := RedisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return statuses
+	}
+	for i, value := range shared {
+		raw, _ := value.(string)
+		nanos, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		if last := time.Unix(0, nanos); last.After(statuses[i].LastRefresh) {
+			statuses[i].LastRefresh = last
+		}
+	}
+	return statuses
+}
+
+func viewKey(status ViewStatus) string {
+	return MaterializedViewPrefix + status.Session + ":" + status.Name
+}
+
+// run refreshes view and records outcome
+func (v *materializedView) run(ctx context.Context) error {
+	v.refresh.Lock()
+	defer v.refresh.Unlock()
+
+	v.mu.Lock()
+	status := v.status
+	v.mu.Unlock()
+
+	start := time.Now()
+	err := refreshView(ctx, status)
+
+	v.mu.Lock()
+	v.status.LastDuration = time.Since(start)
+	if err != nil {
+		v.status.Failures++
+		v.status.LastError = err.Error()
+		v.mu.Unlock()
+		return fmt.Errorf("refresh materialized view %s: %w", status.Name, err)
+	}
+	v.status.Refreshes++
+	v.status.LastError = ""
+	v.status.LastRefresh = start
+	v.mu.Unlock()
+
+	// Shared so other instances don't refresh again and report staleness of this refresh
+	if RedisClient != nil {
+		if err := RedisClient.Set(ctx, viewKey(status), strconv.FormatInt(start.UnixNano(), 10), 0).Err(); err != nil {
+			slog.WarnContext(ctx, "failed to store materialized view refresh time", "view", status.Name, "error", err)
+		}
+	}
+	return nil
+}
+
+func refreshView(ctx context.Context, status ViewStatus) error {
+	db, err := GetDB(status.Session)
+	if err != nil {
+		return err
+	}
+	if db.Dialect().Name() != dialect.PG {
+		return ErrUnsupported(status.Session, "materialized views")
+	}
+
+	query := "REFRESH MATERIALIZED VIEW ?"
+	if status.Concurrent {
+		query = "REFRESH MATERIALIZED VIEW CONCURRENTLY ?"
+	}
+	_, err = db.ExecContext(ctx, query, bun.Ident(status.Name))
+	return classified(err)
+}
@@ -0,0 +1,38 @@
+package migrate
+
+import (
+	"github.com/uptrace/bun/migrate"
+)
+
+// Config represents migrator configuration
+type Config struct {
+	TableName      string
+	LocksTableName string
+	// Directory where new migration files are created
+	Directory string
+}
+
+var (
+	config = Config{
+		TableName:      "bun_migrations",
+		LocksTableName: "bun_migration_locks",
+		Directory:      "migrations",
+	}
+
+	// Migrations holds all registered migrations
+	Migrations = migrate.NewMigrations()
+)
+
+// Init sets migrator configuration, must be called before registering migrations
+func Init(cfg Config) {
+	if cfg.TableName != "" {
+		config.TableName = cfg.TableName
+	}
+	if cfg.LocksTableName != "" {
+		config.LocksTableName = cfg.LocksTableName
+	}
+	if cfg.Directory != "" {
+		config.Directory = cfg.Directory
+		Migrations = migrate.NewMigrations(migrate.WithMigrationsDirectory(cfg.Directory))
+	}
+}
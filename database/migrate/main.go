@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun/migrate"
+)
+
+// Register registers Go migration
+func Register(up, down migrate.MigrationFunc) error {
+	return Migrations.Register(up, down)
+}
+
+// Discover registers SQL migrations found in fsys (e.g. embed.FS)
+func Discover(fsys fs.FS) error {
+	return Migrations.Discover(fsys)
+}
+
+// NewMigrator creates bun migrator for session
+func NewMigrator(sessionName string) (*migrate.Migrator, error) {
+	db, err := database.GetDB(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	if !database.Supports(sessionName, database.CapMigrations) {
+		return nil, database.ErrUnsupported(sessionName, "migrations")
+	}
+	return migrate.NewMigrator(db, Migrations,
+		migrate.WithTableName(config.TableName),
+		migrate.WithLocksTableName(config.LocksTableName),
+	), nil
+}
+
+// withLock initializes migration tables and runs fn holding the migration lock
+func withLock(ctx context.Context, sessionName string, fn func(m *migrate.Migrator) error) error {
+	migrator, err := NewMigrator(sessionName)
+	if err != nil {
+		return err
+	}
+	if err := migrator.Init(ctx); err != nil {
+		return fmt.Errorf("failed to init migration tables: %w", err)
+	}
+	if err := migrator.Lock(ctx); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer migrator.Unlock(ctx)
+
+	return fn(migrator)
+}
+
+// Up applies all pending migrations as a new group
+func Up(ctx context.Context, sessionName string) (*migrate.MigrationGroup, error) {
+	var group *migrate.MigrationGroup
+	err := withLock(ctx, sessionName, func(m *migrate.Migrator) error {
+		var err error
+		group, err = m.Migrate(ctx)
+		return err
+	})
+	return group, err
+}
+
+// Down rolls back the last migration group
+func Down(ctx context.Context, sessionName string) (*migrate.MigrationGroup, error) {
+	var group *migrate.MigrationGroup
+	err := withLock(ctx, sessionName, func(m *migrate.Migrator) error {
+		var err error
+		group, err = m.Rollback(ctx)
+		return err
+	})
+	return group, err
+}
+
+// Status returns all migrations with applied state
+func Status(ctx context.Context, sessionName string) (migrate.MigrationSlice, error) {
+	migrator, err := NewMigrator(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrator.Init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to init migration tables: %w", err)
+	}
+	return migrator.MigrationsWithStatus(ctx)
+}
+
+// Create creates transactional up/down SQL migration files in the configured directory
+func Create(ctx context.Context, sessionName, name string) ([]*migrate.MigrationFile, error) {
+	migrator, err := NewMigrator(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	return migrator.CreateTxSQLMigrations(ctx, name)
+}
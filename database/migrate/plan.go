@@ -0,0 +1,284 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+)
+
+// PlannedMigration represents statements a migration would execute
+type PlannedMigration struct {
+	Name       string
+	Comment    string
+	Statements []string
+}
+
+// Plan returns SQL of pending migrations without applying them.
+// Migrations run against a recording connection, reads go to the database and writes are only captured
+func Plan(ctx context.Context, sessionName string) ([]PlannedMigration, error) {
+	migrations, err := pending(ctx, sessionName)
+	if err != nil {
+		return nil, err
+	}
+	return record(ctx, sessionName, migrations, false)
+}
+
+// PlanDown returns SQL that Down would execute for the last migration group
+func PlanDown(ctx context.Context, sessionName string) ([]PlannedMigration, error) {
+	migrator, err := NewMigrator(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := migrator.MigrationsWithStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rollback runs migrations of the group in reverse order
+	applied := ms.LastGroup().Migrations
+	for i, j := 0, len(applied)-1; i < j; i, j = i+1, j-1 {
+		applied[i], applied[j] = applied[j], applied[i]
+	}
+	return record(ctx, sessionName, applied, true)
+}
+
+// pending returns unapplied migrations, all of them when migration table does not exist yet
+func pending(ctx context.Context, sessionName string) (migrate.MigrationSlice, error) {
+	db, err := database.GetDB(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := NewMigrator(sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, err := migrator.MigrationsWithStatus(ctx)
+	if err != nil {
+		if pingErr := db.PingContext(ctx); pingErr != nil {
+			return nil, pingErr
+		}
+		return Migrations.Sorted(), nil
+	}
+	return ms.Unapplied(), nil
+}
+
+// record runs migrations on recording DB sharing dialect of session
+func record(ctx context.Context, sessionName string, migrations migrate.MigrationSlice, down bool) ([]PlannedMigration, error) {
+	db, err := database.GetDB(sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &recorder{db: db.DB}
+	dryDB := bun.NewDB(sql.OpenDB(rec), db.Dialect())
+	defer dryDB.Close()
+
+	plans := make([]PlannedMigration, 0, len(migrations))
+	for _, m := range migrations {
+		fn := m.Up
+		if down {
+			fn = m.Down
+		}
+
+		rec.reset()
+		if fn != nil {
+			if err := fn(ctx, dryDB, nil); err != nil {
+				return nil, fmt.Errorf("failed to plan migration %s: %w", m.Name, err)
+			}
+		}
+		plans = append(plans, PlannedMigration{Name: m.Name, Comment: m.Comment, Statements: rec.statements()})
+	}
+	return plans, nil
+}
+
+// recorder is database/sql connector capturing writes and forwarding reads to db
+type recorder struct {
+	db *sql.DB
+
+	mu    sync.Mutex
+	stmts []string
+}
+
+func (r *recorder) add(stmt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stmts = append(r.stmts, stmt)
+}
+
+func (r *recorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stmts = nil
+}
+
+func (r *recorder) statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.stmts...)
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) {
+	return &recordConn{r: r}, nil
+}
+
+func (r *recorder) Driver() driver.Driver {
+	return recordDriver{r: r}
+}
+
+type recordDriver struct {
+	r *recorder
+}
+
+func (d recordDriver) Open(string) (driver.Conn, error) {
+	return &recordConn{r: d.r}, nil
+}
+
+type recordConn struct {
+	r *recorder
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("migrate : prepared statements are not supported in plan")
+}
+
+func (c *recordConn) Close() error {
+	return nil
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.r.add("BEGIN")
+	return recordTx{r: c.r}, nil
+}
+
+func (c *recordConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.add(withArgs(query, args))
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !readOnly(query) {
+		c.r.add(withArgs(query, args))
+		return &forwardRows{}, nil
+	}
+
+	// reads run in a read-only transaction that is always rolled back, drivers without read-only
+	// transactions such as MSSQL only record them
+	tx, err := c.r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		c.r.add(withArgs(query, args))
+		return &forwardRows{}, nil
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	rows, err := tx.QueryContext(ctx, query, values...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return &forwardRows{rows: rows, tx: tx}, nil
+}
+
+type recordTx struct {
+	r *recorder
+}
+
+func (t recordTx) Commit() error {
+	t.r.add("COMMIT")
+	return nil
+}
+
+func (t recordTx) Rollback() error {
+	t.r.add("ROLLBACK")
+	return nil
+}
+
+// forwardRows adapts *sql.Rows of the real database, nil rows is empty result. Closing rolls back tx
+type forwardRows struct {
+	rows *sql.Rows
+	tx   *sql.Tx
+}
+
+func (f *forwardRows) Columns() []string {
+	if f.rows == nil {
+		return nil
+	}
+	columns, _ := f.rows.Columns()
+	return columns
+}
+
+func (f *forwardRows) Close() error {
+	if f.rows == nil {
+		return nil
+	}
+	err := f.rows.Close()
+	f.tx.Rollback()
+	return err
+}
+
+func (f *forwardRows) Next(dest []driver.Value) error {
+	if f.rows == nil || !f.rows.Next() {
+		if f.rows != nil && f.rows.Err() != nil {
+			return f.rows.Err()
+		}
+		return io.EOF
+	}
+
+	values := make([]interface{}, len(dest))
+	pointers := make([]interface{}, len(dest))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := f.rows.Scan(pointers...); err != nil {
+		return err
+	}
+	for i, v := range values {
+		dest[i] = v
+	}
+	return nil
+}
+
+// readOnly reports whether query only reads and can run on the real database, EXPLAIN ANALYZE
+// executes the explained statement so it is recorded instead
+func readOnly(query string) bool {
+	upper := strings.ToUpper(query)
+	fields := strings.Fields(upper)
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "EXPLAIN":
+		return !strings.Contains(upper, "ANALYZE")
+	case "SELECT", "SHOW":
+		return !strings.Contains(upper, " FOR UPDATE")
+	}
+	return false
+}
+
+// withArgs appends bind arguments, bun usually formats them into the query itself
+func withArgs(query string, args []driver.NamedValue) string {
+	query = strings.TrimSpace(query)
+	if len(args) == 0 {
+		return query
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprintf("%v", arg.Value)
+	}
+	return fmt.Sprintf("%s -- args: %s", query, strings.Join(values, ", "))
+}
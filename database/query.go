@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/uptrace/bun"
+)
+
+// QueryError wraps errors of Query, QueryOne and Exec with their classification
+type QueryError struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// classified wraps err in QueryError
+func classified(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &QueryError{Kind: Classify(err), Err: err}
+}
+
+// leadingComments matches comments and parentheses before the first keyword of a statement
+var leadingComments = regexp.MustCompile(`^(?s:\s|\(|--[^\n]*|/\*.*?\*/)*`)
+
+// writeClause matches clauses making a SELECT or WITH statement write or lock rows
+var writeClause = regexp.MustCompile(`(?i)\b(?:INSERT|UPDATE|DELETE|MERGE|INTO|FOR\s+(?:NO\s+KEY\s+)?UPDATE|FOR\s+(?:KEY\s+)?SHARE)\b`)
+
+// readStatement reports whether query only reads, false positives merely run on the primary
+func readStatement(query string) bool {
+	query = leadingComments.ReplaceAllString(query, "")
+	end := strings.IndexFunc(query, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		end = len(query)
+	}
+	switch strings.ToUpper(query[:end]) {
+	case "SELECT", "WITH":
+		return !writeClause.MatchString(query)
+	}
+	return false
+}
+
+// queryDB returns Reader of session for read statements and transaction of ctx or primary otherwise
+func queryDB(ctx context.Context, sessionName, query string) (bun.IDB, error) {
+	if readStatement(query) {
+		return Reader(ctx, sessionName)
+	}
+	return IDB(ctx, sessionName)
+}
+
+// Query runs raw SQL and scans rows into structs or scalars of T. SELECT statements run on Reader
+// of session, others such as INSERT ... RETURNING on transaction of ctx or primary
+func Query[T any](ctx context.Context, sessionName string, query string, args ...interface{}) ([]T, error) {
+	db, err := queryDB(ctx, sessionName, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []T
+	if err := db.NewRaw(query, args...).Scan(ctx, &rows); err != nil {
+		return nil, classified(err)
+	}
+	return rows, nil
+}
+
+// QueryOne runs raw SQL routed like Query and scans first row into T, no rows is classified as KindNotFound
+func QueryOne[T any](ctx context.Context, sessionName string, query string, args ...interface{}) (T, error) {
+	var row T
+	db, err := queryDB(ctx, sessionName, query)
+	if err != nil {
+		return row, err
+	}
+
+	if err := db.NewRaw(query, args...).Scan(ctx, &row); err != nil {
+		return row, classified(err)
+	}
+	return row, nil
+}
+
+// Exec runs raw SQL on transaction of ctx or primary of session
+func Exec(ctx context.Context, sessionName string, query string, args ...interface{}) (sql.Result, error) {
+	db, err := IDB(ctx, sessionName)
+	if err != nil {
+		return nil, err
+	}
+	if !Supports(sessionName, CapWrites) {
+		return nil, ErrUnsupported(sessionName, "writes")
+	}
+
+	result, err := db.ExecContext(ctx, query, args...)
+	return result, classified(err)
+}
@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Redaction represents masking rules of logged queries
+type Redaction struct {
+	// Columns are case-insensitive substrings of column names whose values are masked
+	Columns []string
+	// MaxLength truncates logged statements
+	MaxLength int
+	// SampleRate is fraction of queries logged, between 0 and 1
+	SampleRate float64
+	// Mask replaces masked values
+	Mask string
+
+	assignment *regexp.Regexp
+	list       *regexp.Regexp
+}
+
+// DefaultRedaction fills zero fields of Config.Redaction and is used by slow query logs without it
+var DefaultRedaction = Redaction{
+	Columns:    []string{"password", "passwd", "token", "secret", "ssn", "api_key", "credit_card"},
+	MaxLength:  2048,
+	SampleRate: 1,
+	Mask:       "'***'",
+}
+
+// insertStatement also matches aliased tables, e.g. bun's INSERT INTO "users" AS "user" (...)
+var insertStatement = regexp.MustCompile(`(?is)^(\s*INSERT\s+INTO\s+\S+(?:\s+AS\s+\S+)?\s*)\(([^)]*)\)(\s*VALUES\s*)(.*)$`)
+
+// withDefaults fills zero fields from DefaultRedaction
+func (r Redaction) withDefaults() Redaction {
+	if len(r.Columns) == 0 {
+		r.Columns = DefaultRedaction.Columns
+	}
+	if r.MaxLength <= 0 {
+		r.MaxLength = DefaultRedaction.MaxLength
+	}
+	if r.SampleRate <= 0 {
+		r.SampleRate = DefaultRedaction.SampleRate
+	}
+	if r.Mask == "" {
+		r.Mask = DefaultRedaction.Mask
+	}
+
+	patterns := make([]string, len(r.Columns))
+	for i, column := range r.Columns {
+		patterns[i] = regexp.QuoteMeta(column)
+	}
+	sensitive := "[\"`\\[]?\\w*(?:" + strings.Join(patterns, "|") + ")\\w*[\"`\\]]?"
+	r.assignment = regexp.MustCompile("(?i)(" + sensitive + "\\s*(?:=|<>|!=|\\bLIKE\\b)\\s*)('(?:[^']|'')*'|[\\w.+-]+)")
+	r.list = regexp.MustCompile("(?i)(" + sensitive + "\\s+(?:NOT\\s+)?IN\\s*)\\((?:'(?:[^']|'')*'|[^)'])*\\)")
+	return r
+}
+
+// sampled reports whether this query should be logged
+func (r Redaction) sampled() bool {
+	return r.SampleRate >= 1 || rand.Float64() < r.SampleRate
+}
+
+// sensitive reports whether column name matches a redaction pattern
+func (r Redaction) sensitive(column string) bool {
+	column = strings.ToLower(strings.Trim(strings.TrimSpace(column), "\"`[]"))
+	if i := strings.LastIndexByte(column, '.'); i >= 0 {
+		column = strings.Trim(column[i+1:], "\"`[]")
+	}
+	for _, pattern := range r.Columns {
+		if strings.Contains(column, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact masks values of sensitive columns in comparisons, IN lists, SET clauses and INSERT values, then truncates
+func (r Redaction) Redact(query string) string {
+	if r.assignment == nil {
+		r = r.withDefaults()
+	}
+
+	if m := insertStatement.FindStringSubmatch(query); m != nil {
+		query = r.redactInsert(m)
+	}
+
+	mask := strings.ReplaceAll(r.Mask, "$", "$$")
+	query = r.assignment.ReplaceAllString(query, "${1}"+mask)
+	query = r.list.ReplaceAllString(query, "${1}("+mask+")")
+
+	if len(query) > r.MaxLength {
+		query = query[:r.MaxLength] + "..."
+	}
+	return query
+}
+
+// redactInsert masks VALUES positions of sensitive columns
+func (r Redaction) redactInsert(m []string) string {
+	columns := strings.Split(m[2], ",")
+	var masked []int
+	for i, column := range columns {
+		if r.sensitive(column) {
+			masked = append(masked, i)
+		}
+	}
+	if len(masked) == 0 {
+		return m[0]
+	}
+
+	// Walk value tuples tracking quotes, parentheses depth and column position
+	var b strings.Builder
+	rest := m[4]
+	depth, position := 0, 0
+	inQuote, skipping := false, false
+	for i := 0; i < len(rest); i++ {
+		ch := rest[i]
+		switch {
+		case inQuote:
+			if ch == '\'' {
+				if i+1 < len(rest) && rest[i+1] == '\'' {
+					i++
+					if !skipping {
+						b.WriteString("''")
+					}
+					continue
+				}
+				inQuote = false
+			}
+		case ch == '\'':
+			inQuote = true
+		case ch == '(':
+			depth++
+			if depth == 1 {
+				position = 0
+				b.WriteByte(ch)
+				skipping = contains(masked, position)
+				if skipping {
+					b.WriteString(r.Mask)
+				}
+				continue
+			}
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				skipping = false
+			}
+		case ch == ',' && depth == 1:
+			position++
+			b.WriteByte(ch)
+			skipping = contains(masked, position)
+			if skipping {
+				b.WriteString(r.Mask)
+			}
+			continue
+		}
+		if !skipping {
+			b.WriteByte(ch)
+		}
+	}
+
+	return m[1] + "(" + m[2] + ")" + m[3] + b.String()
+}
+
+func contains(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// queryLogHook logs redacted queries at debug level, replacing bundebug when redaction is configured
+type queryLogHook struct {
+	session   string
+	redaction Redaction
+}
+
+func (h *queryLogHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *queryLogHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if !h.redaction.sampled() {
+		return
+	}
+	attrs := []any{
+		"session", h.session,
+		"duration", time.Since(event.StartTime),
+		"query", h.redaction.Redact(event.Query),
+	}
+	if event.Err != nil {
+		attrs = append(attrs, "error", event.Err)
+	}
+	slog.DebugContext(ctx, "query", attrs...)
+}
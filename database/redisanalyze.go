@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisAnalyzeOptions represents AnalyzeRedis configuration
+type RedisAnalyzeOptions struct {
+	// Match is SCAN pattern, defaults to "*"
+	Match string
+	// Limit stops after sampling this many keys, defaults to 10000
+	Limit int
+	// Separator splits keys into namespaces, defaults to ":"
+	Separator string
+	// Depth is namespace segments grouped together, defaults to 2, e.g. "nest:cache" of "nest:cache:user:1"
+	Depth int
+	// NoTTLSamples is keys without TTL listed per namespace, defaults to 5
+	NoTTLSamples int
+}
+
+// RedisNamespace represents sampled keys sharing a prefix
+type RedisNamespace struct {
+	Prefix string `json:"prefix"`
+	Keys   int64  `json:"keys"`
+	// Memory is bytes reported by MEMORY USAGE
+	Memory int64 `json:"memory"`
+	// TTLs counts keys per bucket: none, <1m, <1h, <1d, >=1d
+	TTLs  map[string]int64 `json:"ttls"`
+	Types map[string]int64 `json:"types"`
+	// NoTTL lists sample keys without expiry
+	NoTTL []string `json:"no_ttl,omitempty"`
+}
+
+// RedisReport represents result of AnalyzeRedis
+type RedisReport struct {
+	Scanned    int64            `json:"scanned"`
+	Memory     int64            `json:"memory"`
+	NoTTL      int64            `json:"no_ttl"`
+	Namespaces []RedisNamespace `json:"namespaces"`
+}
+
+// AnalyzeRedis samples keys with SCAN and groups their count, memory, TTL distribution and types by
+// namespace, largest first. Keys without TTL are flagged since they never leave memory on their own
+func AnalyzeRedis(ctx context.Context, opts RedisAnalyzeOptions) (*RedisReport, error) {
+	rdb, err := streamClient()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Match == "" {
+		opts.Match = "*"
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 10000
+	}
+	if opts.Separator == "" {
+		opts.Separator = ":"
+	}
+	if opts.Depth <= 0 {
+		opts.Depth = 2
+	}
+	if opts.NoTTLSamples <= 0 {
+		opts.NoTTLSamples = 5
+	}
+
+	report := &RedisReport{}
+	namespaces := map[string]*RedisNamespace{}
+	var cursor uint64
+	for {
+		keys, next, err := rdb.Scan(ctx, cursor, opts.Match, 500).Result()
+		if err != nil {
+			return nil, err
+		}
+		if remaining := opts.Limit - int(report.Scanned); len(keys) > remaining {
+			keys = keys[:remaining]
+		}
+		if err := sampleKeys(ctx, rdb, keys, opts, report, namespaces); err != nil {
+			return nil, err
+		}
+
+		cursor = next
+		if cursor == 0 || int(report.Scanned) >= opts.Limit {
+			break
+		}
+	}
+
+	for _, ns := range namespaces {
+		report.Namespaces = append(report.Namespaces, *ns)
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		if report.Namespaces[i].Memory != report.Namespaces[j].Memory {
+			return report.Namespaces[i].Memory > report.Namespaces[j].Memory
+		}
+		return report.Namespaces[i].Prefix < report.Namespaces[j].Prefix
+	})
+	return report, nil
+}
+
+// sampleKeys reads memory, TTL and type of keys in one pipeline
+func sampleKeys(ctx context.Context, rdb *redis.Client, keys []string, opts RedisAnalyzeOptions, report *RedisReport, namespaces map[string]*RedisNamespace) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pipe := rdb.Pipeline()
+	memory := make([]*redis.IntCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	types := make([]*redis.StatusCmd, len(keys))
+	for i, key := range keys {
+		memory[i] = pipe.MemoryUsage(ctx, key)
+		ttls[i] = pipe.TTL(ctx, key)
+		types[i] = pipe.Type(ctx, key)
+	}
+	// keys expiring between SCAN and the pipeline fail with redis.Nil
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	for i, key := range keys {
+		if types[i].Val() == "none" {
+			continue
+		}
+		prefix := namespaceOf(key, opts.Separator, opts.Depth)
+		ns, ok := namespaces[prefix]
+		if !ok {
+			ns = &RedisNamespace{Prefix: prefix, TTLs: map[string]int64{}, Types: map[string]int64{}}
+			namespaces[prefix] = ns
+		}
+
+		ns.Keys++
+		ns.Memory += memory[i].Val()
+		ns.Types[types[i].Val()]++
+		bucket := ttlBucket(ttls[i].Val())
+		ns.TTLs[bucket]++
+		if bucket == "none" {
+			report.NoTTL++
+			if len(ns.NoTTL) < opts.NoTTLSamples {
+				ns.NoTTL = append(ns.NoTTL, key)
+			}
+		}
+		report.Scanned++
+		report.Memory += memory[i].Val()
+	}
+	return nil
+}
+
+// namespaceOf returns first depth segments of key, leaving out its last segment
+func namespaceOf(key, separator string, depth int) string {
+	segments := strings.Split(key, separator)
+	n := min(depth, len(segments)-1)
+	if n <= 0 {
+		return "(root)"
+	}
+	return strings.Join(segments[:n], separator)
+}
+
+// ttlBucket groups TTL reported by Redis, negative values mean no expiry
+func ttlBucket(ttl time.Duration) string {
+	switch {
+	case ttl < 0:
+		return "none"
+	case ttl < time.Minute:
+		return "<1m"
+	case ttl < time.Hour:
+		return "<1h"
+	case ttl < 24*time.Hour:
+		return "<1d"
+	default:
+		return ">=1d"
+	}
+}
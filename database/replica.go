@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/uptrace/bun"
+)
+
+// DefaultStickyWindow is used when Config.StickyWindow is zero
+var DefaultStickyWindow = 5 * time.Second
+
+// StickyPrefix is prepended to Redis keys of entity stickiness
+var StickyPrefix = "nest:sticky:"
+
+type stickyKey struct{}
+
+// stickiness tracks last write per session of a request
+type stickiness struct {
+	mu     sync.Mutex
+	writes map[string]time.Time
+}
+
+// WithStickiness returns context recording writes, reads of the same context then go to primary.
+// Usually set per request by StickyMiddleware
+func WithStickiness(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(stickyKey{}).(*stickiness); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, stickyKey{}, &stickiness{writes: map[string]time.Time{}})
+}
+
+// StickyMiddleware scopes stickiness to each request through its user context, so reads after
+// writes of a request go to primary
+func StickyMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(WithStickiness(c.UserContext()))
+		return c.Next()
+	}
+}
+
+// MarkWrite records write on session for ctx, keys also pin those entities to primary
+// across requests for the sticky window when Redis is initialized
+func MarkWrite(ctx context.Context, sessionName string, keys ...string) {
+	if s, ok := ctx.Value(stickyKey{}).(*stickiness); ok {
+		s.mu.Lock()
+		s.writes[sessionName] = time.Now()
+		s.mu.Unlock()
+	}
+
+	if len(keys) == 0 || RedisClient == nil {
+		return
+	}
+	window := stickyWindow(sessionName)
+	pipe := RedisClient.Pipeline()
+	for _, key := range keys {
+		pipe.Set(ctx, StickyPrefix+sessionName+":"+key, 1, window)
+	}
+	pipe.Exec(ctx)
+}
+
+// Reader returns DB for reads: transaction of ctx, primary within sticky window of a write
+// by ctx or keys, otherwise the next replica. Sessions without replicas always use primary
+func Reader(ctx context.Context, sessionName string, keys ...string) (bun.IDB, error) {
+	if tx, ok := TxFromContext(ctx, sessionName); ok {
+		return tx, nil
+	}
+
+	session, ok := GetSession(sessionName)
+	if !ok {
+		return nil, ErrSessionNotFound(sessionName)
+	}
+	if len(session.Replicas) == 0 || sticky(ctx, session, keys) {
+		return session.DB, nil
+	}
+
+	i := session.next.Add(1) % uint64(len(session.Replicas))
+	return session.Replicas[i], nil
+}
+
+// sticky reports whether reads must go to primary
+func sticky(ctx context.Context, session *Session, keys []string) bool {
+	window := stickyWindow(session.Name)
+
+	if s, ok := ctx.Value(stickyKey{}).(*stickiness); ok {
+		s.mu.Lock()
+		last, written := s.writes[session.Name]
+		s.mu.Unlock()
+		if written && time.Since(last) < window {
+			return true
+		}
+	}
+
+	if len(keys) == 0 || RedisClient == nil {
+		return false
+	}
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = StickyPrefix + session.Name + ":" + key
+	}
+	// Prefer primary when Redis is unavailable, stale reads are worse than extra load
+	n, err := RedisClient.Exists(ctx, redisKeys...).Result()
+	return err != nil || n > 0
+}
+
+func stickyWindow(sessionName string) time.Duration {
+	if session, ok := GetSession(sessionName); ok && session.Config.StickyWindow > 0 {
+		return session.Config.StickyWindow
+	}
+	return DefaultStickyWindow
+}
+
+// stickyHook marks successful writes on primary in the query context
+type stickyHook struct {
+	session string
+}
+
+func (h *stickyHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *stickyHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if event.Err != nil || ctx.Value(stickyKey{}) == nil {
+		return
+	}
+	switch strings.ToUpper(event.Operation()) {
+	case "SELECT", "SHOW", "EXPLAIN", "":
+		return
+	}
+	MarkWrite(ctx, h.session)
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+)
+
+// QueryFunc modifies select query, used for filters, ordering and pagination
+type QueryFunc func(q *bun.SelectQuery) *bun.SelectQuery
+
+// Hook is called after writes with primary keys of written rows, composite keys joined by commas
+type Hook func(ctx context.Context, ids []interface{})
+
+// Repository provides CRUD for model T, joining the transaction of ctx when present
+type Repository[T any] struct {
+	Session string
+
+	hooks []Hook
+}
+
+// OnWrite registers hook called after Create, CreateMany, BulkInsert, Update, Delete and DeleteByID
+// succeed, e.g. to invalidate caches of T. Register hooks before the repository is used
+func (r *Repository[T]) OnWrite(hook Hook) {
+	r.hooks = append(r.hooks, hook)
+}
+
+// written calls hooks with primary keys of entities
+func (r *Repository[T]) written(ctx context.Context, db bun.IDB, entities ...*T) {
+	if len(r.hooks) == 0 || len(entities) == 0 {
+		return
+	}
+	table := db.Dialect().Tables().Get(reflect.TypeOf((*T)(nil)).Elem())
+	ids := make([]interface{}, 0, len(entities))
+	for _, entity := range entities {
+		v := reflect.ValueOf(entity).Elem()
+		parts := make([]string, len(table.PKs))
+		for i, pk := range table.PKs {
+			parts[i] = fmt.Sprint(pk.Value(v).Interface())
+		}
+		ids = append(ids, strings.Join(parts, ","))
+	}
+	r.notify(ctx, ids)
+}
+
+func (r *Repository[T]) notify(ctx context.Context, ids []interface{}) {
+	for _, hook := range r.hooks {
+		hook(ctx, ids)
+	}
+}
+
+// New creates repository of T on session
+func New[T any](session string) *Repository[T] {
+	return &Repository[T]{Session: session}
+}
+
+// DB returns transaction of ctx or session DB
+func (r *Repository[T]) DB(ctx context.Context) (bun.IDB, error) {
+	return database.IDB(ctx, r.Session)
+}
+
+// Select returns select query of T with modifiers applied, routed to a replica when configured
+func (r *Repository[T]) Select(ctx context.Context, dst interface{}, mods ...QueryFunc) (*bun.SelectQuery, error) {
+	db, err := database.Reader(ctx, r.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	q := db.NewSelect().Model(dst)
+	for _, mod := range mods {
+		q = mod(q)
+	}
+	return q, nil
+}
+
+// Find returns entity by primary key, sql.ErrNoRows when missing
+func (r *Repository[T]) Find(ctx context.Context, id interface{}) (*T, error) {
+	entity := new(T)
+	q, err := r.Select(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.Where("?PKs = ?", id).Scan(ctx); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// FindByIDs returns entities with primary keys in ids, missing ones are skipped
+func (r *Repository[T]) FindByIDs(ctx context.Context, ids []interface{}) ([]T, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.List(ctx, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("?PKs IN (?)", bun.In(ids))
+	})
+}
+
+// First returns first entity matching modifiers, sql.ErrNoRows when missing
+func (r *Repository[T]) First(ctx context.Context, mods ...QueryFunc) (*T, error) {
+	entity := new(T)
+	q, err := r.Select(ctx, entity, mods...)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.Limit(1).Scan(ctx); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// List returns entities matching modifiers
+func (r *Repository[T]) List(ctx context.Context, mods ...QueryFunc) ([]T, error) {
+	var entities []T
+	q, err := r.Select(ctx, &entities, mods...)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.Scan(ctx); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Paginate returns page of entities with total count ignoring limit and offset
+func (r *Repository[T]) Paginate(ctx context.Context, limit, offset int, mods ...QueryFunc) ([]T, int, error) {
+	var entities []T
+	q, err := r.Select(ctx, &entities, mods...)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := q.Limit(limit).Offset(offset).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entities, total, nil
+}
+
+// Count returns number of entities matching modifiers
+func (r *Repository[T]) Count(ctx context.Context, mods ...QueryFunc) (int, error) {
+	q, err := r.Select(ctx, (*T)(nil), mods...)
+	if err != nil {
+		return 0, err
+	}
+	return q.Count(ctx)
+}
+
+// Exists reports whether any entity matches modifiers
+func (r *Repository[T]) Exists(ctx context.Context, mods ...QueryFunc) (bool, error) {
+	q, err := r.Select(ctx, (*T)(nil), mods...)
+	if err != nil {
+		return false, err
+	}
+	return q.Exists(ctx)
+}
+
+// Create inserts entity
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	db, err := r.DB(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err = db.NewInsert().Model(entity).Exec(ctx); err != nil {
+		return err
+	}
+	r.written(ctx, db, entity)
+	return nil
+}
+
+// CreateMany inserts entities in one statement
+func (r *Repository[T]) CreateMany(ctx context.Context, entities []T) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	db, err := r.DB(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err = db.NewInsert().Model(&entities).Exec(ctx); err != nil {
+		return err
+	}
+	written := make([]*T, len(entities))
+	for i := range entities {
+		written[i] = &entities[i]
+	}
+	r.written(ctx, db, written...)
+	return nil
+}
+
+// BulkInsert inserts entities in statements of at most batchSize rows, returning inserted count.
+// Batches are not wrapped in a transaction, run it inside database.RunInTx for all or nothing
+func (r *Repository[T]) BulkInsert(ctx context.Context, entities []T, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = len(entities)
+	}
+	inserted := 0
+	for start := 0; start < len(entities); start += batchSize {
+		batch := entities[start:min(start+batchSize, len(entities))]
+		if err := r.CreateMany(ctx, batch); err != nil {
+			return inserted, err
+		}
+		inserted += len(batch)
+	}
+	return inserted, nil
+}
+
+// Update updates entity by primary key, only given columns when set.
+// Returns sql.ErrNoRows when no row has the primary key, updates changing no values succeed
+func (r *Repository[T]) Update(ctx context.Context, entity *T, columns ...string) error {
+	db, err := r.DB(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := db.NewUpdate().Model(entity).WherePK()
+	if len(columns) > 0 {
+		q = q.Column(columns...)
+	}
+	result, err := q.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	// MySQL reports zero affected rows for updates changing no values, so existence is checked separately
+	if err := affected(result); err != nil {
+		exists, existsErr := db.NewSelect().Model(entity).WherePK().Exists(ctx)
+		if existsErr != nil {
+			return existsErr
+		}
+		if !exists {
+			return err
+		}
+	}
+	r.written(ctx, db, entity)
+	return nil
+}
+
+// Delete deletes entity by primary key, sql.ErrNoRows when missing
+func (r *Repository[T]) Delete(ctx context.Context, entity *T) error {
+	db, err := r.DB(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := db.NewDelete().Model(entity).WherePK().Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if err := affected(result); err != nil {
+		return err
+	}
+	r.written(ctx, db, entity)
+	return nil
+}
+
+// DeleteByID deletes entity by primary key value, sql.ErrNoRows when missing
+func (r *Repository[T]) DeleteByID(ctx context.Context, id interface{}) error {
+	db, err := r.DB(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := db.NewDelete().Model((*T)(nil)).Where("?PKs = ?", id).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if err := affected(result); err != nil {
+		return err
+	}
+	r.notify(ctx, []interface{}{id})
+	return nil
+}
+
+// affected converts zero affected rows into sql.ErrNoRows
+func affected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
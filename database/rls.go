@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/rikiihsan/nest/requestctx"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// RLS maps request values to PostgreSQL settings set local to every transaction of a session,
+// so row-level security policies can read them with current_setting('app.user_id', true)
+type RLS struct {
+	// Settings maps setting name to value of ctx, settings without value are left unset
+	Settings map[string]func(ctx context.Context) (string, bool)
+}
+
+// DefaultRLS sets app.user_id from SubjectID of requestctx.User and app.tenant_id from requestctx.Tenant
+var DefaultRLS = RLS{
+	Settings: map[string]func(ctx context.Context) (string, bool){
+		"app.user_id":   RLSUserID,
+		"app.tenant_id": requestctx.Tenant.Get,
+	},
+}
+
+// RLSUserID returns SubjectID of requestctx.User, see authz.Subject
+func RLSUserID(ctx context.Context) (string, bool) {
+	subject, ok := requestctx.UserAs[interface{ SubjectID() string }](ctx)
+	if !ok {
+		return "", false
+	}
+	return subject.SubjectID(), true
+}
+
+// ApplyRLS sets RLS settings of session on tx, transactions of RunInTx and WithTransaction apply them automatically.
+// Settings are transaction local, so values never leak to other requests through the pool
+func ApplyRLS(ctx context.Context, tx bun.Tx, sessionName string) error {
+	session, exists := Manager.sessions[sessionName]
+	if !exists || session.Config.RLS == nil || tx.Dialect().Name() != dialect.PG {
+		return nil
+	}
+
+	names := make([]string, 0, len(session.Config.RLS.Settings))
+	for name := range session.Config.RLS.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var calls []string
+	var args []interface{}
+	for _, name := range names {
+		value, ok := session.Config.RLS.Settings[name](ctx)
+		if !ok {
+			continue
+		}
+		calls = append(calls, "set_config(?, ?, true)")
+		args = append(args, name, value)
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, "SELECT "+strings.Join(calls, ", "), args...)
+	return classified(err)
+}
@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Column represents live table column
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// Index represents live table index
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+// Table represents introspected table
+type Table struct {
+	Name    string            `json:"name"`
+	Columns map[string]Column `json:"columns"`
+	Indexes []Index           `json:"indexes"`
+}
+
+// IssueKind represents kind of schema drift
+type IssueKind string
+
+const (
+	MissingTable        IssueKind = "missing_table"
+	MissingColumn       IssueKind = "missing_column"
+	ExtraColumn         IssueKind = "extra_column"
+	TypeMismatch        IssueKind = "type_mismatch"
+	NullabilityMismatch IssueKind = "nullability_mismatch"
+	MissingIndex        IssueKind = "missing_index"
+)
+
+// Issue represents difference between model and live schema
+type Issue struct {
+	Kind     IssueKind `json:"kind"`
+	Table    string    `json:"table"`
+	Column   string    `json:"column,omitempty"`
+	Expected string    `json:"expected,omitempty"`
+	Actual   string    `json:"actual,omitempty"`
+}
+
+// String returns human readable issue
+func (i Issue) String() string {
+	target := i.Table
+	if i.Column != "" {
+		target += "." + i.Column
+	}
+	switch {
+	case i.Expected != "" && i.Actual != "":
+		return fmt.Sprintf("%s: %s expected %s, got %s", i.Kind, target, i.Expected, i.Actual)
+	case i.Expected != "":
+		return fmt.Sprintf("%s: %s expected %s", i.Kind, target, i.Expected)
+	case i.Actual != "":
+		return fmt.Sprintf("%s: %s (%s)", i.Kind, target, i.Actual)
+	}
+	return fmt.Sprintf("%s: %s", i.Kind, target)
+}
+
+// Report represents result of Diff
+type Report struct {
+	Session string  `json:"session"`
+	Issues  []Issue `json:"issues"`
+}
+
+// OK reports whether live schema matches the models
+func (r *Report) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// String returns one issue per line
+func (r *Report) String() string {
+	lines := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		lines[i] = issue.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,203 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// Inspect introspects live tables of session, missing tables are left out of the result
+func Inspect(ctx context.Context, name string, tables ...string) (map[string]*Table, error) {
+	db, err := database.GetDB(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*Table, len(tables))
+	for _, table := range tables {
+		t, err := inspectTable(ctx, db, table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect table %s: %w", table, err)
+		}
+		if t != nil {
+			result[table] = t
+		}
+	}
+	return result, nil
+}
+
+// inspectTable returns nil when table does not exist
+func inspectTable(ctx context.Context, db *bun.DB, table string) (*Table, error) {
+	t := &Table{Name: table, Columns: map[string]Column{}}
+
+	var columnsQuery, indexesQuery string
+	switch db.Dialect().Name() {
+	case dialect.PG:
+		columnsQuery = `SELECT column_name, CASE WHEN data_type = 'USER-DEFINED' THEN udt_name ELSE data_type END, is_nullable = 'YES'
+			FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? ORDER BY ordinal_position`
+		indexesQuery = `SELECT i.relname, ix.indisunique, a.attname
+			FROM pg_index ix
+			JOIN pg_class t ON t.oid = ix.indrelid
+			JOIN pg_class i ON i.oid = ix.indexrelid
+			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)
+			WHERE t.relname = ? AND t.relnamespace = current_schema()::regnamespace
+			ORDER BY i.relname, array_position(ix.indkey::int2[], a.attnum)`
+	case dialect.MySQL:
+		columnsQuery = `SELECT column_name, data_type, is_nullable = 'YES'
+			FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position`
+		indexesQuery = `SELECT index_name, non_unique = 0, column_name
+			FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ?
+			ORDER BY index_name, seq_in_index`
+	case dialect.MSSQL:
+		columnsQuery = `SELECT column_name, data_type, CASE WHEN is_nullable = 'YES' THEN 1 ELSE 0 END
+			FROM information_schema.columns WHERE table_schema = SCHEMA_NAME() AND table_name = ? ORDER BY ordinal_position`
+		indexesQuery = `SELECT i.name, i.is_unique, c.name
+			FROM sys.indexes i
+			JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+			JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+			WHERE i.object_id = OBJECT_ID(?) AND i.name IS NOT NULL
+			ORDER BY i.name, ic.key_ordinal`
+	case dialect.SQLite:
+		return inspectSQLite(ctx, db, table)
+	default:
+		return nil, fmt.Errorf("schema inspection is not supported for %s", db.Dialect().Name())
+	}
+
+	rows, err := db.QueryContext(ctx, columnsQuery, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c Column
+		if err := rows.Scan(&c.Name, &c.Type, &c.Nullable); err != nil {
+			return nil, err
+		}
+		t.Columns[c.Name] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.Columns) == 0 {
+		return nil, nil
+	}
+
+	indexRows, err := db.QueryContext(ctx, indexesQuery, table)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+	for indexRows.Next() {
+		var name, column string
+		var unique bool
+		if err := indexRows.Scan(&name, &unique, &column); err != nil {
+			return nil, err
+		}
+		t.addIndexColumn(name, unique, column)
+	}
+	return t, indexRows.Err()
+}
+
+// inspectSQLite reads table using PRAGMA statements
+func inspectSQLite(ctx context.Context, db *bun.DB, table string) (*Table, error) {
+	t := &Table{Name: table, Columns: map[string]Column{}}
+
+	rows, err := db.QueryContext(ctx, "SELECT name, type, \"notnull\", pk FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c Column
+		var notNull, pk int
+		if err := rows.Scan(&c.Name, &c.Type, &notNull, &pk); err != nil {
+			return nil, err
+		}
+		c.Nullable = notNull == 0 && pk == 0
+		t.Columns[c.Name] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.Columns) == 0 {
+		return nil, nil
+	}
+
+	indexRows, err := db.QueryContext(ctx, `SELECT il.name, il."unique", ii.name
+		FROM pragma_index_list(?) il, pragma_index_info(il.name) ii
+		ORDER BY il.name, ii.seqno`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+	for indexRows.Next() {
+		var name string
+		var unique bool
+		var column sql.NullString
+		if err := indexRows.Scan(&name, &unique, &column); err != nil {
+			return nil, err
+		}
+		// Expression indexes have no column name
+		if column.Valid {
+			t.addIndexColumn(name, unique, column.String)
+		}
+	}
+	return t, indexRows.Err()
+}
+
+// addIndexColumn appends column to index, rows arrive ordered by index
+func (t *Table) addIndexColumn(name string, unique bool, column string) {
+	if n := len(t.Indexes); n > 0 && t.Indexes[n-1].Name == name {
+		t.Indexes[n-1].Columns = append(t.Indexes[n-1].Columns, column)
+		return
+	}
+	t.Indexes = append(t.Indexes, Index{Name: name, Unique: unique, Columns: []string{column}})
+}
+
+// normalizeType lowercases type, strips length and resolves common aliases
+func normalizeType(typ string) string {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if i := strings.IndexByte(typ, '('); i >= 0 {
+		typ = strings.TrimSpace(typ[:i]) + typ[strings.IndexByte(typ, ')')+1:]
+	}
+	typ = strings.TrimSpace(typ)
+	if alias, ok := typeAliases[typ]; ok {
+		return alias
+	}
+	return typ
+}
+
+var typeAliases = map[string]string{
+	"int":                         "integer",
+	"int4":                        "integer",
+	"serial":                      "integer",
+	"int8":                        "bigint",
+	"bigserial":                   "bigint",
+	"int2":                        "smallint",
+	"smallserial":                 "smallint",
+	"bool":                        "boolean",
+	"tinyint":                     "boolean",
+	"bit":                         "boolean",
+	"float8":                      "double precision",
+	"double":                      "double precision",
+	"float":                       "double precision",
+	"float4":                      "real",
+	"character varying":           "varchar",
+	"nvarchar":                    "varchar",
+	"character":                   "char",
+	"timestamptz":                 "timestamp with time zone",
+	"timestamp without time zone": "timestamp",
+	"datetime":                    "timestamp",
+	"datetime2":                   "timestamp",
+	"datetimeoffset":              "timestamp with time zone",
+	"numeric":                     "decimal",
+	"ntext":                       "text",
+	"longtext":                    "text",
+	"mediumtext":                  "text",
+	"jsonb":                       "json",
+}
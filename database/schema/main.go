@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rikiihsan/nest/database"
+	bunschema "github.com/uptrace/bun/schema"
+)
+
+// Diff compares live schema of session with bun models, reporting missing tables, columns,
+// unique indexes, type and nullability mismatches and columns not present in models
+func Diff(ctx context.Context, name string, models ...interface{}) (*Report, error) {
+	db, err := database.GetDB(name)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Session: name}
+	for _, model := range models {
+		typ := reflect.TypeOf(model)
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		expected := db.Table(typ)
+
+		live, err := Inspect(ctx, name, expected.Name)
+		if err != nil {
+			return nil, err
+		}
+		actual, ok := live[expected.Name]
+		if !ok {
+			report.Issues = append(report.Issues, Issue{Kind: MissingTable, Table: expected.Name})
+			continue
+		}
+
+		report.Issues = append(report.Issues, diffTable(expected, actual)...)
+	}
+	return report, nil
+}
+
+// diffTable compares model table with live table
+func diffTable(expected *bunschema.Table, actual *Table) []Issue {
+	var issues []Issue
+	known := map[string]bool{}
+
+	for _, field := range expected.Fields {
+		known[field.Name] = true
+		column, ok := actual.Columns[field.Name]
+		if !ok {
+			issues = append(issues, Issue{Kind: MissingColumn, Table: expected.Name, Column: field.Name, Expected: field.CreateTableSQLType})
+			continue
+		}
+
+		if want, got := normalizeType(field.CreateTableSQLType), normalizeType(column.Type); want != got {
+			issues = append(issues, Issue{Kind: TypeMismatch, Table: expected.Name, Column: field.Name, Expected: want, Actual: got})
+		}
+		if nullable := !field.NotNull && !field.IsPK; nullable != column.Nullable {
+			issues = append(issues, Issue{
+				Kind:     NullabilityMismatch,
+				Table:    expected.Name,
+				Column:   field.Name,
+				Expected: nullability(nullable),
+				Actual:   nullability(column.Nullable),
+			})
+		}
+	}
+
+	var extra []string
+	for name := range actual.Columns {
+		if !known[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		issues = append(issues, Issue{Kind: ExtraColumn, Table: expected.Name, Column: name, Actual: actual.Columns[name].Type})
+	}
+
+	// Unique groups of the model must be backed by a unique index on the same columns
+	groups := make([]string, 0, len(expected.Unique))
+	for group := range expected.Unique {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		columns := make([]string, len(expected.Unique[group]))
+		for i, field := range expected.Unique[group] {
+			columns[i] = field.Name
+		}
+		if !hasUniqueIndex(actual, columns) {
+			issues = append(issues, Issue{Kind: MissingIndex, Table: expected.Name, Column: joinColumns(columns), Expected: "unique"})
+		}
+	}
+
+	return issues
+}
+
+// hasUniqueIndex reports whether table has unique index on exactly columns in any order
+func hasUniqueIndex(t *Table, columns []string) bool {
+	want := joinColumns(columns)
+	for _, index := range t.Indexes {
+		if index.Unique && joinColumns(index.Columns) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func joinColumns(columns []string) string {
+	sorted := append([]string(nil), columns...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func nullability(nullable bool) string {
+	if nullable {
+		return "null"
+	}
+	return "not null"
+}
@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/uptrace/bun/dialect"
+)
+
+// ScriptOptions represents ExecScript configuration
+type ScriptOptions struct {
+	// Transaction runs the whole script in one transaction, joining the transaction of ctx when present
+	Transaction bool
+	// ContinueOnError executes remaining statements after failures, ignored with Transaction
+	ContinueOnError bool
+}
+
+// ScriptStatement is statement of a script with its starting line
+type ScriptStatement struct {
+	Line int
+	SQL  string
+}
+
+// ScriptError reports failed statement of a script
+type ScriptError struct {
+	Index     int
+	Line      int
+	Statement string
+	Err       error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("statement %d at line %d: %v", e.Index+1, e.Line, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// ExecScript splits script of r with SplitScript and executes its statements in order, returning executed count.
+// Failures are *ScriptError, joined with errors.Join when ContinueOnError collects several
+func (s *Session) ExecScript(ctx context.Context, r io.Reader, options ...ScriptOptions) (int, error) {
+	var opts ScriptOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if !Supports(s.Name, CapWrites) {
+		return 0, ErrUnsupported(s.Name, "writes")
+	}
+
+	script, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	statements := SplitScript(s.DB.Dialect().Name(), string(script))
+
+	executed := 0
+	run := func(ctx context.Context) error {
+		db, err := IDB(ctx, s.Name)
+		if err != nil {
+			return err
+		}
+
+		var errs []error
+		for i, statement := range statements {
+			if _, err := db.ExecContext(ctx, statement.SQL); err != nil {
+				scriptErr := &ScriptError{Index: i, Line: statement.Line, Statement: statement.SQL, Err: classified(err)}
+				if opts.Transaction || !opts.ContinueOnError {
+					return scriptErr
+				}
+				errs = append(errs, scriptErr)
+				continue
+			}
+			executed++
+		}
+		return errors.Join(errs...)
+	}
+
+	if opts.Transaction {
+		err = RunInTx(ctx, s.Name, run)
+		if err != nil {
+			executed = 0
+		}
+		return executed, err
+	}
+	return executed, run(ctx)
+}
+
+// SplitScript splits SQL script into statements. Semicolons inside quotes, comments and PostgreSQL
+// dollar-quoted bodies don't split, MySQL scripts may switch terminator with DELIMITER and MSSQL scripts
+// split only on GO lines into batches
+func SplitScript(name dialect.Name, script string) []ScriptStatement {
+	splitter := scriptSplitter{dialect: name, src: script, delimiter: ";", line: 1}
+	if name == dialect.MSSQL {
+		splitter.delimiter = ""
+	}
+	return splitter.split()
+}
+
+type scriptSplitter struct {
+	dialect   dialect.Name
+	src       string
+	delimiter string
+	line      int
+
+	statements []ScriptStatement
+	current    strings.Builder
+	startLine  int
+}
+
+func (p *scriptSplitter) split() []ScriptStatement {
+	atLineStart := true
+	for i := 0; i < len(p.src); {
+		c := p.src[i]
+
+		if atLineStart {
+			if consumed, ok := p.directive(i); ok {
+				i += consumed
+				continue
+			}
+		}
+		atLineStart = false
+
+		switch {
+		case c == '\n':
+			p.write(p.src[i : i+1])
+			i++
+			atLineStart = true
+		case p.delimiter != "" && strings.HasPrefix(p.src[i:], p.delimiter):
+			p.flush()
+			i += len(p.delimiter)
+		case c == '-' && strings.HasPrefix(p.src[i:], "--"):
+			i += p.copyUntil(i, "\n", false)
+		case c == '#' && p.dialect == dialect.MySQL:
+			i += p.copyUntil(i, "\n", false)
+		case c == '/' && strings.HasPrefix(p.src[i:], "/*"):
+			i += p.copyUntil(i, "*/", true)
+		case c == '\'':
+			i += p.copyQuoted(i, '\'')
+		case c == '"':
+			i += p.copyQuoted(i, '"')
+		case c == '`' && p.dialect == dialect.MySQL:
+			i += p.copyQuoted(i, '`')
+		case c == '[' && p.dialect == dialect.MSSQL:
+			i += p.copyQuoted(i, ']')
+		case c == '$' && p.dialect == dialect.PG:
+			if tag := dollarTag(p.src[i:]); tag != "" {
+				end := strings.Index(p.src[i+len(tag):], tag)
+				if end < 0 {
+					end = len(p.src)
+				} else {
+					end += i + 2*len(tag)
+				}
+				p.write(p.src[i:end])
+				i = end
+				continue
+			}
+			p.write(p.src[i : i+1])
+			i++
+		default:
+			p.write(p.src[i : i+1])
+			i++
+		}
+	}
+	p.flush()
+	return p.statements
+}
+
+// directive handles GO batch separators of MSSQL and DELIMITER commands of MySQL at line start
+func (p *scriptSplitter) directive(i int) (int, bool) {
+	end := strings.IndexByte(p.src[i:], '\n')
+	if end < 0 {
+		end = len(p.src) - i
+	}
+	line := strings.TrimSpace(p.src[i : i+end])
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return 0, false
+	}
+
+	switch {
+	case p.dialect == dialect.MSSQL && strings.EqualFold(fields[0], "GO") && len(fields) <= 2:
+		p.flush()
+	case p.dialect == dialect.MySQL && strings.EqualFold(fields[0], "DELIMITER") && len(fields) == 2:
+		p.flush()
+		p.delimiter = fields[1]
+	default:
+		return 0, false
+	}
+
+	if i+end < len(p.src) {
+		end++
+		p.line++
+	}
+	return end, true
+}
+
+// copyUntil copies from i through terminator (or end of script), keeping terminator when inclusive
+func (p *scriptSplitter) copyUntil(i int, terminator string, inclusive bool) int {
+	end := strings.Index(p.src[i+1:], terminator)
+	if end < 0 {
+		p.write(p.src[i:])
+		return len(p.src) - i
+	}
+	end += i + 1
+	if inclusive {
+		end += len(terminator)
+	}
+	p.write(p.src[i:end])
+	return end - i
+}
+
+// copyQuoted copies quoted text starting at i, doubled closing quotes are escapes
+func (p *scriptSplitter) copyQuoted(i int, closing byte) int {
+	j := i + 1
+	for j < len(p.src) {
+		if p.src[j] == '\\' && closing == '\'' && p.dialect == dialect.MySQL {
+			j += 2
+			continue
+		}
+		if p.src[j] == closing {
+			if j+1 < len(p.src) && p.src[j+1] == closing {
+				j += 2
+				continue
+			}
+			j++
+			break
+		}
+		j++
+	}
+	j = min(j, len(p.src))
+	p.write(p.src[i:j])
+	return j - i
+}
+
+func (p *scriptSplitter) write(s string) {
+	if p.current.Len() == 0 && strings.TrimSpace(s) == "" {
+		p.line += strings.Count(s, "\n")
+		return
+	}
+	if p.current.Len() == 0 {
+		p.startLine = p.line
+	}
+	p.current.WriteString(s)
+	p.line += strings.Count(s, "\n")
+}
+
+// flush ends current statement, statements of only comments are dropped
+func (p *scriptSplitter) flush() {
+	statement := strings.TrimSpace(p.current.String())
+	p.current.Reset()
+	if statement == "" || onlyComments(statement) {
+		return
+	}
+	p.statements = append(p.statements, ScriptStatement{Line: p.startLine, SQL: statement})
+}
+
+// onlyComments reports whether statement has nothing but comments
+func onlyComments(statement string) bool {
+	for len(statement) > 0 {
+		statement = strings.TrimLeftFunc(statement, unicode.IsSpace)
+		switch {
+		case statement == "":
+			return true
+		case strings.HasPrefix(statement, "--"), strings.HasPrefix(statement, "#"):
+			end := strings.IndexByte(statement, '\n')
+			if end < 0 {
+				return true
+			}
+			statement = statement[end+1:]
+		case strings.HasPrefix(statement, "/*"):
+			end := strings.Index(statement, "*/")
+			if end < 0 {
+				return true
+			}
+			statement = statement[end+2:]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// dollarTag returns dollar quote opening s like $$ or $body$, empty for positional parameters like $1
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || unicode.IsLetter(rune(c)) || (i > 1 && c >= '0' && c <= '9'):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
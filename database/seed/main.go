@@ -0,0 +1,64 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+)
+
+// Seeder populates database with data
+type Seeder func(ctx context.Context, db bun.IDB) error
+
+var (
+	mu      sync.Mutex
+	names   []string
+	seeders = make(map[string]Seeder)
+)
+
+// Register registers seeder, seeders run in registration order
+func Register(name string, fn Seeder) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := seeders[name]; !exists {
+		names = append(names, name)
+	}
+	seeders[name] = fn
+}
+
+// Names returns registered seeder names
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]string(nil), names...)
+}
+
+// Run runs seeders by name (all when empty) in a single transaction
+func Run(ctx context.Context, sessionName string, only ...string) error {
+	if len(only) == 0 {
+		only = Names()
+	}
+
+	mu.Lock()
+	selected := make([]Seeder, 0, len(only))
+	for _, name := range only {
+		fn, exists := seeders[name]
+		if !exists {
+			mu.Unlock()
+			return fmt.Errorf("seeder '%s' not found", name)
+		}
+		selected = append(selected, fn)
+	}
+	mu.Unlock()
+
+	return database.WithTransaction(ctx, sessionName, func(tx bun.Tx) error {
+		for i, fn := range selected {
+			if err := fn(ctx, tx); err != nil {
+				return fmt.Errorf("seeder '%s' failed: %w", only[i], err)
+			}
+		}
+		return nil
+	})
+}
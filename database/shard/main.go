@@ -0,0 +1,123 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rikiihsan/nest/database"
+)
+
+// Resolver maps shard key to session name
+type Resolver func(key any) string
+
+// Router resolves shard keys to database sessions
+type Router struct {
+	Resolve Resolver
+	// Shards are all session names, used by fan-in queries
+	Shards []string
+}
+
+type pinKey struct{}
+
+// Default is the router used by package-level functions
+var Default = &Router{}
+
+// NewRouter creates router over shards
+func NewRouter(resolve Resolver, shards ...string) *Router {
+	return &Router{Resolve: resolve, Shards: shards}
+}
+
+// Init sets resolver and shards of the default router
+func Init(resolve Resolver, shards ...string) {
+	Default = NewRouter(resolve, shards...)
+}
+
+// NewConsistent creates router using consistent hashing over shards
+func NewConsistent(shards ...string) *Router {
+	return NewRouter(NewRing(0, shards...).Resolver(), shards...)
+}
+
+// WithShard returns context pinning For to session regardless of key, e.g. for migrations per shard
+func WithShard(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, pinKey{}, session)
+}
+
+// Name returns session name of key
+func (r *Router) Name(ctx context.Context, key any) (string, error) {
+	if session, ok := ctx.Value(pinKey{}).(string); ok && session != "" {
+		return session, nil
+	}
+	if r.Resolve == nil {
+		return "", errors.New("shard : resolver is not configured")
+	}
+	name := r.Resolve(key)
+	if name == "" {
+		return "", fmt.Errorf("shard : no shard for key %v", key)
+	}
+	return name, nil
+}
+
+// For returns session of key
+func (r *Router) For(ctx context.Context, key any) (*database.Session, error) {
+	name, err := r.Name(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	session, ok := database.GetSession(name)
+	if !ok {
+		return nil, database.ErrSessionNotFound(name)
+	}
+	return session, nil
+}
+
+// Sessions returns sessions of all shards
+func (r *Router) Sessions() ([]*database.Session, error) {
+	sessions := make([]*database.Session, 0, len(r.Shards))
+	for _, name := range r.Shards {
+		session, ok := database.GetSession(name)
+		if !ok {
+			return nil, database.ErrSessionNotFound(name)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// For returns session of key from the default router
+func For(ctx context.Context, key any) (*database.Session, error) {
+	return Default.For(ctx, key)
+}
+
+// FanIn runs fn on every shard concurrently and concatenates results in shard order.
+// Errors of all shards are joined, partial results are returned with them
+func FanIn[T any](ctx context.Context, r *Router, fn func(ctx context.Context, session *database.Session) ([]T, error)) ([]T, error) {
+	sessions, err := r.Sessions()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]T, len(sessions))
+	errs := make([]error, len(sessions))
+
+	var wg sync.WaitGroup
+	for i, session := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rows, err := fn(ctx, session)
+			results[i] = rows
+			if err != nil {
+				errs[i] = fmt.Errorf("shard %s: %w", session.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var merged []T
+	for _, rows := range results {
+		merged = append(merged, rows...)
+	}
+	return merged, errors.Join(errs...)
+}
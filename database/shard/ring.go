@@ -0,0 +1,95 @@
+package shard
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// Ring distributes keys over sessions with consistent hashing,
+// adding or removing a session only moves keys of its neighbours
+type Ring struct {
+	replicas int
+	hashes   []uint64
+	owners   map[uint64]string
+	sessions []string
+}
+
+// NewRing creates ring with virtual nodes per session, 100 when replicas <= 0
+func NewRing(replicas int, sessions ...string) *Ring {
+	if replicas <= 0 {
+		replicas = 100
+	}
+	r := &Ring{replicas: replicas, owners: map[uint64]string{}}
+	for _, session := range sessions {
+		r.Add(session)
+	}
+	return r
+}
+
+// Add adds session to ring
+func (r *Ring) Add(session string) {
+	for i := 0; i < r.replicas; i++ {
+		h := hashKey(session + "#" + strconv.Itoa(i))
+		if _, exists := r.owners[h]; exists {
+			continue
+		}
+		r.owners[h] = session
+		r.hashes = append(r.hashes, h)
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	r.sessions = append(r.sessions, session)
+}
+
+// Get returns session owning key, empty when ring is empty
+func (r *Ring) Get(key any) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+// Sessions returns sessions of ring in insertion order
+func (r *Ring) Sessions() []string {
+	return append([]string(nil), r.sessions...)
+}
+
+// Resolver returns ring as Resolver
+func (r *Ring) Resolver() Resolver {
+	return r.Get
+}
+
+// Modulo returns resolver hashing key modulo number of sessions, keys move when sessions change
+func Modulo(sessions ...string) Resolver {
+	return func(key any) string {
+		if len(sessions) == 0 {
+			return ""
+		}
+		return sessions[hashKey(key)%uint64(len(sessions))]
+	}
+}
+
+// hashKey hashes key by its string form
+func hashKey(key any) uint64 {
+	h := fnv.New64a()
+	switch k := key.(type) {
+	case string:
+		h.Write([]byte(k))
+	case []byte:
+		h.Write(k)
+	default:
+		fmt.Fprint(h, k)
+	}
+	// Mix bits since FNV of short sequential keys clusters on the ring
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// MaxQueryFingerprints bounds number of distinct slow query fingerprints kept per process
+var MaxQueryFingerprints = 1000
+
+// QueryStat represents aggregated slow queries sharing a fingerprint
+type QueryStat struct {
+	Session     string    `json:"session"`
+	Fingerprint string    `json:"fingerprint"`
+	Tables      []string  `json:"tables"`
+	Count       int64     `json:"count"`
+	TotalMs     float64   `json:"total_ms"`
+	MeanMs      float64   `json:"mean_ms"`
+	MaxMs       float64   `json:"max_ms"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+var (
+	queryStatsMu sync.Mutex
+	queryStats   = map[string]*QueryStat{}
+
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	nullLiteral   = regexp.MustCompile(`(?i)\bnull\b`)
+	placeholders  = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	valuesList    = regexp.MustCompile(`(?i)(values\s*\(\?[^)]*\))(?:\s*,\s*\(\?[^)]*\))+`)
+	whitespace    = regexp.MustCompile(`\s+`)
+	tableRef      = regexp.MustCompile("(?i)\\b(?:from|join|update|into)\\s+([\"`\\[]?[\\w.]+[\"`\\]]?)")
+)
+
+// slowQueryHook logs and aggregates queries slower than threshold
+type slowQueryHook struct {
+	session   string
+	threshold time.Duration
+	redaction Redaction
+}
+
+func (h *slowQueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *slowQueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	elapsed := time.Since(event.StartTime)
+	if elapsed < h.threshold {
+		return
+	}
+
+	// Fingerprint has no literals, so only length and sampling apply
+	fingerprint := Fingerprint(event.Query)
+	recordQuery(h.session, fingerprint, event.Query, elapsed)
+	if !h.redaction.sampled() {
+		return
+	}
+
+	logged := fingerprint
+	if len(logged) > h.redaction.MaxLength {
+		logged = logged[:h.redaction.MaxLength] + "..."
+	}
+	slog.WarnContext(ctx, "slow query",
+		"session", h.session,
+		"duration", elapsed,
+		"operation", event.Operation(),
+		"fingerprint", logged,
+	)
+}
+
+// Fingerprint normalizes query by replacing literals and collapsing lists and whitespace
+func Fingerprint(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	query = numberLiteral.ReplaceAllString(query, "?")
+	query = nullLiteral.ReplaceAllString(query, "?")
+	query = placeholders.ReplaceAllString(query, "(?...)")
+	query = valuesList.ReplaceAllString(query, "$1...")
+	return strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+}
+
+// queryTables extracts table names referenced by query
+func queryTables(query string) []string {
+	seen := map[string]bool{}
+	var tables []string
+	for _, match := range tableRef.FindAllStringSubmatch(query, -1) {
+		table := strings.Trim(match[1], "\"`[]")
+		if table != "" && !seen[table] {
+			seen[table] = true
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// recordQuery adds query duration to its fingerprint stats
+func recordQuery(session, fingerprint, query string, elapsed time.Duration) {
+	queryStatsMu.Lock()
+	defer queryStatsMu.Unlock()
+
+	key := session + "\x00" + fingerprint
+	stat, ok := queryStats[key]
+	if !ok {
+		if len(queryStats) >= MaxQueryFingerprints {
+			return
+		}
+		stat = &QueryStat{Session: session, Fingerprint: fingerprint, Tables: queryTables(query)}
+		queryStats[key] = stat
+	}
+
+	ms := float64(elapsed) / float64(time.Millisecond)
+	stat.Count++
+	stat.TotalMs += ms
+	stat.MeanMs = stat.TotalMs / float64(stat.Count)
+	if ms > stat.MaxMs {
+		stat.MaxMs = ms
+	}
+	stat.LastSeen = time.Now()
+}
+
+// QueryReport returns slow query fingerprints ordered by total time, all when limit is zero
+func QueryReport(limit int) []QueryStat {
+	queryStatsMu.Lock()
+	report := make([]QueryStat, 0, len(queryStats))
+	for _, stat := range queryStats {
+		copied := *stat
+		copied.Tables = append([]string(nil), stat.Tables...)
+		report = append(report, copied)
+	}
+	queryStatsMu.Unlock()
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].TotalMs > report[j].TotalMs
+	})
+	if limit > 0 && len(report) > limit {
+		report = report[:limit]
+	}
+	return report
+}
+
+// ResetQueryReport clears aggregated slow queries
+func ResetQueryReport() {
+	queryStatsMu.Lock()
+	defer queryStatsMu.Unlock()
+	queryStats = map[string]*QueryStat{}
+}
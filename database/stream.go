@@ -0,0 +1,269 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamConsumer represents a consumer of a Redis Stream consumer group
+type StreamConsumer struct {
+	Stream   string
+	Group    string
+	Consumer string
+	// Count is messages read per call, defaults to 10
+	Count int64
+	// Block waits for new messages, defaults to 5s
+	Block time.Duration
+	// MinIdle is idle time after which pending messages of dead consumers are claimed, defaults to 1m
+	MinIdle time.Duration
+	// MaxLen approximately trims stream on Add and Trim, zero keeps all entries
+	MaxLen int64
+	// MaxAge trims entries older than this on Trim, zero keeps all entries
+	MaxAge time.Duration
+	// MaxDeliveries drops messages delivered more often, zero retries forever
+	MaxDeliveries int64
+
+	claimCursor string
+}
+
+// StreamLag represents consumer group progress
+type StreamLag struct {
+	Length    int64 `json:"length"`
+	Pending   int64 `json:"pending"`
+	Lag       int64 `json:"lag"`
+	Consumers int64 `json:"consumers"`
+}
+
+// NewStreamConsumer creates consumer with default settings
+func NewStreamConsumer(stream, group, consumer string) *StreamConsumer {
+	return &StreamConsumer{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		Count:    10,
+		Block:    5 * time.Second,
+		MinIdle:  time.Minute,
+	}
+}
+
+func streamClient() (*redis.Client, error) {
+	if RedisClient == nil {
+		return nil, &DatabaseError{Message: "redis client not initialized"}
+	}
+	return RedisClient, nil
+}
+
+// EnsureGroup creates stream and consumer group reading from the beginning, existing groups are kept
+func (c *StreamConsumer) EnsureGroup(ctx context.Context) error {
+	rdb, err := streamClient()
+	if err != nil {
+		return err
+	}
+	err = rdb.XGroupCreateMkStream(ctx, c.Stream, c.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create group %s: %w", c.Group, err)
+	}
+	return nil
+}
+
+// Add appends message to stream, trimming it to MaxLen
+func (c *StreamConsumer) Add(ctx context.Context, values map[string]interface{}) (string, error) {
+	rdb, err := streamClient()
+	if err != nil {
+		return "", err
+	}
+	return rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: c.Stream,
+		MaxLen: c.MaxLen,
+		Approx: c.MaxLen > 0,
+		Values: values,
+	}).Result()
+}
+
+// Read claims pending messages idle longer than MinIdle, then reads new messages
+func (c *StreamConsumer) Read(ctx context.Context) ([]redis.XMessage, error) {
+	rdb, err := streamClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.MinIdle > 0 {
+		if c.claimCursor == "" {
+			c.claimCursor = "0-0"
+		}
+		claimed, cursor, err := rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   c.Stream,
+			Group:    c.Group,
+			Consumer: c.Consumer,
+			MinIdle:  c.MinIdle,
+			Start:    c.claimCursor,
+			Count:    c.Count,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		c.claimCursor = cursor
+		if claimed, err = c.dropExhausted(ctx, rdb, claimed); err != nil {
+			return nil, err
+		}
+		if len(claimed) > 0 {
+			return claimed, nil
+		}
+	}
+
+	streams, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.Group,
+		Consumer: c.Consumer,
+		Streams:  []string{c.Stream, ">"},
+		Count:    c.Count,
+		Block:    c.Block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []redis.XMessage
+	for _, stream := range streams {
+		messages = append(messages, stream.Messages...)
+	}
+	return messages, nil
+}
+
+// dropExhausted acknowledges claimed messages delivered more than MaxDeliveries times
+func (c *StreamConsumer) dropExhausted(ctx context.Context, rdb *redis.Client, claimed []redis.XMessage) ([]redis.XMessage, error) {
+	if c.MaxDeliveries <= 0 || len(claimed) == 0 {
+		return claimed, nil
+	}
+
+	pending, err := rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: c.Stream,
+		Group:  c.Group,
+		Start:  claimed[0].ID,
+		End:    claimed[len(claimed)-1].ID,
+		Count:  int64(len(claimed)),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	exhausted := make(map[string]bool)
+	for _, p := range pending {
+		if p.RetryCount > c.MaxDeliveries {
+			exhausted[p.ID] = true
+		}
+	}
+
+	kept := claimed[:0]
+	for _, message := range claimed {
+		if !exhausted[message.ID] {
+			kept = append(kept, message)
+			continue
+		}
+		slog.WarnContext(ctx, "stream message dropped after max deliveries", "stream", c.Stream, "group", c.Group, "id", message.ID)
+		if err := c.Ack(ctx, message.ID); err != nil {
+			return nil, err
+		}
+	}
+	return kept, nil
+}
+
+// Ack acknowledges processed messages
+func (c *StreamConsumer) Ack(ctx context.Context, ids ...string) error {
+	rdb, err := streamClient()
+	if err != nil {
+		return err
+	}
+	return rdb.XAck(ctx, c.Stream, c.Group, ids...).Err()
+}
+
+// Run ensures group and processes messages until ctx is done, messages are acknowledged when fn succeeds
+// and otherwise redelivered after MinIdle
+func (c *StreamConsumer) Run(ctx context.Context, fn func(ctx context.Context, message redis.XMessage) error) error {
+	if err := c.EnsureGroup(ctx); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		messages, err := c.Read(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			slog.ErrorContext(ctx, "stream read failed", "stream", c.Stream, "group", c.Group, "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			if err := fn(ctx, message); err != nil {
+				slog.WarnContext(ctx, "stream message failed", "stream", c.Stream, "id", message.ID, "error", err)
+				continue
+			}
+			if err := c.Ack(ctx, message.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Lag returns stream length, pending and undelivered message counts of the group
+func (c *StreamConsumer) Lag(ctx context.Context) (StreamLag, error) {
+	rdb, err := streamClient()
+	if err != nil {
+		return StreamLag{}, err
+	}
+
+	length, err := rdb.XLen(ctx, c.Stream).Result()
+	if err != nil {
+		return StreamLag{}, err
+	}
+	groups, err := rdb.XInfoGroups(ctx, c.Stream).Result()
+	if err != nil {
+		return StreamLag{}, err
+	}
+	for _, group := range groups {
+		if group.Name == c.Group {
+			return StreamLag{Length: length, Pending: group.Pending, Lag: group.Lag, Consumers: group.Consumers}, nil
+		}
+	}
+	return StreamLag{}, &DatabaseError{Message: fmt.Sprintf("group '%s' not found on stream '%s'", c.Group, c.Stream)}
+}
+
+// Trim removes entries beyond MaxLen and older than MaxAge, returning removed count
+func (c *StreamConsumer) Trim(ctx context.Context) (int64, error) {
+	rdb, err := streamClient()
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	if c.MaxLen > 0 {
+		n, err := rdb.XTrimMaxLenApprox(ctx, c.Stream, c.MaxLen, 0).Result()
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	if c.MaxAge > 0 {
+		minID := fmt.Sprintf("%d-0", time.Now().Add(-c.MaxAge).UnixMilli())
+		n, err := rdb.XTrimMinIDApprox(ctx, c.Stream, minID, 0).Result()
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	return removed, nil
+}
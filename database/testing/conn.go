@@ -0,0 +1,241 @@
+package testing
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// dsnConnector opens connections of drivers without DriverContext
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// faultConnector wraps connections of base in conn
+type faultConnector struct {
+	base     driver.Connector
+	injector *FaultInjector
+}
+
+func (c *faultConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	base, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{base: base, injector: c.injector}, nil
+}
+
+func (c *faultConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// conn injects faults before delegating to base, optional interfaces of base are used when present
+type conn struct {
+	base     driver.Conn
+	injector *FaultInjector
+	bad      bool
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		base driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.base.(driver.ConnPrepareContext); ok {
+		base, err = preparer.PrepareContext(ctx, query)
+	} else {
+		base, err = c.base.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{base: base, conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return c.base.Close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.injector.inject(ctx, c, "BEGIN"); err != nil {
+		return nil, err
+	}
+
+	var (
+		base driver.Tx
+		err  error
+	)
+	if beginner, ok := c.base.(driver.ConnBeginTx); ok {
+		base, err = beginner.BeginTx(ctx, opts)
+	} else {
+		base, err = c.base.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tx{base: base, conn: c}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.base.(driver.ExecerContext)
+	if !ok {
+		// database/sql prepares the query instead, faults are injected by stmt
+		return nil, driver.ErrSkip
+	}
+	if err := c.injector.inject(ctx, c, query); err != nil {
+		return nil, err
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.base.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.injector.inject(ctx, c, query); err != nil {
+		return nil, err
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if c.bad {
+		return driver.ErrBadConn
+	}
+	if pinger, ok := c.base.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if c.bad {
+		return driver.ErrBadConn
+	}
+	if resetter, ok := c.base.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if c.bad {
+		return false
+	}
+	if validator, ok := c.base.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.base.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// stmt injects faults on every execution of a prepared query
+type stmt struct {
+	base  driver.Stmt
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return s.base.Close()
+}
+
+func (s *stmt) NumInput() int {
+	return s.base.NumInput()
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.conn.injector.inject(ctx, s.conn, s.query); err != nil {
+		return nil, err
+	}
+	if execer, ok := s.base.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	return s.base.Exec(values(args))
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.conn.injector.inject(ctx, s.conn, s.query); err != nil {
+		return nil, err
+	}
+	if queryer, ok := s.base.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	return s.base.Query(values(args))
+}
+
+func (s *stmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.base.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return s.conn.CheckNamedValue(value)
+}
+
+// tx injects faults on COMMIT and ROLLBACK
+type tx struct {
+	base driver.Tx
+	conn *conn
+}
+
+func (t *tx) Commit() error {
+	if err := t.conn.injector.inject(context.Background(), t.conn, "COMMIT"); err != nil {
+		t.base.Rollback()
+		return err
+	}
+	return t.base.Commit()
+}
+
+func (t *tx) Rollback() error {
+	if err := t.conn.injector.inject(context.Background(), t.conn, "ROLLBACK"); err != nil {
+		t.base.Rollback()
+		return err
+	}
+	return t.base.Rollback()
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	plain := make([]driver.Value, len(args))
+	for i, arg := range args {
+		plain[i] = arg.Value
+	}
+	return plain
+}
@@ -0,0 +1,185 @@
+package testing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+)
+
+// Fault represents failure injected into queries matching Pattern
+type Fault struct {
+	// Pattern is regular expression matched against SQL, empty matches every query.
+	// BEGIN, COMMIT and ROLLBACK are matched as those statements
+	Pattern string
+	// Latency delays matching queries, context cancellation and deadlines end the delay with ctx.Err()
+	Latency time.Duration
+	// Drop fails with driver.ErrBadConn and discards the connection, database/sql retries such
+	// queries on fresh connections, so use Times above its retries to surface the error
+	Drop bool
+	// SQLState fails with SQLStateError, classified by database.Classify like PostgreSQL errors
+	SQLState string
+	// Err fails with any other error
+	Err error
+	// Times limits how often fault fires, zero fires on every match
+	Times int
+}
+
+// SQLStateError is returned for faults with SQLState
+type SQLStateError struct {
+	Code  string
+	Query string
+}
+
+func (e *SQLStateError) Error() string {
+	return fmt.Sprintf("injected fault: SQLSTATE %s", e.Code)
+}
+
+// SQLState returns the injected code
+func (e *SQLStateError) SQLState() string {
+	return e.Code
+}
+
+// Rule is injected fault
+type Rule struct {
+	fault   Fault
+	pattern *regexp.Regexp
+	hits    atomic.Int64
+}
+
+// Hits returns how often rule fired
+func (r *Rule) Hits() int {
+	return int(r.hits.Load())
+}
+
+// FaultInjector wraps driver so tests can inject latency, dropped connections and SQL errors per query.
+// Connections are opened by the sql driver of Base with the session DSN, connector options of Base
+// such as pgbouncer mode or MSSQL token auth are not applied
+type FaultInjector struct {
+	Base database.DatabaseDriver
+
+	mu    sync.Mutex
+	rules []*Rule
+}
+
+// NewFaultInjector creates injector over base driver
+func NewFaultInjector(base database.DatabaseDriver) *FaultInjector {
+	return &FaultInjector{Base: base}
+}
+
+// Register registers injector over base as database driver name, sessions with Driver name then use it
+func Register(name string, base database.DatabaseDriver) *FaultInjector {
+	injector := NewFaultInjector(base)
+	database.RegisterDriver(name, injector)
+	return injector
+}
+
+// Inject adds fault, earlier rules win when several match
+func (f *FaultInjector) Inject(fault Fault) (*Rule, error) {
+	rule := &Rule{fault: fault}
+	if fault.Pattern != "" {
+		pattern, err := regexp.Compile(fault.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("database/testing : invalid pattern: %w", err)
+		}
+		rule.pattern = pattern
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, rule)
+	return rule, nil
+}
+
+// Reset removes all faults
+func (f *FaultInjector) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = nil
+}
+
+// match returns fault firing for query and records the hit
+func (f *FaultInjector) match(query string) (Fault, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, rule := range f.rules {
+		if rule.pattern != nil && !rule.pattern.MatchString(query) {
+			continue
+		}
+		if rule.fault.Times > 0 && rule.Hits() >= rule.fault.Times {
+			continue
+		}
+		rule.hits.Add(1)
+		return rule.fault, true
+	}
+	return Fault{}, false
+}
+
+// inject applies fault matching query, a dropped connection is marked bad on conn
+func (f *FaultInjector) inject(ctx context.Context, c *conn, query string) error {
+	fault, ok := f.match(query)
+	if !ok {
+		return nil
+	}
+
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case fault.Drop:
+		c.bad = true
+		return driver.ErrBadConn
+	case fault.SQLState != "":
+		return &SQLStateError{Code: fault.SQLState, Query: query}
+	default:
+		return fault.Err
+	}
+}
+
+func (f *FaultInjector) Open(dsn string) (*sql.DB, error) {
+	baseDB, err := f.Base.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	// baseDB only provides the sql driver, it has not connected yet
+	sqlDriver := baseDB.Driver()
+	baseDB.Close()
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: sqlDriver}
+	if driverCtx, ok := sqlDriver.(driver.DriverContext); ok {
+		if connector, err = driverCtx.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(&faultConnector{base: connector, injector: f}), nil
+}
+
+func (f *FaultInjector) CreateBunDB(sqlDB *sql.DB) *bun.DB {
+	return f.Base.CreateBunDB(sqlDB)
+}
+
+func (f *FaultInjector) GetDriverName() string {
+	return f.Base.GetDriverName()
+}
+
+func (f *FaultInjector) Capabilities() database.Capability {
+	if provider, ok := f.Base.(database.CapabilityProvider); ok {
+		return provider.Capabilities()
+	}
+	return database.CapAll
+}
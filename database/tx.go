@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+
+	"github.com/rikiihsan/nest/requestctx"
+	"github.com/uptrace/bun"
+)
+
+type (
+	txKey      struct{ session string }
+	currentKey struct{}
+)
+
+// unitOfWork tracks transaction of a session and callbacks run after commit
+type unitOfWork struct {
+	tx          bun.Tx
+	afterCommit []func(ctx context.Context)
+}
+
+// RunInTx runs fn in a transaction of session propagated through ctx.
+// Nested calls for the same session join the outer transaction
+func RunInTx(ctx context.Context, sessionName string, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{sessionName}).(*unitOfWork); ok {
+		return fn(ctx)
+	}
+
+	db, err := GetDB(sessionName)
+	if err != nil {
+		return err
+	}
+	if !Supports(sessionName, CapTransactions) {
+		return ErrUnsupported(sessionName, "transactions")
+	}
+
+	uow := &unitOfWork{}
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := ApplyRLS(ctx, tx, sessionName); err != nil {
+			return err
+		}
+		uow.tx = tx
+		ctx = context.WithValue(ctx, txKey{sessionName}, uow)
+		ctx = context.WithValue(ctx, currentKey{}, uow)
+		ctx = requestctx.Tx.With(ctx, tx)
+		return fn(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, callback := range uow.afterCommit {
+		callback(ctx)
+	}
+	return nil
+}
+
+// TxFromContext returns transaction of session started by RunInTx
+func TxFromContext(ctx context.Context, sessionName string) (bun.Tx, bool) {
+	if uow, ok := ctx.Value(txKey{sessionName}).(*unitOfWork); ok {
+		return uow.tx, true
+	}
+	return bun.Tx{}, false
+}
+
+// IDB returns transaction of session from ctx, otherwise the session DB
+func IDB(ctx context.Context, sessionName string) (bun.IDB, error) {
+	if tx, ok := TxFromContext(ctx, sessionName); ok {
+		return tx, nil
+	}
+	return GetDB(sessionName)
+}
+
+// AfterCommit runs fn once the innermost transaction of ctx commits, immediately without transaction
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if uow, ok := ctx.Value(currentKey{}).(*unitOfWork); ok {
+		uow.afterCommit = append(uow.afterCommit, fn)
+		return
+	}
+	fn(ctx)
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WaitOptions represents dependencies WaitFor blocks on
+type WaitOptions struct {
+	// Sessions are names of database sessions to ping
+	Sessions []string
+	// Configs are sessions WaitFor creates like Init, retrying until their database responds. Use them
+	// instead of Init when the database may not be up yet, Init fails on the first unreachable ping
+	Configs []Config
+	// Redis pings RedisClient
+	Redis bool
+	// MaxWait defaults to 30s
+	MaxWait time.Duration
+	// Interval between attempts defaults to 1s
+	Interval time.Duration
+}
+
+// WaitFor blocks until all listed dependencies respond to ping, MaxWait elapses or ctx is done
+func WaitFor(ctx context.Context, opts WaitOptions) error {
+	if opts.MaxWait <= 0 {
+		opts.MaxWait = 30 * time.Second
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	checks := make(map[string]func(context.Context) error)
+	for _, config := range opts.Configs {
+		checks[config.Name] = func(ctx context.Context) error {
+			if session, exists := Manager.sessions[config.Name]; exists {
+				return session.Ping(ctx)
+			}
+			return Manager.createSession(config)
+		}
+	}
+	for _, name := range opts.Sessions {
+		if _, exists := checks[name]; exists {
+			continue
+		}
+		session, exists := Manager.sessions[name]
+		if !exists {
+			return ErrSessionNotFound(name)
+		}
+		checks[name] = session.Ping
+	}
+	if opts.Redis {
+		if RedisClient == nil {
+			return &DatabaseError{Message: "redis client not initialized"}
+		}
+		checks["redis"] = func(ctx context.Context) error {
+			return RedisClient.Ping(ctx).Err()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.MaxWait)
+	defer cancel()
+
+	start := time.Now()
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	failures := make(map[string]error)
+	for attempt := 1; ; attempt++ {
+		for name, check := range checks {
+			pingCtx, pingCancel := context.WithTimeout(ctx, opts.Interval)
+			err := check(pingCtx)
+			pingCancel()
+			if err != nil {
+				failures[name] = err
+				continue
+			}
+			if attempt > 1 {
+				slog.InfoContext(ctx, "dependency ready", "name", name, "elapsed", time.Since(start).Round(time.Millisecond))
+			}
+			delete(failures, name)
+			delete(checks, name)
+		}
+		if len(checks) == 0 {
+			return nil
+		}
+
+		slog.WarnContext(ctx, "waiting for dependencies",
+			"pending", pendingNames(failures),
+			"attempt", attempt,
+			"elapsed", time.Since(start).Round(time.Millisecond),
+		)
+
+		select {
+		case <-ctx.Done():
+			var errs []string
+			for name, err := range failures {
+				errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			}
+			return &DatabaseError{
+				Message: fmt.Sprintf("dependencies not ready after %s (%s)", time.Since(start).Round(time.Millisecond), strings.Join(errs, "; ")),
+				Err:     ctx.Err(),
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// pendingNames returns sorted names of failing dependencies
+func pendingNames(failures map[string]error) []string {
+	names := make([]string, 0, len(failures))
+	for name := range failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,47 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BatchFunc loads values of keys in one call, keys absent from the result are reported missing
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Option configures Loader
+type Option func(*options)
+
+type options struct {
+	wait     time.Duration
+	maxBatch int
+}
+
+// Defaults used when options are not given
+var (
+	DefaultWait     = 2 * time.Millisecond
+	DefaultMaxBatch = 500
+)
+
+// WithWait sets how long loads are collected before the batch runs
+func WithWait(wait time.Duration) Option {
+	return func(o *options) {
+		o.wait = wait
+	}
+}
+
+// WithMaxBatch runs batch early once it holds n keys
+func WithMaxBatch(n int) Option {
+	return func(o *options) {
+		o.maxBatch = n
+	}
+}
+
+// panicError reports panic of batch function
+type panicError struct {
+	value interface{}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("dataloader : batch function panicked: %v", e.value)
+}
@@ -0,0 +1,239 @@
+package dataloader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/database/repository"
+)
+
+// result holds outcome of one key, done is closed once it is set
+type result[V any] struct {
+	value V
+	err   error
+	done  chan struct{}
+}
+
+// batch collects keys until it runs
+type batch[K comparable, V any] struct {
+	keys    []K
+	results map[K]*result[V]
+	timer   *time.Timer
+}
+
+// Loader batches and caches loads of V by K, create one per request with Get
+type Loader[K comparable, V any] struct {
+	fn      BatchFunc[K, V]
+	options options
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+// New creates loader of fn
+func New[K comparable, V any](fn BatchFunc[K, V], opts ...Option) *Loader[K, V] {
+	o := options{wait: DefaultWait, maxBatch: DefaultMaxBatch}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Loader[K, V]{fn: fn, options: o, cache: map[K]*result[V]{}}
+}
+
+// Load returns value of key, sql.ErrNoRows when the batch did not return it.
+// Loads of the same key share one result for the life of the loader
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	r := l.enqueue(ctx, key)
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// LoadMany returns values of keys found, in one or more batches
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	pending := make(map[K]*result[V], len(keys))
+	for _, key := range keys {
+		pending[key] = l.enqueue(ctx, key)
+	}
+
+	values := make(map[K]V, len(keys))
+	for key, r := range pending {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if errors.Is(r.err, sql.ErrNoRows) {
+			continue
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		values[key] = r.value
+	}
+	return values, nil
+}
+
+// enqueue returns cached or pending result of key, adding it to the current batch
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.cache[key]; ok {
+		return r
+	}
+	r := &result[V]{done: make(chan struct{})}
+	l.cache[key] = r
+
+	if l.pending == nil {
+		b := &batch[K, V]{results: map[K]*result[V]{}}
+		// Callers waiting on the batch may give up, the batch itself finishes
+		batchCtx := context.WithoutCancel(ctx)
+		b.timer = time.AfterFunc(l.options.wait, func() {
+			l.dispatch(batchCtx, b)
+		})
+		l.pending = b
+	}
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.results[key] = r
+	if len(b.keys) >= l.options.maxBatch && b.timer.Stop() {
+		l.pending = nil
+		go l.run(context.WithoutCancel(ctx), b)
+	}
+	return r
+}
+
+// dispatch runs batch started by its timer
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+	l.run(ctx, b)
+}
+
+// run calls batch function and resolves results of batch
+func (l *Loader[K, V]) run(ctx context.Context, b *batch[K, V]) {
+	values, err := l.call(ctx, b.keys)
+	for key, r := range b.results {
+		value, ok := values[key]
+		switch {
+		case err != nil:
+			r.err = err
+		case !ok:
+			r.err = sql.ErrNoRows
+		default:
+			r.value = value
+		}
+		close(r.done)
+	}
+
+	// Failed loads are retried by the next Load
+	if err != nil {
+		l.mu.Lock()
+		for key, r := range b.results {
+			if l.cache[key] == r {
+				delete(l.cache, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// call runs batch function converting panic into error
+func (l *Loader[K, V]) call(ctx context.Context, keys []K) (values map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r}
+		}
+	}()
+	return l.fn(ctx, keys)
+}
+
+// Prime stores value of key unless already loaded
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[key]; ok {
+		return
+	}
+	r := &result[V]{value: value, done: make(chan struct{})}
+	close(r.done)
+	l.cache[key] = r
+}
+
+// Clear forgets cached value of key, e.g. after updating it
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, key)
+}
+
+// FromRepository creates loader of entities by primary key using Repository.FindByIDs,
+// key returns primary key of loaded entity
+func FromRepository[K comparable, T any](repo *repository.Repository[T], key func(entity *T) K, opts ...Option) *Loader[K, *T] {
+	return New(func(ctx context.Context, keys []K) (map[K]*T, error) {
+		ids := make([]interface{}, len(keys))
+		for i, k := range keys {
+			ids[i] = k
+		}
+		entities, err := repo.FindByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[K]*T, len(entities))
+		for i := range entities {
+			values[key(&entities[i])] = &entities[i]
+		}
+		return values, nil
+	}, opts...)
+}
+
+type registryKey struct{}
+
+// registry holds loaders of one request
+type registry struct {
+	mu      sync.Mutex
+	loaders map[string]interface{}
+}
+
+// WithLoaders returns context scoping loaders created by Get
+func WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, registryKey{}, &registry{loaders: map[string]interface{}{}})
+}
+
+// Middleware scopes loaders to each request through its user context
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(WithLoaders(c.UserContext()))
+		return c.Next()
+	}
+}
+
+// Get returns loader named name of ctx, creating it with factory on first use.
+// Without WithLoaders or Middleware every call creates a new loader
+func Get[K comparable, V any](ctx context.Context, name string, factory func() *Loader[K, V]) *Loader[K, V] {
+	reg, ok := ctx.Value(registryKey{}).(*registry)
+	if !ok {
+		return factory()
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if loader, ok := reg.loaders[name].(*Loader[K, V]); ok {
+		return loader
+	}
+	loader := factory()
+	reg.loaders[name] = loader
+	return loader
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package disconnect
+
+import "net"
+
+// peerClosed is not supported on this platform, queries are cancelled when the handler returns
+func peerClosed(conn net.Conn) bool {
+	return false
+}
@@ -0,0 +1,34 @@
+//go:build linux || darwin
+
+package disconnect
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// peerClosed peeks socket without consuming data, EOF or reset means the peer is gone
+func peerClosed(conn net.Conn) bool {
+	if tlsConn, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = tlsConn.NetConn()
+	}
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return false
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	closed := false
+	raw.Read(func(fd uintptr) bool {
+		var buf [1]byte
+		n, _, err := syscall.Recvfrom(int(fd), buf[:], syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		closed = (n == 0 && err == nil) || errors.Is(err, syscall.ECONNRESET)
+		// Never wait for readability
+		return true
+	})
+	return closed
+}
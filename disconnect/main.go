@@ -0,0 +1,93 @@
+package disconnect
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/uptrace/bun"
+)
+
+// ErrClientDisconnected is the cancel cause of request contexts whose client went away
+var ErrClientDisconnected = errors.New("disconnect : client disconnected")
+
+// Config represents disconnect middleware configuration
+type Config struct {
+	// PollInterval is how often the connection is checked
+	PollInterval time.Duration
+}
+
+var cancelled atomic.Int64
+
+// New returns middleware cancelling c.UserContext() when the client disconnects,
+// queries run with that context are aborted and release their pool connection
+func New(config ...Config) fiber.Handler {
+	cfg := Config{PollInterval: 100 * time.Millisecond}
+	if len(config) > 0 && config[0].PollInterval > 0 {
+		cfg.PollInterval = config[0].PollInterval
+	}
+
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithCancelCause(c.UserContext())
+		defer cancel(nil)
+		c.SetUserContext(ctx)
+
+		done := make(chan struct{})
+		defer close(done)
+		go watch(c.Context().Conn(), cfg.PollInterval, done, func() {
+			cancel(ErrClientDisconnected)
+		})
+
+		return c.Next()
+	}
+}
+
+// watch polls conn until done and calls onClose once it is closed by the peer
+func watch(conn net.Conn, interval time.Duration, done <-chan struct{}, onClose func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if peerClosed(conn) {
+				onClose()
+				return
+			}
+		}
+	}
+}
+
+// Disconnected reports whether ctx was cancelled because the client disconnected
+func Disconnected(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrClientDisconnected)
+}
+
+// Cancelled returns number of queries aborted by client disconnects
+func Cancelled() int64 {
+	return cancelled.Load()
+}
+
+// QueryHook counts and logs queries aborted by client disconnects, register with database.AddQueryHook
+type QueryHook struct{}
+
+func (QueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (QueryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if event.Err == nil || !Disconnected(ctx) {
+		return
+	}
+	cancelled.Add(1)
+	slog.DebugContext(ctx, "query cancelled after client disconnect",
+		"operation", event.Operation(),
+		"duration", time.Since(event.StartTime),
+	)
+}
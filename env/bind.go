@@ -0,0 +1,146 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rikiihsan/nest/secure"
+)
+
+// Bind populates struct fields from environment using `env` and `default` tags
+//
+//	type Config struct {
+//		Port    int           `env:"PORT" default:"3000"`
+//		DSN     string        `env:"DATABASE_DSN,required"`
+//		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+//	}
+func Bind(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("env: bind target must be pointer to struct")
+	}
+	return bindStruct(v.Elem())
+}
+
+func bindStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("env")
+		if tag == "" {
+			// Nested structs without env tag are bound recursively
+			if value.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+				if err := bindStruct(value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		key := parts[0]
+		required := len(parts) > 1 && parts[1] == "required"
+
+		secret := field.Type == reflect.TypeOf(secure.Sensitive{})
+		raw, exists := os.LookupEnv(key)
+		if exists {
+			value, err := Resolve(raw)
+			if err != nil {
+				return err
+			}
+			source, file := sourceOf(key)
+			track(key, value, source, file, secret || value != raw)
+			raw = value
+		}
+		if !exists {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				raw, exists = def, true
+				track(key, def, SourceDefault, "", secret)
+			}
+		}
+		if !exists {
+			if required {
+				return fmt.Errorf("env: required variable %s is not set", key)
+			}
+			continue
+		}
+
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("env: invalid value for %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, raw string) error {
+	// time.Duration is int64 kind, handle it first
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.Type() == reflect.TypeOf(secure.Sensitive{}) {
+		v.Set(reflect.ValueOf(secure.New(raw)))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := []string{}
+		if raw != "" {
+			items = strings.Split(raw, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package env
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rikiihsan/nest/secure"
+)
+
+// Sources of effective values
+const (
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceDefault = "default"
+)
+
+// SecretPatterns are key substrings whose values are masked in Effective
+var SecretPatterns = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "KEY", "DSN", "CREDENTIAL", "PRIVATE"}
+
+// HashKey keys the HMAC of Entry.Hash so low-entropy secrets can't be brute-forced from it. Defaults to a
+// random key of this process, set the same key before Load on instances whose snapshots are compared by Diff
+var HashKey = randomKey()
+
+func randomKey() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}
+
+// Entry represents effective value of a key, secret values are masked and identified by Hash only
+type Entry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	// File is set for SourceFile
+	File   string `json:"file,omitempty"`
+	Secret bool   `json:"secret"`
+	Hash   string `json:"hash"`
+}
+
+// Change represents key differing between two configurations
+type Change struct {
+	Key string `json:"key"`
+	Old *Entry `json:"old,omitempty"`
+	New *Entry `json:"new,omitempty"`
+}
+
+var (
+	effectiveMu sync.RWMutex
+	effective   = make(map[string]Entry)
+)
+
+// track records origin of key value set by Load or read by Bind
+func track(key, value, source, file string, secret bool) {
+	mac := hmac.New(sha256.New, HashKey)
+	mac.Write([]byte(value))
+	sum := mac.Sum(nil)
+	entry := Entry{
+		Key:    key,
+		Value:  value,
+		Source: source,
+		File:   file,
+		Secret: secret || isSecretKey(key),
+		Hash:   hex.EncodeToString(sum[:8]),
+	}
+
+	effectiveMu.Lock()
+	defer effectiveMu.Unlock()
+
+	// Values resolved from references by Load stay masked when Bind reads them again
+	if previous, ok := effective[key]; ok && previous.Secret && previous.Hash == entry.Hash {
+		entry.Secret = true
+	}
+	if entry.Secret {
+		entry.Value = secure.Redacted
+	}
+	effective[key] = entry
+}
+
+// sourceOf returns recorded source of key present in process env
+func sourceOf(key string) (string, string) {
+	effectiveMu.RLock()
+	defer effectiveMu.RUnlock()
+	if entry, ok := effective[key]; ok && entry.Source == SourceFile {
+		return SourceFile, entry.File
+	}
+	return SourceEnv, ""
+}
+
+// isSecretKey reports whether key matches SecretPatterns
+func isSecretKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, pattern := range SecretPatterns {
+		if strings.Contains(upper, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Effective returns values loaded by Load and read by Bind with their source, sorted by key.
+// Values of secret keys, resolved references and secure.Sensitive fields are masked
+func Effective() []Entry {
+	effectiveMu.RLock()
+	defer effectiveMu.RUnlock()
+
+	entries := make([]Entry, 0, len(effective))
+	for _, entry := range effective {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Diff returns keys whose value or source differ between Effective and other, e.g. a snapshot of
+// another instance. Values are compared by Hash, which matches across instances sharing HashKey only
+func Diff(other []Entry) []Change {
+	current := make(map[string]Entry)
+	for _, entry := range Effective() {
+		current[entry.Key] = entry
+	}
+	previous := make(map[string]Entry)
+	for _, entry := range other {
+		previous[entry.Key] = entry
+	}
+
+	var changes []Change
+	for key, entry := range current {
+		old, ok := previous[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: key, New: &entry})
+		case old.Hash != entry.Hash || old.Source != entry.Source || old.File != entry.File:
+			changes = append(changes, Change{Key: key, Old: &old, New: &entry})
+		}
+	}
+	for key, old := range previous {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, Change{Key: key, Old: &old})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
@@ -0,0 +1,98 @@
+package env
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyEnv holds base64 encoded key used by the env:encrypt/env:decrypt commands when --key is not given
+const KeyEnv = "NEST_ENV_KEY"
+
+// encryptedHeader is the first line of encrypted env files
+const encryptedHeader = "NEST-ENV-AES256GCM-V1"
+
+var (
+	ErrInvalidKey       = errors.New("env: key must be 32 bytes encoded as base64")
+	ErrInvalidEncrypted = errors.New("env: file is not an encrypted env file or key is wrong")
+)
+
+// GenerateKey returns random base64 encoded AES-256 key
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// newGCM creates AES-256-GCM cipher from base64 key
+func newGCM(key string) (cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(raw) != 32 {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts dotenv content with AES-256-GCM into a text file safe to commit
+func Encrypt(plaintext []byte, key string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(encryptedHeader))
+	return []byte(encryptedHeader + "\n" + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Decrypt decrypts content produced by Encrypt
+func Decrypt(data []byte, key string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header, body, ok := bytes.Cut(data, []byte("\n"))
+	if !ok || string(bytes.TrimSpace(header)) != encryptedHeader {
+		return nil, ErrInvalidEncrypted
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(body)))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return nil, ErrInvalidEncrypted
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(encryptedHeader))
+	if err != nil {
+		return nil, ErrInvalidEncrypted
+	}
+	return plaintext, nil
+}
+
+// LoadEncrypted decrypts env file at path with base64 key and sets its values like Load
+func LoadEncrypted(path, key string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	plaintext, err := Decrypt(data, key)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, path)
+	}
+	defer clear(plaintext)
+	return parseandset(bytes.NewReader(plaintext), path)
+}
@@ -2,6 +2,8 @@ package env
 
 import (
 	"bufio"
+	"io"
+	"log/slog"
 	"os"
 	"strings"
 )
@@ -12,16 +14,25 @@ func openandset(filename string) error {
 		return err
 	}
 	defer file.Close()
-	scanner := bufio.NewScanner(file)
+	return parseandset(file, filename)
+}
+
+func parseandset(r io.Reader, filename string) error {
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
 			continue
 		}
-		os.Setenv(parts[0], parts[1])
+		value, err := Resolve(parts[1])
+		if err != nil {
+			return err
+		}
+		os.Setenv(parts[0], value)
+		track(parts[0], value, SourceFile, filename, value != parts[1])
 	}
-	return nil
+	return scanner.Err()
 }
 
 func Load(path ...string) error {
@@ -41,14 +52,32 @@ func Load(path ...string) error {
 	return nil
 }
 
+// Lookup returns resolved value of key and whether it is set, failing when its @file: or $(command)
+// reference cannot be resolved
+func Lookup(key string) (string, bool, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return "", false, nil
+	}
+	value, err := Resolve(v)
+	if err != nil {
+		return "", true, err
+	}
+	return value, true, nil
+}
+
+// Get returns resolved value of key or its default. Unresolvable references are logged and fall back
+// like unset keys, use Lookup to handle them
 func Get(key string, defaults ...string) string {
-	v, s := os.LookupEnv(key)
-	if s {
-		return v
-	} else {
-		if len(defaults) > 0 {
-			return defaults[0]
-		}
-		return v
+	value, ok, err := Lookup(key)
+	if err != nil {
+		slog.Error("env: failed to resolve variable", "key", key, "error", err)
+	}
+	if ok && err == nil {
+		return value
+	}
+	if len(defaults) > 0 {
+		return defaults[0]
 	}
+	return ""
 }
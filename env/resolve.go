@@ -0,0 +1,83 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FilePrefix marks values read from a file, e.g. DB_PASSWORD=@file:/run/secrets/db_password
+const FilePrefix = "@file:"
+
+// CommandTimeout limits $(command) execution
+var CommandTimeout = 10 * time.Second
+
+var (
+	resolveMu       sync.Mutex
+	allowedCommands []string
+	resolved        = make(map[string]string)
+)
+
+// AllowCommands allows executables usable in $(command args) values, e.g. AllowCommands("op", "vault").
+// Commands are looked up in PATH by exact name and run without a shell, arguments are split on whitespace
+func AllowCommands(names ...string) {
+	resolveMu.Lock()
+	defer resolveMu.Unlock()
+	allowedCommands = append(allowedCommands, names...)
+}
+
+// Resolve returns value with @file: and $(command) references replaced by file content or command output,
+// trailing newlines are trimmed. Plain values are returned unchanged
+func Resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, FilePrefix):
+		content, err := os.ReadFile(strings.TrimPrefix(value, FilePrefix))
+		if err != nil {
+			return "", fmt.Errorf("env: failed to read %s: %w", value, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case strings.HasPrefix(value, "$(") && strings.HasSuffix(value, ")"):
+		return command(strings.TrimSuffix(strings.TrimPrefix(value, "$("), ")"))
+	}
+	return value, nil
+}
+
+// command runs allowlisted command once, later calls return cached output
+func command(line string) (string, error) {
+	resolveMu.Lock()
+	defer resolveMu.Unlock()
+
+	if out, ok := resolved[line]; ok {
+		return out, nil
+	}
+
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return "", fmt.Errorf("env: empty command")
+	}
+	// paths would run any binary sharing an allowlisted name
+	if strings.ContainsAny(args[0], `/\`) || !slices.Contains(allowedCommands, args[0]) {
+		return "", fmt.Errorf("env: command %s is not allowed, see AllowCommands", args[0])
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return "", fmt.Errorf("env: command %s not found: %w", args[0], err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CommandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, args[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("env: command %s failed: %w", args[0], err)
+	}
+
+	value := strings.TrimRight(string(out), "\r\n")
+	resolved[line] = value
+	return value, nil
+}
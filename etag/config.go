@@ -0,0 +1,29 @@
+package etag
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Config represents ETag middleware configuration
+type Config struct {
+	// Next skips middleware when returning true
+	Next func(c *fiber.Ctx) bool
+	// Weak generates W/ prefixed tags from response bodies
+	Weak bool
+	// CacheKey enables serving GET responses from the cache package, empty key skips caching.
+	// Include everything the response varies on, e.g. user ID, and mount after authentication
+	CacheKey func(c *fiber.Ctx) string
+	// CacheTTL of cached responses, cache.Config.DefaultTTL when zero
+	CacheTTL time.Duration
+	// CacheTags returns tags of cached response, invalidated with cache.InvalidateTags
+	CacheTags func(c *fiber.Ctx) []string
+}
+
+// entry represents cached response
+type entry struct {
+	ETag        string `json:"etag"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
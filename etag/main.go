@@ -0,0 +1,163 @@
+package etag
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/cache"
+	"github.com/rikiihsan/nest/logger"
+)
+
+// ErrPreconditionFailed is returned by Precondition when If-Match does not match current entity
+var ErrPreconditionFailed = fiber.NewError(fiber.StatusPreconditionFailed, "resource was modified")
+
+// New returns middleware setting ETag of successful GET and HEAD responses and answering
+// If-None-Match with 304. Tags set by handlers with Set are kept, otherwise the body is hashed
+func New(configs ...Config) fiber.Handler {
+	cfg := Config{}
+	if len(configs) > 0 {
+		cfg = configs[0]
+	}
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		key := ""
+		if cfg.CacheKey != nil {
+			key = cfg.CacheKey(c)
+		}
+		if key != "" {
+			var cached entry
+			if err := cache.Get(c.UserContext(), key, &cached); err == nil {
+				c.Set(fiber.HeaderETag, cached.ETag)
+				if notModified(c, cached.ETag) {
+					return c.SendStatus(fiber.StatusNotModified)
+				}
+				c.Set(fiber.HeaderContentType, cached.ContentType)
+				return c.Status(fiber.StatusOK).Send(cached.Body)
+			} else if !errors.Is(err, cache.ErrMiss) {
+				logger.Get().WarnContext(c.UserContext(), "etag cache lookup failed", "key", key, "error", err)
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		res := c.Response()
+		if res.StatusCode() != fiber.StatusOK || res.IsBodyStream() {
+			return nil
+		}
+
+		tag := string(res.Header.Peek(fiber.HeaderETag))
+		if tag == "" {
+			tag = hash(res.Body(), cfg.Weak)
+			c.Set(fiber.HeaderETag, tag)
+		}
+
+		if key != "" {
+			var tags []string
+			if cfg.CacheTags != nil {
+				tags = cfg.CacheTags(c)
+			}
+			stored := entry{ETag: tag, ContentType: string(res.Header.ContentType()), Body: res.Body()}
+			if err := cache.Set(c.UserContext(), key, stored, cfg.CacheTTL, cache.WithTags(tags...)); err != nil {
+				logger.Get().WarnContext(c.UserContext(), "etag cache store failed", "key", key, "error", err)
+			}
+		}
+
+		if notModified(c, tag) {
+			res.ResetBody()
+			c.Status(fiber.StatusNotModified)
+		}
+		return nil
+	}
+}
+
+// hash returns tag of body
+func hash(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	tag := fmt.Sprintf(`"%x-%x"`, len(body), sum[:12])
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// opaque strips weak prefix of tag
+func opaque(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+}
+
+// matches reports whether header list contains tag, comparing opaque tags
+func matches(header, tag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if opaque(candidate) == opaque(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified reports whether If-None-Match of request matches tag
+func notModified(c *fiber.Ctx, tag string) bool {
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	return header != "" && matches(header, tag)
+}
+
+// Of returns weak ETag of model from its Version field, otherwise its UpdatedAt field.
+// Returns false when model has neither
+func Of(model interface{}) (string, bool) {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	if v.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	if version := v.FieldByName("Version"); version.IsValid() {
+		switch version.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return fmt.Sprintf(`W/"v%d"`, version.Int()), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return fmt.Sprintf(`W/"v%d"`, version.Uint()), true
+		}
+	}
+	if updatedAt := v.FieldByName("UpdatedAt"); updatedAt.IsValid() && updatedAt.CanInterface() {
+		if t, ok := updatedAt.Interface().(time.Time); ok {
+			return fmt.Sprintf(`W/"t%x"`, t.UnixNano()), true
+		}
+	}
+	return "", false
+}
+
+// Set sets ETag of model on response, the middleware keeps it instead of hashing the body
+func Set(c *fiber.Ctx, model interface{}) {
+	if tag, ok := Of(model); ok {
+		c.Set(fiber.HeaderETag, tag)
+	}
+}
+
+// Precondition checks If-Match of request against model before it is modified,
+// returns ErrPreconditionFailed when another write happened since the client read it
+func Precondition(c *fiber.Ctx, model interface{}) error {
+	header := c.Get(fiber.HeaderIfMatch)
+	if header == "" {
+		return nil
+	}
+	tag, ok := Of(model)
+	if !ok || !matches(header, tag) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Event represents message published on the bus
+type Event struct {
+	Topic      string
+	Payload    interface{}
+	OccurredAt time.Time
+}
+
+// Handler handles published event
+type Handler func(ctx context.Context, event Event) error
+
+// subscription binds handler to topic pattern
+type subscription struct {
+	id      uint64
+	pattern string
+	handler Handler
+}
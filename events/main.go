@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+)
+
+// Bus dispatches events to subscribers in process
+type Bus struct {
+	mu            sync.RWMutex
+	nextID        uint64
+	subscriptions []subscription
+}
+
+// Default is the bus used by package-level functions
+var Default = New()
+
+// New creates empty bus
+func New() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers handler for topic, "*" matches one segment and trailing ".>" the rest.
+// The returned func removes the subscription
+func (b *Bus) Subscribe(pattern string, handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subscriptions = append(b.subscriptions, subscription{id: id, pattern: pattern, handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.subscriptions {
+			if sub.id == id {
+				b.subscriptions = append(b.subscriptions[:i], b.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish calls matching handlers synchronously and joins their errors
+func (b *Bus) Publish(ctx context.Context, topic string, payload interface{}) error {
+	event := Event{Topic: topic, Payload: payload, OccurredAt: time.Now()}
+
+	b.mu.RLock()
+	var handlers []Handler
+	for _, sub := range b.subscriptions {
+		if Match(sub.pattern, topic) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := call(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PublishAfterCommit publishes once the transaction in ctx commits, errors go to onError
+func (b *Bus) PublishAfterCommit(ctx context.Context, topic string, payload interface{}, onError func(error)) {
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if err := b.Publish(ctx, topic, payload); err != nil && onError != nil {
+			onError(err)
+		}
+	})
+}
+
+// call runs handler converting panic into error
+func call(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event handler for %s panicked: %v", event.Topic, r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// Match reports whether dotted topic matches pattern
+func Match(pattern, topic string) bool {
+	if pattern == topic || pattern == ">" {
+		return true
+	}
+
+	patternParts := strings.Split(pattern, ".")
+	topicParts := strings.Split(topic, ".")
+	for i, part := range patternParts {
+		if part == ">" && i == len(patternParts)-1 {
+			return len(topicParts) > i
+		}
+		if i >= len(topicParts) || (part != "*" && part != topicParts[i]) {
+			return false
+		}
+	}
+	return len(patternParts) == len(topicParts)
+}
+
+// Subscribe registers handler on the default bus
+func Subscribe(pattern string, handler Handler) func() {
+	return Default.Subscribe(pattern, handler)
+}
+
+// Publish publishes event on the default bus
+func Publish(ctx context.Context, topic string, payload interface{}) error {
+	return Default.Publish(ctx, topic, payload)
+}
+
+// PublishAfterCommit publishes event on the default bus after commit
+func PublishAfterCommit(ctx context.Context, topic string, payload interface{}, onError func(error)) {
+	Default.PublishAfterCommit(ctx, topic, payload, onError)
+}
@@ -0,0 +1,99 @@
+package exporter
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Format of exported file
+type Format string
+
+const (
+	CSV   Format = "csv"
+	XLSX  Format = "xlsx"
+	JSONL Format = "jsonl"
+)
+
+// Status of export job
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Progress represents export job state stored in Redis
+type Progress struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name"`
+	Format Format            `json:"format"`
+	Params map[string]string `json:"params,omitempty"`
+	Owner  string            `json:"-"`
+	Status Status            `json:"status"`
+	// Rows written so far, Total is counted before streaming starts
+	Rows      int64     `json:"rows"`
+	Total     int64     `json:"total"`
+	Key       string    `json:"key,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Config represents exporter configuration
+type Config struct {
+	// Disk stores exported files
+	Disk string
+	// Queue runs export jobs
+	Queue string
+	// ChunkSize rows are written between progress updates
+	ChunkSize int
+	// Retention keeps progress of finished jobs
+	Retention time.Duration
+	// URLExpiry limits signed download URLs
+	URLExpiry time.Duration
+	// OwnerFunc identifies requester, jobs are only visible to their owner when set
+	OwnerFunc func(c *fiber.Ctx) string
+}
+
+// DefaultConfig is used until Init is called
+var DefaultConfig = Config{
+	Disk:      "default",
+	Queue:     "default",
+	ChunkSize: 1000,
+	Retention: 7 * 24 * time.Hour,
+	URLExpiry: time.Hour,
+}
+
+// Prefix of progress keys
+var Prefix = "nest:export:"
+
+var config = DefaultConfig
+
+// Init sets global exporter configuration, empty fields fall back to DefaultConfig
+func Init(cfg Config) {
+	if cfg.Disk == "" {
+		cfg.Disk = DefaultConfig.Disk
+	}
+	if cfg.Queue == "" {
+		cfg.Queue = DefaultConfig.Queue
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = DefaultConfig.ChunkSize
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultConfig.Retention
+	}
+	if cfg.URLExpiry <= 0 {
+		cfg.URLExpiry = DefaultConfig.URLExpiry
+	}
+	config = cfg
+}
+
+// GetConfig returns the current exporter configuration
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,150 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var contentTypes = map[Format]string{
+	CSV:   "text/csv",
+	XLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	JSONL: "application/x-ndjson",
+}
+
+// encoder writes exported rows
+type encoder interface {
+	Write(v reflect.Value) error
+	Close() error
+}
+
+// column of tabular formats
+type column struct {
+	name  string
+	index []int
+}
+
+func newEncoder(format Format, w io.Writer, typ reflect.Type) (encoder, error) {
+	switch format {
+	case CSV:
+		return newCSVEncoder(w, columnsOf(typ))
+	case XLSX:
+		return newXLSXEncoder(w, columnsOf(typ))
+	case JSONL:
+		buf := bufio.NewWriter(w)
+		return &jsonlEncoder{buf: buf, enc: json.NewEncoder(buf)}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrFormat, format)
+}
+
+// columnsOf returns exported fields of typ named by csv tag, falling back to json tag then field name
+func columnsOf(typ reflect.Type) []column {
+	var columns []column
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := field.Name
+		for _, key := range []string{"csv", "json"} {
+			if value := strings.Split(field.Tag.Get(key), ",")[0]; value != "" {
+				name = value
+				break
+			}
+		}
+		if name == "-" {
+			continue
+		}
+		columns = append(columns, column{name: name, index: field.Index})
+	}
+	return columns
+}
+
+// cell formats field value of tabular formats, nil pointers are empty and text is escaped against
+// formula injection
+func cell(v reflect.Value) (string, bool) {
+	text, numeric := value(v)
+	if !numeric {
+		text = escapeFormula(text)
+	}
+	return text, numeric
+}
+
+// escapeFormula prefixes text spreadsheets would evaluate as formula with a quote
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// value formats field value as text, reporting whether it is numeric
+func value(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return "", false
+		}
+		return t.Format(time.RFC3339), false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface()), true
+	case reflect.String:
+		return v.String(), false
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		raw, _ := json.Marshal(v.Interface())
+		return string(raw), false
+	}
+	return fmt.Sprint(v.Interface()), false
+}
+
+type csvEncoder struct {
+	w       *csv.Writer
+	columns []column
+	record  []string
+}
+
+func newCSVEncoder(w io.Writer, columns []column) (*csvEncoder, error) {
+	e := &csvEncoder{w: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+	for i, col := range columns {
+		e.record[i] = col.name
+	}
+	return e, e.w.Write(e.record)
+}
+
+func (e *csvEncoder) Write(v reflect.Value) error {
+	for i, col := range e.columns {
+		e.record[i], _ = cell(v.FieldByIndex(col.index))
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlEncoder struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *jsonlEncoder) Write(v reflect.Value) error {
+	return e.enc.Encode(v.Interface())
+}
+
+func (e *jsonlEncoder) Close() error {
+	return e.buf.Flush()
+}
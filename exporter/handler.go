@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/response"
+)
+
+type startRequest struct {
+	Format Format            `json:"format"`
+	Params map[string]string `json:"params"`
+}
+
+// RegisterRoutes mounts export endpoints on router
+//
+//	POST /exports/:name        {"format": "csv", "params": {...}}
+//	GET  /exports/:id
+//	GET  /exports/:id/download
+func RegisterRoutes(router fiber.Router) {
+	router.Post("/exports/:name", startHandler)
+	router.Get("/exports/:id", statusHandler)
+	router.Get("/exports/:id/download", downloadHandler)
+}
+
+func owner(c *fiber.Ctx) string {
+	if config.OwnerFunc == nil {
+		return ""
+	}
+	return config.OwnerFunc(c)
+}
+
+func startHandler(c *fiber.Ctx) error {
+	var req startRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, err)
+		}
+	}
+	if req.Format == "" {
+		req.Format = CSV
+	}
+
+	p, err := Start(c.UserContext(), c.Params("name"), req.Format, req.Params, owner(c))
+	switch {
+	case errors.Is(err, ErrUnknownExport):
+		return response.Error(c, fiber.StatusNotFound, err)
+	case errors.Is(err, ErrFormat):
+		return response.Error(c, fiber.StatusBadRequest, err)
+	case err != nil:
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.JSON(c, fiber.StatusAccepted, response.Envelope{
+		Status:  response.GetConfig().SuccessStatus,
+		Message: response.GetConfig().SuccessMessage,
+		Data:    p,
+	})
+}
+
+// progress returns export of :id, hiding exports of other owners
+func progress(c *fiber.Ctx) (*Progress, error) {
+	p, err := Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	if p.Owner != owner(c) {
+		return nil, ErrNotFound
+	}
+	return p, nil
+}
+
+func statusHandler(c *fiber.Ctx) error {
+	p, err := progress(c)
+	if errors.Is(err, ErrNotFound) {
+		return response.Error(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, p, nil)
+}
+
+func downloadHandler(c *fiber.Ctx) error {
+	p, err := progress(c)
+	if errors.Is(err, ErrNotFound) {
+		return response.Error(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	if p.Status != StatusDone {
+		return response.Error(c, fiber.StatusConflict, errors.New("export is "+string(p.Status)))
+	}
+	return c.Redirect(p.URL, fiber.StatusFound)
+}
@@ -0,0 +1,273 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/database/repository"
+	"github.com/rikiihsan/nest/queue"
+	"github.com/rikiihsan/nest/storage"
+	"github.com/uptrace/bun"
+)
+
+// JobType of export jobs processed by queue workers
+const JobType = "exporter:export"
+
+var (
+	ErrNoRedis       = errors.New("exporter : redis client is not initialized")
+	ErrNotFound      = errors.New("exporter : export not found")
+	ErrUnknownExport = errors.New("exporter : export is not registered")
+	ErrFormat        = errors.New("exporter : unsupported format")
+)
+
+// Source narrows the select query of an export using request params
+type Source func(ctx context.Context, q *bun.SelectQuery, params map[string]string) (*bun.SelectQuery, error)
+
+// export streams rows of a registered model into an encoder
+type export interface {
+	run(ctx context.Context, p *Progress, w io.Writer, tick func(rows int64)) error
+}
+
+var (
+	mu       sync.RWMutex
+	exports  = make(map[string]export)
+	register sync.Once
+)
+
+func client() (*redis.Client, error) {
+	if database.RedisClient == nil {
+		return nil, ErrNoRedis
+	}
+	return database.RedisClient, nil
+}
+
+// Register registers export of T by name, source may be nil to export all rows. The first call
+// registers the JobType queue handler
+func Register[T any](name string, repo *repository.Repository[T], source Source) {
+	register.Do(func() {
+		queue.Register(JobType, handle)
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	exports[name] = &modelExport[T]{repo: repo, source: source}
+}
+
+func lookup(name string) (export, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := exports[name]
+	return e, ok
+}
+
+type modelExport[T any] struct {
+	repo   *repository.Repository[T]
+	source Source
+}
+
+func (e *modelExport[T]) run(ctx context.Context, p *Progress, w io.Writer, tick func(rows int64)) error {
+	q, err := e.repo.Select(ctx, (*T)(nil))
+	if err != nil {
+		return err
+	}
+	if e.source != nil {
+		if q, err = e.source(ctx, q, p.Params); err != nil {
+			return err
+		}
+	}
+
+	total, err := q.Count(ctx)
+	if err != nil {
+		return err
+	}
+	p.Total = int64(total)
+
+	enc, err := newEncoder(p.Format, w, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.Rows(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var n int64
+	for rows.Next() {
+		var entity T
+		if err := q.DB().ScanRow(ctx, rows, &entity); err != nil {
+			return err
+		}
+		if err := enc.Write(reflect.ValueOf(entity)); err != nil {
+			return err
+		}
+		if n++; n%int64(config.ChunkSize) == 0 {
+			tick(n)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	p.Rows = n
+	return enc.Close()
+}
+
+// Key returns redis key of export progress
+func Key(id string) string {
+	return Prefix + id
+}
+
+// Start enqueues export of name in format with params, owner restricts who may read its status
+func Start(ctx context.Context, name string, format Format, params map[string]string, owner string) (*Progress, error) {
+	if _, ok := lookup(name); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExport, name)
+	}
+	if _, ok := contentTypes[format]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFormat, format)
+	}
+
+	now := time.Now()
+	p := &Progress{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Format:    format,
+		Params:    params,
+		Owner:     owner,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := save(ctx, p); err != nil {
+		return nil, err
+	}
+	if _, err := queue.Enqueue(ctx, JobType, jobPayload{ID: p.ID}, queue.OnQueue(config.Queue), queue.WithID(p.ID)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Get returns progress of export, with signed download URL once done
+func Get(ctx context.Context, id string) (*Progress, error) {
+	p, err := load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status == StatusDone {
+		disk, err := storage.Disk(config.Disk)
+		if err != nil {
+			return nil, err
+		}
+		if p.URL, err = disk.SignedURL(ctx, p.Key, config.URLExpiry); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// stored keeps Owner which is hidden from JSON responses
+type stored struct {
+	*Progress
+	Owner string `json:"owner,omitempty"`
+}
+
+type jobPayload struct {
+	ID string `json:"id"`
+}
+
+func save(ctx context.Context, p *Progress) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	p.UpdatedAt = time.Now()
+	raw, err := json.Marshal(stored{Progress: p, Owner: p.Owner})
+	if err != nil {
+		return err
+	}
+	return rdb.Set(ctx, Key(p.ID), raw, config.Retention).Err()
+}
+
+func load(ctx context.Context, id string) (*Progress, error) {
+	rdb, err := client()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rdb.Get(ctx, Key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := stored{Progress: &Progress{}}
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	s.Progress.Owner = s.Owner
+	return s.Progress, nil
+}
+
+// handle runs export job, streaming rows through a pipe into storage
+func handle(ctx context.Context, job *queue.Job) error {
+	var payload jobPayload
+	if err := job.Bind(&payload); err != nil {
+		return err
+	}
+	p, err := load(ctx, payload.ID)
+	if err != nil {
+		return err
+	}
+	e, ok := lookup(p.Name)
+	if !ok {
+		return fail(ctx, p, fmt.Errorf("%w: %s", ErrUnknownExport, p.Name))
+	}
+	disk, err := storage.Disk(config.Disk)
+	if err != nil {
+		return fail(ctx, p, err)
+	}
+
+	p.Status, p.Rows, p.Error = StatusRunning, 0, ""
+	p.Key = "exports/" + p.ID + "." + string(p.Format)
+	if err := save(ctx, p); err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := disk.Put(ctx, p.Key, pr, storage.WithContentType(contentTypes[p.Format]))
+		pr.CloseWithError(err)
+		done <- err
+	}()
+
+	err = e.run(ctx, p, pw, func(rows int64) {
+		p.Rows = rows
+		save(ctx, p)
+	})
+	pw.CloseWithError(err)
+	if putErr := <-done; err == nil {
+		err = putErr
+	}
+	if err != nil {
+		return fail(ctx, p, err)
+	}
+
+	p.Status = StatusDone
+	return save(ctx, p)
+}
+
+// fail records error on progress and returns it so the queue retries the job
+func fail(ctx context.Context, p *Progress, err error) error {
+	p.Status, p.Error = StatusFailed, err.Error()
+	save(ctx, p)
+	return err
+}
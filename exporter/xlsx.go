@@ -0,0 +1,111 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetFooter = `</sheetData></worksheet>`
+)
+
+// xlsxEncoder streams a single sheet workbook with inline strings, the sheet is the last zip entry
+type xlsxEncoder struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []column
+	row     int
+}
+
+func newXLSXEncoder(w io.Writer, columns []column) (*xlsxEncoder, error) {
+	z := zip.NewWriter(w)
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	e := &xlsxEncoder{zip: z, sheet: bufio.NewWriter(f), columns: columns}
+	e.sheet.WriteString(xlsxSheetHeader)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.name
+	}
+	e.writeRow(len(header), func(i int) (string, bool) { return header[i], false })
+	return e, nil
+}
+
+func (e *xlsxEncoder) Write(v reflect.Value) error {
+	e.writeRow(len(e.columns), func(i int) (string, bool) {
+		return cell(v.FieldByIndex(e.columns[i].index))
+	})
+	return nil
+}
+
+// writeRow writes n cells, numbers as values and everything else as inline strings
+func (e *xlsxEncoder) writeRow(n int, value func(i int) (string, bool)) {
+	e.row++
+	row := strconv.Itoa(e.row)
+	e.sheet.WriteString(`<row r="` + row + `">`)
+	for i := 0; i < n; i++ {
+		text, numeric := value(i)
+		if text == "" {
+			continue
+		}
+		ref := columnName(i) + row
+		if numeric {
+			e.sheet.WriteString(`<c r="` + ref + `"><v>` + text + `</v></c>`)
+			continue
+		}
+		e.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(e.sheet, []byte(strings.ToValidUTF8(text, "")))
+		e.sheet.WriteString(`</t></is></c>`)
+	}
+	e.sheet.WriteString(`</row>`)
+}
+
+func (e *xlsxEncoder) Close() error {
+	e.sheet.WriteString(xlsxSheetFooter)
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.zip.Close()
+}
+
+// columnName returns spreadsheet letters of 0-based column index
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
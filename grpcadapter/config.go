@@ -0,0 +1,41 @@
+package grpcadapter
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Config represents gRPC adapter configuration
+type Config struct {
+	// Metadata keys propagated into requestctx
+	RequestIDKey string
+	LocaleKey    string
+	// Tenant returns tenant of call stored in requestctx.Tenant, nil leaves it unset. Derive it from
+	// the authenticated identity, client metadata is only trustworthy behind TenantFromMetadata gateways
+	Tenant func(ctx context.Context, md metadata.MD) (string, error)
+	// Session used by Transactional methods
+	Session string
+	// Transactional reports whether method runs in a transaction of Session, nil disables transactions
+	Transactional func(fullMethod string) bool
+	// Sticky routes reads after writes of a call to the primary, see database.WithStickiness
+	Sticky bool
+	// Source tag naming fields of validation errors
+	Source string
+}
+
+// DefaultConfig is used for empty fields passed to Interceptors
+var DefaultConfig = Config{
+	RequestIDKey: "x-request-id",
+	LocaleKey:    "accept-language",
+	Session:      "default",
+	Source:       "json",
+}
+
+// TenantFromMetadata returns Config.Tenant reading tenant from metadata key, only for calls of trusted
+// callers such as an authenticating gateway since clients may send any tenant
+func TenantFromMetadata(key string) func(ctx context.Context, md metadata.MD) (string, error) {
+	return func(ctx context.Context, md metadata.MD) (string, error) {
+		return first(md, key), nil
+	}
+}
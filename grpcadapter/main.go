@@ -0,0 +1,209 @@
+package grpcadapter
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/i18n"
+	"github.com/rikiihsan/nest/requestctx"
+	"github.com/rikiihsan/nest/requestid"
+	"github.com/rikiihsan/nest/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	mu       sync.RWMutex
+	mappings = make(map[reflect.Type]func(interface{}) interface{})
+)
+
+// Map registers struct mapping of request messages of P, the mapped struct is validated by its validate tags
+func Map[P any, S any](fn func(P) S) {
+	mu.Lock()
+	defer mu.Unlock()
+	mappings[reflect.TypeOf((*P)(nil)).Elem()] = func(msg interface{}) interface{} {
+		mapped := fn(msg.(P))
+		return &mapped
+	}
+}
+
+func mappingFor(msg interface{}) (func(interface{}) interface{}, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	fn, ok := mappings[reflect.TypeOf(msg)]
+	return fn, ok
+}
+
+// Interceptors returns unary interceptors propagating context, validating requests, running
+// transactional methods in a transaction and mapping errors to statuses, in that order. Chain
+// authentication before them so Config.Tenant sees the caller identity
+//
+//	grpc.NewServer(grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{auth}, grpcadapter.Interceptors(cfg)...)...))
+func Interceptors(cfg Config) []grpc.UnaryServerInterceptor {
+	cfg = withDefaults(cfg)
+	return []grpc.UnaryServerInterceptor{
+		ErrorInterceptor(),
+		ContextInterceptor(cfg),
+		ValidationInterceptor(cfg),
+		TxInterceptor(cfg),
+	}
+}
+
+func withDefaults(cfg Config) Config {
+	if cfg.RequestIDKey == "" {
+		cfg.RequestIDKey = DefaultConfig.RequestIDKey
+	}
+	if cfg.LocaleKey == "" {
+		cfg.LocaleKey = DefaultConfig.LocaleKey
+	}
+	if cfg.Session == "" {
+		cfg.Session = DefaultConfig.Session
+	}
+	if cfg.Source == "" {
+		cfg.Source = DefaultConfig.Source
+	}
+	return cfg
+}
+
+// ContextInterceptor stores request ID and locale of incoming metadata and tenant of Config.Tenant in
+// requestctx, generating request IDs and echoing them in response headers
+func ContextInterceptor(cfg Config) grpc.UnaryServerInterceptor {
+	cfg = withDefaults(cfg)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		id := first(md, cfg.RequestIDKey)
+		if !requestid.Valid(id) {
+			id = uuid.NewString()
+		}
+		ctx = requestctx.RequestID.With(ctx, id)
+		grpc.SetHeader(ctx, metadata.Pairs(cfg.RequestIDKey, id))
+
+		if cfg.Tenant != nil {
+			tenant, err := cfg.Tenant(ctx, md)
+			if err != nil {
+				return nil, err
+			}
+			if tenant != "" {
+				ctx = requestctx.Tenant.With(ctx, tenant)
+			}
+		}
+		if tag := first(md, cfg.LocaleKey); tag != "" {
+			tag, _, _ = strings.Cut(strings.Split(tag, ",")[0], ";")
+			if locale, ok := i18n.GetBundle().Match(strings.TrimSpace(tag)); ok {
+				ctx = requestctx.Locale.With(ctx, locale)
+			}
+		}
+		if cfg.Sticky {
+			ctx = database.WithStickiness(ctx)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationInterceptor validates request messages, returning validator.Errors mapped to InvalidArgument
+// by ErrorInterceptor. Messages are validated by the first of:
+//   - ValidateAll() or Validate() error generated by protoc-gen-validate
+//   - Validate() []validator.ValidatorError generated by `nest gen validators`
+//   - struct registered with Map
+//   - validate tags of the message itself, e.g. injected by protoc-go-inject-tag
+func ValidationInterceptor(cfg Config) grpc.UnaryServerInterceptor {
+	cfg = withDefaults(cfg)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if errs := Validate(ctx, req, cfg.Source); len(errs) > 0 {
+			return nil, validator.Errors(errs)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Validate validates message as ValidationInterceptor does
+func Validate(ctx context.Context, msg interface{}, source string) []validator.ValidatorError {
+	switch m := msg.(type) {
+	case interface{ ValidateAll() error }:
+		return fromPGV(m.ValidateAll(), "")
+	case interface{ Validate() error }:
+		return fromPGV(m.Validate(), "")
+	case interface {
+		Validate() []validator.ValidatorError
+	}:
+		return m.Validate()
+	}
+
+	if fn, ok := mappingFor(msg); ok {
+		return validator.ValidateCtx(ctx, fn(msg), source)
+	}
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Struct {
+		return validator.ValidateCtx(ctx, msg, source)
+	}
+	return nil
+}
+
+// pgvError is field error generated by protoc-gen-validate
+type pgvError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// fromPGV converts protoc-gen-validate errors, following causes of embedded messages into dotted paths
+func fromPGV(err error, prefix string) []validator.ValidatorError {
+	if err == nil {
+		return nil
+	}
+	if multi, ok := err.(interface{ AllErrors() []error }); ok {
+		var errs []validator.ValidatorError
+		for _, e := range multi.AllErrors() {
+			errs = append(errs, fromPGV(e, prefix)...)
+		}
+		return errs
+	}
+
+	fieldErr, ok := err.(pgvError)
+	if !ok {
+		return []validator.ValidatorError{{FailedField: strings.TrimSuffix(prefix, "."), Tag: "pgv", Message: err.Error()}}
+	}
+	if cause := fieldErr.Cause(); cause != nil {
+		if _, nested := cause.(pgvError); nested {
+			return fromPGV(cause, prefix+fieldErr.Field()+".")
+		}
+		if _, nested := cause.(interface{ AllErrors() []error }); nested {
+			return fromPGV(cause, prefix+fieldErr.Field()+".")
+		}
+	}
+	return []validator.ValidatorError{{
+		FailedField: prefix + fieldErr.Field(),
+		Tag:         "pgv",
+		Message:     fieldErr.Reason(),
+	}}
+}
+
+// TxInterceptor runs methods reported by Config.Transactional in a transaction of Config.Session,
+// repositories called by the handler join it through the context
+func TxInterceptor(cfg Config) grpc.UnaryServerInterceptor {
+	cfg = withDefaults(cfg)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if cfg.Transactional == nil || !cfg.Transactional(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		var resp interface{}
+		err := database.RunInTx(ctx, cfg.Session, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package grpcadapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/rikiihsan/nest/apperror"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/logger"
+	"github.com/rikiihsan/nest/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+var codeByKind = map[database.ErrorKind]codes.Code{
+	database.KindNotFound:            codes.NotFound,
+	database.KindUniqueViolation:     codes.AlreadyExists,
+	database.KindForeignKeyViolation: codes.FailedPrecondition,
+	database.KindNotNullViolation:    codes.InvalidArgument,
+	database.KindCheckViolation:      codes.InvalidArgument,
+	database.KindTimeout:             codes.DeadlineExceeded,
+	database.KindConnection:          codes.Unavailable,
+}
+
+// ErrorInterceptor converts handler errors into gRPC statuses with Status, logging internal errors
+func ErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		st := Status(err)
+		if st.Code() == codes.Internal || st.Code() == codes.Unknown {
+			logger.Get().ErrorContext(ctx, "grpc request failed", "method", info.FullMethod, "error", err)
+		}
+		return resp, st.Err()
+	}
+}
+
+// Status maps err to gRPC status. Validation errors carry BadRequest field violations,
+// application errors ErrorInfo with their code, other errors only expose their class
+func Status(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	var validationErrs validator.Errors
+	if errors.As(err, &validationErrs) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(validationErrs))
+		for i, e := range validationErrs {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: e.FailedField, Description: e.Message}
+		}
+		return withDetails(status.New(codes.InvalidArgument, validationErrs.Error()), &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	if appErr, ok := apperror.As(err); ok {
+		return withDetails(status.New(CodeOf(appErr.HTTPStatus), appErr.Message), &errdetails.ErrorInfo{Reason: appErr.Code})
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	}
+
+	if code, ok := codeByKind[database.Classify(err)]; ok {
+		return status.New(code, code.String())
+	}
+	return status.New(codes.Internal, "internal server error")
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	for _, d := range details {
+		if next, err := st.WithDetails(d); err == nil {
+			st = next
+		}
+	}
+	return st
+}
+
+// CodeOf returns gRPC code matching HTTP status
+func CodeOf(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if httpStatus >= 400 && httpStatus < 500 {
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
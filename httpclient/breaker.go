@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when host circuit is open
+var ErrCircuitOpen = errors.New("httpclient : circuit breaker is open")
+
+// BreakerState represents circuit state
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+type breaker struct {
+	config   BreakerConfig
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// allow reports whether request may proceed
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.config.OpenTimeout {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.trial = true
+		return true
+	case StateHalfOpen:
+		// Single trial request at a time
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if success {
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// breakers holds breaker per host
+type breakers struct {
+	config BreakerConfig
+	mu     sync.Mutex
+	hosts  map[string]*breaker
+}
+
+func (bs *breakers) get(host string) *breaker {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	b, ok := bs.hosts[host]
+	if !ok {
+		b = &breaker{config: bs.config}
+		bs.hosts[host] = b
+	}
+	return b
+}
+
+// State returns breaker state of host
+func (bs *breakers) State(host string) BreakerState {
+	b := bs.get(host)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
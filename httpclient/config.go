@@ -0,0 +1,106 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// Config represents outbound HTTP client configuration
+type Config struct {
+	// Timeout applies to each attempt unless overridden per host
+	Timeout      time.Duration
+	HostTimeouts map[string]time.Duration
+
+	// MaxRetries for idempotent requests on network errors and RetryStatuses
+	MaxRetries    int
+	RetryStatuses []int
+	Backoff       func(attempt int) time.Duration
+
+	// Breaker enables per-host circuit breaker when FailureThreshold > 0
+	Breaker BreakerConfig
+
+	// OnRequest is called before every attempt
+	OnRequest func(req *http.Request)
+	// OnResponse is called after every attempt
+	OnResponse func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+
+	// DisablePropagation stops injecting trace context and request ID headers
+	DisablePropagation bool
+
+	Transport http.RoundTripper
+}
+
+// BreakerConfig represents circuit breaker configuration
+type BreakerConfig struct {
+	// FailureThreshold is consecutive failures that open the circuit, i.e. transport errors,
+	// RetryStatuses and 5xx responses
+	FailureThreshold int
+	// OpenTimeout is how long circuit stays open before a trial request
+	OpenTimeout time.Duration
+}
+
+// Builder builds configured *http.Client
+type Builder struct {
+	config Config
+}
+
+// NewBuilder creates builder with sane defaults
+func NewBuilder() *Builder {
+	return &Builder{config: Config{
+		Timeout:       10 * time.Second,
+		RetryStatuses: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}}
+}
+
+// Timeout sets default per-attempt timeout
+func (b *Builder) Timeout(d time.Duration) *Builder {
+	b.config.Timeout = d
+	return b
+}
+
+// HostTimeout overrides timeout for host (host or host:port)
+func (b *Builder) HostTimeout(host string, d time.Duration) *Builder {
+	if b.config.HostTimeouts == nil {
+		b.config.HostTimeouts = make(map[string]time.Duration)
+	}
+	b.config.HostTimeouts[host] = d
+	return b
+}
+
+// Retry enables retries with optional backoff function
+func (b *Builder) Retry(maxRetries int, backoff ...func(attempt int) time.Duration) *Builder {
+	b.config.MaxRetries = maxRetries
+	if len(backoff) > 0 {
+		b.config.Backoff = backoff[0]
+	}
+	return b
+}
+
+// CircuitBreaker enables per-host circuit breaker
+func (b *Builder) CircuitBreaker(failureThreshold int, openTimeout time.Duration) *Builder {
+	b.config.Breaker = BreakerConfig{FailureThreshold: failureThreshold, OpenTimeout: openTimeout}
+	return b
+}
+
+// OnRequest sets request hook
+func (b *Builder) OnRequest(fn func(req *http.Request)) *Builder {
+	b.config.OnRequest = fn
+	return b
+}
+
+// OnResponse sets response hook
+func (b *Builder) OnResponse(fn func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)) *Builder {
+	b.config.OnResponse = fn
+	return b
+}
+
+// Transport sets base transport
+func (b *Builder) Transport(rt http.RoundTripper) *Builder {
+	b.config.Transport = rt
+	return b
+}
+
+// Build creates client
+func (b *Builder) Build() *http.Client {
+	return New(b.config)
+}
@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rikiihsan/nest/requestid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// transport applies timeouts, retries, circuit breaking and hooks
+type transport struct {
+	config   Config
+	base     http.RoundTripper
+	breakers *breakers
+}
+
+// New creates *http.Client from config
+func New(cfg Config) *http.Client {
+	if cfg.Backoff == nil {
+		cfg.Backoff = defaultBackoff
+	}
+	if cfg.Breaker.FailureThreshold > 0 && cfg.Breaker.OpenTimeout <= 0 {
+		cfg.Breaker.OpenTimeout = 30 * time.Second
+	}
+
+	base := cfg.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &http.Client{Transport: &transport{
+		config:   cfg,
+		base:     base,
+		breakers: &breakers{config: cfg.Breaker, hosts: make(map[string]*breaker)},
+	}}
+}
+
+// defaultBackoff is exponential backoff with jitter capped at 10s
+func defaultBackoff(attempt int) time.Duration {
+	d := 100 * time.Millisecond << uint(attempt-1)
+	if d > 10*time.Second {
+		d = 10 * time.Second
+	}
+	return d/2 + time.Duration(rand.Int64N(int64(d/2)+1))
+}
+
+// Idempotent reports whether method is safe to retry
+func Idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (t *transport) timeout(host string) time.Duration {
+	if d, ok := t.config.HostTimeouts[host]; ok {
+		return d
+	}
+	return t.config.Timeout
+}
+
+func (t *transport) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	for _, status := range t.config.RetryStatuses {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// RoundTrip executes request with configured resilience policies
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.config.DisablePropagation {
+		req = req.Clone(req.Context())
+		otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+		if id := requestid.FromContext(req.Context()); id != "" && req.Header.Get("X-Request-ID") == "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+	}
+
+	maxRetries := 0
+	if Idempotent(req.Method) && (req.Body == nil || req.GetBody != nil) {
+		maxRetries = t.config.MaxRetries
+	}
+
+	var br *breaker
+	if t.config.Breaker.FailureThreshold > 0 {
+		br = t.breakers.get(req.URL.Host)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if br != nil && !br.allow() {
+			return nil, ErrCircuitOpen
+		}
+
+		resp, err := t.attempt(req, attempt)
+		retry := t.retryable(resp, err)
+		if br != nil {
+			br.record(!retry && resp.StatusCode < http.StatusInternalServerError)
+		}
+
+		if !retry || attempt >= maxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		wait := t.config.Backoff(attempt + 1)
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				wait = after
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// attempt performs single try with per-host timeout
+func (t *transport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx := req.Context()
+	cancel := context.CancelFunc(func() {})
+	if d := t.timeout(req.URL.Host); d > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d)
+	}
+
+	try := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		try.Body = body
+	}
+
+	if t.config.OnRequest != nil {
+		t.config.OnRequest(try)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(try)
+	if t.config.OnResponse != nil {
+		t.config.OnResponse(try, resp, err, time.Since(start))
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Timeout context lives until body is closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryAfter parses Retry-After header in seconds or HTTP date
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
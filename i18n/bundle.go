@@ -0,0 +1,235 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// pluralKeys are object keys that mark a plural message instead of nested keys
+var pluralKeys = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true}
+
+// NewBundle creates empty bundle with fallback locale
+func NewBundle(fallback string) *Bundle {
+	return &Bundle{
+		fallback: fallback,
+		messages: map[string]map[string]Message{},
+	}
+}
+
+// Add adds message of key to locale
+func (b *Bundle) Add(locale, key string, msg Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	locale = normalize(locale)
+	if b.messages[locale] == nil {
+		b.messages[locale] = map[string]Message{}
+	}
+	b.messages[locale][key] = msg
+}
+
+// AddMessages adds singular messages to locale
+func (b *Bundle) AddMessages(locale string, messages map[string]string) {
+	for key, text := range messages {
+		b.Add(locale, key, Message{Other: text})
+	}
+}
+
+// Load reads every .json and .toml file in dir, locale is taken from the file name
+func (b *Bundle) Load(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read i18n dir %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".toml") {
+			continue
+		}
+
+		file := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		raw := map[string]interface{}{}
+		if ext == ".json" {
+			err = json.Unmarshal(data, &raw)
+		} else {
+			err = toml.Unmarshal(data, &raw)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		// messages.id.json and id.json both load into "id"
+		name := strings.TrimSuffix(entry.Name(), ext)
+		locale := name[strings.LastIndex(name, ".")+1:]
+		if err := b.flatten(locale, "", raw); err != nil {
+			return fmt.Errorf("failed to load %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// flatten adds nested objects as dotted keys
+func (b *Bundle) flatten(locale, prefix string, raw map[string]interface{}) error {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			b.Add(locale, key, Message{Other: v})
+		case map[string]interface{}:
+			if msg, ok := pluralMessage(v); ok {
+				b.Add(locale, key, msg)
+				continue
+			}
+			if err := b.flatten(locale, key, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported value of key %s", key)
+		}
+	}
+	return nil
+}
+
+// pluralMessage converts object with only plural keys to Message
+func pluralMessage(raw map[string]interface{}) (Message, bool) {
+	forms := map[string]string{}
+	for key, value := range raw {
+		text, ok := value.(string)
+		if !pluralKeys[key] || !ok {
+			return Message{}, false
+		}
+		forms[key] = text
+	}
+	return Message{
+		Zero:  forms["zero"],
+		One:   forms["one"],
+		Two:   forms["two"],
+		Few:   forms["few"],
+		Many:  forms["many"],
+		Other: forms["other"],
+	}, len(forms) > 0
+}
+
+// Locales returns sorted loaded locales
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Match returns best loaded locale for tag, trying base language then fallback
+func (b *Bundle) Match(tag string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	tag = normalize(tag)
+	if _, ok := b.messages[tag]; ok {
+		return tag, true
+	}
+	if i := strings.Index(tag, "-"); i > 0 {
+		if _, ok := b.messages[tag[:i]]; ok {
+			return tag[:i], true
+		}
+	}
+	return b.fallback, false
+}
+
+// lookup finds message in locale, its base language, then fallback locale
+func (b *Bundle) lookup(locale, key string) (Message, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	locale = normalize(locale)
+	candidates := []string{locale}
+	if i := strings.Index(locale, "-"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+	candidates = append(candidates, normalize(b.fallback))
+
+	for _, candidate := range candidates {
+		if msg, ok := b.messages[candidate][key]; ok {
+			return msg, true
+		}
+	}
+	return Message{}, false
+}
+
+// Has reports whether key is translated for locale or fallback
+func (b *Bundle) Has(locale, key string) bool {
+	_, ok := b.lookup(locale, key)
+	return ok
+}
+
+// T translates key, missing keys are returned as is
+func (b *Bundle) T(locale, key string, params ...Params) string {
+	msg, ok := b.lookup(locale, key)
+	if !ok {
+		return key
+	}
+	return interpolate(msg.Other, merge(params))
+}
+
+// Plural translates key using plural form of count, exposed to message as {count}
+func (b *Bundle) Plural(locale, key string, count int, params ...Params) string {
+	msg, ok := b.lookup(locale, key)
+	if !ok {
+		return key
+	}
+
+	values := merge(params)
+	if _, ok := values["count"]; !ok {
+		values["count"] = count
+	}
+	return interpolate(msg.form(pluralCategory(locale, count)), values)
+}
+
+// merge combines params into one map
+func merge(params []Params) Params {
+	values := Params{}
+	for _, p := range params {
+		for k, v := range p {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// interpolate replaces {name} placeholders with params
+func interpolate(text string, params Params) string {
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	pairs := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// normalize lowercases locale and uses dash separator
+func normalize(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"io/fs"
+	"sync"
+
+	"github.com/rikiihsan/nest/requestctx"
+)
+
+// Message holds plural forms of a translation, Other is used for singular messages
+type Message struct {
+	Zero  string `json:"zero" toml:"zero"`
+	One   string `json:"one" toml:"one"`
+	Two   string `json:"two" toml:"two"`
+	Few   string `json:"few" toml:"few"`
+	Many  string `json:"many" toml:"many"`
+	Other string `json:"other" toml:"other"`
+}
+
+// form returns message for plural category, falling back to Other
+func (m Message) form(category string) string {
+	var text string
+	switch category {
+	case "zero":
+		text = m.Zero
+	case "one":
+		text = m.One
+	case "two":
+		text = m.Two
+	case "few":
+		text = m.Few
+	case "many":
+		text = m.Many
+	}
+	if text == "" {
+		return m.Other
+	}
+	return text
+}
+
+// Params holds values interpolated into {name} placeholders
+type Params map[string]interface{}
+
+// PluralRule returns plural category (zero, one, two, few, many, other) of count
+type PluralRule func(count int) string
+
+// Bundle holds messages keyed by locale then dotted key
+type Bundle struct {
+	mu       sync.RWMutex
+	fallback string
+	messages map[string]map[string]Message
+}
+
+// Config represents i18n configuration
+type Config struct {
+	// Files holds bundles named <locale>.json or <domain>.<locale>.toml
+	Files fs.FS
+	// Dir inside Files to load from
+	Dir string
+	// DefaultLocale is used when no requested locale matches
+	DefaultLocale string
+	// QueryParam and Cookie override Accept-Language in middleware
+	QueryParam string
+	Cookie     string
+	// Local is the fiber locals key holding detected locale
+	Local string
+	// RedisPrefix prefixes Redis hashes and reload channel of published bundles, see LoadRedis
+	RedisPrefix string
+}
+
+// DefaultConfig is used for empty fields passed to Init
+var DefaultConfig = Config{
+	Dir:           ".",
+	DefaultLocale: "en",
+	QueryParam:    "lang",
+	Cookie:        "lang",
+	Local:         requestctx.Locale.Name(),
+	RedisPrefix:   "nest:i18n",
+}
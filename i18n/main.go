@@ -0,0 +1,118 @@
+package i18n
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/rikiihsan/nest/requestctx"
+	"github.com/rikiihsan/nest/validator"
+)
+
+var (
+	config = DefaultConfig
+	bundle = NewBundle(DefaultConfig.DefaultLocale)
+)
+
+// Init sets global configuration and loads bundles from cfg.Files
+func Init(cfg Config) error {
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultConfig.Dir
+	}
+	if cfg.DefaultLocale == "" {
+		cfg.DefaultLocale = DefaultConfig.DefaultLocale
+	}
+	if cfg.QueryParam == "" {
+		cfg.QueryParam = DefaultConfig.QueryParam
+	}
+	if cfg.Cookie == "" {
+		cfg.Cookie = DefaultConfig.Cookie
+	}
+	if cfg.Local == "" {
+		cfg.Local = DefaultConfig.Local
+	}
+	if cfg.RedisPrefix == "" {
+		cfg.RedisPrefix = DefaultConfig.RedisPrefix
+	}
+
+	b := NewBundle(cfg.DefaultLocale)
+	if cfg.Files != nil {
+		if err := b.Load(cfg.Files, cfg.Dir); err != nil {
+			return err
+		}
+	}
+
+	config = cfg
+	bundle = b
+	return nil
+}
+
+// GetConfig returns the current i18n configuration
+func GetConfig() Config {
+	return config
+}
+
+// GetBundle returns the global bundle
+func GetBundle() *Bundle {
+	return bundle
+}
+
+// Add adds message of key to locale of the global bundle
+func Add(locale, key string, msg Message) {
+	bundle.Add(locale, key, msg)
+}
+
+// AddMessages adds singular messages to locale of the global bundle
+func AddMessages(locale string, messages map[string]string) {
+	bundle.AddMessages(locale, messages)
+}
+
+// T translates key for locale
+func T(locale, key string, params ...Params) string {
+	return bundle.T(locale, key, params...)
+}
+
+// Plural translates key for locale using plural form of count
+func Plural(locale, key string, count int, params ...Params) string {
+	return bundle.Plural(locale, key, count, params...)
+}
+
+// Has reports whether key is translated for locale or default locale
+func Has(locale, key string) bool {
+	return bundle.Has(locale, key)
+}
+
+// Locales returns loaded locales
+func Locales() []string {
+	return bundle.Locales()
+}
+
+// WithLocale returns context carrying locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return requestctx.Locale.With(ctx, locale)
+}
+
+// FromContext returns locale of context or default locale
+func FromContext(ctx context.Context) string {
+	if locale := requestctx.Locale.Value(ctx); locale != "" {
+		return locale
+	}
+	return config.DefaultLocale
+}
+
+// TranslateErrors replaces validation messages with "validation.<tag>" bundle messages.
+// Messages receive {field}, {param} and {index} params, untranslated tags keep their message
+func TranslateErrors(locale string, errs []validator.ValidatorError) []validator.ValidatorError {
+	translated := make([]validator.ValidatorError, len(errs))
+	for i, err := range errs {
+		key := "validation." + err.Tag
+		if bundle.Has(locale, key) {
+			params := Params{"field": err.FailedField, "param": err.Param}
+			if err.Index != nil {
+				params["index"] = strconv.Itoa(*err.Index)
+			}
+			err.Message = bundle.T(locale, key, params)
+		}
+		translated[i] = err
+	}
+	return translated
+}
@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// New returns middleware detecting locale from query, cookie then Accept-Language
+func New() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		locale := detect(c)
+		c.Locals(config.Local, locale)
+		c.SetUserContext(WithLocale(c.UserContext(), locale))
+		c.Set(fiber.HeaderContentLanguage, locale)
+		return c.Next()
+	}
+}
+
+// detect resolves the best loaded locale of request
+func detect(c *fiber.Ctx) string {
+	for _, tag := range []string{c.Query(config.QueryParam), c.Cookies(config.Cookie)} {
+		if tag == "" {
+			continue
+		}
+		if locale, ok := bundle.Match(tag); ok {
+			return locale
+		}
+	}
+
+	for _, tag := range acceptLanguages(c.Get(fiber.HeaderAcceptLanguage)) {
+		if locale, ok := bundle.Match(tag); ok {
+			return locale
+		}
+	}
+	return config.DefaultLocale
+}
+
+// acceptLanguages returns Accept-Language tags ordered by quality
+func acceptLanguages(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// Locale returns locale detected for request
+func Locale(c *fiber.Ctx) string {
+	if locale, ok := c.Locals(config.Local).(string); ok && locale != "" {
+		return locale
+	}
+	return config.DefaultLocale
+}
+
+// Translate translates key in request locale
+func Translate(c *fiber.Ctx, key string, params ...Params) string {
+	return bundle.T(Locale(c), key, params...)
+}
+
+// TranslatePlural translates key in request locale using plural form of count
+func TranslatePlural(c *fiber.Ctx, key string, count int, params ...Params) string {
+	return bundle.Plural(Locale(c), key, count, params...)
+}
+
+// ResponseMessage translates response messages that are bundle keys, for response.Config.Translate
+func ResponseMessage(c *fiber.Ctx, message string) string {
+	locale := Locale(c)
+	if !bundle.Has(locale, message) {
+		return message
+	}
+	return bundle.T(locale, message)
+}
+
+// ResponseErrors translates validation errors in request locale, for response.Config.TranslateErrors
+func ResponseErrors(c *fiber.Ctx, errs []validator.ValidatorError) []validator.ValidatorError {
+	return TranslateErrors(Locale(c), errs)
+}
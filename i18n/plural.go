@@ -0,0 +1,101 @@
+package i18n
+
+import "strings"
+
+var pluralRules = map[string]PluralRule{}
+
+// Initialize CLDR cardinal rules of common languages
+func init() {
+	for _, lang := range []string{"en", "de", "nl", "sv", "da", "no", "nb", "fi", "it", "es", "el", "hu", "tr", "bg", "et"} {
+		pluralRules[lang] = oneOther
+	}
+	for _, lang := range []string{"id", "ms", "ja", "ko", "zh", "th", "vi"} {
+		pluralRules[lang] = func(int) string { return "other" }
+	}
+	pluralRules["fr"] = func(n int) string {
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+	pluralRules["pt"] = pluralRules["fr"]
+	for _, lang := range []string{"ru", "uk", "be", "sr", "hr", "bs"} {
+		pluralRules[lang] = slavic
+	}
+	pluralRules["pl"] = func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	}
+	pluralRules["cs"] = func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		default:
+			return "other"
+		}
+	}
+	pluralRules["ar"] = func(n int) string {
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		default:
+			return "other"
+		}
+	}
+}
+
+func oneOther(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func slavic(n int) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return "one"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return "few"
+	default:
+		return "many"
+	}
+}
+
+// RegisterPluralRule sets plural rule of language, overriding built-in rule
+func RegisterPluralRule(lang string, rule PluralRule) {
+	pluralRules[strings.ToLower(lang)] = rule
+}
+
+// pluralCategory resolves category of count for locale, defaulting to one/other
+func pluralCategory(locale string, count int) string {
+	if count < 0 {
+		count = -count
+	}
+	lang := strings.ToLower(locale)
+	if rule, ok := pluralRules[lang]; ok {
+		return rule(count)
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		if rule, ok := pluralRules[lang[:i]]; ok {
+			return rule(count)
+		}
+	}
+	return oneOther(count)
+}
@@ -0,0 +1,137 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// ErrNoRedis is returned when publishing bundles without database.RedisClient
+var ErrNoRedis = errors.New("i18n : redis client not initialized")
+
+// Messages of a locale are stored in hash <prefix>:bundle:<locale> as JSON encoded Message per key,
+// validator templates in hash <prefix>:validator per tag
+func bundleKey(locale string) string {
+	return config.RedisPrefix + ":bundle:" + normalize(locale)
+}
+
+func localesKey() string {
+	return config.RedisPrefix + ":locales"
+}
+
+func validatorKey() string {
+	return config.RedisPrefix + ":validator"
+}
+
+func reloadChannel() string {
+	return config.RedisPrefix + ":reload"
+}
+
+// PublishMessages stores messages of locale in Redis and tells every instance to reload them
+func PublishMessages(ctx context.Context, locale string, messages map[string]Message) error {
+	if database.RedisClient == nil {
+		return ErrNoRedis
+	}
+
+	fields := make(map[string]interface{}, len(messages))
+	for key, msg := range messages {
+		raw, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		fields[key] = string(raw)
+	}
+
+	pipe := database.RedisClient.TxPipeline()
+	pipe.HSet(ctx, bundleKey(locale), fields)
+	pipe.SAdd(ctx, localesKey(), normalize(locale))
+	pipe.Publish(ctx, reloadChannel(), normalize(locale))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// PublishValidatorMessages stores validator message templates per tag in Redis, see validator.RegisterMessage,
+// and tells every instance to reload them
+func PublishValidatorMessages(ctx context.Context, templates map[string]string) error {
+	if database.RedisClient == nil {
+		return ErrNoRedis
+	}
+
+	pipe := database.RedisClient.TxPipeline()
+	pipe.HSet(ctx, validatorKey(), templates)
+	pipe.Publish(ctx, reloadChannel(), "validator")
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// LoadRedis adds published messages to the global bundle over file bundles and registers published
+// validator templates. Keys removed from Redis stay loaded until restart
+func LoadRedis(ctx context.Context) error {
+	if database.RedisClient == nil {
+		return ErrNoRedis
+	}
+
+	locales, err := database.RedisClient.SMembers(ctx, localesKey()).Result()
+	if err != nil {
+		return err
+	}
+	for _, locale := range locales {
+		fields, err := database.RedisClient.HGetAll(ctx, bundleKey(locale)).Result()
+		if err != nil {
+			return err
+		}
+		for key, raw := range fields {
+			var msg Message
+			if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+				return fmt.Errorf("invalid message %s of locale %s: %w", key, locale, err)
+			}
+			bundle.Add(locale, key, msg)
+		}
+	}
+
+	templates, err := database.RedisClient.HGetAll(ctx, validatorKey()).Result()
+	if err != nil {
+		return err
+	}
+	for tag, template := range templates {
+		if err := validator.RegisterMessage(tag, template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Listen loads published messages, then reloads them whenever any instance publishes, blocks until ctx is done
+func Listen(ctx context.Context) error {
+	if database.RedisClient == nil {
+		return nil
+	}
+
+	sub := database.RedisClient.Subscribe(ctx, reloadChannel())
+	defer sub.Close()
+	if err := LoadRedis(ctx); err != nil {
+		return err
+	}
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			if err := LoadRedis(ctx); err != nil {
+				slog.WarnContext(ctx, "failed to reload i18n messages", "source", message.Payload, "error", err)
+				continue
+			}
+			slog.DebugContext(ctx, "i18n messages reloaded", "source", message.Payload)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"time"
+
+	"github.com/rikiihsan/nest/validator"
+)
+
+// Format of imported file
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// Options represents import configuration
+type Options struct {
+	// Format is detected from file name when empty
+	Format Format
+	// Comma separates CSV fields
+	Comma rune
+	// Tag names header of struct fields, falling back to json tag then field name.
+	// Headers match case-insensitively, unknown columns are ignored
+	Tag string
+	// BatchSize rows are validated and inserted together
+	BatchSize int
+	// StopOnError aborts import at the first batch with invalid rows, nothing of it is inserted
+	StopOnError bool
+	// ReportDisk stores error report as CSV on the storage disk when set, see Result.ReportURL
+	ReportDisk   string
+	ReportExpiry time.Duration
+}
+
+// DefaultOptions are used for empty Options fields
+var DefaultOptions = Options{
+	Comma:        ',',
+	Tag:          "csv",
+	BatchSize:    500,
+	ReportExpiry: 24 * time.Hour,
+}
+
+// Result represents import outcome
+type Result struct {
+	Total    int `json:"total"`
+	Imported int `json:"imported"`
+	Failed   int `json:"failed"`
+	// Errors carry Row and Column of each invalid cell
+	Errors    []validator.ValidatorError `json:"errors,omitempty"`
+	ReportKey string                     `json:"report_key,omitempty"`
+	ReportURL string                     `json:"report_url,omitempty"`
+}
@@ -0,0 +1,364 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rikiihsan/nest/database/repository"
+	"github.com/rikiihsan/nest/storage"
+	"github.com/rikiihsan/nest/validator"
+)
+
+var ErrUnknownFormat = errors.New("importer : unknown file format")
+
+// rowReader returns rows with their 1-based row number
+type rowReader interface {
+	Read() ([]string, int, error)
+}
+
+type csvRows struct {
+	r *csv.Reader
+}
+
+func (c csvRows) Read() ([]string, int, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		return nil, 0, err
+	}
+	line, _ := c.r.FieldPos(0)
+	return record, line, nil
+}
+
+// column maps file column to struct field
+type column struct {
+	index  int
+	field  []int
+	path   string
+	header string
+}
+
+// withDefaults fills empty options from DefaultOptions
+func (o Options) withDefaults(filename string) Options {
+	if o.Format == "" {
+		o.Format = Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), "."))
+	}
+	if o.Comma == 0 {
+		o.Comma = DefaultOptions.Comma
+	}
+	if o.Tag == "" {
+		o.Tag = DefaultOptions.Tag
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultOptions.BatchSize
+	}
+	if o.ReportExpiry <= 0 {
+		o.ReportExpiry = DefaultOptions.ReportExpiry
+	}
+	return o
+}
+
+// FromUpload imports multipart file of form field into repo
+func FromUpload[T any](c *fiber.Ctx, field string, repo *repository.Repository[T], opts Options) (*Result, error) {
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Import(c.UserContext(), file, header.Size, header.Filename, repo, opts)
+}
+
+// Import parses CSV or XLSX file with header row into T, validates rows in batches and inserts valid
+// ones with Repository.BulkInsert. Invalid rows are reported with row and column, not inserted
+func Import[T any](ctx context.Context, r io.ReaderAt, size int64, filename string, repo *repository.Repository[T], opts Options) (*Result, error) {
+	opts = opts.withDefaults(filename)
+
+	var rows rowReader
+	switch opts.Format {
+	case CSV:
+		reader := csv.NewReader(io.NewSectionReader(r, 0, size))
+		reader.Comma = opts.Comma
+		reader.FieldsPerRecord = -1
+		reader.ReuseRecord = true
+		rows = csvRows{r: reader}
+	case XLSX:
+		x, err := newXLSXRows(r, size)
+		if err != nil {
+			return nil, err
+		}
+		defer x.Close()
+		rows = x
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, opts.Format)
+	}
+
+	header, _, err := rows.Read()
+	if errors.Is(err, io.EOF) {
+		return &Result{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := mapColumns[T](header, opts.Tag)
+
+	result := &Result{}
+	batch := make([]T, 0, opts.BatchSize)
+	lines := make([]int, 0, opts.BatchSize)
+	var conversion []validator.ValidatorError
+
+	flush := func() error {
+		defer func() {
+			batch, lines, conversion = batch[:0], lines[:0], nil
+		}()
+		return importBatch(ctx, repo, batch, lines, conversion, columns, opts, result)
+	}
+
+	for {
+		record, line, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		if blank(record) {
+			continue
+		}
+
+		var entity T
+		conversion = append(conversion, decodeRow(reflect.ValueOf(&entity).Elem(), record, columns, line)...)
+		batch = append(batch, entity)
+		lines = append(lines, line)
+		result.Total++
+
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+
+	if len(result.Errors) > 0 && opts.ReportDisk != "" {
+		if err := storeReport(ctx, result, opts); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// importBatch validates batch and inserts its valid rows
+func importBatch[T any](ctx context.Context, repo *repository.Repository[T], batch []T, lines []int, conversion []validator.ValidatorError, columns []column, opts Options, result *Result) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	origin := validator.Origin{Rows: lines, Columns: map[string]string{}}
+	for _, col := range columns {
+		origin.Columns[col.path] = col.header
+	}
+	errs := validator.SliceValidateWithOrigin(batch, opts.Tag, origin)
+	for i, err := range errs {
+		// Index is relative to the batch, Row locates the error instead
+		if err.Index != nil {
+			prefix := fmt.Sprintf("[%d].", *err.Index)
+			errs[i].FailedField = strings.TrimPrefix(err.FailedField, prefix)
+			errs[i].Message = strings.TrimPrefix(err.Message, fmt.Sprintf("Index %d: ", *err.Index))
+			errs[i].Index = nil
+		}
+	}
+	errs = append(conversion, errs...)
+
+	invalid := map[int]bool{}
+	for _, err := range errs {
+		if err.Row != nil {
+			invalid[*err.Row] = true
+		}
+	}
+	result.Errors = append(result.Errors, errs...)
+	result.Failed += len(invalid)
+	if len(invalid) > 0 && opts.StopOnError {
+		return fmt.Errorf("importer : %d invalid row(s) in batch ending at row %d", len(invalid), lines[len(lines)-1])
+	}
+
+	valid := make([]T, 0, len(batch))
+	for i, entity := range batch {
+		if !invalid[lines[i]] {
+			valid = append(valid, entity)
+		}
+	}
+	inserted, err := repo.BulkInsert(ctx, valid, opts.BatchSize)
+	result.Imported += inserted
+	return err
+}
+
+// mapColumns matches header cells to fields of T by tag, json tag or field name
+func mapColumns[T any](header []string, tag string) []column {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	fields := map[string]column{}
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := field.Name
+		for _, key := range []string{tag, "json"} {
+			if value := strings.Split(field.Tag.Get(key), ",")[0]; value != "" {
+				name = value
+				break
+			}
+		}
+		if name == "-" {
+			continue
+		}
+		fields[strings.ToLower(name)] = column{field: field.Index, path: name}
+	}
+
+	var columns []column
+	for i, cell := range header {
+		cell = strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff"))
+		if col, ok := fields[strings.ToLower(cell)]; ok {
+			col.index, col.header = i, cell
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// decodeRow sets fields of v from record, returning conversion errors
+func decodeRow(v reflect.Value, record []string, columns []column, line int) []validator.ValidatorError {
+	var errs []validator.ValidatorError
+	for _, col := range columns {
+		if col.index >= len(record) {
+			continue
+		}
+		raw := strings.TrimSpace(record[col.index])
+		if raw == "" {
+			continue
+		}
+		if err := setField(v.FieldByIndex(col.field), raw); err != nil {
+			row := line
+			errs = append(errs, validator.ValidatorError{
+				FailedField: col.path,
+				Tag:         "type",
+				Message:     fmt.Sprintf("%s has invalid value %q", col.header, raw),
+				Row:         &row,
+				Column:      col.header,
+			})
+		}
+	}
+	return errs
+}
+
+// setField converts raw cell into field value
+func setField(field reflect.Value, raw string) error {
+	if field.Kind() == reflect.Pointer {
+		value := reflect.New(field.Type().Elem())
+		if err := setField(value.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+	if _, ok := field.Interface().(time.Time); ok {
+		for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+			if t, err := time.Parse(layout, raw); err == nil {
+				field.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q", raw)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+func blank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteReport writes errors as CSV with row, column and message columns
+func WriteReport(w io.Writer, errs []validator.ValidatorError) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"row", "column", "rule", "message"})
+	for _, err := range errs {
+		row := ""
+		if err.Row != nil {
+			row = strconv.Itoa(*err.Row)
+		}
+		writer.Write([]string{row, err.Column, err.Tag, err.Message})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// storeReport puts error report on the report disk and sets its key and signed URL
+func storeReport(ctx context.Context, result *Result, opts Options) error {
+	disk, err := storage.Disk(opts.ReportDisk)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, result.Errors); err != nil {
+		return err
+	}
+	key := "imports/" + uuid.NewString() + "-errors.csv"
+	if err := disk.Put(ctx, key, &buf, storage.WithContentType("text/csv"), storage.WithSize(int64(buf.Len()))); err != nil {
+		return err
+	}
+	result.ReportKey = key
+	result.ReportURL, err = disk.SignedURL(ctx, key, opts.ReportExpiry)
+	return err
+}
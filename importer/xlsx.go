@@ -0,0 +1,231 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsxRows streams rows of the first worksheet of an XLSX file
+type xlsxRows struct {
+	sheet   io.ReadCloser
+	decoder *xml.Decoder
+	strings []string
+	line    int
+}
+
+// newXLSXRows opens first worksheet, shared strings are loaded in memory
+func newXLSXRows(r io.ReaderAt, size int64) (*xlsxRows, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("importer : invalid xlsx file: %w", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	shared, err := sharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+	sheetPath, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, errors.New("importer : xlsx file has no worksheet")
+	}
+	rc, err := sheet.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxRows{sheet: rc, decoder: xml.NewDecoder(rc), strings: shared}, nil
+}
+
+// decodeXML decodes zip entry into v
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// firstSheet resolves path of first worksheet through workbook relationships
+func firstSheet(files map[string]*zip.File) (string, error) {
+	fallback := "xl/worksheets/sheet1.xml"
+	workbookFile, ok1 := files["xl/workbook.xml"]
+	relsFile, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 {
+		return fallback, nil
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXML(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if err := decodeXML(relsFile, &rels); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return fallback, nil
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+// sharedStrings loads shared string table, rich text runs are concatenated
+func sharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var table struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := decodeXML(f, &table); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		if len(item.Runs) == 0 {
+			values[i] = item.Text
+			continue
+		}
+		var b strings.Builder
+		for _, run := range item.Runs {
+			b.WriteString(run.Text)
+		}
+		values[i] = b.String()
+	}
+	return values, nil
+}
+
+// Read returns next row with its 1-based row number, io.EOF at the end
+func (x *xlsxRows) Read() ([]string, int, error) {
+	var row []string
+	var cellType, cellRef string
+	var value strings.Builder
+	inRow, inValue := false, false
+
+	for {
+		token, err := x.decoder.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				inRow, row = true, nil
+				x.line++
+				if n, err := strconv.Atoi(attr(t, "r")); err == nil {
+					x.line = n
+				}
+			case "c":
+				cellType, cellRef = attr(t, "t"), attr(t, "r")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				column := len(row)
+				if cellRef != "" {
+					column = columnIndex(cellRef)
+				}
+				for len(row) <= column {
+					row = append(row, "")
+				}
+				row[column] = x.cellValue(cellType, value.String())
+			case "row":
+				if inRow {
+					return row, x.line, nil
+				}
+			}
+		}
+	}
+}
+
+// cellValue converts raw cell value by type
+func (x *xlsxRows) cellValue(cellType, raw string) string {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(raw)
+		if err == nil && i >= 0 && i < len(x.strings) {
+			return x.strings[i]
+		}
+		return ""
+	case "b":
+		if raw == "1" {
+			return "true"
+		}
+		return "false"
+	}
+	return raw
+}
+
+// Close releases worksheet reader
+func (x *xlsxRows) Close() error {
+	return x.sheet.Close()
+}
+
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// columnIndex returns zero based column of cell reference like "AB12"
+func columnIndex(ref string) int {
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1
+}
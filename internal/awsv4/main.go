@@ -0,0 +1,195 @@
+// Package awsv4 implements AWS Signature Version 4 signing for S3-compatible and AWS APIs
+package awsv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// UnsignedPayload is used when request body is not hashed
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+	timeFormat      = "20060102T150405Z"
+	dateFormat      = "20060102"
+)
+
+// Credentials represents access key pair
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// Algorithm defaults to AWS4-HMAC-SHA256, GCS uses GOOG4-HMAC-SHA256
+	Algorithm string
+	// HeaderPrefix defaults to X-Amz, GCS uses X-Goog
+	HeaderPrefix string
+}
+
+func (c Credentials) algorithm() string {
+	if c.Algorithm != "" {
+		return c.Algorithm
+	}
+	return "AWS4-HMAC-SHA256"
+}
+
+func (c Credentials) prefix() string {
+	if c.HeaderPrefix != "" {
+		return c.HeaderPrefix
+	}
+	return "X-Amz"
+}
+
+func (c Credentials) keyPrefix() string {
+	return strings.SplitN(c.algorithm(), "-", 2)[0]
+}
+
+// HashHex returns hex encoded SHA256 of data
+func HashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// EscapePath encodes each path segment per RFC 3986 keeping slashes
+func EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func canonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if name == "authorization" || name == "user-agent" {
+			continue
+		}
+		headers[name] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+func signature(creds Credentials, canonical, amzDate, scope string, date, region, service string) string {
+	stringToSign := strings.Join([]string{
+		creds.algorithm(),
+		amzDate,
+		scope,
+		HashHex([]byte(canonical)),
+	}, "\n")
+
+	key := hmacSHA256([]byte(creds.keyPrefix()+creds.SecretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, strings.ToLower(creds.keyPrefix())+"_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// Sign adds authorization headers to req
+func Sign(req *http.Request, payloadHash string, creds Credentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(timeFormat)
+	date := now.Format(dateFormat)
+	prefix := creds.prefix()
+
+	req.Header.Set(prefix+"-Date", amzDate)
+	req.Header.Set(prefix+"-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set(prefix+"-Security-Token", creds.SessionToken)
+	}
+
+	headers, signed := canonicalHeaders(req)
+	canonical := strings.Join([]string{
+		req.Method,
+		EscapePath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		headers,
+		signed,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/%s_request", date, region, service, strings.ToLower(creds.keyPrefix()))
+	sig := signature(creds, canonical, amzDate, scope, date, region, service)
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.algorithm(), creds.AccessKey, scope, signed, sig))
+}
+
+// Presign returns URL valid for expires with signature in query string
+func Presign(method string, u *url.URL, creds Credentials, region, service string, expires time.Duration, now time.Time) string {
+	now = now.UTC()
+	amzDate := now.Format(timeFormat)
+	date := now.Format(dateFormat)
+	prefix := creds.prefix()
+	scope := fmt.Sprintf("%s/%s/%s/%s_request", date, region, service, strings.ToLower(creds.keyPrefix()))
+
+	query := u.Query()
+	query.Set(prefix+"-Algorithm", creds.algorithm())
+	query.Set(prefix+"-Credential", creds.AccessKey+"/"+scope)
+	query.Set(prefix+"-Date", amzDate)
+	query.Set(prefix+"-Expires", fmt.Sprint(int(expires.Seconds())))
+	query.Set(prefix+"-SignedHeaders", "host")
+	if creds.SessionToken != "" {
+		query.Set(prefix+"-Security-Token", creds.SessionToken)
+	}
+
+	canonical := strings.Join([]string{
+		method,
+		EscapePath(u.Path),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		UnsignedPayload,
+	}, "\n")
+
+	query.Set(prefix+"-Signature", signature(creds, canonical, amzDate, scope, date, region, service))
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(query)
+	return signed.String()
+}
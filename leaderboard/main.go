@@ -0,0 +1,136 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+)
+
+var (
+	ErrNoRedis   = errors.New("leaderboard : redis client is not initialized")
+	ErrNotRanked = errors.New("leaderboard : member is not ranked")
+)
+
+// Prefix is prepended to all leaderboard keys
+var Prefix = "nest:leaderboard:"
+
+// Entry represents ranked member, Rank starts at 1 for the highest score
+type Entry struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+	Rank   int64   `json:"rank"`
+}
+
+// Leaderboard represents sorted set ranked by descending score
+type Leaderboard struct {
+	key string
+}
+
+// New creates leaderboard on key
+func New(key string) *Leaderboard {
+	return &Leaderboard{key: Prefix + key}
+}
+
+func client() (*redis.Client, error) {
+	if database.RedisClient == nil {
+		return nil, ErrNoRedis
+	}
+	return database.RedisClient, nil
+}
+
+// AddScore adds delta to member score and returns new score
+func (l *Leaderboard) AddScore(ctx context.Context, member string, delta float64) (float64, error) {
+	rdb, err := client()
+	if err != nil {
+		return 0, err
+	}
+	return rdb.ZIncrBy(ctx, l.key, delta, member).Result()
+}
+
+// SetScore replaces member score
+func (l *Leaderboard) SetScore(ctx context.Context, member string, score float64) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	return rdb.ZAdd(ctx, l.key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// Remove removes members
+func (l *Leaderboard) Remove(ctx context.Context, members ...string) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+	return rdb.ZRem(ctx, l.key, values...).Err()
+}
+
+// Top returns n highest ranked members
+func (l *Leaderboard) Top(ctx context.Context, n int64) ([]Entry, error) {
+	return l.between(ctx, 0, n-1)
+}
+
+// Rank returns entry of member, ErrNotRanked when absent
+func (l *Leaderboard) Rank(ctx context.Context, member string) (Entry, error) {
+	rdb, err := client()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	rank, err := rdb.ZRevRankWithScore(ctx, l.key, member).Result()
+	if errors.Is(err, redis.Nil) {
+		return Entry{}, ErrNotRanked
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{Member: member, Score: rank.Score, Rank: rank.Rank + 1}, nil
+}
+
+// AroundMe returns up to n members ranked above and below member, including member
+func (l *Leaderboard) AroundMe(ctx context.Context, member string, n int64) ([]Entry, error) {
+	entry, err := l.Rank(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	start := max(entry.Rank-1-n, 0)
+	return l.between(ctx, start, entry.Rank-1+n)
+}
+
+// Count returns number of ranked members
+func (l *Leaderboard) Count(ctx context.Context) (int64, error) {
+	rdb, err := client()
+	if err != nil {
+		return 0, err
+	}
+	return rdb.ZCard(ctx, l.key).Result()
+}
+
+// between returns entries of zero based rank range
+func (l *Leaderboard) between(ctx context.Context, start, stop int64) ([]Entry, error) {
+	rdb, err := client()
+	if err != nil {
+		return nil, err
+	}
+	if stop < start {
+		return []Entry{}, nil
+	}
+
+	members, err := rdb.ZRevRangeWithScores(ctx, l.key, start, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(members))
+	for i, z := range members {
+		member, _ := z.Member.(string)
+		entries[i] = Entry{Member: member, Score: z.Score, Rank: start + int64(i) + 1}
+	}
+	return entries, nil
+}
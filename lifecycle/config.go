@@ -0,0 +1,33 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+)
+
+// Hook represents lifecycle callback
+type Hook func(ctx context.Context) error
+
+// Lifecycle holds start and stop hooks executed in dependency order
+type Lifecycle struct {
+	mu      sync.Mutex
+	onStart []Hook
+	onStop  []stopHook
+	started bool
+	// ran counts start hooks that succeeded since Start
+	ran int
+}
+
+// stopHook belongs to the start hooks registered before it
+type stopHook struct {
+	fn    Hook
+	after int
+}
+
+// Default is the process wide lifecycle used by package helpers
+var Default = New()
+
+// New creates empty lifecycle
+func New() *Lifecycle {
+	return &Lifecycle{}
+}
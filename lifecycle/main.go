@@ -0,0 +1,88 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+)
+
+// OnStart registers hook executed on Start in registration order
+func (l *Lifecycle) OnStart(fn Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onStart = append(l.onStart, fn)
+}
+
+// OnStop registers hook executed on Stop in reverse registration order. It belongs to the start hooks
+// registered before it, e.g. OnStart(startWorker); OnStop(stopWorker), and only runs once they all succeeded
+func (l *Lifecycle) OnStop(fn Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onStop = append(l.onStop, stopHook{fn: fn, after: len(l.onStart)})
+}
+
+// Start runs start hooks, stopping at the first error. Stop then only stops what started before it
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := append([]Hook(nil), l.onStart...)
+	l.started = true
+	l.ran = 0
+	l.mu.Unlock()
+
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("start hook %d failed: %w", i, err)
+		}
+		l.mu.Lock()
+		l.ran = i + 1
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop runs stop hooks of started components in reverse order and collects errors. It does nothing
+// before Start, hooks registered after a start hook that failed or never ran are skipped
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	if !l.started {
+		l.mu.Unlock()
+		return nil
+	}
+	var hooks []Hook
+	for _, hook := range l.onStop {
+		if hook.after <= l.ran {
+			hooks = append(hooks, hook.fn)
+		}
+	}
+	l.started = false
+	l.ran = 0
+	l.mu.Unlock()
+
+	var errors []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errors = append(errors, fmt.Errorf("stop hook %d failed: %w", i, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("errors occurred while stopping: %v", errors)
+	}
+	return nil
+}
+
+// Started reports whether Start has been called without Stop
+func (l *Lifecycle) Started() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.started
+}
+
+// OnStart registers start hook on Default lifecycle
+func OnStart(fn Hook) {
+	Default.OnStart(fn)
+}
+
+// OnStop registers stop hook on Default lifecycle
+func OnStop(fn Hook) {
+	Default.OnStop(fn)
+}
@@ -0,0 +1,110 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+)
+
+var (
+	ErrNoRedis     = errors.New("lock : redis client is not initialized")
+	ErrNotAcquired = errors.New("lock : lock is held by another owner")
+	ErrNotHeld     = errors.New("lock : lock is no longer held")
+)
+
+// Prefix is prepended to all lock keys
+var Prefix = "nest:lock:"
+
+var (
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+	refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+// Lock represents distributed lock held in Redis
+type Lock struct {
+	key   string
+	token string
+}
+
+// Acquire tries to acquire lock once, returns ErrNotAcquired when held by another owner
+func Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if database.RedisClient == nil {
+		return nil, ErrNoRedis
+	}
+
+	l := &Lock{key: Prefix + key, token: uuid.NewString()}
+	ok, err := database.RedisClient.SetNX(ctx, l.key, l.token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotAcquired
+	}
+	return l, nil
+}
+
+// AcquireWait retries Acquire every interval until ctx is done
+func AcquireWait(ctx context.Context, key string, ttl, interval time.Duration) (*Lock, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		l, err := Acquire(ctx, key, ttl)
+		if !errors.Is(err, ErrNotAcquired) {
+			return l, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Key returns lock key including prefix
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token returns unique owner token
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Refresh extends lock ttl if still held
+func (l *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	n, err := refreshScript.Run(ctx, database.RedisClient, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Release releases lock if still held
+func (l *Lock) Release(ctx context.Context) error {
+	n, err := releaseScript.Run(ctx, database.RedisClient, []string{l.key}, l.token).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
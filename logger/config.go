@@ -0,0 +1,13 @@
+package logger
+
+import (
+	"io"
+)
+
+// Config represents logger configuration
+type Config struct {
+	Level     string // debug, info, warn, error
+	Format    string // json or text
+	Output    io.Writer
+	AddSource bool
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/rikiihsan/nest/requestctx"
+)
+
+// ContextExtractor returns attributes derived from context, e.g. request ID
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = []ContextExtractor{requestAttrs}
+)
+
+// requestAttrs returns request ID and tenant of context
+func requestAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if id := requestctx.RequestID.Value(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if tenant := requestctx.Tenant.Value(ctx); tenant != "" {
+		attrs = append(attrs, slog.String("tenant", tenant))
+	}
+	return attrs
+}
+
+// RegisterContextExtractor adds attributes to every record logged with context
+func RegisterContextExtractor(fn ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, fn)
+}
+
+// contextHandler enriches records with registered context attributes
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		extractorsMu.RLock()
+		for _, fn := range extractors {
+			r.AddAttrs(fn(ctx)...)
+		}
+		extractorsMu.RUnlock()
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+var log = slog.Default()
+
+// Init initializes the default structured logger
+func Init(cfg Config) *slog.Logger {
+	output := cfg.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:     ParseLevel(cfg.Level),
+		AddSource: cfg.AddSource,
+	}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.Format, "text") {
+		handler = slog.NewTextHandler(output, opts)
+	} else {
+		handler = slog.NewJSONHandler(output, opts)
+	}
+
+	log = slog.New(contextHandler{handler})
+	slog.SetDefault(log)
+	return log
+}
+
+// Get returns logger instance
+func Get() *slog.Logger {
+	return log
+}
+
+// ParseLevel converts level name to slog level, defaults to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
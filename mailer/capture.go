@@ -0,0 +1,196 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrCaptureNotFound is returned for unknown captured message IDs
+var ErrCaptureNotFound = errors.New("mailer : captured message not found")
+
+// CapturedMessage is message stored by CaptureDriver
+type CapturedMessage struct {
+	Message
+	ID         string    `json:"id"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// CaptureDriver stores messages instead of sending them, for development and tests. Messages are kept
+// in memory, or as <id>.json files in Dir so captures survive restarts and are shared with workers
+type CaptureDriver struct {
+	// Dir stores captures on disk when set
+	Dir string
+	// Limit keeps newest messages, defaults to 100
+	Limit int
+	// Log logs subject and recipients of every captured message
+	Log bool
+
+	mu       sync.Mutex
+	messages []CapturedMessage
+}
+
+// Capture returns configured driver when it is a CaptureDriver
+func Capture() (*CaptureDriver, bool) {
+	d, ok := config.Driver.(*CaptureDriver)
+	return d, ok
+}
+
+func (d *CaptureDriver) limit() int {
+	if d.Limit > 0 {
+		return d.Limit
+	}
+	return 100
+}
+
+// Send captures message
+func (d *CaptureDriver) Send(ctx context.Context, msg *Message) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+	captured := CapturedMessage{Message: *msg, ID: id.String(), CapturedAt: time.Now()}
+	if d.Log {
+		slog.InfoContext(ctx, "email captured", "id", captured.ID, "subject", msg.Subject, "to", msg.Recipients())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Dir == "" {
+		d.messages = append(d.messages, captured)
+		if over := len(d.messages) - d.limit(); over > 0 {
+			d.messages = append(d.messages[:0:0], d.messages[over:]...)
+		}
+		return nil
+	}
+
+	// Captured mails hold reset links and tokens, only the process user may read them
+	if err := os.MkdirAll(d.Dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(captured)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(d.Dir, captured.ID+".json"), data, 0o600); err != nil {
+		return err
+	}
+
+	files, err := d.files()
+	if err != nil {
+		return err
+	}
+	for _, old := range files[min(d.limit(), len(files)):] {
+		os.Remove(old)
+	}
+	return nil
+}
+
+// files returns capture files of Dir, newest first since IDs are time ordered
+func (d *CaptureDriver) files() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(d.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files, nil
+}
+
+// Messages returns captured messages, newest first
+func (d *CaptureDriver) Messages() ([]CapturedMessage, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Dir == "" {
+		messages := make([]CapturedMessage, len(d.messages))
+		for i, msg := range d.messages {
+			messages[len(messages)-1-i] = msg
+		}
+		return messages, nil
+	}
+
+	files, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]CapturedMessage, 0, len(files))
+	for _, file := range files {
+		msg, err := readCapture(file)
+		if err != nil {
+			continue
+		}
+		messages = append(messages, *msg)
+	}
+	return messages, nil
+}
+
+// Find returns captured message by ID
+func (d *CaptureDriver) Find(id string) (*CapturedMessage, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Dir == "" {
+		for i := range d.messages {
+			if d.messages[i].ID == id {
+				msg := d.messages[i]
+				return &msg, nil
+			}
+		}
+		return nil, ErrCaptureNotFound
+	}
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrCaptureNotFound
+	}
+	msg, err := readCapture(filepath.Join(d.Dir, id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCaptureNotFound
+	}
+	return msg, err
+}
+
+// Clear removes all captured messages
+func (d *CaptureDriver) Clear() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.messages = nil
+	if d.Dir == "" {
+		return nil
+	}
+	files, err := d.files()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func readCapture(file string) (*CapturedMessage, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var msg CapturedMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	if msg.ID == "" {
+		msg.ID = strings.TrimSuffix(filepath.Base(file), ".json")
+	}
+	return &msg, nil
+}
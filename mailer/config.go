@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"context"
+	"io/fs"
+)
+
+// Attachment represents file attached to message
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+	// Inline attachments can be referenced from HTML with cid:Filename
+	Inline bool `json:"inline,omitempty"`
+}
+
+// Message represents email message
+type Message struct {
+	From        string            `json:"from"`
+	To          []string          `json:"to"`
+	Cc          []string          `json:"cc,omitempty"`
+	Bcc         []string          `json:"bcc,omitempty"`
+	ReplyTo     string            `json:"reply_to,omitempty"`
+	Subject     string            `json:"subject"`
+	Text        string            `json:"text,omitempty"`
+	HTML        string            `json:"html,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+}
+
+// Attach adds attachment to message
+func (m *Message) Attach(filename, contentType string, data []byte) *Message {
+	m.Attachments = append(m.Attachments, Attachment{
+		Filename:    filename,
+		ContentType: contentType,
+		Data:        data,
+	})
+	return m
+}
+
+// Recipients returns all envelope recipients
+func (m *Message) Recipients() []string {
+	all := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	all = append(all, m.To...)
+	all = append(all, m.Cc...)
+	return append(all, m.Bcc...)
+}
+
+// Driver sends messages through a provider
+type Driver interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config represents mailer configuration
+type Config struct {
+	Driver Driver
+	// From is used when message has no sender
+	From string
+	// Templates holds page templates and layouts
+	Templates fs.FS
+	// Layout is template file wrapping page templates, empty disables layouts
+	Layout string
+	// Queue is queue name used by Queue, defaults to queue default
+	Queue string
+	// MaxRetries for queued sending, zero uses queue default
+	MaxRetries int
+}
@@ -0,0 +1,253 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/rikiihsan/nest/internal/awsv4"
+)
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
+
+// do executes request and turns non-2xx responses into errors
+func do(client *http.Client, req *http.Request) error {
+	resp, err := httpClient(client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// SESDriver sends raw messages through Amazon SES v2 API
+type SESDriver struct {
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// Endpoint overrides https://email.{region}.amazonaws.com
+	Endpoint string
+	Client   *http.Client
+}
+
+// Send sends message through SES
+func (d *SESDriver) Send(ctx context.Context, msg *Message) error {
+	raw, err := msg.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": msg.From,
+		"Destination": map[string][]string{
+			"ToAddresses":  msg.To,
+			"CcAddresses":  msg.Cc,
+			"BccAddresses": msg.Bcc,
+		},
+		"Content": map[string]interface{}{
+			"Raw": map[string]string{"Data": base64.StdEncoding.EncodeToString(raw)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := d.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", d.Region)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	awsv4.Sign(req, awsv4.HashHex(body), awsv4.Credentials{
+		AccessKey:    d.AccessKey,
+		SecretKey:    d.SecretKey,
+		SessionToken: d.SessionToken,
+	}, d.Region, "ses", time.Now())
+
+	return do(d.Client, req)
+}
+
+// MailgunDriver sends MIME messages through Mailgun API
+type MailgunDriver struct {
+	Domain string
+	APIKey string
+	// BaseURL defaults to https://api.mailgun.net, use https://api.eu.mailgun.net for EU
+	BaseURL string
+	Client  *http.Client
+}
+
+// Send sends message through Mailgun
+func (d *MailgunDriver) Send(ctx context.Context, msg *Message) error {
+	raw, err := msg.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for _, rcpt := range msg.Recipients() {
+		form.WriteField("to", rcpt)
+	}
+	part, err := form.CreateFormFile("message", "message.mime")
+	if err != nil {
+		return err
+	}
+	part.Write(raw)
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	baseURL := d.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.mailgun.net"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v3/%s/messages.mime", baseURL, d.Domain), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.SetBasicAuth("api", d.APIKey)
+
+	return do(d.Client, req)
+}
+
+// SendGridDriver sends messages through SendGrid v3 API
+type SendGridDriver struct {
+	APIKey string
+	// BaseURL defaults to https://api.sendgrid.com
+	BaseURL string
+	Client  *http.Client
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+func sendGridAddresses(list []string) ([]sendGridAddress, error) {
+	var out []sendGridAddress
+	for _, s := range list {
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", s, err)
+		}
+		out = append(out, sendGridAddress{Email: addr.Address, Name: addr.Name})
+	}
+	return out, nil
+}
+
+// Send sends message through SendGrid
+func (d *SendGridDriver) Send(ctx context.Context, msg *Message) error {
+	to, err := sendGridAddresses(msg.To)
+	if err != nil {
+		return err
+	}
+	cc, err := sendGridAddresses(msg.Cc)
+	if err != nil {
+		return err
+	}
+	bcc, err := sendGridAddresses(msg.Bcc)
+	if err != nil {
+		return err
+	}
+	from, err := sendGridAddresses([]string{msg.From})
+	if err != nil {
+		return err
+	}
+
+	personalization := map[string]interface{}{"to": to}
+	if len(cc) > 0 {
+		personalization["cc"] = cc
+	}
+	if len(bcc) > 0 {
+		personalization["bcc"] = bcc
+	}
+
+	var content []map[string]string
+	if msg.Text != "" {
+		content = append(content, map[string]string{"type": "text/plain", "value": msg.Text})
+	}
+	if msg.HTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": msg.HTML})
+	}
+
+	payload := map[string]interface{}{
+		"personalizations": []interface{}{personalization},
+		"from":             from[0],
+		"subject":          msg.Subject,
+		"content":          content,
+	}
+	if msg.ReplyTo != "" {
+		replyTo, err := sendGridAddresses([]string{msg.ReplyTo})
+		if err != nil {
+			return err
+		}
+		payload["reply_to"] = replyTo[0]
+	}
+	if len(msg.Headers) > 0 {
+		payload["headers"] = msg.Headers
+	}
+	if len(msg.Attachments) > 0 {
+		var attachments []map[string]string
+		for _, a := range msg.Attachments {
+			attachment := map[string]string{
+				"content":     base64.StdEncoding.EncodeToString(a.Data),
+				"filename":    a.Filename,
+				"disposition": "attachment",
+			}
+			if a.ContentType != "" {
+				attachment["type"] = a.ContentType
+			}
+			if a.Inline {
+				attachment["disposition"] = "inline"
+				attachment["content_id"] = a.Filename
+			}
+			attachments = append(attachments, attachment)
+		}
+		payload["attachments"] = attachments
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	baseURL := d.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.sendgrid.com"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+d.APIKey)
+
+	return do(d.Client, req)
+}
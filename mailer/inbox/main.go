@@ -0,0 +1,192 @@
+package inbox
+
+import (
+	"errors"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/mailer"
+	"github.com/rikiihsan/nest/response"
+	"github.com/rikiihsan/nest/security"
+)
+
+// ErrNoCapture is returned when mailer is not configured with mailer.CaptureDriver
+var ErrNoCapture = errors.New("inbox : mailer driver is not a capture driver")
+
+// Summary is captured message without bodies and attachments
+type Summary struct {
+	ID          string    `json:"id"`
+	CapturedAt  time.Time `json:"captured_at"`
+	From        string    `json:"from"`
+	To          []string  `json:"to"`
+	Subject     string    `json:"subject"`
+	Attachments int       `json:"attachments"`
+}
+
+// Register mounts development endpoints browsing messages of mailer.CaptureDriver and rendering
+// templates registered with mailer.RegisterPreview. The endpoints are unauthenticated, so nothing is
+// mounted unless security.EnvKey names a development environment, see security.Development. Use
+// RegisterWith to mount them behind middleware in other environments
+//
+//	GET    /mail                     HTML index
+//	GET    /mail/messages            JSON summaries, newest first
+//	GET    /mail/messages/:id        HTML body, text body when there is none
+//	GET    /mail/messages/:id/raw    MIME source
+//	DELETE /mail/messages
+//	GET    /mail/previews/:name      rendered template, ?format=text for the text body
+func Register(router fiber.Router) {
+	if !security.Development() {
+		return
+	}
+	RegisterWith(router)
+}
+
+// RegisterWith mounts endpoints of Register in any environment, handlers run after guards, e.g.
+// inbox.RegisterWith(admin, authz.Require("mail:inbox"))
+func RegisterWith(router fiber.Router, guards ...fiber.Handler) {
+	group := router.Group("/mail", guards...)
+	group.Get("/", indexHandler)
+	group.Get("/messages", listHandler)
+	group.Get("/messages/:id", showHandler)
+	group.Get("/messages/:id/raw", rawHandler)
+	group.Delete("/messages", clearHandler)
+	group.Get("/previews/:name", previewHandler)
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Mail</title>
+<style>body{font-family:sans-serif;margin:2em}table{border-collapse:collapse}td,th{padding:4px 12px;border-bottom:1px solid #ddd;text-align:left}</style>
+</head><body>
+<h1>Captured mail</h1>
+{{if .Captured}}<table><tr><th>Captured</th><th>To</th><th>Subject</th><th></th></tr>
+{{range .Messages}}<tr><td>{{.CapturedAt.Format "2006-01-02 15:04:05"}}</td><td>{{range $i, $to := .To}}{{if $i}}, {{end}}{{$to}}{{end}}</td>
+<td><a href="{{$.Base}}/messages/{{.ID}}">{{.Subject}}</a></td><td><a href="{{$.Base}}/messages/{{.ID}}/raw">source</a></td></tr>
+{{else}}<tr><td colspan="4">No messages</td></tr>{{end}}</table>
+{{else}}<p>Configure mailer with mailer.CaptureDriver to capture messages.</p>{{end}}
+<h1>Previews</h1>
+<ul>{{range .Previews}}<li><a href="{{$.Base}}/previews/{{.}}">{{.}}</a> (<a href="{{$.Base}}/previews/{{.}}?format=text">text</a>)</li>
+{{else}}<li>No previews, see mailer.RegisterPreview</li>{{end}}</ul>
+</body></html>`))
+
+func summaries() ([]Summary, error) {
+	capture, ok := mailer.Capture()
+	if !ok {
+		return nil, ErrNoCapture
+	}
+	messages, err := capture.Messages()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Summary, len(messages))
+	for i, msg := range messages {
+		out[i] = Summary{
+			ID:          msg.ID,
+			CapturedAt:  msg.CapturedAt,
+			From:        msg.From,
+			To:          msg.To,
+			Subject:     msg.Subject,
+			Attachments: len(msg.Attachments),
+		}
+	}
+	return out, nil
+}
+
+func indexHandler(c *fiber.Ctx) error {
+	messages, err := summaries()
+	if err != nil && !errors.Is(err, ErrNoCapture) {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+
+	c.Type("html", "utf-8")
+	return indexTemplate.Execute(c, map[string]interface{}{
+		"Base":     strings.TrimSuffix(c.Path(), "/"),
+		"Captured": !errors.Is(err, ErrNoCapture),
+		"Messages": messages,
+		"Previews": mailer.Previews(),
+	})
+}
+
+func listHandler(c *fiber.Ctx) error {
+	messages, err := summaries()
+	if errors.Is(err, ErrNoCapture) {
+		return response.Error(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, messages, nil)
+}
+
+// find returns captured message of request with status code of its error
+func find(c *fiber.Ctx) (*mailer.CapturedMessage, int, error) {
+	capture, ok := mailer.Capture()
+	if !ok {
+		return nil, fiber.StatusNotFound, ErrNoCapture
+	}
+	msg, err := capture.Find(c.Params("id"))
+	if errors.Is(err, mailer.ErrCaptureNotFound) {
+		return nil, fiber.StatusNotFound, err
+	}
+	if err != nil {
+		return nil, fiber.StatusInternalServerError, err
+	}
+	return msg, fiber.StatusOK, nil
+}
+
+func showHandler(c *fiber.Ctx) error {
+	msg, code, err := find(c)
+	if err != nil {
+		return response.Error(c, code, err)
+	}
+	return body(c, &msg.Message, msg.HTML == "")
+}
+
+func rawHandler(c *fiber.Ctx) error {
+	msg, code, err := find(c)
+	if err != nil {
+		return response.Error(c, code, err)
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	c.Type("txt", "utf-8")
+	return c.Send(raw)
+}
+
+func clearHandler(c *fiber.Ctx) error {
+	capture, ok := mailer.Capture()
+	if !ok {
+		return response.Error(c, fiber.StatusNotFound, ErrNoCapture)
+	}
+	if err := capture.Clear(); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func previewHandler(c *fiber.Ctx) error {
+	msg, err := mailer.Preview(c.Params("name"))
+	if errors.Is(err, mailer.ErrUnknownPreview) {
+		return response.Error(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return response.Error(c, fiber.StatusUnprocessableEntity, err)
+	}
+	return body(c, msg, c.Query("format") == "text")
+}
+
+// body writes HTML body of message, or its text body when text is set. HTML is sandboxed so
+// scripts of mail bodies don't run on the app origin
+func body(c *fiber.Ctx, msg *mailer.Message, text bool) error {
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	if text {
+		c.Type("txt", "utf-8")
+		return c.SendString(msg.Text)
+	}
+	c.Set(fiber.HeaderContentSecurityPolicy, "sandbox")
+	c.Type("html", "utf-8")
+	return c.SendString(msg.HTML)
+}
@@ -0,0 +1,105 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rikiihsan/nest/queue"
+)
+
+// JobType is queue job type used for queued sending
+const JobType = "mailer:send"
+
+var (
+	ErrNoDriver    = errors.New("mailer : driver is not configured")
+	ErrNoTemplates = errors.New("mailer : templates are not configured")
+)
+
+var (
+	config   Config
+	renderer *Renderer
+)
+
+// Init initializes mailer and registers queue handler for queued sending
+func Init(cfg Config) error {
+	if cfg.Driver == nil {
+		return ErrNoDriver
+	}
+	config = cfg
+	renderer = nil
+	if cfg.Templates != nil {
+		renderer = NewRenderer(cfg.Templates, cfg.Layout)
+	}
+
+	queue.Register(JobType, func(ctx context.Context, job *queue.Job) error {
+		var msg Message
+		if err := job.Bind(&msg); err != nil {
+			return fmt.Errorf("failed to decode message: %w", err)
+		}
+		return Send(ctx, &msg)
+	})
+	return nil
+}
+
+// Send sends message immediately
+func Send(ctx context.Context, msg *Message) error {
+	if config.Driver == nil {
+		return ErrNoDriver
+	}
+	if msg.From == "" {
+		msg.From = config.From
+	}
+	if len(msg.Recipients()) == 0 {
+		return errors.New("mailer : message has no recipients")
+	}
+	return config.Driver.Send(ctx, msg)
+}
+
+// Render fills message bodies from template
+func Render(msg *Message, name string, data interface{}) error {
+	if renderer == nil {
+		return ErrNoTemplates
+	}
+	html, text, err := renderer.Render(name, data)
+	if err != nil {
+		return fmt.Errorf("failed to render template '%s': %w", name, err)
+	}
+	msg.HTML = html
+	if text != "" {
+		msg.Text = text
+	}
+	return nil
+}
+
+// SendTemplate renders template into message and sends it
+func SendTemplate(ctx context.Context, msg *Message, name string, data interface{}) error {
+	if err := Render(msg, name, data); err != nil {
+		return err
+	}
+	return Send(ctx, msg)
+}
+
+// Queue enqueues message for background sending with queue retries
+func Queue(ctx context.Context, msg *Message, opts ...queue.Option) (*queue.Job, error) {
+	if msg.From == "" {
+		msg.From = config.From
+	}
+
+	defaults := []queue.Option{}
+	if config.Queue != "" {
+		defaults = append(defaults, queue.OnQueue(config.Queue))
+	}
+	if config.MaxRetries > 0 {
+		defaults = append(defaults, queue.WithMaxRetries(config.MaxRetries))
+	}
+	return queue.Enqueue(ctx, JobType, msg, append(defaults, opts...)...)
+}
+
+// QueueTemplate renders template and enqueues message
+func QueueTemplate(ctx context.Context, msg *Message, name string, data interface{}) (*queue.Job, error) {
+	if err := Render(msg, name, data); err != nil {
+		return nil, err
+	}
+	return Queue(ctx, msg)
+}
@@ -0,0 +1,183 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrHeaderInjection is returned by Bytes for subjects, addresses, headers or attachment names containing CR or LF
+var ErrHeaderInjection = errors.New("mailer : header contains line break")
+
+// Bytes renders message as RFC 5322 MIME document, non-ASCII subject and display names are RFC 2047 encoded
+func (m *Message) Bytes() ([]byte, error) {
+	if err := m.checkHeaders(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+
+	headers := map[string]string{
+		"From":         formatAddress(m.From),
+		"To":           formatAddresses(m.To),
+		"Subject":      mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-ID":   fmt.Sprintf("<%s@nest>", uuid.NewString()),
+		"MIME-Version": "1.0",
+	}
+	if len(m.Cc) > 0 {
+		headers["Cc"] = formatAddresses(m.Cc)
+	}
+	if m.ReplyTo != "" {
+		headers["Reply-To"] = formatAddress(m.ReplyTo)
+	}
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, headers[k])
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	// Body as alternative text/html parts
+	var body bytes.Buffer
+	alt := multipart.NewWriter(&body)
+	if m.Text != "" {
+		if err := writeEncodedPart(alt, "text/plain; charset=utf-8", m.Text); err != nil {
+			return nil, err
+		}
+	}
+	if m.HTML != "" {
+		if err := writeEncodedPart(alt, "text/html; charset=utf-8", m.HTML); err != nil {
+			return nil, err
+		}
+	}
+	if err := alt.Close(); err != nil {
+		return nil, err
+	}
+
+	altHeader := textproto.MIMEHeader{}
+	altHeader.Set("Content-Type", "multipart/alternative; boundary="+alt.Boundary())
+	altPart, err := mixed.CreatePart(altHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := altPart.Write(body.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, a := range m.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		disposition := "attachment"
+		if a.Inline {
+			disposition = "inline"
+		}
+
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Type", contentType)
+		h.Set("Content-Transfer-Encoding", "base64")
+		h.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+		if a.Inline {
+			h.Set("Content-ID", "<"+a.Filename+">")
+		}
+
+		part, err := mixed.CreatePart(h)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// checkHeaders rejects line breaks in values written to headers, they would start new headers, e.g. Bcc
+func (m *Message) checkHeaders() error {
+	values := []string{m.From, m.ReplyTo, m.Subject}
+	values = append(values, m.To...)
+	values = append(values, m.Cc...)
+	values = append(values, m.Bcc...)
+	for k, v := range m.Headers {
+		values = append(values, k, v)
+	}
+	for _, a := range m.Attachments {
+		values = append(values, a.Filename, a.ContentType)
+	}
+	for _, v := range values {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("%w: %q", ErrHeaderInjection, v)
+		}
+	}
+	return nil
+}
+
+// formatAddress encodes non-ASCII display name of address, e.g. "Jöhn <john@example.com>"
+func formatAddress(address string) string {
+	i := strings.LastIndexByte(address, '<')
+	if i <= 0 {
+		return address
+	}
+	name := strings.Trim(strings.TrimSpace(address[:i]), `"`)
+	encoded := mime.QEncoding.Encode("utf-8", name)
+	if encoded == name {
+		return address
+	}
+	return encoded + " " + address[i:]
+}
+
+func formatAddresses(addresses []string) string {
+	formatted := make([]string, len(addresses))
+	for i, address := range addresses {
+		formatted[i] = formatAddress(address)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func writeEncodedPart(w *multipart.Writer, contentType, body string) error {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Transfer-Encoding", "base64")
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	return writeBase64(part, []byte(body))
+}
+
+// writeBase64 writes data wrapped at 76 characters per line
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := w.Write([]byte(encoded + "\r\n"))
+	return err
+}
@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownPreview is returned for template names not registered with RegisterPreview
+var ErrUnknownPreview = errors.New("mailer : preview is not registered")
+
+var (
+	previewsMu sync.RWMutex
+	previews   = make(map[string]func() interface{})
+)
+
+// RegisterPreview makes template previewable, data returns sample template data
+func RegisterPreview(name string, data func() interface{}) {
+	previewsMu.Lock()
+	defer previewsMu.Unlock()
+	previews[name] = data
+}
+
+// Previews returns names of previewable templates, sorted
+func Previews() []string {
+	previewsMu.RLock()
+	defer previewsMu.RUnlock()
+	names := make([]string, 0, len(previews))
+	for name := range previews {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preview renders template registered with RegisterPreview with its sample data
+func Preview(name string) (*Message, error) {
+	previewsMu.RLock()
+	data, ok := previews[name]
+	previewsMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownPreview
+	}
+
+	msg := &Message{From: config.From, Subject: name}
+	if err := Render(msg, name, data()); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
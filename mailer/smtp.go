@@ -0,0 +1,108 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPDriver sends messages through SMTP server
+type SMTPDriver struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// ImplicitTLS connects with TLS directly (port 465), otherwise STARTTLS is used when offered
+	ImplicitTLS bool
+	TLSConfig   *tls.Config
+}
+
+// Send sends message through SMTP
+func (d *SMTPDriver) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	tlsConfig := d.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: d.Host}
+	}
+
+	addr := net.JoinHostPort(d.Host, strconv.Itoa(d.Port))
+	var conn net.Conn
+	if d.ImplicitTLS {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, d.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !d.ImplicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("failed to start TLS: %w", err)
+			}
+		}
+	}
+
+	if d.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", d.Username, d.Password, d.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	from, err := envelopeAddress(msg.From)
+	if err != nil {
+		return err
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range msg.Recipients() {
+		addr, err := envelopeAddress(rcpt)
+		if err != nil {
+			return err
+		}
+		if err := client.Rcpt(addr); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", addr, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// envelopeAddress extracts bare address from "Name <addr>" form
+func envelopeAddress(s string) (string, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", s, err)
+	}
+	return addr.Address, nil
+}
@@ -0,0 +1,100 @@
+package mailer
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"io/fs"
+	"sync"
+	texttemplate "text/template"
+)
+
+// Renderer renders email templates with optional layout
+//
+// Page templates named "<name>.html" define a "content" block used by the layout,
+// optional "<name>.txt" files provide the plain text alternative.
+type Renderer struct {
+	fsys   fs.FS
+	layout string
+	mu     sync.Mutex
+	html   map[string]*htmltemplate.Template
+	text   map[string]*texttemplate.Template
+}
+
+// NewRenderer creates renderer reading templates from fsys
+func NewRenderer(fsys fs.FS, layout string) *Renderer {
+	return &Renderer{
+		fsys:   fsys,
+		layout: layout,
+		html:   make(map[string]*htmltemplate.Template),
+		text:   make(map[string]*texttemplate.Template),
+	}
+}
+
+func (r *Renderer) htmlTemplate(name string) (*htmltemplate.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.html[name]; ok {
+		return t, nil
+	}
+
+	files := []string{name + ".html"}
+	if r.layout != "" {
+		files = append([]string{r.layout}, files...)
+	}
+	t, err := htmltemplate.ParseFS(r.fsys, files...)
+	if err != nil {
+		return nil, err
+	}
+	r.html[name] = t
+	return t, nil
+}
+
+func (r *Renderer) textTemplate(name string) (*texttemplate.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.text[name]; ok {
+		return t, nil
+	}
+
+	t, err := texttemplate.ParseFS(r.fsys, name+".txt")
+	if err != nil {
+		return nil, err
+	}
+	r.text[name] = t
+	return t, nil
+}
+
+// Render renders HTML and text bodies of template
+func (r *Renderer) Render(name string, data interface{}) (html string, text string, err error) {
+	t, err := r.htmlTemplate(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	html = buf.String()
+
+	if !fileExists(r.fsys, name+".txt") {
+		return html, "", nil
+	}
+	tt, err := r.textTemplate(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	buf.Reset()
+	if err := tt.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	return html, buf.String(), nil
+}
+
+func fileExists(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
@@ -0,0 +1,180 @@
+package nest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/apperror"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/env"
+	"github.com/rikiihsan/nest/i18n"
+	"github.com/rikiihsan/nest/lifecycle"
+	"github.com/rikiihsan/nest/logger"
+	"github.com/rikiihsan/nest/response"
+	"github.com/rikiihsan/nest/tasks"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// App wires subsystems together and manages their lifecycle
+type App struct {
+	Config    Config
+	Fiber     *fiber.App
+	Logger    *slog.Logger
+	Lifecycle *lifecycle.Lifecycle
+}
+
+// New creates application, routes can be registered on Fiber before Run
+func New(cfg Config) *App {
+	if cfg.Addr == "" {
+		cfg.Addr = ":3000"
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+	if cfg.Fiber.ErrorHandler == nil {
+		cfg.Fiber.ErrorHandler = apperror.ErrorHandler()
+	}
+
+	return &App{
+		Config:    cfg,
+		Fiber:     fiber.New(cfg.Fiber),
+		Logger:    logger.Get(),
+		Lifecycle: lifecycle.Default,
+	}
+}
+
+// OnStart registers hook executed after all subsystems are initialized
+func (a *App) OnStart(fn lifecycle.Hook) {
+	a.Lifecycle.OnStart(fn)
+}
+
+// OnStop registers hook executed after HTTP server and background tasks are drained and before connections are closed
+func (a *App) OnStop(fn lifecycle.Hook) {
+	a.Lifecycle.OnStop(fn)
+}
+
+// Boot initializes subsystems in dependency order and runs start hooks
+func (a *App) Boot(ctx context.Context) error {
+	if err := a.Init(ctx); err != nil {
+		return err
+	}
+	return a.Lifecycle.Start(ctx)
+}
+
+// Init initializes subsystems without running start hooks, used by CLI commands
+func (a *App) Init(ctx context.Context) error {
+	// Load environment
+	if len(a.Config.EnvFiles) > 0 {
+		if err := env.Load(a.Config.EnvFiles...); err != nil {
+			return fmt.Errorf("failed to load env: %w", err)
+		}
+	} else if err := env.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to load env: %w", err)
+	}
+
+	// Bind configuration
+	if a.Config.Bind != nil {
+		if err := env.Bind(a.Config.Bind); err != nil {
+			return fmt.Errorf("failed to bind config: %w", err)
+		}
+	}
+	if a.Config.Setup != nil {
+		if err := a.Config.Setup(a); err != nil {
+			return fmt.Errorf("failed to setup app: %w", err)
+		}
+	}
+
+	a.Logger = logger.Init(a.Config.Logger)
+
+	// Connect databases
+	if err := database.Init(a.Config.Databases...); err != nil {
+		return err
+	}
+	if a.Config.Redis != nil {
+		if err := database.InitRedis(*a.Config.Redis); err != nil {
+			return err
+		}
+	}
+
+	if err := validator.Init(a.Config.Translators...); err != nil {
+		return err
+	}
+
+	// Serve response messages and validation errors from the same bundles
+	if a.Config.I18n != nil {
+		if err := i18n.Init(*a.Config.I18n); err != nil {
+			return err
+		}
+		cfg := response.GetConfig()
+		cfg.Translate = i18n.ResponseMessage
+		cfg.TranslateErrors = i18n.ResponseErrors
+		response.Init(cfg)
+	}
+
+	return nil
+}
+
+// Run boots the app, serves HTTP and shuts down gracefully on SIGINT/SIGTERM
+func (a *App) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := a.Boot(ctx); err != nil {
+		// Stop components started before the failure, then release tasks and connections
+		return errors.Join(err, a.Shutdown(context.Background()))
+	}
+
+	// Registrations after this point would race request validation
+	if a.Config.FreezeValidator {
+		validator.Freeze()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		a.Logger.Info("http server listening", "addr", a.Config.Addr)
+		serveErr <- a.Fiber.Listen(a.Config.Addr)
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		a.Logger.Info("shutdown signal received")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.Config.ShutdownTimeout)
+	defer cancel()
+
+	if shutdownErr := a.Shutdown(shutdownCtx); shutdownErr != nil {
+		return errors.Join(err, shutdownErr)
+	}
+	return err
+}
+
+// Shutdown drains HTTP server and background tasks, runs stop hooks of started components and closes connections
+func (a *App) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	if err := a.Fiber.ShutdownWithContext(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to shutdown http server: %w", err))
+	}
+	// Tasks may use resources stop hooks release
+	if err := tasks.Drain(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := a.Lifecycle.Stop(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := database.CloseAll(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,43 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// State represents enabled maintenance mode
+type State struct {
+	Message string `json:"message"`
+	// RetryAfter is sent as Retry-After header in seconds, omitted when zero
+	RetryAfter int `json:"retry_after,omitempty"`
+	// Data is returned as envelope data, e.g. {"eta":"..."}
+	Data json.RawMessage `json:"data,omitempty"`
+	// Secret lets requests carrying it in BypassHeader or BypassCookie through, e.g. for smoke tests
+	Secret string    `json:"secret,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// Config represents maintenance middleware configuration
+type Config struct {
+	// Allow lists path prefixes served during maintenance, matched on path segments
+	Allow []string
+	// RefreshInterval caches the Redis flag in process to avoid a lookup per request, it is refreshed
+	// in the background so the first requests after start are served before the flag is known
+	RefreshInterval time.Duration
+	BypassHeader    string
+	BypassCookie    string
+}
+
+// DefaultConfig is used for empty Config fields
+var DefaultConfig = Config{
+	Allow:           []string{"/health", "/admin"},
+	RefreshInterval: 2 * time.Second,
+	BypassHeader:    "X-Maintenance-Bypass",
+	BypassCookie:    "nest_maintenance_bypass",
+}
+
+// DefaultMessage is used when State.Message is empty
+var DefaultMessage = "Service is under maintenance"
+
+// Key is Redis key holding State while maintenance is enabled
+var Key = "nest:maintenance"
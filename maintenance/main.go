@@ -0,0 +1,175 @@
+package maintenance
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/logger"
+	"github.com/rikiihsan/nest/response"
+)
+
+// ErrNoRedis is returned when Redis is not initialized
+var ErrNoRedis = errors.New("maintenance : redis client is not initialized")
+
+func client() (*redis.Client, error) {
+	if database.RedisClient == nil {
+		return nil, ErrNoRedis
+	}
+	return database.RedisClient, nil
+}
+
+// Enable turns maintenance mode on for all instances
+func Enable(ctx context.Context, state State) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	if state.Message == "" {
+		state.Message = DefaultMessage
+	}
+	if state.Since.IsZero() {
+		state.Since = time.Now()
+	}
+
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return rdb.Set(ctx, Key, raw, 0).Err()
+}
+
+// Disable turns maintenance mode off
+func Disable(ctx context.Context) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	return rdb.Del(ctx, Key).Err()
+}
+
+// Status returns state of enabled maintenance mode, nil when disabled
+func Status(ctx context.Context) (*State, error) {
+	rdb, err := client()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := rdb.Get(ctx, Key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := new(State)
+	if err := json.Unmarshal(raw, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// flag caches Status for the refresh interval, refreshing it in the background so requests never
+// wait on Redis
+type flag struct {
+	state      atomic.Pointer[State]
+	checked    atomic.Int64
+	refreshing atomic.Bool
+	interval   time.Duration
+}
+
+// get returns cached state, starting a refresh once it is older than the interval. Lookup failures
+// keep the app serving
+func (f *flag) get() *State {
+	if time.Since(time.Unix(0, f.checked.Load())) >= f.interval && f.refreshing.CompareAndSwap(false, true) {
+		go f.refresh()
+	}
+	return f.state.Load()
+}
+
+func (f *flag) refresh() {
+	defer f.refreshing.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), max(f.interval, time.Second))
+	defer cancel()
+
+	state, err := Status(ctx)
+	if err != nil {
+		logger.Get().WarnContext(ctx, "maintenance flag lookup failed", "error", err)
+		state = nil
+	}
+	f.state.Store(state)
+	f.checked.Store(time.Now().UnixNano())
+}
+
+// allowed reports whether path is prefix or lies below it, so /admin allows /admin/users but not /administrator
+func allowed(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// Middleware returns 503 with Retry-After for all requests except allowed paths while
+// maintenance mode is enabled, see Enable and the nest down/up commands
+func Middleware(configs ...Config) fiber.Handler {
+	cfg := DefaultConfig
+	if len(configs) > 0 {
+		cfg = configs[0]
+		if cfg.RefreshInterval <= 0 {
+			cfg.RefreshInterval = DefaultConfig.RefreshInterval
+		}
+		if cfg.BypassHeader == "" {
+			cfg.BypassHeader = DefaultConfig.BypassHeader
+		}
+		if cfg.BypassCookie == "" {
+			cfg.BypassCookie = DefaultConfig.BypassCookie
+		}
+	}
+	f := &flag{interval: cfg.RefreshInterval}
+
+	return func(c *fiber.Ctx) error {
+		for _, prefix := range cfg.Allow {
+			if allowed(c.Path(), prefix) {
+				return c.Next()
+			}
+		}
+
+		state := f.get()
+		if state == nil || bypassed(c, cfg, state.Secret) {
+			return c.Next()
+		}
+
+		if state.RetryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(state.RetryAfter))
+		}
+		var data interface{}
+		if len(state.Data) > 0 {
+			data = state.Data
+		}
+		return response.JSON(c, fiber.StatusServiceUnavailable, response.Envelope{
+			Status:  response.GetConfig().ErrorStatus,
+			Message: state.Message,
+			Data:    data,
+		})
+	}
+}
+
+// bypassed reports whether request carries maintenance secret
+func bypassed(c *fiber.Ctx, cfg Config, secret string) bool {
+	if secret == "" {
+		return false
+	}
+	for _, value := range []string{c.Get(cfg.BypassHeader), c.Cookies(cfg.BypassCookie)} {
+		if value != "" && subtle.ConstantTimeCompare([]byte(value), []byte(secret)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Config represents metrics configuration
+type Config struct {
+	// Path of metrics endpoint
+	Path string
+	// Namespace prefixes metric names
+	Namespace string
+	// Buckets of HTTP request duration histogram in seconds
+	Buckets []float64
+	// Registry defaults to a new registry with Go and process collectors
+	Registry *prometheus.Registry
+	// SkipPaths are not measured
+	SkipPaths []string
+}
@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/queue"
+)
+
+// cacheCounts holds hits and misses of a cache, exported by stackCollector under Config.Namespace
+type cacheCounts struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+var caches sync.Map
+
+func countsOf(cache string) *cacheCounts {
+	counts, _ := caches.LoadOrStore(cache, &cacheCounts{})
+	return counts.(*cacheCounts)
+}
+
+// CacheHit records cache hit, used by cache layers to expose hit ratio
+func CacheHit(cache string) {
+	countsOf(cache).hits.Add(1)
+}
+
+// CacheMiss records cache miss
+func CacheMiss(cache string) {
+	countsOf(cache).misses.Add(1)
+}
+
+// Register mounts metrics endpoint and HTTP instrumentation on app
+func Register(app *fiber.App, configs ...Config) *prometheus.Registry {
+	cfg := Config{}
+	if len(configs) > 0 {
+		cfg = configs[0]
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/metrics"
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = "nest"
+	}
+	if cfg.Buckets == nil {
+		cfg.Buckets = prometheus.DefBuckets
+	}
+	if cfg.Registry == nil {
+		cfg.Registry = prometheus.NewRegistry()
+		cfg.Registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	}
+
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: cfg.Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request duration by route, method and status.",
+		Buckets:   cfg.Buckets,
+	}, []string{"method", "route", "status"})
+	inflight := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: cfg.Namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Number of HTTP requests being served.",
+	})
+
+	cfg.Registry.MustRegister(duration, inflight, &stackCollector{namespace: cfg.Namespace})
+
+	skip := map[string]bool{cfg.Path: true}
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+
+	app.Use(func(c *fiber.Ctx) error {
+		if skip[c.Path()] {
+			return c.Next()
+		}
+
+		inflight.Inc()
+		defer inflight.Dec()
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+
+		// Route template keeps label cardinality bounded
+		duration.WithLabelValues(c.Method(), c.Route().Path, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+		return err
+	})
+
+	app.Get(cfg.Path, adaptor.HTTPHandler(promhttp.HandlerFor(cfg.Registry, promhttp.HandlerOpts{})))
+	return cfg.Registry
+}
+
+// stackCollector reads database, Redis and queue stats on scrape
+type stackCollector struct {
+	namespace string
+}
+
+func (s *stackCollector) desc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(s.namespace, "", name), help, labels, nil)
+}
+
+// Describe sends nothing so the collector is unchecked, sessions may change after registration
+func (s *stackCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (s *stackCollector) Collect(ch chan<- prometheus.Metric) {
+	caches.Range(func(name, value interface{}) bool {
+		counts := value.(*cacheCounts)
+		ch <- prometheus.MustNewConstMetric(s.desc("cache_hits_total", "Number of cache hits.", "cache"),
+			prometheus.CounterValue, float64(counts.hits.Load()), name.(string))
+		ch <- prometheus.MustNewConstMetric(s.desc("cache_misses_total", "Number of cache misses.", "cache"),
+			prometheus.CounterValue, float64(counts.misses.Load()), name.(string))
+		return true
+	})
+
+	for name, session := range database.GetAllSessions() {
+		stats := session.Stats()
+		gauge := func(metric, help string, value float64) {
+			ch <- prometheus.MustNewConstMetric(s.desc(metric, help, "session"), prometheus.GaugeValue, value, name)
+		}
+		counter := func(metric, help string, value float64) {
+			ch <- prometheus.MustNewConstMetric(s.desc(metric, help, "session"), prometheus.CounterValue, value, name)
+		}
+		gauge("db_open_connections", "Open database connections.", float64(stats.OpenConnections))
+		gauge("db_in_use_connections", "Database connections in use.", float64(stats.InUse))
+		gauge("db_idle_connections", "Idle database connections.", float64(stats.Idle))
+		gauge("db_max_open_connections", "Maximum open database connections.", float64(stats.MaxOpenConnections))
+		counter("db_wait_count_total", "Connections waited for.", float64(stats.WaitCount))
+		counter("db_wait_duration_seconds_total", "Time blocked waiting for connections.", stats.WaitDuration.Seconds())
+	}
+
+	for _, view := range database.MaterializedViews(context.Background()) {
+		labels := []string{view.Session, view.Name}
+		if !view.LastRefresh.IsZero() {
+			ch <- prometheus.MustNewConstMetric(s.desc("db_materialized_view_staleness_seconds", "Time since last successful materialized view refresh.", "session", "view"),
+				prometheus.GaugeValue, view.Staleness().Seconds(), labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(s.desc("db_materialized_view_refresh_duration_seconds", "Duration of last materialized view refresh.", "session", "view"),
+			prometheus.GaugeValue, view.LastDuration.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(s.desc("db_materialized_view_refresh_failures_total", "Failed materialized view refreshes.", "session", "view"),
+			prometheus.CounterValue, float64(view.Failures), labels...)
+	}
+
+	if database.RedisClient == nil {
+		return
+	}
+
+	stats := database.RedisClient.PoolStats()
+	ch <- prometheus.MustNewConstMetric(s.desc("redis_pool_total_connections", "Redis pool connections."), prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(s.desc("redis_pool_idle_connections", "Idle Redis pool connections."), prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(s.desc("redis_pool_hits_total", "Redis pool hits."), prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(s.desc("redis_pool_misses_total", "Redis pool misses."), prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(s.desc("redis_pool_timeouts_total", "Redis pool timeouts."), prometheus.CounterValue, float64(stats.Timeouts))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for _, name := range queue.GetConfig().Queues {
+		if size, err := queue.Size(ctx, name); err == nil {
+			ch <- prometheus.MustNewConstMetric(s.desc("queue_depth", "Jobs waiting in queue.", "queue"), prometheus.GaugeValue, float64(size), name)
+		}
+		if depth, err := queue.Depth(ctx, name); err == nil {
+			for priority, n := range depth {
+				ch <- prometheus.MustNewConstMetric(s.desc("queue_priority_depth", "Jobs waiting in queue per priority.", "queue", "priority"),
+					prometheus.GaugeValue, float64(n), name, string(priority))
+			}
+		}
+	}
+}
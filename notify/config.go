@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/mailer"
+	"github.com/uptrace/bun"
+)
+
+// Channel names a delivery channel
+type Channel string
+
+const (
+	ChannelMail     Channel = "mail"
+	ChannelSMS      Channel = "sms"
+	ChannelWhatsApp Channel = "whatsapp"
+	ChannelPush     Channel = "push"
+	ChannelWebPush  Channel = "webpush"
+	ChannelDatabase Channel = "database"
+)
+
+// Notifiable is recipient of notifications, usually a user model
+type Notifiable interface {
+	// NotifiableID identifies recipient for preferences and in-app notifications
+	NotifiableID() string
+	// NotificationRoutes returns addresses of channel, e.g. emails, phone numbers or device tokens
+	NotificationRoutes(ch Channel) []string
+}
+
+// Notification is channel-agnostic message. It implements To* methods of the channels it uses
+type Notification interface {
+	// Type names notification for preferences, e.g. "invoice.paid"
+	Type() string
+	// Channels returns channels notification is sent on for recipient
+	Channels(to Notifiable) []Channel
+}
+
+// MailNotification renders mail channel message
+type MailNotification interface {
+	ToMail(to Notifiable) (*mailer.Message, error)
+}
+
+// SMSNotification renders sms and whatsapp channel message
+type SMSNotification interface {
+	ToSMS(to Notifiable) (*SMSMessage, error)
+}
+
+// PushNotification renders push and webpush channel message
+type PushNotification interface {
+	ToPush(to Notifiable) (*PushMessage, error)
+}
+
+// DatabaseNotification renders data of in-app notification
+type DatabaseNotification interface {
+	ToDatabase(to Notifiable) (map[string]interface{}, error)
+}
+
+// Formatter renders payload of custom channels
+type Formatter interface {
+	Format(ch Channel, to Notifiable) (interface{}, error)
+}
+
+// SMSMessage represents text message
+type SMSMessage struct {
+	From string `json:"from,omitempty"`
+	Text string `json:"text"`
+}
+
+// PushMessage represents mobile or browser push message
+type PushMessage struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Icon  string            `json:"icon,omitempty"`
+	URL   string            `json:"url,omitempty"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// Delivery is notification rendered for one channel, the unit sent by drivers and queued jobs
+type Delivery struct {
+	Type        string          `json:"type"`
+	Channel     Channel         `json:"channel"`
+	RecipientID string          `json:"recipient_id"`
+	Routes      []string        `json:"routes,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// Bind decodes delivery payload into dst
+func (d *Delivery) Bind(dst interface{}) error {
+	return json.Unmarshal(d.Payload, dst)
+}
+
+// Driver sends deliveries of a channel. Drivers sending to several routes may drop routes that were
+// sent from Routes when returning an error, queued deliveries are then retried for remaining routes only
+type Driver interface {
+	Send(ctx context.Context, d *Delivery) error
+}
+
+// Record represents persisted in-app notification
+type Record struct {
+	bun.BaseModel `bun:"table:notifications,alias:ntf"`
+
+	ID          int64      `bun:",pk,autoincrement" json:"id"`
+	RecipientID string     `bun:",notnull" json:"-"`
+	Type        string     `bun:",notnull" json:"type"`
+	Data        string     `bun:",notnull" json:"-"`
+	ReadAt      *time.Time `bun:",nullzero" json:"read_at"`
+	CreatedAt   time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// MarshalJSON exposes Data as JSON object
+func (r Record) MarshalJSON() ([]byte, error) {
+	type record Record
+	return json.Marshal(struct {
+		record
+		Data json.RawMessage `json:"data"`
+	}{record(r), json.RawMessage(r.Data)})
+}
+
+// Preference enables or disables channel of notification type for recipient, type "*" matches all types
+type Preference struct {
+	bun.BaseModel `bun:"table:notification_preferences,alias:ntp"`
+
+	RecipientID string  `bun:",pk" json:"-"`
+	Type        string  `bun:",pk" json:"type"`
+	Channel     Channel `bun:",pk" json:"channel"`
+	Enabled     bool    `bun:",notnull" json:"enabled"`
+}
+
+// DeliveryStatus is provider reported state of a text message
+type DeliveryStatus string
+
+// Delivery statuses in the order providers report them, failed and read are final
+const (
+	StatusQueued    DeliveryStatus = "queued"
+	StatusSent      DeliveryStatus = "sent"
+	StatusDelivered DeliveryStatus = "delivered"
+	StatusRead      DeliveryStatus = "read"
+	StatusFailed    DeliveryStatus = "failed"
+)
+
+// DeliveryLog represents text message handed to a provider, updated by its status webhooks
+type DeliveryLog struct {
+	bun.BaseModel `bun:"table:notification_deliveries,alias:ntd"`
+
+	ID          int64          `bun:",pk,autoincrement" json:"id"`
+	Provider    string         `bun:",notnull" json:"provider"`
+	MessageID   string         `bun:",nullzero" json:"message_id,omitempty"`
+	Channel     Channel        `bun:",notnull" json:"channel"`
+	Type        string         `bun:",notnull" json:"type"`
+	RecipientID string         `bun:",notnull" json:"recipient_id"`
+	Route       string         `bun:",notnull" json:"route"`
+	Status      DeliveryStatus `bun:",notnull" json:"status"`
+	Error       string         `bun:",nullzero" json:"error,omitempty"`
+	CreatedAt   time.Time      `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt   time.Time      `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+// Config represents notification configuration
+type Config struct {
+	// Drivers by channel, ChannelMail and ChannelDatabase are registered by default
+	Drivers map[Channel]Driver
+	// Session stores in-app notifications and preferences
+	Session string
+	// Preferences filters channels by rows of notification_preferences
+	Preferences bool
+	// DeliveryLog records messages of text drivers in notification_deliveries for status webhooks
+	DeliveryLog bool
+	// Queue is queue name used by Queue, defaults to queue default
+	Queue string
+	// MaxRetries for queued deliveries, zero uses queue default
+	MaxRetries int
+	// RecipientFunc identifies recipient of requests for in-app and preference endpoints
+	RecipientFunc func(c *fiber.Ctx) string
+}
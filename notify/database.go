@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/realtime"
+	"github.com/uptrace/bun"
+)
+
+// DatabaseDriver stores in-app notifications, broadcasting them on Hub when set
+type DatabaseDriver struct {
+	Hub *realtime.Hub
+	// Channel returns realtime channel of recipient, defaults to "notifications.{id}"
+	Channel func(recipientID string) string
+}
+
+// Send inserts notification record of delivery
+func (d DatabaseDriver) Send(ctx context.Context, delivery *Delivery) error {
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return err
+	}
+
+	record := &Record{
+		RecipientID: delivery.RecipientID,
+		Type:        delivery.Type,
+		Data:        string(delivery.Payload),
+		CreatedAt:   time.Now(),
+	}
+	if _, err := db.NewInsert().Model(record).Exec(ctx); err != nil {
+		return err
+	}
+
+	if d.Hub != nil {
+		channel := "notifications." + delivery.RecipientID
+		if d.Channel != nil {
+			channel = d.Channel(delivery.RecipientID)
+		}
+		database.AfterCommit(ctx, func(ctx context.Context) {
+			d.Hub.Broadcast(ctx, channel, "notification", record)
+		})
+	}
+	return nil
+}
+
+// List returns latest in-app notifications of recipient, only unread ones when unread is set
+func List(ctx context.Context, recipientID string, unread bool, limit, offset int) ([]Record, int, error) {
+	db, err := database.Reader(ctx, GetConfig().Session)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var records []Record
+	q := db.NewSelect().Model(&records).Where("recipient_id = ?", recipientID)
+	if unread {
+		q = q.Where("read_at IS NULL")
+	}
+	total, err := q.Order("created_at DESC", "id DESC").Limit(limit).Offset(offset).ScanAndCount(ctx)
+	return records, total, err
+}
+
+// UnreadCount returns number of unread in-app notifications of recipient
+func UnreadCount(ctx context.Context, recipientID string) (int, error) {
+	db, err := database.Reader(ctx, GetConfig().Session)
+	if err != nil {
+		return 0, err
+	}
+	return db.NewSelect().Model((*Record)(nil)).
+		Where("recipient_id = ?", recipientID).
+		Where("read_at IS NULL").
+		Count(ctx)
+}
+
+// MarkRead marks notifications of recipient as read, all unread ones when ids are empty
+func MarkRead(ctx context.Context, recipientID string, ids ...int64) (int64, error) {
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return 0, err
+	}
+
+	q := db.NewUpdate().Model((*Record)(nil)).
+		Set("read_at = ?", time.Now()).
+		Where("recipient_id = ?", recipientID).
+		Where("read_at IS NULL")
+	if len(ids) > 0 {
+		q = q.Where("id IN (?)", bun.In(ids))
+	}
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
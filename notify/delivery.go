@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+)
+
+// ErrInvalidSignature is returned by status webhooks failing provider signature checks
+var ErrInvalidSignature = errors.New("notify : invalid webhook signature")
+
+// statusRank orders statuses so late webhooks don't move messages back
+var statusRank = map[DeliveryStatus]int{
+	StatusQueued:    0,
+	StatusSent:      1,
+	StatusDelivered: 2,
+	StatusFailed:    2,
+	StatusRead:      3,
+}
+
+// textSender sends text to one route, returning provider message ID and status
+type textSender func(ctx context.Context, to string, msg *SMSMessage) (string, DeliveryStatus, error)
+
+// sendText sends sms or whatsapp delivery to every route, recording messages when Config.DeliveryLog is set.
+// A failing route doesn't stop the others, Routes keeps only failed routes so retries skip sent ones
+func sendText(ctx context.Context, provider string, delivery *Delivery, from string, send textSender) error {
+	var msg SMSMessage
+	if err := delivery.Bind(&msg); err != nil {
+		return err
+	}
+	if len(delivery.Routes) == 0 {
+		return ErrNoRoutes
+	}
+	if msg.From == "" {
+		msg.From = from
+	}
+
+	var failed []string
+	var errs []error
+	for _, to := range delivery.Routes {
+		id, status, err := send(ctx, to, &msg)
+		logDelivery(ctx, provider, delivery, to, id, status, err)
+		if err != nil {
+			failed = append(failed, to)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		delivery.Routes = failed
+	}
+	return errors.Join(errs...)
+}
+
+// logDelivery records sent message, failures are only logged since the message already left
+func logDelivery(ctx context.Context, provider string, delivery *Delivery, route, id string, status DeliveryStatus, sendErr error) {
+	if !GetConfig().DeliveryLog {
+		return
+	}
+
+	now := time.Now()
+	entry := &DeliveryLog{
+		Provider:    provider,
+		MessageID:   id,
+		Channel:     delivery.Channel,
+		Type:        delivery.Type,
+		RecipientID: delivery.RecipientID,
+		Route:       route,
+		Status:      status,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if entry.Status == "" {
+		entry.Status = StatusQueued
+	}
+	if sendErr != nil {
+		entry.Status, entry.Error = StatusFailed, sendErr.Error()
+	}
+
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err == nil {
+		_, err = db.NewInsert().Model(entry).Exec(ctx)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to log notification delivery", "provider", provider, "message_id", id, "error", err)
+	}
+}
+
+// UpdateDeliveryStatus applies status reported by provider to logged message, statuses never move
+// back, e.g. a late "sent" after "delivered". Returns whether a message was updated
+func UpdateDeliveryStatus(ctx context.Context, provider, messageID string, status DeliveryStatus, reason string) (bool, error) {
+	rank, ok := statusRank[status]
+	if !ok || messageID == "" {
+		return false, nil
+	}
+	var earlier []DeliveryStatus
+	for s, r := range statusRank {
+		if r < rank {
+			earlier = append(earlier, s)
+		}
+	}
+	if len(earlier) == 0 {
+		return false, nil
+	}
+
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return false, err
+	}
+	q := db.NewUpdate().Model((*DeliveryLog)(nil)).
+		Set("status = ?", status).
+		Set("updated_at = ?", time.Now()).
+		Where("provider = ?", provider).
+		Where("message_id = ?", messageID).
+		Where("status IN (?)", bun.In(earlier))
+	if reason != "" {
+		q = q.Set("error = ?", reason)
+	}
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Deliveries returns latest logged text messages of recipient
+func Deliveries(ctx context.Context, recipientID string, limit int) ([]DeliveryLog, error) {
+	db, err := database.Reader(ctx, GetConfig().Session)
+	if err != nil {
+		return nil, err
+	}
+	var logs []DeliveryLog
+	err = db.NewSelect().Model(&logs).
+		Where("recipient_id = ?", recipientID).
+		Order("created_at DESC", "id DESC").
+		Limit(limit).
+		Scan(ctx)
+	return logs, err
+}
+
+// call executes provider request, decoding JSON response into out when set
+func call(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := httpClient(client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return providerError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
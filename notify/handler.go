@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/response"
+)
+
+type readRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+type preferenceRequest struct {
+	Type    string  `json:"type"`
+	Channel Channel `json:"channel"`
+	Enabled bool    `json:"enabled"`
+}
+
+// RegisterRoutes mounts in-app notification and preference endpoints of the requesting recipient,
+// resolved by Config.RecipientFunc
+//
+//	GET  /notifications?unread=true&page=1&per_page=20
+//	GET  /notifications/unread-count
+//	POST /notifications/read          {"ids": [1, 2]}, empty marks all
+//	GET  /notifications/preferences
+//	PUT  /notifications/preferences   {"type": "*", "channel": "mail", "enabled": false}
+func RegisterRoutes(router fiber.Router) {
+	group := router.Group("/notifications", func(c *fiber.Ctx) error {
+		if recipient(c) == "" {
+			return response.Error(c, fiber.StatusUnauthorized, errors.New("unauthenticated"))
+		}
+		return c.Next()
+	})
+	group.Get("/", listHandler)
+	group.Get("/unread-count", unreadCountHandler)
+	group.Post("/read", readHandler)
+	group.Get("/preferences", preferencesHandler)
+	group.Put("/preferences", setPreferenceHandler)
+}
+
+func recipient(c *fiber.Ctx) string {
+	cfg := GetConfig()
+	if cfg.RecipientFunc == nil {
+		return ""
+	}
+	return cfg.RecipientFunc(c)
+}
+
+func listHandler(c *fiber.Ctx) error {
+	page := max(c.QueryInt("page", 1), 1)
+	perPage := min(max(c.QueryInt("per_page", 20), 1), 100)
+
+	records, total, err := List(c.UserContext(), recipient(c), c.QueryBool("unread"), perPage, (page-1)*perPage)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, records, fiber.Map{"page": page, "per_page": perPage, "total": total})
+}
+
+func unreadCountHandler(c *fiber.Ctx) error {
+	count, err := UnreadCount(c.UserContext(), recipient(c))
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, fiber.Map{"count": count}, nil)
+}
+
+func readHandler(c *fiber.Ctx) error {
+	var req readRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, err)
+		}
+	}
+
+	updated, err := MarkRead(c.UserContext(), recipient(c), req.IDs...)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, fiber.Map{"updated": updated}, nil)
+}
+
+func preferencesHandler(c *fiber.Ctx) error {
+	prefs, err := Preferences(c.UserContext(), recipient(c))
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, prefs, nil)
+}
+
+func setPreferenceHandler(c *fiber.Ctx) error {
+	var req preferenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, err)
+	}
+	if req.Type == "" || req.Channel == "" {
+		return response.Error(c, fiber.StatusBadRequest, errors.New("type and channel are required"))
+	}
+
+	if err := SetPreference(c.UserContext(), recipient(c), req.Type, req.Channel, req.Enabled); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, req, nil)
+}
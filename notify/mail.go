@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/rikiihsan/nest/mailer"
+)
+
+// MailDriver sends mail deliveries through the mailer, routes are used when message has no recipients
+type MailDriver struct{}
+
+// Send sends mail delivery
+func (MailDriver) Send(ctx context.Context, d *Delivery) error {
+	var msg mailer.Message
+	if err := d.Bind(&msg); err != nil {
+		return err
+	}
+	if len(msg.Recipients()) == 0 {
+		if len(d.Routes) == 0 {
+			return ErrNoRoutes
+		}
+		msg.To = d.Routes
+	}
+	return mailer.Send(ctx, &msg)
+}
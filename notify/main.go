@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rikiihsan/nest/queue"
+)
+
+// JobType is queue job type of queued deliveries
+const JobType = "notify:send"
+
+var (
+	ErrNoDriver    = errors.New("notify : no driver registered for channel")
+	ErrUnsupported = errors.New("notify : notification does not support channel")
+	ErrNoRoutes    = errors.New("notify : recipient has no routes for channel")
+)
+
+var (
+	mu      sync.RWMutex
+	config  = Config{Session: "default"}
+	drivers = map[Channel]Driver{
+		ChannelMail:     MailDriver{},
+		ChannelDatabase: DatabaseDriver{},
+	}
+)
+
+func init() {
+	queue.Register(JobType, func(ctx context.Context, job *queue.Job) error {
+		var d Delivery
+		if err := job.Bind(&d); err != nil {
+			return fmt.Errorf("failed to decode delivery: %w", err)
+		}
+		routes := len(d.Routes)
+		err := deliver(ctx, &d)
+		// retries carry the job payload, so they only send to routes that failed
+		if err != nil && len(d.Routes) != routes {
+			if payload, merr := json.Marshal(&d); merr == nil {
+				job.Payload = payload
+			}
+		}
+		return err
+	})
+}
+
+// Init sets notification configuration and registers its drivers
+func Init(cfg Config) {
+	if cfg.Session == "" {
+		cfg.Session = "default"
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	config = cfg
+	for ch, driver := range cfg.Drivers {
+		drivers[ch] = driver
+	}
+}
+
+// GetConfig returns current notification configuration
+func GetConfig() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config
+}
+
+// RegisterDriver registers driver of channel, replacing existing one
+func RegisterDriver(ch Channel, driver Driver) {
+	mu.Lock()
+	defer mu.Unlock()
+	drivers[ch] = driver
+}
+
+func driverFor(ch Channel) (Driver, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	driver, ok := drivers[ch]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoDriver, ch)
+	}
+	return driver, nil
+}
+
+// Send renders notification for each enabled channel and sends it immediately.
+// Failing channels do not stop the others, their errors are joined
+func Send(ctx context.Context, to Notifiable, n Notification) error {
+	deliveries, err := Render(ctx, to, n)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, d := range deliveries {
+		if err := deliver(ctx, d); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", d.Channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Queue renders notification for each enabled channel and enqueues one job per channel,
+// so channels are retried independently
+func Queue(ctx context.Context, to Notifiable, n Notification, opts ...queue.Option) ([]*queue.Job, error) {
+	deliveries, err := Render(ctx, to, n)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := GetConfig()
+	defaults := []queue.Option{}
+	if cfg.Queue != "" {
+		defaults = append(defaults, queue.OnQueue(cfg.Queue))
+	}
+	if cfg.MaxRetries > 0 {
+		defaults = append(defaults, queue.WithMaxRetries(cfg.MaxRetries))
+	}
+
+	jobs := make([]*queue.Job, 0, len(deliveries))
+	for _, d := range deliveries {
+		job, err := queue.Enqueue(ctx, JobType, d, append(defaults, opts...)...)
+		if err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Render returns deliveries of notification for channels enabled by recipient preferences
+func Render(ctx context.Context, to Notifiable, n Notification) ([]*Delivery, error) {
+	channels, err := Enabled(ctx, to.NotifiableID(), n.Type(), n.Channels(to))
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*Delivery, 0, len(channels))
+	for _, ch := range channels {
+		payload, err := format(ch, to, n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s notification: %w", ch, err)
+		}
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &Delivery{
+			Type:        n.Type(),
+			Channel:     ch,
+			RecipientID: to.NotifiableID(),
+			Routes:      to.NotificationRoutes(ch),
+			Payload:     raw,
+		})
+	}
+	return deliveries, nil
+}
+
+// format renders payload of channel through the matching To* method, falling back to Formatter
+func format(ch Channel, to Notifiable, n Notification) (interface{}, error) {
+	switch ch {
+	case ChannelMail:
+		if m, ok := n.(MailNotification); ok {
+			return m.ToMail(to)
+		}
+	case ChannelSMS, ChannelWhatsApp:
+		if m, ok := n.(SMSNotification); ok {
+			return m.ToSMS(to)
+		}
+	case ChannelPush, ChannelWebPush:
+		if m, ok := n.(PushNotification); ok {
+			return m.ToPush(to)
+		}
+	case ChannelDatabase:
+		if m, ok := n.(DatabaseNotification); ok {
+			return m.ToDatabase(to)
+		}
+	}
+	if f, ok := n.(Formatter); ok {
+		return f.Format(ch, to)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupported, ch)
+}
+
+func deliver(ctx context.Context, d *Delivery) error {
+	driver, err := driverFor(d.Channel)
+	if err != nil {
+		return err
+	}
+	return driver.Send(ctx, d)
+}
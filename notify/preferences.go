@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// AllTypes matches every notification type in preferences
+const AllTypes = "*"
+
+// CreateTables creates notification tables if they do not exist
+func CreateTables(ctx context.Context, db bun.IDB) error {
+	models := []interface{}{(*Record)(nil), (*Preference)(nil), (*DeliveryLog)(nil)}
+	for _, model := range models {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return err
+		}
+	}
+	_, err := db.NewCreateIndex().Model((*Record)(nil)).Index("notifications_recipient_idx").
+		Column("recipient_id", "created_at").IfNotExists().Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = db.NewCreateIndex().Model((*DeliveryLog)(nil)).Index("notification_deliveries_message_idx").
+		Column("provider", "message_id").IfNotExists().Exec(ctx)
+	return err
+}
+
+// Enabled filters channels by preferences of recipient, preferences of the type override AllTypes.
+// Channels without preference stay enabled
+func Enabled(ctx context.Context, recipientID, typ string, channels []Channel) ([]Channel, error) {
+	cfg := GetConfig()
+	if !cfg.Preferences || recipientID == "" || len(channels) == 0 {
+		return channels, nil
+	}
+
+	prefs, err := Preferences(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[Channel]bool)
+	for _, pref := range prefs {
+		if pref.Type == AllTypes {
+			enabled[pref.Channel] = pref.Enabled
+		}
+	}
+	for _, pref := range prefs {
+		if pref.Type == typ {
+			enabled[pref.Channel] = pref.Enabled
+		}
+	}
+
+	out := make([]Channel, 0, len(channels))
+	for _, ch := range channels {
+		if on, ok := enabled[ch]; !ok || on {
+			out = append(out, ch)
+		}
+	}
+	return out, nil
+}
+
+// Preferences returns stored preferences of recipient
+func Preferences(ctx context.Context, recipientID string) ([]Preference, error) {
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefs []Preference
+	err = db.NewSelect().Model(&prefs).Where("recipient_id = ?", recipientID).Order("type", "channel").Scan(ctx)
+	return prefs, err
+}
+
+// SetPreference enables or disables channel of notification type for recipient
+func SetPreference(ctx context.Context, recipientID, typ string, ch Channel, enabled bool) error {
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return err
+	}
+
+	pref := &Preference{RecipientID: recipientID, Type: typ, Channel: ch, Enabled: enabled}
+	query := db.NewInsert().Model(pref)
+	switch db.Dialect().Name() {
+	case dialect.MySQL:
+		query = query.On("DUPLICATE KEY UPDATE").Set("enabled = VALUES(enabled)")
+	case dialect.MSSQL:
+		if _, err := db.NewDelete().Model(pref).WherePK().Exec(ctx); err != nil {
+			return err
+		}
+	default:
+		query = query.On("CONFLICT (recipient_id, type, channel) DO UPDATE").Set("enabled = EXCLUDED.enabled")
+	}
+	_, err = query.Exec(ctx)
+	return err
+}
@@ -0,0 +1,253 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
+
+// providerError turns non-2xx responses into errors
+func providerError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// signJWT returns compact JWT of header and claims signed by sign
+func signJWT(header, claims interface{}, sign func(digest []byte) ([]byte, error)) (string, error) {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := sign(digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// FCMDriver sends push deliveries to device tokens through Firebase Cloud Messaging HTTP v1 API
+type FCMDriver struct {
+	// Credentials is service account JSON key
+	Credentials []byte
+	// ProjectID defaults to project of Credentials
+	ProjectID string
+	// OnInvalidToken is called for tokens FCM reports unregistered, e.g. to delete them
+	OnInvalidToken func(ctx context.Context, token string)
+	// Endpoint overrides https://fcm.googleapis.com
+	Endpoint string
+	Client   *http.Client
+
+	mu      sync.Mutex
+	account *serviceAccount
+	token   string
+	expiry  time.Time
+}
+
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+	key         *rsa.PrivateKey
+}
+
+// Send sends push delivery to every route
+func (d *FCMDriver) Send(ctx context.Context, delivery *Delivery) error {
+	var msg PushMessage
+	if err := delivery.Bind(&msg); err != nil {
+		return err
+	}
+	if len(delivery.Routes) == 0 {
+		return ErrNoRoutes
+	}
+
+	token, err := d.accessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	data := make(map[string]string, len(msg.Data)+1)
+	for k, v := range msg.Data {
+		data[k] = v
+	}
+	if msg.URL != "" {
+		data["url"] = msg.URL
+	}
+
+	endpoint := d.Endpoint
+	if endpoint == "" {
+		endpoint = "https://fcm.googleapis.com"
+	}
+	endpoint += "/v1/projects/" + d.projectID() + "/messages:send"
+
+	for _, route := range delivery.Routes {
+		body, err := json.Marshal(map[string]interface{}{
+			"message": map[string]interface{}{
+				"token": route,
+				"notification": map[string]string{
+					"title": msg.Title,
+					"body":  msg.Body,
+				},
+				"data": data,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := httpClient(d.Client).Do(req)
+		if err != nil {
+			return err
+		}
+		switch {
+		case resp.StatusCode == http.StatusNotFound && d.OnInvalidToken != nil:
+			d.OnInvalidToken(ctx, route)
+		case resp.StatusCode >= 300:
+			err = providerError(resp)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *FCMDriver) projectID() string {
+	if d.ProjectID != "" {
+		return d.ProjectID
+	}
+	if d.account != nil {
+		return d.account.ProjectID
+	}
+	return ""
+}
+
+// accessToken returns cached OAuth2 token, exchanging a service account JWT when it expires
+func (d *FCMDriver) accessToken(ctx context.Context) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.token != "" && time.Until(d.expiry) > time.Minute {
+		return d.token, nil
+	}
+	if d.account == nil {
+		account, err := parseServiceAccount(d.Credentials)
+		if err != nil {
+			return "", err
+		}
+		d.account = account
+	}
+
+	now := time.Now()
+	assertion, err := signJWT(
+		map[string]string{"alg": "RS256", "typ": "JWT"},
+		map[string]interface{}{
+			"iss":   d.account.ClientEmail,
+			"scope": fcmScope,
+			"aud":   d.account.TokenURI,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		},
+		func(digest []byte) ([]byte, error) {
+			return rsa.SignPKCS1v15(rand.Reader, d.account.key, crypto.SHA256, digest)
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient(d.Client).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", providerError(resp)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	d.token = token.AccessToken
+	d.expiry = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return d.token, nil
+}
+
+func parseServiceAccount(credentials []byte) (*serviceAccount, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, fmt.Errorf("invalid service account: %w", err)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, errors.New("notify : service account has no PEM private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("notify : service account key is not RSA")
+	}
+	account.key = rsaKey
+	return &account, nil
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+)
+
+// SMSSender sends text message to phone number through a provider
+type SMSSender interface {
+	SendSMS(ctx context.Context, to string, msg *SMSMessage) error
+}
+
+// SMSDriver sends sms deliveries to every route through Sender
+type SMSDriver struct {
+	Sender SMSSender
+	// From is used when message has no sender
+	From string
+}
+
+// Send sends sms delivery
+func (d SMSDriver) Send(ctx context.Context, delivery *Delivery) error {
+	var msg SMSMessage
+	if err := delivery.Bind(&msg); err != nil {
+		return err
+	}
+	if len(delivery.Routes) == 0 {
+		return ErrNoRoutes
+	}
+	if msg.From == "" {
+		msg.From = d.From
+	}
+
+	for _, to := range delivery.Routes {
+		if err := d.Sender.SendSMS(ctx, to, &msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/response"
+)
+
+// TwilioDriver sends sms and whatsapp deliveries through Twilio Programmable Messaging,
+// register it for ChannelSMS and ChannelWhatsApp
+type TwilioDriver struct {
+	AccountSID string
+	AuthToken  string
+	// From is sender number, used when message has no sender
+	From string
+	// MessagingServiceSID sends through a messaging service instead of From
+	MessagingServiceSID string
+	// StatusURL is public URL of StatusHandler, sent as StatusCallback and used to verify signatures
+	StatusURL string
+	// Endpoint overrides https://api.twilio.com
+	Endpoint string
+	Client   *http.Client
+}
+
+// Send sends delivery to every route
+func (d *TwilioDriver) Send(ctx context.Context, delivery *Delivery) error {
+	endpoint := d.Endpoint
+	if endpoint == "" {
+		endpoint = "https://api.twilio.com"
+	}
+	endpoint += "/2010-04-01/Accounts/" + d.AccountSID + "/Messages.json"
+
+	return sendText(ctx, "twilio", delivery, d.From, func(ctx context.Context, to string, msg *SMSMessage) (string, DeliveryStatus, error) {
+		form := url.Values{"To": {twilioAddress(delivery.Channel, to)}, "Body": {msg.Text}}
+		if d.MessagingServiceSID != "" && msg.From == d.From {
+			form.Set("MessagingServiceSid", d.MessagingServiceSID)
+		} else {
+			form.Set("From", twilioAddress(delivery.Channel, msg.From))
+		}
+		if d.StatusURL != "" {
+			form.Set("StatusCallback", d.StatusURL)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", "", err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(d.AccountSID, d.AuthToken)
+
+		var out struct {
+			SID    string `json:"sid"`
+			Status string `json:"status"`
+		}
+		if err := call(d.Client, req, &out); err != nil {
+			return "", "", err
+		}
+		return out.SID, twilioStatus(out.Status), nil
+	})
+}
+
+// twilioAddress prefixes whatsapp routes as Twilio expects
+func twilioAddress(ch Channel, address string) string {
+	if ch == ChannelWhatsApp && !strings.HasPrefix(address, "whatsapp:") {
+		return "whatsapp:" + address
+	}
+	return address
+}
+
+func twilioStatus(status string) DeliveryStatus {
+	switch status {
+	case "accepted", "scheduled", "queued", "sending":
+		return StatusQueued
+	case "sent":
+		return StatusSent
+	case "delivered":
+		return StatusDelivered
+	case "read":
+		return StatusRead
+	case "undelivered", "failed", "canceled":
+		return StatusFailed
+	}
+	return ""
+}
+
+// StatusHandler returns fiber handler of Twilio status callbacks updating the delivery log
+func (d *TwilioDriver) StatusHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params := map[string]string{}
+		c.Request().PostArgs().VisitAll(func(key, value []byte) {
+			params[string(key)] = string(value)
+		})
+
+		statusURL := d.StatusURL
+		if statusURL == "" {
+			statusURL = c.BaseURL() + c.OriginalURL()
+		}
+		if !hmac.Equal([]byte(c.Get("X-Twilio-Signature")), []byte(twilioSignature(d.AuthToken, statusURL, params))) {
+			return response.Error(c, fiber.StatusForbidden, ErrInvalidSignature)
+		}
+
+		reason := ""
+		if code := params["ErrorCode"]; code != "" {
+			reason = "twilio error " + code
+		}
+		if _, err := UpdateDeliveryStatus(c.UserContext(), "twilio", params["MessageSid"], twilioStatus(params["MessageStatus"]), reason); err != nil {
+			return response.Error(c, fiber.StatusInternalServerError, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// twilioSignature returns base64 HMAC-SHA1 of URL followed by sorted POST parameters
+func twilioSignature(token, url string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mac := hmac.New(sha1.New, []byte(token))
+	mac.Write([]byte(url))
+	for _, key := range keys {
+		mac.Write([]byte(key + params[key]))
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
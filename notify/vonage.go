@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/response"
+)
+
+// VonageDriver sends sms and whatsapp deliveries through Vonage Messages API,
+// register it for ChannelSMS and ChannelWhatsApp
+type VonageDriver struct {
+	APIKey    string
+	APISecret string
+	// From is sender number or alphanumeric ID, used when message has no sender
+	From string
+	// SignatureSecret verifies JWT signed status webhooks, StatusHandler rejects every webhook without it
+	SignatureSecret string
+	// Tolerance rejects status webhooks whose token was issued longer ago, defaults to 5 minutes
+	Tolerance time.Duration
+	// Endpoint overrides https://api.nexmo.com
+	Endpoint string
+	Client   *http.Client
+}
+
+// Send sends delivery to every route
+func (d *VonageDriver) Send(ctx context.Context, delivery *Delivery) error {
+	endpoint := d.Endpoint
+	if endpoint == "" {
+		endpoint = "https://api.nexmo.com"
+	}
+	endpoint += "/v1/messages"
+
+	channel := "sms"
+	if delivery.Channel == ChannelWhatsApp {
+		channel = "whatsapp"
+	}
+
+	return sendText(ctx, "vonage", delivery, d.From, func(ctx context.Context, to string, msg *SMSMessage) (string, DeliveryStatus, error) {
+		body, err := json.Marshal(map[string]string{
+			"message_type": "text",
+			"channel":      channel,
+			"to":           strings.TrimPrefix(to, "+"),
+			"from":         strings.TrimPrefix(msg.From, "+"),
+			"text":         msg.Text,
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return "", "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.SetBasicAuth(d.APIKey, d.APISecret)
+
+		var out struct {
+			MessageUUID string `json:"message_uuid"`
+		}
+		if err := call(d.Client, req, &out); err != nil {
+			return "", "", err
+		}
+		return out.MessageUUID, StatusQueued, nil
+	})
+}
+
+func vonageStatus(status string) DeliveryStatus {
+	switch status {
+	case "submitted":
+		return StatusSent
+	case "delivered":
+		return StatusDelivered
+	case "read":
+		return StatusRead
+	case "rejected", "undeliverable":
+		return StatusFailed
+	}
+	return ""
+}
+
+// StatusHandler returns fiber handler of Vonage message status webhooks updating the delivery log
+func (d *VonageDriver) StatusHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tolerance := d.Tolerance
+		if tolerance <= 0 {
+			tolerance = 5 * time.Minute
+		}
+		if d.SignatureSecret == "" || !verifyVonageJWT(d.SignatureSecret, c.Get(fiber.HeaderAuthorization), c.Body(), tolerance) {
+			return response.Error(c, fiber.StatusForbidden, ErrInvalidSignature)
+		}
+
+		var event struct {
+			MessageUUID string `json:"message_uuid"`
+			Status      string `json:"status"`
+			Error       struct {
+				Title  string `json:"title"`
+				Detail string `json:"detail"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(c.Body(), &event); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, err)
+		}
+
+		reason := strings.TrimSpace(event.Error.Title + " " + event.Error.Detail)
+		if _, err := UpdateDeliveryStatus(c.UserContext(), "vonage", event.MessageUUID, vonageStatus(event.Status), reason); err != nil {
+			return response.Error(c, fiber.StatusInternalServerError, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// verifyVonageJWT checks HS256 bearer token of webhook, its payload_hash claim against body and that
+// it was issued within tolerance and hasn't expired, so captured tokens can't be replayed
+func verifyVonageJWT(secret, authorization string, body []byte, tolerance time.Duration) bool {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return false
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(raw, &header) != nil || header.Alg != "HS256" {
+		return false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return false
+	}
+
+	var claims struct {
+		PayloadHash string `json:"payload_hash"`
+		IssuedAt    int64  `json:"iat"`
+		Expires     int64  `json:"exp"`
+	}
+	raw, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(raw, &claims) != nil || claims.PayloadHash == "" {
+		return false
+	}
+	now := time.Now()
+	if issued := time.Unix(claims.IssuedAt, 0); claims.IssuedAt == 0 || now.Sub(issued).Abs() > tolerance {
+		return false
+	}
+	if claims.Expires != 0 && !now.Before(time.Unix(claims.Expires, 0)) {
+		return false
+	}
+	sum := sha256.Sum256(body)
+	return hmac.Equal([]byte(strings.ToLower(claims.PayloadHash)), []byte(hex.EncodeToString(sum[:])))
+}
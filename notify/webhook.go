@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/response"
+	"github.com/rikiihsan/nest/webhooks"
+)
+
+// WebhookDriver posts sms and whatsapp deliveries as JSON to a gateway of your own, signed like
+// webhooks.Sign. The gateway may answer {"message_id": "..."} and later post
+// {"message_id": "...", "status": "delivered", "error": ""} signed with Secret to StatusHandler
+type WebhookDriver struct {
+	URL    string
+	Secret string
+	// Provider names the gateway in the delivery log, defaults to "webhook"
+	Provider string
+	// From is sender, used when message has no sender
+	From string
+	// Header carries signature, defaults to webhooks.DefaultConfig.SignatureHeader
+	Header string
+	// Tolerance rejects status webhooks signed longer ago, defaults to 5 minutes
+	Tolerance time.Duration
+	Client    *http.Client
+}
+
+// WebhookMessage is body posted by WebhookDriver
+type WebhookMessage struct {
+	Channel     Channel `json:"channel"`
+	Type        string  `json:"type"`
+	RecipientID string  `json:"recipient_id"`
+	To          string  `json:"to"`
+	From        string  `json:"from,omitempty"`
+	Text        string  `json:"text"`
+}
+
+func (d *WebhookDriver) provider() string {
+	if d.Provider != "" {
+		return d.Provider
+	}
+	return "webhook"
+}
+
+func (d *WebhookDriver) header() string {
+	if d.Header != "" {
+		return d.Header
+	}
+	return webhooks.DefaultConfig.SignatureHeader
+}
+
+// Send posts delivery once per route
+func (d *WebhookDriver) Send(ctx context.Context, delivery *Delivery) error {
+	return sendText(ctx, d.provider(), delivery, d.From, func(ctx context.Context, to string, msg *SMSMessage) (string, DeliveryStatus, error) {
+		body, err := json.Marshal(WebhookMessage{
+			Channel:     delivery.Channel,
+			Type:        delivery.Type,
+			RecipientID: delivery.RecipientID,
+			To:          to,
+			From:        msg.From,
+			Text:        msg.Text,
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+		if err != nil {
+			return "", "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(d.header(), webhooks.Sign(d.Secret, time.Now(), body))
+
+		resp, err := httpClient(d.Client).Do(req)
+		if err != nil {
+			return "", "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return "", "", providerError(resp)
+		}
+
+		// gateways may answer without a body
+		var out struct {
+			MessageID string `json:"message_id"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out.MessageID, StatusQueued, nil
+	})
+}
+
+// StatusHandler returns fiber handler of signed gateway status webhooks updating the delivery log
+func (d *WebhookDriver) StatusHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tolerance := d.Tolerance
+		if tolerance <= 0 {
+			tolerance = 5 * time.Minute
+		}
+		if err := webhooks.Verify(d.Secret, c.Get(d.header()), c.Body(), tolerance); err != nil {
+			return response.Error(c, fiber.StatusForbidden, ErrInvalidSignature)
+		}
+
+		var event struct {
+			MessageID string         `json:"message_id"`
+			Status    DeliveryStatus `json:"status"`
+			Error     string         `json:"error"`
+		}
+		if err := json.Unmarshal(c.Body(), &event); err != nil {
+			return response.Error(c, fiber.StatusBadRequest, err)
+		}
+
+		if _, err := UpdateDeliveryStatus(c.UserContext(), d.provider(), event.MessageID, event.Status, event.Error); err != nil {
+			return response.Error(c, fiber.StatusInternalServerError, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
@@ -0,0 +1,293 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Subscription is browser PushSubscription as returned by PushSubscription.toJSON(), stored as webpush route
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// WebPushDriver sends webpush deliveries to browser subscriptions with VAPID authentication
+// and aes128gcm payload encryption (RFC 8291, RFC 8292). The payload is PushMessage JSON
+type WebPushDriver struct {
+	// PublicKey and PrivateKey are base64url VAPID keys, e.g. from `npx web-push generate-vapid-keys`
+	PublicKey  string
+	PrivateKey string
+	// Subject is contact URL or mailto: of the application server
+	Subject string
+	// TTL push services keep undelivered messages, defaults to 4 weeks
+	TTL time.Duration
+	// OnExpired is called with routes of subscriptions that are gone, e.g. to delete them
+	OnExpired func(ctx context.Context, route string)
+	// Client defaults to one refusing to connect to private addresses, a custom one should do the same
+	Client *http.Client
+}
+
+var (
+	ErrInvalidEndpoint = errors.New("notify : push subscription endpoint is not a public https URL")
+	ErrPayloadTooLarge = errors.New("notify : push payload exceeds the 4096 byte record")
+)
+
+// maxPushPayload is plaintext fitting a single 4096 byte aes128gcm body with its 86 byte header,
+// padding delimiter and tag (RFC 8291)
+const maxPushPayload = 4096 - 86 - 1 - 16
+
+// pushClient dials public addresses only, so endpoints resolving or redirecting to internal hosts fail
+var pushClient = func() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddr(addr.Addr()) {
+				return ErrInvalidEndpoint
+			}
+			return nil
+		},
+	}
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}()
+
+// Send sends webpush delivery to every route, a failing route doesn't stop the others and Routes
+// keeps only failed routes so retries skip sent ones
+func (d WebPushDriver) Send(ctx context.Context, delivery *Delivery) error {
+	if len(delivery.Routes) == 0 {
+		return ErrNoRoutes
+	}
+	if len(delivery.Payload) > maxPushPayload {
+		return ErrPayloadTooLarge
+	}
+
+	key, err := decodeBase64(d.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	vapid, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), key)
+	if err != nil {
+		return fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	ttl := d.TTL
+	if ttl <= 0 {
+		ttl = 28 * 24 * time.Hour
+	}
+
+	var failed []string
+	var errs []error
+	for _, route := range delivery.Routes {
+		var sub Subscription
+		err := json.Unmarshal([]byte(route), &sub)
+		if err != nil {
+			err = fmt.Errorf("invalid subscription: %w", err)
+		} else {
+			err = d.push(ctx, vapid, sub, delivery.Payload, ttl)
+		}
+		if errors.Is(err, errSubscriptionGone) {
+			if d.OnExpired != nil {
+				d.OnExpired(ctx, route)
+			}
+		} else if err != nil {
+			failed = append(failed, route)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		delivery.Routes = failed
+	}
+	return errors.Join(errs...)
+}
+
+var errSubscriptionGone = errors.New("notify : push subscription is gone")
+
+func (d WebPushDriver) push(ctx context.Context, vapid *ecdsa.PrivateKey, sub Subscription, payload []byte, ttl time.Duration) error {
+	endpoint, err := pushEndpoint(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+	body, err := encryptPayload(sub, payload)
+	if err != nil {
+		return err
+	}
+	token, err := signJWT(
+		map[string]string{"typ": "JWT", "alg": "ES256"},
+		map[string]interface{}{
+			"aud": endpoint.Scheme + "://" + endpoint.Host,
+			"exp": time.Now().Add(12 * time.Hour).Unix(),
+			"sub": d.Subject,
+		},
+		func(digest []byte) ([]byte, error) {
+			r, s, err := ecdsa.Sign(rand.Reader, vapid, digest)
+			if err != nil {
+				return nil, err
+			}
+			signature := make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+			return signature, nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+strings.TrimRight(d.PublicKey, "="))
+
+	client := d.Client
+	if client == nil {
+		client = pushClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errSubscriptionGone
+	case resp.StatusCode >= 300:
+		return providerError(resp)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// pushEndpoint parses subscription endpoint, accepting https URLs of public hosts only
+func pushEndpoint(raw string) (*url.URL, error) {
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Scheme != "https" || endpoint.User != nil {
+		return nil, ErrInvalidEndpoint
+	}
+	host := strings.ToLower(strings.TrimSuffix(endpoint.Hostname(), "."))
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return nil, ErrInvalidEndpoint
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return nil, ErrInvalidEndpoint
+	}
+	return endpoint, nil
+}
+
+// sharedAddress is the carrier-grade NAT range of RFC 6598, not covered by netip.Addr.IsPrivate
+var sharedAddress = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr reports whether addr is a globally routable unicast address
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddress.Contains(addr)
+}
+
+// encryptPayload encrypts payload for subscription as single aes128gcm record
+func encryptPayload(sub Subscription, payload []byte) ([]byte, error) {
+	uaPublic, err := decodeBase64(sub.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	authSecret, err := decodeBase64(sub.Keys.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription auth: %w", err)
+	}
+
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asKey.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public)
+	prkKey, err := hkdf.Extract(sha256.New, shared, authSecret)
+	if err != nil {
+		return nil, err
+	}
+	ikm, err := hkdf.Expand(sha256.New, prkKey, "WebPush: info\x00"+string(uaPublic)+string(asPublic), 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt || record size || key id length || key id, then the last record delimited by 0x02
+	body := make([]byte, 0, 86+len(payload)+1+gcm.Overhead())
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, 4096)
+	body = append(body, byte(len(asPublic)))
+	body = append(body, asPublic...)
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+	return gcm.Seal(body, nonce, plaintext, nil), nil
+}
+
+// decodeBase64 decodes base64url or standard base64 with or without padding
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
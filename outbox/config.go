@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Event represents outbox row written in the business transaction
+type Event struct {
+	bun.BaseModel `bun:"table:outbox_events,alias:oe"`
+
+	ID          int64        `bun:",pk,autoincrement" json:"id"`
+	Topic       string       `bun:",notnull" json:"topic"`
+	DedupeKey   string       `bun:",notnull,unique" json:"dedupe_key"`
+	Payload     string       `bun:",notnull" json:"payload"`
+	Attempts    int          `bun:",notnull,default:0" json:"attempts"`
+	LastError   string       `bun:",nullzero" json:"last_error,omitempty"`
+	CreatedAt   time.Time    `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	AvailableAt time.Time    `bun:",nullzero,notnull,default:current_timestamp" json:"available_at"`
+	DeliveredAt bun.NullTime `json:"delivered_at"`
+}
+
+// Publisher delivers outbox events to the messaging system
+type Publisher interface {
+	Deliver(ctx context.Context, event *Event) error
+}
+
+// PublisherFunc adapts function to Publisher
+type PublisherFunc func(ctx context.Context, event *Event) error
+
+func (f PublisherFunc) Deliver(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// Config represents relay configuration
+type Config struct {
+	Session      string
+	Publisher    Publisher
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	Backoff      func(attempt int) time.Duration
+}
+
+// Option customizes published event
+type Option func(e *Event)
+
+// WithDedupeKey sets dedupe key, duplicate keys are ignored on publish
+func WithDedupeKey(key string) Option {
+	return func(e *Event) {
+		e.DedupeKey = key
+	}
+}
+
+// WithDelay delays event delivery
+func WithDelay(d time.Duration) Option {
+	return func(e *Event) {
+		e.AvailableAt = time.Now().Add(d)
+	}
+}
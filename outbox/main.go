@@ -0,0 +1,228 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/lock"
+	"github.com/rikiihsan/nest/queue"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// CreateTable creates outbox table if it does not exist
+func CreateTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().Model((*Event)(nil)).IfNotExists().Exec(ctx)
+	return err
+}
+
+// Publish writes event using tx so it commits atomically with business data
+func Publish(ctx context.Context, tx bun.IDB, topic string, payload interface{}, opts ...Option) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode event payload: %w", err)
+	}
+
+	event := &Event{
+		Topic:       topic,
+		Payload:     string(raw),
+		CreatedAt:   time.Now(),
+		AvailableAt: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(event)
+	}
+	if event.DedupeKey == "" {
+		event.DedupeKey = uuid.NewString()
+	}
+
+	// Duplicate dedupe keys are ignored without aborting the transaction
+	query := tx.NewInsert().Model(event)
+	switch tx.Dialect().Name() {
+	case dialect.PG, dialect.SQLite:
+		query = query.On("CONFLICT (dedupe_key) DO NOTHING")
+	case dialect.MySQL:
+		query = query.Ignore()
+	}
+
+	_, err = query.Exec(ctx)
+	if database.IsUniqueViolation(err) {
+		return nil
+	}
+	return err
+}
+
+// QueuePublisher delivers events as queue jobs typed by topic with dedupe key as job ID
+var QueuePublisher = PublisherFunc(func(ctx context.Context, event *Event) error {
+	_, err := queue.Enqueue(ctx, event.Topic, json.RawMessage(event.Payload), queue.WithID(event.DedupeKey))
+	return err
+})
+
+// Relay polls outbox table and delivers pending events at least once
+type Relay struct {
+	config Config
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRelay creates relay, publisher defaults to QueuePublisher
+func NewRelay(cfg Config) *Relay {
+	if cfg.Session == "" {
+		cfg.Session = "default"
+	}
+	if cfg.Publisher == nil {
+		cfg.Publisher = QueuePublisher
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.Backoff == nil {
+		cfg.Backoff = func(attempt int) time.Duration {
+			return time.Duration(attempt*attempt) * time.Second
+		}
+	}
+	return &Relay{config: cfg}
+}
+
+// Start starts polling in background
+func (r *Relay) Start(ctx context.Context) error {
+	ctx, r.cancel = context.WithCancel(context.WithoutCancel(ctx))
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := r.Flush(ctx); err != nil && !errors.Is(err, lock.ErrNotAcquired) {
+					slog.Error("outbox relay failed", "error", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops polling and waits for the current batch
+func (r *Relay) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("outbox relay did not drain: %w", ctx.Err())
+	}
+}
+
+// Flush delivers one batch of pending events and returns delivered count
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	db, err := database.GetDB(r.config.Session)
+	if err != nil {
+		return 0, err
+	}
+
+	// Only one relay instance processes a batch at a time when Redis is available
+	if database.RedisClient != nil {
+		l, err := lock.Acquire(ctx, "outbox:"+r.config.Session, r.config.PollInterval*30)
+		if err != nil {
+			return 0, err
+		}
+		defer l.Release(context.WithoutCancel(ctx))
+	}
+
+	var events []*Event
+	err = db.NewSelect().
+		Model(&events).
+		Where("delivered_at IS NULL").
+		Where("available_at <= ?", time.Now()).
+		Where("attempts < ?", r.config.MaxAttempts).
+		Order("id ASC").
+		Limit(r.config.BatchSize).
+		Scan(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load outbox events: %w", err)
+	}
+
+	delivered := 0
+	for _, event := range events {
+		if err := r.config.Publisher.Deliver(ctx, event); err != nil {
+			event.Attempts++
+			_, uerr := db.NewUpdate().
+				Model(event).
+				Set("attempts = ?", event.Attempts).
+				Set("last_error = ?", err.Error()).
+				Set("available_at = ?", time.Now().Add(r.config.Backoff(event.Attempts))).
+				WherePK().
+				Exec(ctx)
+			if uerr != nil {
+				return delivered, fmt.Errorf("failed to update outbox event %d: %w", event.ID, uerr)
+			}
+			continue
+		}
+
+		_, err := db.NewUpdate().
+			Model(event).
+			Set("delivered_at = ?", time.Now()).
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return delivered, fmt.Errorf("failed to mark outbox event %d delivered: %w", event.ID, err)
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// Purge deletes delivered events older than age
+func Purge(ctx context.Context, db bun.IDB, age time.Duration) (int64, error) {
+	res, err := db.NewDelete().
+		Model((*Event)(nil)).
+		Where("delivered_at IS NOT NULL").
+		Where("delivered_at < ?", time.Now().Add(-age)).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Seen marks dedupe key as processed and reports whether it was already seen
+func Seen(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if database.RedisClient == nil {
+		return false, queue.ErrNoRedis
+	}
+	ok, err := database.RedisClient.SetNX(ctx, "nest:outbox:seen:"+key, 1, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+	return !ok, nil
+}
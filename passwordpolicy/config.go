@@ -0,0 +1,68 @@
+package passwordpolicy
+
+import (
+	"net/http"
+	"time"
+)
+
+// Policy represents password strength requirements
+type Policy struct {
+	MinLength int
+	MaxLength int
+	// Character classes, MinClasses requires that many of upper, lower, digit and symbol
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	MinClasses    int
+	// DisallowUsername rejects passwords containing the username or email local part
+	DisallowUsername bool
+	// Blacklist rejects common passwords from the embedded list and AddBlacklist
+	Blacklist bool
+
+	// Pwned checks HaveIBeenPwned range API, failures are reported as warnings
+	Pwned bool
+	// PwnedThreshold is breach count from which password is rejected, 1 when zero
+	PwnedThreshold int
+	PwnedTimeout   time.Duration
+	PwnedCacheTTL  time.Duration
+	PwnedURL       string
+	HTTPClient     *http.Client
+
+	// Message is validation message of strongpassword tag
+	Message string
+}
+
+// DefaultPolicy is used until Init is called
+var DefaultPolicy = Policy{
+	MinLength:        8,
+	MaxLength:        128,
+	MinClasses:       3,
+	DisallowUsername: true,
+	Blacklist:        true,
+	PwnedTimeout:     3 * time.Second,
+	PwnedCacheTTL:    24 * time.Hour,
+	PwnedURL:         "https://api.pwnedpasswords.com/range/",
+	Message:          "{0} is not strong enough",
+}
+
+// Violation represents failed policy rule
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Result represents password evaluation result
+type Result struct {
+	Valid      bool        `json:"valid"`
+	Violations []Violation `json:"violations,omitempty"`
+	// Pwned is number of times password appeared in breaches, -1 when not checked
+	Pwned    int      `json:"pwned"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// fail appends violation and marks result invalid
+func (r *Result) fail(code, message string) {
+	r.Valid = false
+	r.Violations = append(r.Violations, Violation{Code: code, Message: message})
+}
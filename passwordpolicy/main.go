@@ -0,0 +1,200 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+	nestvalidator "github.com/rikiihsan/nest/validator"
+)
+
+// Tag is the validation tag registered by Init
+const Tag = "strongpassword"
+
+//go:embed common.txt
+var commonPasswords string
+
+var (
+	policy = DefaultPolicy
+
+	blacklistOnce sync.Once
+	blacklistMu   sync.RWMutex
+	blacklist     map[string]struct{}
+)
+
+// Init sets global policy and registers strongpassword validation tag.
+// The tag accepts name of the username field, e.g. validate:"strongpassword=Email"
+func Init(p Policy) error {
+	if p.PwnedURL == "" {
+		p.PwnedURL = DefaultPolicy.PwnedURL
+	}
+	if p.Message == "" {
+		p.Message = DefaultPolicy.Message
+	}
+	policy = p
+
+	return nestvalidator.AddCustomValidation(Tag, validate, p.Message)
+}
+
+// GetPolicy returns the current policy
+func GetPolicy() Policy {
+	return policy
+}
+
+// AddBlacklist adds passwords rejected regardless of case
+func AddBlacklist(passwords ...string) {
+	loadBlacklist()
+
+	blacklistMu.Lock()
+	defer blacklistMu.Unlock()
+	for _, password := range passwords {
+		blacklist[strings.ToLower(password)] = struct{}{}
+	}
+}
+
+// loadBlacklist parses embedded common passwords once
+func loadBlacklist() {
+	blacklistOnce.Do(func() {
+		blacklist = map[string]struct{}{}
+		scanner := bufio.NewScanner(strings.NewReader(commonPasswords))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				blacklist[line] = struct{}{}
+			}
+		}
+	})
+}
+
+// IsCommon reports whether password is blacklisted
+func IsCommon(password string) bool {
+	loadBlacklist()
+
+	blacklistMu.RLock()
+	defer blacklistMu.RUnlock()
+	_, ok := blacklist[strings.ToLower(password)]
+	return ok
+}
+
+// Evaluate checks password against global policy, username is optional
+func Evaluate(password string, username ...string) Result {
+	return EvaluateContext(context.Background(), password, username...)
+}
+
+// EvaluateContext checks password against global policy with context for breach check
+func EvaluateContext(ctx context.Context, password string, username ...string) Result {
+	return policy.Evaluate(ctx, password, username...)
+}
+
+// Evaluate checks password against policy
+func (p Policy) Evaluate(ctx context.Context, password string, username ...string) Result {
+	result := Result{Valid: true, Pwned: -1}
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		result.fail("min_length", fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		result.fail("max_length", fmt.Sprintf("must be at most %d characters", p.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		result.fail("upper", "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		result.fail("lower", "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		result.fail("digit", "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		result.fail("symbol", "must contain a symbol")
+	}
+	if p.MinClasses > 0 {
+		classes := 0
+		for _, has := range []bool{upper, lower, digit, symbol} {
+			if has {
+				classes++
+			}
+		}
+		if classes < p.MinClasses {
+			result.fail("classes", fmt.Sprintf("must contain %d of uppercase, lowercase, digit and symbol", p.MinClasses))
+		}
+	}
+
+	if p.DisallowUsername && len(username) > 0 && containsUsername(password, username[0]) {
+		result.fail("username", "must not contain the username")
+	}
+
+	if p.Blacklist && IsCommon(password) {
+		result.fail("common", "is too common")
+	}
+
+	// Skip remote check when password is already rejected locally
+	if p.Pwned && result.Valid {
+		count, err := pwnedCount(ctx, p, password)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+			return result
+		}
+
+		result.Pwned = count
+		threshold := p.PwnedThreshold
+		if threshold <= 0 {
+			threshold = 1
+		}
+		if count >= threshold {
+			result.fail("pwned", "has appeared in a data breach")
+		}
+	}
+
+	return result
+}
+
+// containsUsername checks username and email local part, ignoring short names
+func containsUsername(password, username string) bool {
+	name := strings.ToLower(strings.TrimSpace(username))
+	if local, _, ok := strings.Cut(name, "@"); ok {
+		name = local
+	}
+	if utf8.RuneCountInString(name) < 3 {
+		return false
+	}
+	return strings.Contains(strings.ToLower(password), name)
+}
+
+// validate implements strongpassword tag
+func validate(fl validator.FieldLevel) bool {
+	var username []string
+	if param := fl.Param(); param != "" {
+		parent := fl.Parent()
+		for parent.Kind() == reflect.Ptr {
+			parent = parent.Elem()
+		}
+		if field := parent.FieldByName(param); field.IsValid() && field.Kind() == reflect.String {
+			username = append(username, field.String())
+		}
+	}
+
+	ctx := context.Background()
+	return policy.Evaluate(ctx, fl.Field().String(), username...).Valid
+}
@@ -0,0 +1,133 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/httpclient"
+)
+
+// CachePrefix is prepended to Redis keys of cached range responses
+var CachePrefix = "nest:pwned:range:"
+
+var (
+	memoryCache sync.Map
+	httpClient  = httpclient.NewBuilder().Retry(2).Build()
+)
+
+type cachedRange struct {
+	body    string
+	expires time.Time
+}
+
+// PwnedCount returns how many times password appeared in breaches using k-anonymity,
+// only the first 5 hex chars of its SHA-1 leave the process
+func PwnedCount(ctx context.Context, password string) (int, error) {
+	return pwnedCount(ctx, policy, password)
+}
+
+func pwnedCount(ctx context.Context, p Policy, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	// ranges are cached by prefix like the API serves them, caching per password hash would turn
+	// the cache into a lookup table of hashed passwords
+	body, ok := cacheGet(ctx, prefix)
+	if !ok {
+		var err error
+		if body, err = fetchRange(ctx, p, prefix); err != nil {
+			return 0, err
+		}
+		cacheSet(ctx, prefix, body, p.PwnedCacheTTL)
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		if value, ok := strings.CutPrefix(line, suffix+":"); ok {
+			count, _ := strconv.Atoi(value)
+			return count, nil
+		}
+	}
+	return 0, nil
+}
+
+// fetchRange returns "SUFFIX:count" lines of hashes starting with prefix, without padding entries
+func fetchRange(ctx context.Context, p Policy, prefix string) (string, error) {
+	if p.PwnedTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.PwnedTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.PwnedURL+prefix, nil)
+	if err != nil {
+		return "", err
+	}
+	// Padding hides the real size of the response range
+	req.Header.Set("Add-Padding", "true")
+
+	client := p.HTTPClient
+	if client == nil {
+		client = httpClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("pwned passwords request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("pwned passwords returned status %d", resp.StatusCode)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if _, count, ok := strings.Cut(line, ":"); ok && count != "0" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read pwned passwords response: %w", err)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// cacheGet reads range of prefix from Redis when initialized, otherwise from memory
+func cacheGet(ctx context.Context, prefix string) (string, bool) {
+	if database.RedisClient != nil {
+		body, err := database.RedisClient.Get(ctx, CachePrefix+prefix).Result()
+		return body, err == nil
+	}
+
+	if v, ok := memoryCache.Load(prefix); ok {
+		cached := v.(cachedRange)
+		if time.Now().Before(cached.expires) {
+			return cached.body, true
+		}
+		memoryCache.Delete(prefix)
+	}
+	return "", false
+}
+
+// cacheSet stores range, errors are ignored since cache is an optimization
+func cacheSet(ctx context.Context, prefix, body string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	if database.RedisClient != nil {
+		database.RedisClient.Set(ctx, CachePrefix+prefix, body, ttl)
+		return
+	}
+	memoryCache.Store(prefix, cachedRange{body: body, expires: time.Now().Add(ttl)})
+}
@@ -0,0 +1,71 @@
+package payloadlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/requestctx"
+)
+
+// Entry represents captured request and response
+type Entry struct {
+	Time         time.Time     `json:"time"`
+	Method       string        `json:"method"`
+	Path         string        `json:"path"`
+	Route        string        `json:"route"`
+	Status       int           `json:"status"`
+	Duration     time.Duration `json:"duration"`
+	RequestID    string        `json:"request_id,omitempty"`
+	RequestBody  string        `json:"request_body,omitempty"`
+	ResponseBody string        `json:"response_body,omitempty"`
+	RequestSize  int           `json:"request_size"`
+	ResponseSize int           `json:"response_size"`
+	Truncated    bool          `json:"truncated,omitempty"`
+	RequestType  string        `json:"request_type,omitempty"`
+	ResponseType string        `json:"response_type,omitempty"`
+}
+
+// Sink receives captured entries
+type Sink interface {
+	Write(ctx context.Context, entry *Entry) error
+}
+
+// SinkFunc adapts function to Sink
+type SinkFunc func(ctx context.Context, entry *Entry) error
+
+func (f SinkFunc) Write(ctx context.Context, entry *Entry) error {
+	return f(ctx, entry)
+}
+
+// Config represents payload logging configuration
+type Config struct {
+	// Sink receives entries, LogSink when nil. Wrap slow sinks with AsyncSink
+	Sink Sink
+	// MaxBodySize caps logged bytes of each body, larger bodies are not parsed and logged by size only
+	MaxBodySize int
+	// Scrub lists JSON keys masked at any depth, or dotted paths from the root where * matches any key.
+	// Keys match case-insensitively as substrings ignoring _ and -, so password also masks newPassword.
+	// Form bodies are scrubbed by key, other content types are logged by size only
+	Scrub []string
+	// SampleRate of responses below 400, ErrorSampleRate of the others. Zero means 1, negative never samples
+	SampleRate      float64
+	ErrorSampleRate float64
+	// RouteSampleRates override SampleRate and ErrorSampleRate by route pattern, e.g. "/users/:id"
+	RouteSampleRates map[string]float64
+	// Next skips middleware when returning true
+	Next func(c *fiber.Ctx) bool
+	// SkipPaths prefixes are never captured
+	SkipPaths []string
+	// RequestIDLocal is fiber locals key holding request ID
+	RequestIDLocal string
+}
+
+// DefaultConfig is used for empty Config fields
+var DefaultConfig = Config{
+	MaxBodySize:     4096,
+	Scrub:           []string{"password", "passwd", "token", "secret", "authorization", "api_key", "private_key", "cookie", "card", "cvv", "ssn"},
+	SampleRate:      1,
+	ErrorSampleRate: 1,
+	RequestIDLocal:  requestctx.RequestID.Name(),
+}
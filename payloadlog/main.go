@@ -0,0 +1,309 @@
+package payloadlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/apperror"
+	"github.com/rikiihsan/nest/logger"
+	"github.com/rikiihsan/nest/secure"
+)
+
+// New returns middleware capturing sampled request and response bodies with sensitive values scrubbed.
+// Errors of later handlers are passed to the app error handler here so error responses are captured,
+// middleware registered before New does not see them
+func New(configs ...Config) fiber.Handler {
+	cfg := DefaultConfig
+	if len(configs) > 0 {
+		cfg = configs[0]
+		if cfg.MaxBodySize <= 0 {
+			cfg.MaxBodySize = DefaultConfig.MaxBodySize
+		}
+		if cfg.Scrub == nil {
+			cfg.Scrub = DefaultConfig.Scrub
+		}
+		if cfg.SampleRate == 0 {
+			cfg.SampleRate = DefaultConfig.SampleRate
+		}
+		if cfg.ErrorSampleRate == 0 {
+			cfg.ErrorSampleRate = DefaultConfig.ErrorSampleRate
+		}
+		if cfg.RequestIDLocal == "" {
+			cfg.RequestIDLocal = DefaultConfig.RequestIDLocal
+		}
+	}
+	if cfg.Sink == nil {
+		cfg.Sink = LogSink()
+	}
+	scrubber := newScrubber(cfg.Scrub)
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+		for _, path := range cfg.SkipPaths {
+			if strings.HasPrefix(c.Path(), path) {
+				return c.Next()
+			}
+		}
+
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			// Write the error response now, like fiber's logger, so it can be captured
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				c.Status(apperror.StatusOf(err))
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if !sampled(cfg, c.Route().Path, status) {
+			return nil
+		}
+
+		// Entries outlive the request with AsyncSink, fiber strings are copied
+		entry := &Entry{
+			Time:         start,
+			Method:       strings.Clone(c.Method()),
+			Path:         strings.Clone(c.Path()),
+			Route:        c.Route().Path,
+			Status:       status,
+			Duration:     time.Since(start),
+			RequestSize:  len(c.Body()),
+			RequestType:  string(c.Request().Header.ContentType()),
+			ResponseType: string(c.Response().Header.ContentType()),
+		}
+		if id, ok := c.Locals(cfg.RequestIDLocal).(string); ok {
+			entry.RequestID = id
+		}
+
+		var truncated bool
+		entry.RequestBody, truncated = capture(scrubber, entry.RequestType, c.Body(), cfg.MaxBodySize)
+		entry.Truncated = truncated
+		if !c.Response().IsBodyStream() {
+			body := c.Response().Body()
+			entry.ResponseSize = len(body)
+			entry.ResponseBody, truncated = capture(scrubber, entry.ResponseType, body, cfg.MaxBodySize)
+			entry.Truncated = entry.Truncated || truncated
+		}
+
+		if err := cfg.Sink.Write(c.UserContext(), entry); err != nil {
+			logger.Get().WarnContext(c.UserContext(), "payload log sink failed", "error", err)
+		}
+		return nil
+	}
+}
+
+// sampled decides whether request is captured
+func sampled(cfg Config, route string, status int) bool {
+	rate, ok := cfg.RouteSampleRates[route]
+	if !ok {
+		rate = cfg.SampleRate
+		if status >= fiber.StatusBadRequest {
+			rate = cfg.ErrorSampleRate
+		}
+	}
+	return rate >= 1 || (rate > 0 && rand.Float64() < rate)
+}
+
+// capture returns scrubbed body, bodies over max bytes are not parsed and logged by size only
+func capture(s *scrubber, contentType string, body []byte, max int) (string, bool) {
+	if len(body) == 0 {
+		return "", false
+	}
+	if len(body) > max {
+		return "", true
+	}
+
+	var out []byte
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	switch {
+	case mediaType == fiber.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		out = s.json(body)
+	case mediaType == fiber.MIMEApplicationForm:
+		out = s.form(body)
+	default:
+		return "", false
+	}
+
+	// Scrubbing may reformat the body, keep the cap on the output too
+	if len(out) > max {
+		return string(out[:max]), true
+	}
+	return string(out), false
+}
+
+// scrubber masks configured keys and paths
+type scrubber struct {
+	keys  []string
+	paths [][]string
+}
+
+func newScrubber(rules []string) *scrubber {
+	s := &scrubber{}
+	for _, rule := range rules {
+		if strings.Contains(rule, ".") {
+			s.paths = append(s.paths, strings.Split(strings.ToLower(rule), "."))
+		} else if key := normalizeKey(rule); key != "" {
+			s.keys = append(s.keys, key)
+		}
+	}
+	return s
+}
+
+// normalizeKey lowercases key and drops separators, so newPassword, new_password and new-password compare equal
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// json scrubs JSON body, invalid JSON is dropped since it cannot be scrubbed reliably
+func (s *scrubber) json(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []byte(`"[INVALID JSON]"`)
+	}
+	out, err := json.Marshal(s.walk(value, nil))
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// walk masks values of sensitive keys, path holds lowercase keys from the root
+func (s *scrubber) walk(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := append(path[:len(path):len(path)], strings.ToLower(key))
+			if s.sensitive(childPath) {
+				v[key] = secure.Redacted
+			} else {
+				v[key] = s.walk(child, childPath)
+			}
+		}
+	case []interface{}:
+		// Array elements share the path of their array
+		for i, child := range v {
+			v[i] = s.walk(child, path)
+		}
+	}
+	return value
+}
+
+// sensitive reports whether value at path is scrubbed
+func (s *scrubber) sensitive(path []string) bool {
+	key := normalizeKey(path[len(path)-1])
+	for _, rule := range s.keys {
+		if strings.Contains(key, rule) {
+			return true
+		}
+	}
+	for _, rule := range s.paths {
+		if len(rule) != len(path) {
+			continue
+		}
+		match := true
+		for i := range rule {
+			if rule[i] != "*" && rule[i] != path[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// form scrubs URL encoded body by key
+func (s *scrubber) form(body []byte) []byte {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil
+	}
+	for key := range values {
+		if s.sensitive([]string{strings.ToLower(key)}) {
+			values[key] = []string{secure.Redacted}
+		}
+	}
+	return []byte(values.Encode())
+}
+
+// LogSink writes entries to the structured logger at info level
+func LogSink() Sink {
+	return SinkFunc(func(ctx context.Context, entry *Entry) error {
+		logger.Get().LogAttrs(ctx, slog.LevelInfo, "http payload",
+			slog.String("method", entry.Method),
+			slog.String("path", entry.Path),
+			slog.String("route", entry.Route),
+			slog.Int("status", entry.Status),
+			slog.Duration("duration", entry.Duration),
+			slog.String("request_id", entry.RequestID),
+			slog.String("request_body", entry.RequestBody),
+			slog.String("response_body", entry.ResponseBody),
+			slog.Int("request_size", entry.RequestSize),
+			slog.Int("response_size", entry.ResponseSize),
+			slog.Bool("truncated", entry.Truncated),
+		)
+		return nil
+	})
+}
+
+// AsyncSink writes entries to target from a background goroutine through a buffer of size,
+// entries are dropped when the buffer is full so logging never slows requests down
+type AsyncSink struct {
+	target  Sink
+	entries chan *Entry
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// NewAsyncSink starts background writer of target
+func NewAsyncSink(target Sink, size int) *AsyncSink {
+	s := &AsyncSink{target: target, entries: make(chan *Entry, size), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		for entry := range s.entries {
+			if err := s.target.Write(context.Background(), entry); err != nil {
+				logger.Get().Warn("payload log sink failed", "error", err)
+			}
+		}
+	}()
+	return s
+}
+
+// Write enqueues entry without blocking
+func (s *AsyncSink) Write(_ context.Context, entry *Entry) error {
+	select {
+	case s.entries <- entry:
+	default:
+		s.dropped.Add(1)
+	}
+	return nil
+}
+
+// Dropped returns number of entries dropped because the buffer was full
+func (s *AsyncSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close flushes buffered entries and stops the writer, Write must not be called afterwards
+func (s *AsyncSink) Close() {
+	close(s.entries)
+	<-s.done
+}
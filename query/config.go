@@ -0,0 +1,75 @@
+package query
+
+// Op represents filter operator, given as filter[param][op]=value
+type Op string
+
+// Filter operators
+const (
+	Eq       Op = "eq"
+	Ne       Op = "ne"
+	Gt       Op = "gt"
+	Gte      Op = "gte"
+	Lt       Op = "lt"
+	Lte      Op = "lte"
+	In       Op = "in"
+	Prefix   Op = "prefix"
+	Contains Op = "contains"
+	Null     Op = "null"
+)
+
+// DefaultOps are allowed when Field.Ops is empty, they stay index friendly
+var DefaultOps = []Op{Eq, In}
+
+// Field represents filterable column
+type Field struct {
+	Column string
+	// Ops allowed on column, DefaultOps when empty. Contains scans, allow it on small tables only
+	Ops []Op
+}
+
+// Spec represents allowlist of query parameters accepted for a model
+type Spec struct {
+	// Filters maps filter[param] names to columns
+	Filters map[string]Field
+	// Sorts maps sort names to columns, ?sort=name,-other
+	Sorts map[string]string
+	// Fields maps fields names selectable with ?fields=a,b to columns, all columns when empty
+	Fields map[string]string
+	// DefaultSort applies when ?sort is absent, e.g. "-created_at"
+	DefaultSort string
+	// RequireFilter rejects requests without any filter, for tables too large to list
+	RequireFilter bool
+	// MaxIn limits values of the in operator
+	MaxIn      int
+	PerPage    int
+	MaxPerPage int
+}
+
+// Filter represents parsed filter condition
+type Filter struct {
+	Column string
+	Op     Op
+	Values []string
+}
+
+// Sort represents parsed order column
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Query represents validated query parameters
+type Query struct {
+	Filters []Filter
+	Sorts   []Sort
+	Columns []string
+	Page    int
+	PerPage int
+}
+
+// Default limits used when Spec values are zero
+var (
+	DefaultPerPage    = 20
+	DefaultMaxPerPage = 100
+	DefaultMaxIn      = 100
+)
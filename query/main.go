@@ -0,0 +1,228 @@
+package query
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/apperror"
+	"github.com/uptrace/bun"
+)
+
+// likeEscaper escapes LIKE wildcards with '!' which needs no quoting in any dialect
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// invalid returns bad request error of rejected parameter
+func invalid(message string) error {
+	return apperror.ErrBadRequest.WithMessage(message)
+}
+
+// FromCtx parses query string of request against spec
+func FromCtx(c *fiber.Ctx, spec Spec) (*Query, error) {
+	values := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		values.Add(string(key), string(value))
+	})
+	return Parse(values, spec)
+}
+
+// Parse validates values against spec, unknown filter, sort or field names are rejected
+func Parse(values url.Values, spec Spec) (*Query, error) {
+	if spec.PerPage <= 0 {
+		spec.PerPage = DefaultPerPage
+	}
+	if spec.MaxPerPage <= 0 {
+		spec.MaxPerPage = DefaultMaxPerPage
+	}
+	if spec.MaxIn <= 0 {
+		spec.MaxIn = DefaultMaxIn
+	}
+
+	q := &Query{Page: 1, PerPage: spec.PerPage}
+	if err := q.parseFilters(values, spec); err != nil {
+		return nil, err
+	}
+	if spec.RequireFilter && len(q.Filters) == 0 {
+		return nil, invalid("at least one filter is required")
+	}
+
+	sorts := spec.DefaultSort
+	if values.Has("sort") {
+		sorts = values.Get("sort")
+	}
+	for _, name := range strings.Split(sorts, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		bare, desc := strings.CutPrefix(name, "-")
+		column, ok := spec.Sorts[bare]
+		if !ok {
+			return nil, invalid("cannot sort by " + bare)
+		}
+		q.Sorts = append(q.Sorts, Sort{Column: column, Desc: desc})
+	}
+
+	for _, name := range strings.Split(values.Get("fields"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		column, ok := spec.Fields[name]
+		if !ok {
+			return nil, invalid("unknown field " + name)
+		}
+		q.Columns = append(q.Columns, column)
+	}
+
+	if page := values.Get("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return nil, invalid("page must be a positive integer")
+		}
+		q.Page = n
+	}
+	if perPage := values.Get("per_page"); perPage != "" {
+		n, err := strconv.Atoi(perPage)
+		if err != nil || n < 1 {
+			return nil, invalid("per_page must be a positive integer")
+		}
+		q.PerPage = min(n, spec.MaxPerPage)
+	}
+	return q, nil
+}
+
+// parseFilters reads filter[param] and filter[param][op] values in stable order
+func (q *Query) parseFilters(values url.Values, spec Spec) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		param, op, ok := filterKey(key)
+		if !ok {
+			return invalid("malformed filter " + key)
+		}
+		field, ok := spec.Filters[param]
+		if !ok {
+			return invalid("cannot filter by " + param)
+		}
+		if !allowed(field, op) {
+			return invalid("operator " + string(op) + " is not allowed on " + param)
+		}
+
+		value := values.Get(key)
+		filter := Filter{Column: field.Column, Op: op, Values: []string{value}}
+		switch op {
+		case In:
+			filter.Values = strings.Split(value, ",")
+			if len(filter.Values) > spec.MaxIn {
+				return invalid("too many values for " + param)
+			}
+		case Null:
+			if value != "true" && value != "false" {
+				return invalid("null filter of " + param + " must be true or false")
+			}
+		case Prefix, Contains:
+			if value == "" {
+				return invalid("filter " + param + " needs a value")
+			}
+		}
+		q.Filters = append(q.Filters, filter)
+	}
+	return nil
+}
+
+// filterKey splits filter[param] or filter[param][op]
+func filterKey(key string) (string, Op, bool) {
+	rest := strings.TrimPrefix(key, "filter[")
+	param, rest, ok := strings.Cut(rest, "]")
+	if !ok || param == "" {
+		return "", "", false
+	}
+	if rest == "" {
+		return param, Eq, true
+	}
+	if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") {
+		return "", "", false
+	}
+	return param, Op(rest[1 : len(rest)-1]), true
+}
+
+// allowed reports whether op is allowed on field
+func allowed(field Field, op Op) bool {
+	ops := field.Ops
+	if len(ops) == 0 {
+		ops = DefaultOps
+	}
+	for _, allowed := range ops {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply adds filters, order and selected columns to sq, columns are always quoted identifiers
+// and values bound arguments. Pagination is left to the caller, see Limit and Offset
+func (q *Query) Apply(sq *bun.SelectQuery) *bun.SelectQuery {
+	for _, filter := range q.Filters {
+		column := bun.Ident(filter.Column)
+		value := filter.Values[0]
+		switch filter.Op {
+		case Eq:
+			sq = sq.Where("?TableAlias.? = ?", column, value)
+		case Ne:
+			sq = sq.Where("?TableAlias.? <> ?", column, value)
+		case Gt:
+			sq = sq.Where("?TableAlias.? > ?", column, value)
+		case Gte:
+			sq = sq.Where("?TableAlias.? >= ?", column, value)
+		case Lt:
+			sq = sq.Where("?TableAlias.? < ?", column, value)
+		case Lte:
+			sq = sq.Where("?TableAlias.? <= ?", column, value)
+		case In:
+			sq = sq.Where("?TableAlias.? IN (?)", column, bun.In(filter.Values))
+		case Prefix:
+			sq = sq.Where("?TableAlias.? LIKE ? ESCAPE '!'", column, likeEscaper.Replace(value)+"%")
+		case Contains:
+			sq = sq.Where("?TableAlias.? LIKE ? ESCAPE '!'", column, "%"+likeEscaper.Replace(value)+"%")
+		case Null:
+			if value == "true" {
+				sq = sq.Where("?TableAlias.? IS NULL", column)
+			} else {
+				sq = sq.Where("?TableAlias.? IS NOT NULL", column)
+			}
+		}
+	}
+
+	for _, s := range q.Sorts {
+		if s.Desc {
+			sq = sq.OrderExpr("?TableAlias.? DESC", bun.Ident(s.Column))
+		} else {
+			sq = sq.OrderExpr("?TableAlias.? ASC", bun.Ident(s.Column))
+		}
+	}
+
+	if len(q.Columns) > 0 {
+		sq = sq.Column(q.Columns...)
+	}
+	return sq
+}
+
+// Limit returns page size
+func (q *Query) Limit() int {
+	return q.PerPage
+}
+
+// Offset returns rows skipped before current page
+func (q *Query) Offset() int {
+	return (q.Page - 1) * q.PerPage
+}
@@ -0,0 +1,262 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Batch errors
+var (
+	ErrEmptyBatch    = errors.New("queue : batch has no jobs")
+	ErrBatchNotFound = errors.New("queue : batch not found")
+)
+
+// finishScript counts finished job of batch once per job ID and returns pending jobs, -1 when batch
+// expired and -2 when the job was already counted, e.g. after a duplicate delivery
+var finishScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+if redis.call("SADD", KEYS[2], ARGV[2]) == 0 then
+	return -2
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl > 0 then
+	redis.call("PEXPIRE", KEYS[2], ttl)
+end
+redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+return redis.call("HINCRBY", KEYS[1], "pending", -1)
+`)
+
+// Batch enqueues related jobs together and enqueues a callback job once all of them finished,
+// e.g. queue.NewBatch().Add("report:part", p1).Add("report:part", p2).OnComplete("report:merge", r).Dispatch(ctx)
+type Batch struct {
+	ID string
+
+	jobs       []batchEntry
+	onComplete *batchEntry
+	onFailure  *batchEntry
+}
+
+type batchEntry struct {
+	jobType string
+	payload interface{}
+	opts    []Option
+}
+
+// batchJob is job with its delay, stored for callbacks
+type batchJob struct {
+	Job   *Job          `json:"job"`
+	Delay time.Duration `json:"delay"`
+}
+
+// BatchStatus represents progress of batch, callback jobs receive it as payload with their own
+// payload in Payload
+type BatchStatus struct {
+	ID         string          `json:"id"`
+	Total      int64           `json:"total"`
+	Pending    int64           `json:"pending"`
+	Done       int64           `json:"done"`
+	Failed     int64           `json:"failed"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// Finished reports whether every job of batch succeeded or was dead-lettered
+func (s *BatchStatus) Finished() bool {
+	return s.Pending <= 0
+}
+
+// NewBatch creates empty batch
+func NewBatch() *Batch {
+	return &Batch{ID: uuid.NewString()}
+}
+
+// Add adds job to batch
+func (b *Batch) Add(jobType string, payload interface{}, opts ...Option) *Batch {
+	b.jobs = append(b.jobs, batchEntry{jobType, payload, opts})
+	return b
+}
+
+// OnComplete sets job enqueued when every job of batch succeeded
+func (b *Batch) OnComplete(jobType string, payload interface{}, opts ...Option) *Batch {
+	b.onComplete = &batchEntry{jobType, payload, opts}
+	return b
+}
+
+// OnFailure sets job enqueued when batch finished with dead-lettered jobs
+func (b *Batch) OnFailure(jobType string, payload interface{}, opts ...Option) *Batch {
+	b.onFailure = &batchEntry{jobType, payload, opts}
+	return b
+}
+
+func batchKey(id string) string {
+	return Key("batch", id)
+}
+
+// batchFinishedKey is set of IDs of finished jobs of batch
+func batchFinishedKey(id string) string {
+	return Key("batch", id, "finished")
+}
+
+// Dispatch stores batch state and pushes its jobs in one transaction
+func (b *Batch) Dispatch(ctx context.Context) error {
+	if len(b.jobs) == 0 {
+		return ErrEmptyBatch
+	}
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"total":      len(b.jobs),
+		"pending":    len(b.jobs),
+		"done":       0,
+		"failed":     0,
+		"created_at": time.Now().Unix(),
+	}
+	for field, entry := range map[string]*batchEntry{"on_complete": b.onComplete, "on_failure": b.onFailure} {
+		if entry == nil {
+			continue
+		}
+		job, delay, err := newJob(ctx, entry.jobType, entry.payload, entry.opts)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(batchJob{Job: job, Delay: delay})
+		if err != nil {
+			return err
+		}
+		fields[field] = data
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, batchKey(b.ID), fields)
+	pipe.Expire(ctx, batchKey(b.ID), config.BatchTTL)
+	for _, entry := range b.jobs {
+		job, delay, err := newJob(ctx, entry.jobType, entry.payload, entry.opts)
+		if err != nil {
+			return err
+		}
+		job.BatchID = b.ID
+		data, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("failed to encode job: %w", err)
+		}
+		if delay > 0 {
+			pipe.ZAdd(ctx, Key(job.Queue, "delayed"), redis.Z{
+				Score:  float64(time.Now().Add(delay).UnixMilli()),
+				Member: data,
+			})
+			continue
+		}
+		// scripts are sent in full since EVALSHA fallback doesn't work in transactions
+		pushScript.Eval(ctx, pipe, nil, Key(job.Queue), string(job.Priority), job.Group, data)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// FindBatch returns progress of batch
+func FindBatch(ctx context.Context, id string) (*BatchStatus, error) {
+	rdb, err := client()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := rdb.HGetAll(ctx, batchKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrBatchNotFound
+	}
+
+	count := func(field string) int64 {
+		n, _ := strconv.ParseInt(fields[field], 10, 64)
+		return n
+	}
+	status := &BatchStatus{
+		ID:        id,
+		Total:     count("total"),
+		Pending:   count("pending"),
+		Done:      count("done"),
+		Failed:    count("failed"),
+		CreatedAt: time.Unix(count("created_at"), 0),
+	}
+	if finished := count("finished_at"); finished > 0 {
+		status.FinishedAt = time.Unix(finished, 0)
+	}
+	return status, nil
+}
+
+// finishBatch counts finished job of its batch, the job finishing the batch enqueues its callback
+func finishBatch(ctx context.Context, job *Job, succeeded bool) {
+	if job.BatchID == "" {
+		return
+	}
+	rdb, err := client()
+	if err != nil {
+		return
+	}
+
+	field := "done"
+	if !succeeded {
+		field = "failed"
+	}
+	pending, err := finishScript.Run(ctx, rdb, []string{batchKey(job.BatchID), batchFinishedKey(job.BatchID)}, field, job.ID).Int64()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record batch job", "batch_id", job.BatchID, "job_id", job.ID, "error", err)
+		return
+	}
+	// Only the first execution of the last job enqueues the callback
+	if pending != 0 {
+		return
+	}
+
+	if err := runCallback(ctx, rdb, job.BatchID); err != nil {
+		slog.ErrorContext(ctx, "failed to enqueue batch callback", "batch_id", job.BatchID, "error", err)
+	}
+}
+
+// runCallback marks batch finished and pushes its callback with BatchStatus payload
+func runCallback(ctx context.Context, rdb *redis.Client, id string) error {
+	if err := rdb.HSet(ctx, batchKey(id), "finished_at", time.Now().Unix()).Err(); err != nil {
+		return err
+	}
+	status, err := FindBatch(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	field := "on_complete"
+	if status.Failed > 0 {
+		field = "on_failure"
+	}
+	data, err := rdb.HGet(ctx, batchKey(id), field).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var callback batchJob
+	if err := json.Unmarshal(data, &callback); err != nil {
+		return err
+	}
+	status.Payload = callback.Job.Payload
+	if callback.Job.Payload, err = json.Marshal(status); err != nil {
+		return err
+	}
+	return push(ctx, callback.Job, callback.Delay)
+}
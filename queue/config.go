@@ -0,0 +1,171 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Job represents queued unit of work
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Queue      string          `json:"queue"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	MaxRetries int             `json:"max_retries"`
+	LastError  string          `json:"last_error,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Priority   Priority        `json:"priority,omitempty"`
+	Group      string          `json:"group,omitempty"`
+	BatchID    string          `json:"batch_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Bind decodes job payload into dst
+func (j *Job) Bind(dst interface{}) error {
+	return json.Unmarshal(j.Payload, dst)
+}
+
+// Handler processes a job, returning error schedules a retry
+type Handler func(ctx context.Context, job *Job) error
+
+// Config represents queue configuration
+type Config struct {
+	Prefix       string
+	Queues       []string
+	Concurrency  int
+	PollInterval time.Duration
+	MaxRetries   int
+	Backoff      func(attempt int) time.Duration
+	// Priorities maps priority levels to dequeue weights, a level with weight 6 is picked first six times
+	// as often as one with weight 1 while both have jobs
+	Priorities map[Priority]int
+	// DefaultPriority is level of jobs enqueued without WithPriority
+	DefaultPriority Priority
+	// BatchTTL is how long batch state is kept in Redis after dispatch
+	BatchTTL time.Duration
+	// VisibilityTimeout requeues jobs of crashed workers, running jobs keep extending it
+	VisibilityTimeout time.Duration
+}
+
+// DefaultConfig is used until Init is called
+var DefaultConfig = Config{
+	Prefix:       "nest:queue",
+	Queues:       []string{"default"},
+	Concurrency:  10,
+	PollInterval: time.Second,
+	MaxRetries:   3,
+	Backoff: func(attempt int) time.Duration {
+		return time.Duration(attempt*attempt) * 5 * time.Second
+	},
+	Priorities:        map[Priority]int{PriorityHigh: 6, PriorityNormal: 3, PriorityLow: 1},
+	DefaultPriority:   PriorityNormal,
+	BatchTTL:          7 * 24 * time.Hour,
+	VisibilityTimeout: 5 * time.Minute,
+}
+
+var config = DefaultConfig
+
+// Init sets global queue configuration, empty fields fall back to DefaultConfig
+func Init(cfg Config) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultConfig.Prefix
+	}
+	if len(cfg.Queues) == 0 {
+		cfg.Queues = DefaultConfig.Queues
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConfig.Concurrency
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultConfig.PollInterval
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.Backoff == nil {
+		cfg.Backoff = DefaultConfig.Backoff
+	}
+	if len(cfg.Priorities) == 0 {
+		cfg.Priorities = DefaultConfig.Priorities
+	}
+	priorities := make(map[Priority]int, len(cfg.Priorities))
+	for level, weight := range cfg.Priorities {
+		priorities[level] = max(weight, 1)
+	}
+	cfg.Priorities = priorities
+	if cfg.BatchTTL <= 0 {
+		cfg.BatchTTL = DefaultConfig.BatchTTL
+	}
+	if cfg.VisibilityTimeout <= 0 {
+		cfg.VisibilityTimeout = DefaultConfig.VisibilityTimeout
+	}
+	if _, ok := cfg.Priorities[cfg.DefaultPriority]; !ok {
+		cfg.DefaultPriority = priorityLevels(cfg.Priorities)[0]
+		if _, ok := cfg.Priorities[PriorityNormal]; ok {
+			cfg.DefaultPriority = PriorityNormal
+		}
+	}
+	config = cfg
+}
+
+// Option customizes enqueued job
+type Option func(o *enqueueOptions)
+
+type enqueueOptions struct {
+	id         string
+	queue      string
+	delay      time.Duration
+	maxRetries *int
+	priority   Priority
+	group      string
+}
+
+// OnQueue sets target queue name
+func OnQueue(name string) Option {
+	return func(o *enqueueOptions) {
+		o.queue = name
+	}
+}
+
+// WithDelay delays job processing
+func WithDelay(d time.Duration) Option {
+	return func(o *enqueueOptions) {
+		o.delay = d
+	}
+}
+
+// WithMaxRetries overrides configured retry count
+func WithMaxRetries(n int) Option {
+	return func(o *enqueueOptions) {
+		o.maxRetries = &n
+	}
+}
+
+// WithID sets job ID, useful as dedupe key for consumers
+func WithID(id string) Option {
+	return func(o *enqueueOptions) {
+		o.id = id
+	}
+}
+
+// WithPriority sets priority level, see Config.Priorities
+func WithPriority(p Priority) Option {
+	return func(o *enqueueOptions) {
+		o.priority = p
+	}
+}
+
+// WithGroup sets fairness group, jobs of a priority level are dequeued round robin across groups.
+// Defaults to tenant of context, falling back to job type
+func WithGroup(group string) Option {
+	return func(o *enqueueOptions) {
+		o.group = group
+	}
+}
+
+// GetConfig returns the current queue configuration
+func GetConfig() Config {
+	return config
+}
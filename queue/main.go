@@ -0,0 +1,365 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/requestctx"
+)
+
+// Custom errors
+var (
+	ErrNoRedis        = errors.New("queue : redis client is not initialized")
+	ErrHandlerMissing = errors.New("queue : no handler registered for job type")
+)
+
+var (
+	mu       sync.RWMutex
+	handlers = make(map[string]Handler)
+)
+
+// Register registers job handler by type
+func Register(jobType string, h Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers[jobType] = h
+}
+
+func handlerFor(jobType string) (Handler, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	h, ok := handlers[jobType]
+	return h, ok
+}
+
+func client() (*redis.Client, error) {
+	if database.RedisClient == nil {
+		return nil, ErrNoRedis
+	}
+	return database.RedisClient, nil
+}
+
+// Key returns redis key for queue suffix parts
+func Key(queue string, parts ...string) string {
+	key := config.Prefix + ":" + queue
+	for _, part := range parts {
+		key += ":" + part
+	}
+	return key
+}
+
+// Enqueue pushes job with JSON encoded payload
+func Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...Option) (*Job, error) {
+	job, delay, err := newJob(ctx, jobType, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := push(ctx, job, delay); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// newJob builds job of options, returning its delay
+func newJob(ctx context.Context, jobType string, payload interface{}, opts []Option) (*Job, time.Duration, error) {
+	o := enqueueOptions{queue: config.Queues[0], priority: config.DefaultPriority}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if _, ok := config.Priorities[o.priority]; !ok {
+		return nil, 0, fmt.Errorf("%w: %s", ErrUnknownPriority, o.priority)
+	}
+	if o.group == "" {
+		o.group = requestctx.Tenant.Value(ctx)
+	}
+	if o.group == "" {
+		o.group = jobType
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	job := &Job{
+		ID:         uuid.NewString(),
+		Type:       jobType,
+		Queue:      o.queue,
+		Payload:    raw,
+		MaxRetries: config.MaxRetries,
+		RequestID:  requestctx.RequestID.Value(ctx),
+		Priority:   o.priority,
+		Group:      o.group,
+		CreatedAt:  time.Now(),
+	}
+	if o.id != "" {
+		job.ID = o.id
+	}
+	if o.maxRetries != nil {
+		job.MaxRetries = *o.maxRetries
+	}
+	return job, o.delay, nil
+}
+
+// push stores job in ready list or delayed set
+func push(ctx context.Context, job *Job, delay time.Duration) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	if delay > 0 {
+		return rdb.ZAdd(ctx, Key(job.Queue, "delayed"), redis.Z{
+			Score:  float64(time.Now().Add(delay).UnixMilli()),
+			Member: data,
+		}).Err()
+	}
+	return pushScript.Run(ctx, rdb, nil, Key(job.Queue), string(job.Priority), job.Group, data).Err()
+}
+
+// Size returns number of ready jobs in queue across priorities
+func Size(ctx context.Context, queue string) (int64, error) {
+	depth, err := Depth(ctx, queue)
+	if err != nil {
+		return 0, err
+	}
+	// jobs pushed before priorities existed
+	size, err := database.RedisClient.LLen(ctx, Key(queue)).Result()
+	if err != nil {
+		return 0, err
+	}
+	for _, n := range depth {
+		size += n
+	}
+	return size, nil
+}
+
+// Worker consumes jobs from configured queues
+type Worker struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorker creates worker using global configuration
+func NewWorker() *Worker {
+	return &Worker{}
+}
+
+// Start starts consuming in background
+func (w *Worker) Start(ctx context.Context) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+
+	// Detach from start context so workers live until Stop
+	ctx, w.cancel = context.WithCancel(context.WithoutCancel(ctx))
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.promote(ctx, rdb)
+	}()
+
+	for i := 0; i < config.Concurrency; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.consume(ctx, rdb)
+		}()
+	}
+	return nil
+}
+
+// Stop stops fetching new jobs and waits for in-flight jobs until ctx is done
+func (w *Worker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("queue worker did not drain: %w", ctx.Err())
+	}
+}
+
+// Run consumes jobs until ctx is cancelled
+func Run(ctx context.Context) error {
+	w := NewWorker()
+	if err := w.Start(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return w.Stop(context.Background())
+}
+
+func (w *Worker) promote(ctx context.Context, rdb *redis.Client) {
+	ticker := time.NewTicker(config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := fmt.Sprint(time.Now().UnixMilli())
+			for _, queue := range config.Queues {
+				promoteScript.Run(ctx, rdb, []string{Key(queue, "delayed")}, Key(queue), now, string(config.DefaultPriority))
+				// jobs of crashed workers stopped extending their claim
+				n, err := promoteScript.Run(ctx, rdb, []string{Key(queue, "processing")}, Key(queue), now, string(config.DefaultPriority)).Int()
+				if err == nil && n > 0 {
+					slog.WarnContext(ctx, "requeued jobs of stopped workers", "queue", queue, "count", n)
+				}
+			}
+		}
+	}
+}
+
+func (w *Worker) consume(ctx context.Context, rdb *redis.Client) {
+	wake := make([]string, len(config.Queues))
+	for i, queue := range config.Queues {
+		wake[i] = Key(queue, "wake")
+	}
+
+	failures := 0
+	for ctx.Err() == nil {
+		queue, data, err := pop(ctx, rdb)
+		if errors.Is(err, redis.Nil) {
+			// idle until a push wakes us or the poll interval passes
+			err = rdb.BRPop(ctx, config.PollInterval, wake...).Err()
+			if err == nil || errors.Is(err, redis.Nil) {
+				failures = 0
+				continue
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// back off while Redis is unreachable instead of spinning
+			if failures++; failures == 1 {
+				slog.WarnContext(ctx, "queue worker failed to fetch jobs", "error", err)
+			}
+			sleep(ctx, min(config.PollInterval*time.Duration(failures), 30*time.Second))
+			continue
+		}
+		failures = 0
+
+		// In-flight jobs finish even when worker is stopping
+		jobCtx := context.WithoutCancel(ctx)
+		var job Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			rdb.LPush(jobCtx, Key("invalid", "dead"), data)
+			ack(jobCtx, rdb, queue, data)
+			continue
+		}
+
+		stop := renew(jobCtx, config.VisibilityTimeout/3, func(ctx context.Context) {
+			deadline := time.Now().Add(config.VisibilityTimeout).UnixMilli()
+			rdb.ZAddXX(ctx, Key(queue, "processing"), redis.Z{Score: float64(deadline), Member: data})
+		})
+		process(jobCtx, &job)
+		stop()
+		ack(jobCtx, rdb, queue, data)
+	}
+}
+
+// ack releases claim of popped job once it succeeded, was scheduled for retry or dead-lettered
+func ack(ctx context.Context, rdb *redis.Client, queue, data string) {
+	if err := rdb.ZRem(ctx, Key(queue, "processing"), data).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to release job claim, job will be requeued", "queue", queue, "error", err)
+	}
+}
+
+// renew calls fn every interval until the returned stop is called
+func renew(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fn(ctx)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// process runs job handler and schedules retry or dead-letters on failure
+func process(ctx context.Context, job *Job) {
+	job.Attempts++
+	err := execute(ctx, job)
+	if err == nil {
+		finishBatch(ctx, job, true)
+		return
+	}
+
+	job.LastError = err.Error()
+	if job.Attempts <= job.MaxRetries && !errors.Is(err, ErrHandlerMissing) {
+		push(ctx, job, config.Backoff(job.Attempts))
+		return
+	}
+
+	if data, err := json.Marshal(job); err == nil {
+		if rdb, err := client(); err == nil {
+			rdb.LPush(ctx, Key(job.Queue, "dead"), data)
+		}
+	}
+	finishBatch(ctx, job, false)
+}
+
+func execute(ctx context.Context, job *Job) (err error) {
+	h, ok := handlerFor(job.Type)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrHandlerMissing, job.Type)
+	}
+	h = chain(job.Type, h)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return h(ctx, job)
+}
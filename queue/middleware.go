@@ -0,0 +1,179 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rikiihsan/nest/requestctx"
+	"github.com/uptrace/bun"
+)
+
+// ErrJobInProgress is returned by IdempotencyMiddleware while another worker runs job with same ID
+var ErrJobInProgress = errors.New("queue : job with same id is in progress")
+
+// Middleware wraps job handler like HTTP middleware
+type Middleware func(next Handler) Handler
+
+var (
+	middlewares     []Middleware
+	typeMiddlewares = make(map[string][]Middleware)
+)
+
+// Use adds middlewares run around handlers of all job types, first one outermost
+func Use(mws ...Middleware) {
+	mu.Lock()
+	defer mu.Unlock()
+	middlewares = append(middlewares, mws...)
+}
+
+// UseFor adds middlewares run around handler of jobType, inside middlewares added with Use
+func UseFor(jobType string, mws ...Middleware) {
+	mu.Lock()
+	defer mu.Unlock()
+	typeMiddlewares[jobType] = append(typeMiddlewares[jobType], mws...)
+}
+
+// chain wraps h with global then job type middlewares
+func chain(jobType string, h Handler) Handler {
+	mu.RLock()
+	defer mu.RUnlock()
+	typed := typeMiddlewares[jobType]
+	for i := len(typed) - 1; i >= 0; i-- {
+		h = typed[i](h)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// RetryMiddleware retries failed handler up to n times within the same attempt before the job is
+// requeued, backoff defaults to 100ms times retry
+func RetryMiddleware(n int, backoff ...func(retry int) time.Duration) Middleware {
+	delay := func(retry int) time.Duration {
+		return time.Duration(retry) * 100 * time.Millisecond
+	}
+	if len(backoff) > 0 {
+		delay = backoff[0]
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, job *Job) error {
+			err := next(ctx, job)
+			for retry := 1; err != nil && retry <= n; retry++ {
+				timer := time.NewTimer(delay(retry))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+				err = next(ctx, job)
+			}
+			return err
+		}
+	}
+}
+
+// TimeoutMiddleware cancels context of handler after d
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, job *Job) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			err := next(ctx, job)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("job timed out after %s: %w", d, err)
+			}
+			return err
+		}
+	}
+}
+
+// TracingMiddleware restores request ID job was enqueued with, falling back to job ID, comments SQL
+// with job type and ID and logs start and result of every attempt
+func TracingMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, job *Job) error {
+			id := job.RequestID
+			if id == "" {
+				id = job.ID
+			}
+			ctx = requestctx.RequestID.With(ctx, id)
+			ctx = bun.WithComment(ctx, "job:"+job.Type+":"+job.ID)
+
+			attrs := []any{"job_id", job.ID, "job_type", job.Type, "queue", job.Queue, "attempt", job.Attempts}
+			slog.DebugContext(ctx, "job started", attrs...)
+
+			start := time.Now()
+			err := next(ctx, job)
+			attrs = append(attrs, "duration", time.Since(start))
+			if err != nil {
+				slog.WarnContext(ctx, "job failed", append(attrs, "error", err)...)
+				return err
+			}
+			slog.InfoContext(ctx, "job done", attrs...)
+			return nil
+		}
+	}
+}
+
+// IdempotencyLease is how long the "running" marker of IdempotencyMiddleware outlives a crashed
+// worker, running jobs keep extending it
+var IdempotencyLease = time.Minute
+
+// IdempotencyMiddleware skips jobs whose ID already succeeded within ttl, defaults to 24h. Jobs with
+// same ID running on another worker fail with ErrJobInProgress so they retry later
+func IdempotencyMiddleware(ttl ...time.Duration) Middleware {
+	keep := 24 * time.Hour
+	if len(ttl) > 0 {
+		keep = ttl[0]
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, job *Job) error {
+			rdb, err := client()
+			if err != nil {
+				return err
+			}
+
+			key := Key(job.Queue, "idempotency", job.ID)
+			ok, err := rdb.SetNX(ctx, key, "running", IdempotencyLease).Result()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if state, _ := rdb.Get(ctx, key).Result(); state == "done" {
+					slog.DebugContext(ctx, "duplicate job skipped", "job_id", job.ID, "job_type", job.Type)
+					return nil
+				}
+				return ErrJobInProgress
+			}
+
+			// release runs after handler timeouts canceled ctx
+			release := context.WithoutCancel(ctx)
+			stop := renew(ctx, IdempotencyLease/3, func(ctx context.Context) {
+				rdb.Expire(ctx, key, IdempotencyLease)
+			})
+			defer func() {
+				if r := recover(); r != nil {
+					stop()
+					rdb.Del(release, key)
+					panic(r)
+				}
+			}()
+
+			err = next(ctx, job)
+			stop()
+			if err != nil {
+				rdb.Del(release, key)
+				return err
+			}
+			return rdb.Set(release, key, "done", keep).Err()
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUnknownPriority is returned when enqueueing with level missing from Config.Priorities
+var ErrUnknownPriority = errors.New("queue : unknown priority")
+
+// Priority is priority level of job
+type Priority string
+
+// Default priority levels
+const (
+	PriorityHigh   Priority = "high"
+	PriorityNormal Priority = "normal"
+	PriorityLow    Priority = "low"
+)
+
+// Ready jobs of a queue live in list <queue>:ready:<priority>:<group>. List <queue>:groups:<priority>
+// rotates groups having jobs, each group dequeues up to its weight in hash <queue>:weights before
+// moving to the back. Hash <queue>:depth counts jobs per priority and list <queue>:wake wakes idle workers
+const enqueueLua = `
+local function enqueue(base, priority, group, job)
+	local list = base .. ":ready:" .. priority .. ":" .. group
+	if redis.call("LPUSH", list, job) == 1 then
+		redis.call("RPUSH", base .. ":groups:" .. priority, group)
+	end
+	redis.call("HINCRBY", base .. ":depth", priority, 1)
+	redis.call("LPUSH", base .. ":wake", 1)
+	redis.call("LTRIM", base .. ":wake", 0, 999)
+end
+`
+
+// pushScript enqueues ready job, ARGV: queue key, priority, group, job
+var pushScript = redis.NewScript(enqueueLua + `
+enqueue(ARGV[1], ARGV[2], ARGV[3], ARGV[4])
+return 1
+`)
+
+// promoteScript moves due delayed jobs to their ready lists, ARGV: queue key, now, default priority
+var promoteScript = redis.NewScript(enqueueLua + `
+local jobs = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[2], "LIMIT", 0, 100)
+for _, data in ipairs(jobs) do
+	redis.call("ZREM", KEYS[1], data)
+	local job = cjson.decode(data)
+	local priority = job.priority
+	if type(priority) ~= "string" or priority == "" then
+		priority = ARGV[3]
+	end
+	local group = job.group
+	if type(group) ~= "string" or group == "" then
+		group = job.type
+	end
+	enqueue(ARGV[1], priority, group, data)
+end
+return #jobs
+`)
+
+// popScript dequeues next job trying priorities in ARGV order and claims it in sorted set
+// <queue>:processing until deadline, ARGV: queue key, deadline, priorities...
+// Jobs pushed before priorities existed are drained from the plain queue list last
+var popScript = redis.NewScript(`
+local base = ARGV[1]
+local function claim(job)
+	if job then
+		redis.call("ZADD", base .. ":processing", ARGV[2], job)
+	end
+	return job
+end
+for i = 3, #ARGV do
+	local priority = ARGV[i]
+	local groups = base .. ":groups:" .. priority
+	local group = redis.call("LINDEX", groups, 0)
+	if group then
+		local list = base .. ":ready:" .. priority .. ":" .. group
+		local credits = base .. ":credits:" .. priority
+		local job = redis.call("RPOP", list)
+		local weight = tonumber(redis.call("HGET", base .. ":weights", group) or "1")
+		local used = redis.call("HINCRBY", credits, group, 1)
+		local left = redis.call("LLEN", list)
+		if left == 0 or used >= weight then
+			redis.call("LPOP", groups)
+			redis.call("HDEL", credits, group)
+			if left > 0 then
+				redis.call("RPUSH", groups, group)
+			end
+		end
+		if job then
+			redis.call("HINCRBY", base .. ":depth", priority, -1)
+			return claim(job)
+		end
+	end
+end
+return claim(redis.call("RPOP", base))
+`)
+
+// priorityLevels returns levels ordered by weight, highest first
+func priorityLevels(priorities map[Priority]int) []Priority {
+	levels := make([]Priority, 0, len(priorities))
+	for level := range priorities {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if priorities[levels[i]] != priorities[levels[j]] {
+			return priorities[levels[i]] > priorities[levels[j]]
+		}
+		return levels[i] < levels[j]
+	})
+	return levels
+}
+
+// dequeueOrder picks a level at random weighted by Config.Priorities, followed by remaining levels
+// highest first, so low priorities still progress while high ones have a backlog
+func dequeueOrder() []interface{} {
+	levels := priorityLevels(config.Priorities)
+	total := 0
+	for _, level := range levels {
+		total += config.Priorities[level]
+	}
+
+	pick := rand.IntN(total)
+	order := make([]interface{}, 0, len(levels))
+	for _, level := range levels {
+		if pick -= config.Priorities[level]; pick < 0 {
+			order = append(order, string(level))
+			break
+		}
+	}
+	for _, level := range levels {
+		if string(level) != order[0] {
+			order = append(order, string(level))
+		}
+	}
+	return order
+}
+
+// pop dequeues next ready job of queues, queues listed first take precedence. The job stays claimed
+// in the processing set of its queue for VisibilityTimeout, see ack
+func pop(ctx context.Context, rdb *redis.Client) (string, string, error) {
+	deadline := time.Now().Add(config.VisibilityTimeout).UnixMilli()
+	args := append([]interface{}{"", deadline}, dequeueOrder()...)
+	for _, queue := range config.Queues {
+		args[0] = Key(queue)
+		data, err := popScript.Run(ctx, rdb, nil, args...).Text()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		return queue, data, err
+	}
+	return "", "", redis.Nil
+}
+
+// Depth returns number of ready jobs in queue per priority
+func Depth(ctx context.Context, queue string) (map[Priority]int64, error) {
+	rdb, err := client()
+	if err != nil {
+		return nil, err
+	}
+	counts, err := rdb.HGetAll(ctx, Key(queue, "depth")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	depth := make(map[Priority]int64, len(config.Priorities))
+	for level := range config.Priorities {
+		depth[level] = 0
+	}
+	for level, count := range counts {
+		if n, err := strconv.ParseInt(count, 10, 64); err == nil && n > 0 {
+			depth[Priority(level)] = n
+		}
+	}
+	return depth, nil
+}
+
+// SetGroupWeight lets group of queue dequeue weight jobs per turn, weights below 2 reset to 1
+func SetGroupWeight(ctx context.Context, queue, group string, weight int) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	if weight <= 1 {
+		return rdb.HDel(ctx, Key(queue, "weights"), group).Err()
+	}
+	return rdb.HSet(ctx, Key(queue, "weights"), group, weight).Err()
+}
@@ -0,0 +1,93 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/requestctx"
+)
+
+// Overage decides what happens once a limit is reached
+type Overage string
+
+const (
+	// Block rejects consumption beyond limits with ErrExceeded
+	Block Overage = "block"
+	// Log allows consumption beyond limits and logs a warning
+	Log Overage = "log"
+)
+
+// Limits of an API key, zero is unlimited
+type Limits struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+// Usage represents consumption of an API key in the current periods, in UTC
+type Usage struct {
+	Key          string    `json:"key"`
+	Daily        int64     `json:"daily"`
+	Monthly      int64     `json:"monthly"`
+	Limits       Limits    `json:"limits"`
+	DailyReset   time.Time `json:"daily_reset"`
+	MonthlyReset time.Time `json:"monthly_reset"`
+	Exceeded     bool      `json:"exceeded"`
+}
+
+// Day represents daily usage of History
+type Day struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// Config represents quota configuration
+type Config struct {
+	// Limits applies to keys without LimitsFor result
+	Limits Limits
+	// LimitsFor resolves limits per key, e.g. from the plan of the API key
+	LimitsFor func(ctx context.Context, key string) (Limits, error)
+	Overage   Overage
+	// HistoryDays is how long daily counters are kept for History
+	HistoryDays int
+	// KeyFunc resolves key of request for Middleware, requests without key are not counted. It must only
+	// return authenticated identities, defaults to SubjectID of requestctx.User set by your auth middleware
+	KeyFunc func(c *fiber.Ctx) string
+}
+
+// DefaultConfig is used until Init is called
+var DefaultConfig = Config{
+	Overage:     Block,
+	HistoryDays: 62,
+	KeyFunc: func(c *fiber.Ctx) string {
+		user, _ := requestctx.User.From(c)
+		if subject, ok := user.(interface{ SubjectID() string }); ok {
+			return subject.SubjectID()
+		}
+		return ""
+	},
+}
+
+var config = DefaultConfig
+
+// Init sets global quota configuration, empty fields fall back to DefaultConfig
+func Init(cfg Config) {
+	if cfg.Overage == "" {
+		cfg.Overage = DefaultConfig.Overage
+	}
+	if cfg.HistoryDays <= 0 {
+		cfg.HistoryDays = DefaultConfig.HistoryDays
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = DefaultConfig.KeyFunc
+	}
+	config = cfg
+}
+
+// GetConfig returns current quota configuration
+func GetConfig() Config {
+	return config
+}
+
+// Prefix is prepended to Redis keys of usage counters
+var Prefix = "nest:quota:"
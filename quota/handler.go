@@ -0,0 +1,86 @@
+package quota
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/response"
+)
+
+// Middleware consumes one unit per request of the key resolved by Config.KeyFunc,
+// setting X-Quota-* headers. Blocked requests get 429 with Retry-After
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := config.KeyFunc(c)
+		if key == "" {
+			return c.Next()
+		}
+
+		usage, err := Consume(c.UserContext(), key, 1)
+		if err != nil && !errors.Is(err, ErrExceeded) {
+			return err
+		}
+		setHeaders(c, usage)
+		if errors.Is(err, ErrExceeded) {
+			reset := usage.MonthlyReset
+			if usage.Limits.Daily > 0 && usage.Daily >= usage.Limits.Daily {
+				reset = usage.DailyReset
+			}
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(time.Until(reset).Seconds())+1))
+			return response.Error(c, fiber.StatusTooManyRequests, err)
+		}
+		return c.Next()
+	}
+}
+
+func setHeaders(c *fiber.Ctx, usage Usage) {
+	if usage.Limits.Daily > 0 {
+		c.Set("X-Quota-Daily-Limit", strconv.FormatInt(usage.Limits.Daily, 10))
+		c.Set("X-Quota-Daily-Remaining", strconv.FormatInt(max(usage.Limits.Daily-usage.Daily, 0), 10))
+	}
+	if usage.Limits.Monthly > 0 {
+		c.Set("X-Quota-Monthly-Limit", strconv.FormatInt(usage.Limits.Monthly, 10))
+		c.Set("X-Quota-Monthly-Remaining", strconv.FormatInt(max(usage.Limits.Monthly-usage.Monthly, 0), 10))
+	}
+}
+
+// Register mounts GET /quota reporting usage of the requesting key
+func Register(router fiber.Router) {
+	router.Get("/quota", func(c *fiber.Ctx) error {
+		key := config.KeyFunc(c)
+		if key == "" {
+			return response.Error(c, fiber.StatusUnauthorized, errors.New("missing API key"))
+		}
+		return usageHandler(c, key)
+	})
+}
+
+// RegisterAdmin mounts usage reports of any key on router, which must be protected by the caller
+//
+//	GET /quota/:key
+//	GET /quota/:key/history?days=30
+func RegisterAdmin(router fiber.Router) {
+	router.Get("/quota/:key", func(c *fiber.Ctx) error {
+		return usageHandler(c, c.Params("key"))
+	})
+	router.Get("/quota/:key/history", historyHandler)
+}
+
+func usageHandler(c *fiber.Ctx, key string) error {
+	usage, err := Get(c.UserContext(), key)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, usage, nil)
+}
+
+func historyHandler(c *fiber.Ctx) error {
+	days := min(max(c.QueryInt("days", 30), 1), config.HistoryDays)
+	history, err := History(c.UserContext(), c.Params("key"), days)
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, history, nil)
+}
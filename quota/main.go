@@ -0,0 +1,206 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/logger"
+)
+
+// Errors returned by quota functions
+var (
+	ErrNoRedis  = errors.New("quota : redis client is not initialized")
+	ErrExceeded = errors.New("quota : quota exceeded")
+)
+
+// consumeScript increments daily and monthly counters, when blocking only if both stay within
+// limits. KEYS: daily, monthly. ARGV: n, daily limit, monthly limit, block, daily ttl, monthly ttl
+var consumeScript = redis.NewScript(`
+local n = tonumber(ARGV[1])
+local daily = tonumber(redis.call("GET", KEYS[1]) or "0")
+local monthly = tonumber(redis.call("GET", KEYS[2]) or "0")
+local dailyLimit = tonumber(ARGV[2])
+local monthlyLimit = tonumber(ARGV[3])
+local exceeded = (dailyLimit > 0 and daily + n > dailyLimit) or (monthlyLimit > 0 and monthly + n > monthlyLimit)
+if exceeded and ARGV[4] == "1" then
+	return {daily, monthly, 1}
+end
+daily = redis.call("INCRBY", KEYS[1], n)
+monthly = redis.call("INCRBY", KEYS[2], n)
+redis.call("EXPIRE", KEYS[1], ARGV[5])
+redis.call("EXPIRE", KEYS[2], ARGV[6])
+if exceeded then
+	return {daily, monthly, 1}
+end
+return {daily, monthly, 0}
+`)
+
+func client() (*redis.Client, error) {
+	if database.RedisClient == nil {
+		return nil, ErrNoRedis
+	}
+	return database.RedisClient, nil
+}
+
+func dailyKey(key string, t time.Time) string {
+	return Prefix + key + ":d:" + t.Format("20060102")
+}
+
+func monthlyKey(key string, t time.Time) string {
+	return Prefix + key + ":m:" + t.Format("200601")
+}
+
+// periods returns reset times of current day and month in UTC
+func periods(now time.Time) (time.Time, time.Time) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	return day, month
+}
+
+// limitsOf resolves limits of key
+func limitsOf(ctx context.Context, key string) (Limits, error) {
+	if config.LimitsFor != nil {
+		return config.LimitsFor(ctx, key)
+	}
+	return config.Limits, nil
+}
+
+// Consume records n units of usage for key. With Block overage, consumption that would exceed
+// a limit is not recorded and ErrExceeded is returned with current usage
+func Consume(ctx context.Context, key string, n int64) (Usage, error) {
+	rdb, err := client()
+	if err != nil {
+		return Usage{}, err
+	}
+	limits, err := limitsOf(ctx, key)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	now := time.Now().UTC()
+	dayReset, monthReset := periods(now)
+	block := "0"
+	if config.Overage == Block {
+		block = "1"
+	}
+	// Daily counters outlive their day for History
+	dailyTTL := int64(time.Until(dayReset).Seconds()) + int64(config.HistoryDays)*86400
+	monthlyTTL := int64(time.Until(monthReset).Seconds()) + 86400
+
+	values, err := consumeScript.Run(ctx, rdb,
+		[]string{dailyKey(key, now), monthlyKey(key, now)},
+		n, limits.Daily, limits.Monthly, block, dailyTTL, monthlyTTL,
+	).Int64Slice()
+	if err != nil {
+		return Usage{}, err
+	}
+
+	usage := Usage{
+		Key:          key,
+		Daily:        values[0],
+		Monthly:      values[1],
+		Limits:       limits,
+		DailyReset:   dayReset,
+		MonthlyReset: monthReset,
+		Exceeded:     values[2] == 1,
+	}
+	if usage.Exceeded {
+		if config.Overage == Block {
+			return usage, ErrExceeded
+		}
+		logger.Get().WarnContext(ctx, "quota exceeded", "key", key, "daily", usage.Daily, "monthly", usage.Monthly)
+	}
+	return usage, nil
+}
+
+// Check returns usage of key, ErrExceeded when a limit is reached and overage blocks
+func Check(ctx context.Context, key string) (Usage, error) {
+	usage, err := Get(ctx, key)
+	if err != nil {
+		return usage, err
+	}
+	if usage.Exceeded && config.Overage == Block {
+		return usage, ErrExceeded
+	}
+	return usage, nil
+}
+
+// Get returns usage of key without consuming
+func Get(ctx context.Context, key string) (Usage, error) {
+	rdb, err := client()
+	if err != nil {
+		return Usage{}, err
+	}
+	limits, err := limitsOf(ctx, key)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	now := time.Now().UTC()
+	values, err := rdb.MGet(ctx, dailyKey(key, now), monthlyKey(key, now)).Result()
+	if err != nil {
+		return Usage{}, err
+	}
+
+	dayReset, monthReset := periods(now)
+	usage := Usage{
+		Key:          key,
+		Daily:        toInt(values[0]),
+		Monthly:      toInt(values[1]),
+		Limits:       limits,
+		DailyReset:   dayReset,
+		MonthlyReset: monthReset,
+	}
+	usage.Exceeded = (limits.Daily > 0 && usage.Daily >= limits.Daily) ||
+		(limits.Monthly > 0 && usage.Monthly >= limits.Monthly)
+	return usage, nil
+}
+
+// History returns daily usage of key for the last days, oldest first
+func History(ctx context.Context, key string, days int) ([]Day, error) {
+	rdb, err := client()
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	keys := make([]string, days)
+	history := make([]Day, days)
+	for i := range days {
+		t := now.AddDate(0, 0, i-days+1)
+		keys[i] = dailyKey(key, t)
+		history[i].Date = t.Format(time.DateOnly)
+	}
+
+	values, err := rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		history[i].Count = toInt(value)
+	}
+	return history, nil
+}
+
+// Reset clears current daily and monthly usage of key
+func Reset(ctx context.Context, key string) error {
+	rdb, err := client()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	return rdb.Del(ctx, dailyKey(key, now), monthlyKey(key, now)).Err()
+}
+
+func toInt(value interface{}) int64 {
+	s, _ := value.(string)
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
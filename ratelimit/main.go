@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+)
+
+var ErrNoRedis = errors.New("ratelimit : redis client is not initialized")
+
+// Prefix is prepended to all limiter keys
+var Prefix = "nest:ratelimit:"
+
+// Result represents outcome of a limiter check
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// slidingScript keeps request timestamps of the window in a sorted set, returning
+// remaining requests and milliseconds until the oldest request leaves the window when denied
+var slidingScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], window)
+	return {1, limit - count - 1, 0}
+end
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {0, 0, tonumber(oldest[2]) + window - now}
+`)
+
+// Allow records a request for key and reports whether it is within limit per sliding window
+func Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	if database.RedisClient == nil {
+		return Result{}, ErrNoRedis
+	}
+
+	now := time.Now().UnixMilli()
+	values, err := slidingScript.Run(ctx, database.RedisClient, []string{Prefix + key},
+		strconv.FormatInt(now, 10),
+		strconv.FormatInt(window.Milliseconds(), 10),
+		strconv.Itoa(limit),
+		uuid.NewString(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package realtime
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Message represents event delivered to subscribers
+type Message struct {
+	Channel string          `json:"channel"`
+	Event   string          `json:"event,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// command represents client request sent over WebSocket
+type command struct {
+	Action  string `json:"action"`
+	Channel string `json:"channel"`
+}
+
+// Config represents hub configuration
+type Config struct {
+	// Prefix of Redis pub/sub channels
+	Prefix string
+	// Authenticate resolves user from request before upgrade, returning error rejects with 401
+	Authenticate func(c *fiber.Ctx) (interface{}, error)
+	// Authorize decides whether user may subscribe to channel
+	Authorize func(user interface{}, channel string) bool
+	// BufferSize is number of pending messages per client before it is dropped
+	BufferSize int
+	// PingInterval keeps idle connections alive
+	PingInterval time.Duration
+}
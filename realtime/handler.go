@@ -0,0 +1,170 @@
+package realtime
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const userLocal = "realtime_user"
+
+// authenticate runs Authenticate hook and stores user in locals
+func (h *Hub) authenticate(c *fiber.Ctx) error {
+	if h.config.Authenticate == nil {
+		return nil
+	}
+	user, err := h.config.Authenticate(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	c.Locals(userLocal, user)
+	return nil
+}
+
+// WebSocket returns handler accepting subscribe/unsubscribe commands:
+//
+//	{"action":"subscribe","channel":"orders"}
+func (h *Hub) WebSocket() fiber.Handler {
+	ws := websocket.New(func(conn *websocket.Conn) {
+		c := h.newClient(conn.Locals(userLocal))
+		defer h.remove(c)
+
+		// Writer goroutine owns outgoing frames
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ticker := time.NewTicker(h.config.PingInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case payload, ok := <-c.send:
+					if !ok {
+						conn.WriteMessage(websocket.CloseMessage, nil)
+						return
+					}
+					if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+						return
+					}
+				case <-ticker.C:
+					if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+						return
+					}
+				}
+			}
+		}()
+
+		for {
+			var cmd command
+			if err := conn.ReadJSON(&cmd); err != nil {
+				break
+			}
+			switch cmd.Action {
+			case "subscribe":
+				if !h.subscribe(c, cmd.Channel) {
+					h.reply(c, cmd.Channel, "error", "subscription denied")
+				} else {
+					h.reply(c, cmd.Channel, "subscribed", nil)
+				}
+			case "unsubscribe":
+				h.unsubscribe(c, cmd.Channel)
+				h.reply(c, cmd.Channel, "unsubscribed", nil)
+			}
+		}
+
+		h.remove(c)
+		<-done
+	})
+
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+		if err := h.authenticate(c); err != nil {
+			return err
+		}
+		return ws(c)
+	}
+}
+
+// reply sends control message to single client
+func (h *Hub) reply(c *client, channel, event string, data interface{}) {
+	raw, _ := json.Marshal(data)
+	payload, _ := json.Marshal(Message{Channel: channel, Event: event, Data: raw})
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- payload:
+	default:
+	}
+}
+
+// SSE returns Server-Sent Events handler subscribing to ?channels=a,b
+func (h *Hub) SSE() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if err := h.authenticate(ctx); err != nil {
+			return err
+		}
+
+		c := h.newClient(ctx.Locals(userLocal))
+		for _, channel := range strings.Split(ctx.Query("channels"), ",") {
+			channel = strings.TrimSpace(channel)
+			if channel == "" {
+				continue
+			}
+			if !h.subscribe(c, channel) {
+				h.remove(c)
+				return fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("subscription to '%s' denied", channel))
+			}
+		}
+
+		ctx.Set(fiber.HeaderContentType, "text/event-stream")
+		ctx.Set(fiber.HeaderCacheControl, "no-cache")
+		ctx.Set(fiber.HeaderConnection, "keep-alive")
+		ctx.Set("X-Accel-Buffering", "no")
+
+		ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer h.remove(c)
+
+			ticker := time.NewTicker(h.config.PingInterval)
+			defer ticker.Stop()
+
+			fmt.Fprintf(w, "event: ready\ndata: {\"id\":%q}\n\n", c.id)
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			for {
+				select {
+				case payload, ok := <-c.send:
+					if !ok {
+						return
+					}
+					var msg Message
+					json.Unmarshal(payload, &msg)
+					event := msg.Event
+					if event == "" {
+						event = "message"
+					}
+					fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+				case <-ticker.C:
+					fmt.Fprint(w, ": ping\n\n")
+				}
+				// Flush fails once the client disconnects
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		})
+		return nil
+	}
+}
@@ -0,0 +1,202 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+)
+
+// client represents single connected subscriber
+type client struct {
+	id       string
+	user     interface{}
+	send     chan []byte
+	channels map[string]struct{}
+	closed   bool
+}
+
+// Hub routes messages between channels and connected clients
+type Hub struct {
+	config   Config
+	mu       sync.RWMutex
+	channels map[string]map[*client]struct{}
+	pubsub   *redis.PubSub
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New creates hub
+func New(cfg Config) *Hub {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "nest:realtime:"
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 64
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 30 * time.Second
+	}
+	return &Hub{
+		config:   cfg,
+		channels: make(map[string]map[*client]struct{}),
+	}
+}
+
+// Start subscribes to Redis so broadcasts from any instance reach local clients
+func (h *Hub) Start(ctx context.Context) error {
+	if database.RedisClient == nil {
+		return nil
+	}
+
+	ctx, h.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	h.pubsub = database.RedisClient.PSubscribe(ctx, h.config.Prefix+"*")
+	if _, err := h.pubsub.Receive(ctx); err != nil {
+		h.pubsub.Close()
+		return fmt.Errorf("failed to subscribe realtime channels: %w", err)
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		for msg := range h.pubsub.Channel() {
+			h.dispatch(strings.TrimPrefix(msg.Channel, h.config.Prefix), []byte(msg.Payload))
+		}
+	}()
+	return nil
+}
+
+// Stop closes Redis subscription and disconnects clients
+func (h *Hub) Stop(ctx context.Context) error {
+	if h.pubsub != nil {
+		h.pubsub.Close()
+		h.cancel()
+		h.wg.Wait()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, clients := range h.channels {
+		for c := range clients {
+			h.closeClient(c)
+		}
+	}
+	h.channels = make(map[string]map[*client]struct{})
+	return nil
+}
+
+// Broadcast sends event to channel subscribers on all instances
+func (h *Hub) Broadcast(ctx context.Context, channel, event string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(Message{Channel: channel, Event: event, Data: raw})
+	if err != nil {
+		return err
+	}
+
+	if database.RedisClient == nil || h.pubsub == nil {
+		h.dispatch(channel, payload)
+		return nil
+	}
+	return database.RedisClient.Publish(ctx, h.config.Prefix+channel, payload).Err()
+}
+
+// Subscribers returns number of local subscribers of channel
+func (h *Hub) Subscribers(channel string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.channels[channel])
+}
+
+func (h *Hub) newClient(user interface{}) *client {
+	return &client{
+		id:       uuid.NewString(),
+		user:     user,
+		send:     make(chan []byte, h.config.BufferSize),
+		channels: make(map[string]struct{}),
+	}
+}
+
+// dispatch delivers payload to local subscribers, slow clients are disconnected
+func (h *Hub) dispatch(channel string, payload []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.channels[channel] {
+		select {
+		case c.send <- payload:
+		default:
+			h.removeLocked(c)
+		}
+	}
+}
+
+func (h *Hub) authorized(user interface{}, channel string) bool {
+	if channel == "" {
+		return false
+	}
+	if h.config.Authorize == nil {
+		return true
+	}
+	return h.config.Authorize(user, channel)
+}
+
+func (h *Hub) subscribe(c *client, channel string) bool {
+	if !h.authorized(c.user, channel) {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	if h.channels[channel] == nil {
+		h.channels[channel] = make(map[*client]struct{})
+	}
+	h.channels[channel][c] = struct{}{}
+	c.channels[channel] = struct{}{}
+	return true
+}
+
+func (h *Hub) unsubscribe(c *client, channel string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unsubscribeLocked(c, channel)
+}
+
+func (h *Hub) unsubscribeLocked(c *client, channel string) {
+	delete(h.channels[channel], c)
+	if len(h.channels[channel]) == 0 {
+		delete(h.channels, channel)
+	}
+	delete(c.channels, channel)
+}
+
+func (h *Hub) remove(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(c)
+}
+
+func (h *Hub) removeLocked(c *client) {
+	for channel := range c.channels {
+		h.unsubscribeLocked(c, channel)
+	}
+	h.closeClient(c)
+}
+
+func (h *Hub) closeClient(c *client) {
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
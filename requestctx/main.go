@@ -0,0 +1,79 @@
+package requestctx
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/uptrace/bun"
+)
+
+// Key is typed request value stored in context.Context and, for fiber requests, in locals under Name
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates key of values of T, name is the fiber locals key
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// Canonical keys of values modules share
+var (
+	// RequestID is set by requestid middleware
+	RequestID = NewKey[string]("requestid")
+	// User is authenticated user, authz expects it to implement authz.Subject
+	User = NewKey[any]("user")
+	// Tenant identifies tenant of request
+	Tenant = NewKey[string]("tenant")
+	// Locale is set by i18n middleware
+	Locale = NewKey[string]("locale")
+	// Tx is innermost transaction started by database.RunInTx, use database.TxFromContext for a session
+	Tx = NewKey[bun.Tx]("tx")
+)
+
+// Name returns fiber locals key
+func (k *Key[T]) Name() string {
+	return k.name
+}
+
+// With returns context carrying value
+func (k *Key[T]) With(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, k, value)
+}
+
+// Get returns value of context
+func (k *Key[T]) Get(ctx context.Context) (T, bool) {
+	if ctx == nil {
+		var zero T
+		return zero, false
+	}
+	value, ok := ctx.Value(k).(T)
+	return value, ok
+}
+
+// Value returns value of context, zero when absent
+func (k *Key[T]) Value(ctx context.Context) T {
+	value, _ := k.Get(ctx)
+	return value
+}
+
+// Set stores value in locals and user context of request
+func (k *Key[T]) Set(c *fiber.Ctx, value T) {
+	c.Locals(k.name, value)
+	c.SetUserContext(k.With(c.UserContext(), value))
+}
+
+// From returns value of request from locals, falling back to user context
+func (k *Key[T]) From(c *fiber.Ctx) (T, bool) {
+	if value, ok := c.Locals(k.name).(T); ok {
+		return value, true
+	}
+	return k.Get(c.UserContext())
+}
+
+// UserAs returns authenticated user of context as T
+func UserAs[T any](ctx context.Context) (T, bool) {
+	user, _ := User.Get(ctx)
+	typed, ok := user.(T)
+	return typed, ok
+}
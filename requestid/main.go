@@ -0,0 +1,93 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rikiihsan/nest/requestctx"
+	"github.com/uptrace/bun"
+)
+
+// Config represents request ID middleware configuration
+type Config struct {
+	// Header read from request and written to response
+	Header string
+	// Local is fiber locals key holding request ID
+	Local string
+	// Generator creates new IDs, defaults to UUID v4
+	Generator func() string
+	// IgnoreIncoming always generates a new ID
+	IgnoreIncoming bool
+	// DisableQueryComment stops prefixing SQL with /* req:<id> */
+	DisableQueryComment bool
+}
+
+// New returns middleware generating or accepting request ID
+func New(configs ...Config) fiber.Handler {
+	cfg := Config{}
+	if len(configs) > 0 {
+		cfg = configs[0]
+	}
+	if cfg.Header == "" {
+		cfg.Header = fiber.HeaderXRequestID
+	}
+	if cfg.Local == "" {
+		cfg.Local = requestctx.RequestID.Name()
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = uuid.NewString
+	}
+
+	return func(c *fiber.Ctx) error {
+		id := ""
+		if !cfg.IgnoreIncoming {
+			id = c.Get(cfg.Header)
+		}
+		if !Valid(id) {
+			id = cfg.Generator()
+		}
+
+		c.Locals(cfg.Local, id)
+		c.Set(cfg.Header, id)
+
+		ctx := WithContext(c.UserContext(), id)
+		if !cfg.DisableQueryComment {
+			ctx = bun.WithComment(ctx, "req:"+id)
+		}
+		c.SetUserContext(ctx)
+
+		return c.Next()
+	}
+}
+
+// Valid reports whether incoming ID is safe to propagate
+func Valid(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithContext returns context carrying request ID
+func WithContext(ctx context.Context, id string) context.Context {
+	return requestctx.RequestID.With(ctx, id)
+}
+
+// FromContext returns request ID stored in context
+func FromContext(ctx context.Context) string {
+	return requestctx.RequestID.Value(ctx)
+}
+
+// Get returns request ID of fiber request
+func Get(c *fiber.Ctx) string {
+	return FromContext(c.UserContext())
+}
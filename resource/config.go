@@ -0,0 +1,60 @@
+package resource
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest"
+	"github.com/rikiihsan/nest/query"
+	"github.com/uptrace/bun"
+)
+
+var (
+	// ErrNotFillable is returned by create and update when Config.Fillable is empty
+	ErrNotFillable = errors.New("resource : Config.Fillable is required for create and update")
+	// ErrCompositeKey is returned for models whose primary key isn't a single column
+	ErrCompositeKey = errors.New("resource : composite primary keys are not supported")
+)
+
+// Action represents REST endpoint of a resource
+type Action string
+
+// Resource actions, also the suffix of "<Permission>:<action>" permissions
+const (
+	ActionIndex  Action = "index"
+	ActionShow   Action = "show"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// AllActions are registered when Config.Actions is empty
+var AllActions = []Action{ActionIndex, ActionShow, ActionCreate, ActionUpdate, ActionDelete}
+
+// Meta represents pagination of index responses
+type Meta struct {
+	Page     int `json:"page"`
+	PerPage  int `json:"per_page"`
+	Total    int `json:"total"`
+	LastPage int `json:"last_page"`
+}
+
+// Config represents resource configuration of model T
+type Config[T any] struct {
+	// Service handles validation, transactions and events of writes
+	Service *nest.Service[T]
+	// Permission prefix, when set every action requires "<Permission>:<action>" through authz.Require
+	Permission string
+	// Actions to register, all when empty
+	Actions []Action
+	// Query allowlists filters, sorts, fields and page sizes of index
+	Query query.Spec
+	// Scope restricts queries of index, show, update and delete, e.g. to the current tenant. Created
+	// and updated entities must remain within it or the write is rolled back with 403
+	Scope func(c *fiber.Ctx, q *bun.SelectQuery) *bun.SelectQuery
+	// Fillable lists columns create and update accept from the request body, others such as primary
+	// keys, roles or tenant columns are never bound
+	Fillable []string
+	// Assign sets server-side fields after binding in create and update, e.g. tenant or owner of request
+	Assign func(c *fiber.Ctx, entity *T) error
+}
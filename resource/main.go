@@ -0,0 +1,265 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/authz"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/database/repository"
+	"github.com/rikiihsan/nest/query"
+	"github.com/rikiihsan/nest/response"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// Register mounts REST endpoints of T under path on router. Errors are returned to the app
+// error handler, apperror.ErrorHandler renders validation errors and missing rows
+//
+//	GET    /path      index, query parameters of Config.Query
+//	GET    /path/:id  show
+//	POST   /path      create, Config.Fillable columns of the body
+//	PUT    /path/:id  update, Config.Fillable columns of the body are merged into the stored entity
+//	DELETE /path/:id  delete
+func Register[T any](router fiber.Router, path string, cfg Config[T]) fiber.Router {
+	actions := cfg.Actions
+	if len(actions) == 0 {
+		actions = AllActions
+	}
+
+	r := &handlers[T]{cfg: cfg}
+	group := router.Group(path)
+	for _, action := range actions {
+		var handler fiber.Handler
+		method, route := fiber.MethodGet, "/:id"
+		switch action {
+		case ActionIndex:
+			handler, route = r.index, "/"
+		case ActionShow:
+			handler = r.show
+		case ActionCreate:
+			handler, method, route = r.create, fiber.MethodPost, "/"
+		case ActionUpdate:
+			handler, method = r.update, fiber.MethodPut
+		case ActionDelete:
+			handler, method = r.delete, fiber.MethodDelete
+		default:
+			continue
+		}
+
+		if cfg.Permission != "" {
+			group.Add(method, route, authz.Require(cfg.Permission+":"+string(action)), handler)
+		} else {
+			group.Add(method, route, handler)
+		}
+	}
+	return group
+}
+
+type handlers[T any] struct {
+	cfg Config[T]
+}
+
+// scope returns Config.Scope bound to request
+func (r *handlers[T]) scope(c *fiber.Ctx) repository.QueryFunc {
+	return func(q *bun.SelectQuery) *bun.SelectQuery {
+		if r.cfg.Scope != nil {
+			return r.cfg.Scope(c, q)
+		}
+		return q
+	}
+}
+
+// pk returns the primary key of T, composite keys are not addressable by :id
+func (r *handlers[T]) pk() (*schema.Field, error) {
+	db, err := database.GetDB(r.cfg.Service.Repo.Session)
+	if err != nil {
+		return nil, err
+	}
+	table := db.Table(reflect.TypeOf((*T)(nil)).Elem())
+	if len(table.PKs) != 1 {
+		return nil, ErrCompositeKey
+	}
+	return table.PKs[0], nil
+}
+
+// first returns entity with primary key id within scope
+func (r *handlers[T]) first(ctx context.Context, c *fiber.Ctx, id interface{}, mods ...repository.QueryFunc) (*T, error) {
+	pk, err := r.pk()
+	if err != nil {
+		return nil, err
+	}
+	mods = append([]repository.QueryFunc{r.scope(c), func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("?TableAlias.? = ?", bun.Ident(pk.Name), id)
+	}}, mods...)
+	return r.cfg.Service.Repo.First(ctx, mods...)
+}
+
+// lock returns entity of :id within scope from the transaction of ctx, locking its row where supported
+func (r *handlers[T]) lock(ctx context.Context, c *fiber.Ctx) (*T, error) {
+	db, err := database.GetDB(r.cfg.Service.Repo.Session)
+	if err != nil {
+		return nil, err
+	}
+	return r.first(ctx, c, c.Params("id"), func(q *bun.SelectQuery) *bun.SelectQuery {
+		switch db.Dialect().Name() {
+		case dialect.PG, dialect.MySQL:
+			return q.For("UPDATE")
+		}
+		return q
+	})
+}
+
+// find returns entity of :id within scope
+func (r *handlers[T]) find(c *fiber.Ctx) (*T, error) {
+	return r.first(c.UserContext(), c, c.Params("id"))
+}
+
+// bind parses request body into input and copies its Fillable columns onto entity, then applies
+// Config.Assign. Columns not listed keep their value. Returns Fillable columns and those Assign changed
+func (r *handlers[T]) bind(c *fiber.Ctx, entity, input *T) ([]string, error) {
+	if len(r.cfg.Fillable) == 0 {
+		return nil, ErrNotFillable
+	}
+	if err := c.BodyParser(input); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	db, err := database.GetDB(r.cfg.Service.Repo.Session)
+	if err != nil {
+		return nil, err
+	}
+	table := db.Table(reflect.TypeOf(entity).Elem())
+	dst, src := reflect.ValueOf(entity).Elem(), reflect.ValueOf(input).Elem()
+	columns := append([]string(nil), r.cfg.Fillable...)
+	for _, column := range r.cfg.Fillable {
+		field, ok := table.FieldMap[column]
+		if !ok || field.IsPK {
+			return nil, fmt.Errorf("resource : column %s of %s is not fillable", column, table.Name)
+		}
+		field.Value(dst).Set(field.Value(src))
+	}
+
+	if r.cfg.Assign == nil {
+		return columns, nil
+	}
+	before := make([]interface{}, len(table.DataFields))
+	for i, field := range table.DataFields {
+		before[i] = field.Value(dst).Interface()
+	}
+	if err := r.cfg.Assign(c, entity); err != nil {
+		return nil, err
+	}
+	for i, field := range table.DataFields {
+		if !reflect.DeepEqual(before[i], field.Value(dst).Interface()) && !slices.Contains(columns, field.Name) {
+			columns = append(columns, field.Name)
+		}
+	}
+	return columns, nil
+}
+
+// inScope fails write whose entity left Config.Scope, rolling back its transaction
+func (r *handlers[T]) inScope(ctx context.Context, c *fiber.Ctx, entity *T) error {
+	if r.cfg.Scope == nil {
+		return nil
+	}
+	pk, err := r.pk()
+	if err != nil {
+		return err
+	}
+	_, err = r.first(ctx, c, pk.Value(reflect.ValueOf(entity).Elem()).Interface())
+	if database.IsNotFound(err) {
+		return fiber.ErrForbidden
+	}
+	return err
+}
+
+func (r *handlers[T]) index(c *fiber.Ctx) error {
+	q, err := query.FromCtx(c, r.cfg.Query)
+	if err != nil {
+		return err
+	}
+
+	entities, total, err := r.cfg.Service.Repo.Paginate(c.UserContext(), q.Limit(), q.Offset(), r.scope(c), q.Apply)
+	if err != nil {
+		return err
+	}
+	if entities == nil {
+		entities = []T{}
+	}
+	return response.Success(c, entities, Meta{
+		Page:     q.Page,
+		PerPage:  q.PerPage,
+		Total:    total,
+		LastPage: (total + q.PerPage - 1) / q.PerPage,
+	})
+}
+
+func (r *handlers[T]) show(c *fiber.Ctx) error {
+	entity, err := r.find(c)
+	if err != nil {
+		return err
+	}
+	return response.Success(c, entity, nil)
+}
+
+func (r *handlers[T]) create(c *fiber.Ctx) error {
+	entity := new(T)
+	if _, err := r.bind(c, entity, new(T)); err != nil {
+		return err
+	}
+	err := r.cfg.Service.Transaction(c.UserContext(), func(ctx context.Context) error {
+		if err := r.cfg.Service.Create(ctx, entity); err != nil {
+			return err
+		}
+		return r.inScope(ctx, c, entity)
+	})
+	if err != nil {
+		return err
+	}
+	return response.Created(c, entity)
+}
+
+// update re-reads the entity on the primary inside the transaction and writes only the bound columns,
+// so concurrent writes to other columns are kept
+func (r *handlers[T]) update(c *fiber.Ctx) error {
+	var entity *T
+	err := r.cfg.Service.Transaction(c.UserContext(), func(ctx context.Context) error {
+		var err error
+		if entity, err = r.lock(ctx, c); err != nil {
+			return err
+		}
+		// body is parsed into a separate copy so it can't reach fields through shared pointers or maps
+		input, err := r.first(ctx, c, c.Params("id"))
+		if err != nil {
+			return err
+		}
+		columns, err := r.bind(c, entity, input)
+		if err != nil {
+			return err
+		}
+
+		if err := r.cfg.Service.Update(ctx, entity, columns...); err != nil {
+			return err
+		}
+		return r.inScope(ctx, c, entity)
+	})
+	if err != nil {
+		return err
+	}
+	return response.Success(c, entity, nil)
+}
+
+func (r *handlers[T]) delete(c *fiber.Ctx) error {
+	if _, err := r.find(c); err != nil {
+		return err
+	}
+	if err := r.cfg.Service.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return err
+	}
+	return response.Success(c, nil, nil)
+}
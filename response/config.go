@@ -0,0 +1,72 @@
+package response
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/requestctx"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// Envelope represents the standard JSON response structure
+type Envelope struct {
+	Status    string      `json:"status"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data,omitempty"`
+	Meta      interface{} `json:"meta,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// Config represents response envelope configuration
+type Config struct {
+	SuccessStatus     string
+	ErrorStatus       string
+	SuccessMessage    string
+	ValidationMessage string
+	// RequestIDHeader is read from the request (then the response) when RequestIDLocal is empty
+	RequestIDHeader string
+	RequestIDLocal  string
+	// HideInternalErrors replaces messages of 5xx errors with the status text
+	HideInternalErrors bool
+	// Translate localizes envelope messages, e.g. i18n.ResponseMessage
+	Translate func(c *fiber.Ctx, message string) string
+	// TranslateErrors localizes validation errors, e.g. i18n.ResponseErrors
+	TranslateErrors func(c *fiber.Ctx, errs []validator.ValidatorError) []validator.ValidatorError
+}
+
+// DefaultConfig is used until Init is called
+var DefaultConfig = Config{
+	SuccessStatus:      "success",
+	ErrorStatus:        "error",
+	SuccessMessage:     "OK",
+	ValidationMessage:  "Validation failed",
+	RequestIDHeader:    "X-Request-ID",
+	RequestIDLocal:     requestctx.RequestID.Name(),
+	HideInternalErrors: true,
+}
+
+var config = DefaultConfig
+
+// Init sets global response configuration, empty fields fall back to DefaultConfig
+func Init(cfg Config) {
+	if cfg.SuccessStatus == "" {
+		cfg.SuccessStatus = DefaultConfig.SuccessStatus
+	}
+	if cfg.ErrorStatus == "" {
+		cfg.ErrorStatus = DefaultConfig.ErrorStatus
+	}
+	if cfg.SuccessMessage == "" {
+		cfg.SuccessMessage = DefaultConfig.SuccessMessage
+	}
+	if cfg.ValidationMessage == "" {
+		cfg.ValidationMessage = DefaultConfig.ValidationMessage
+	}
+	if cfg.RequestIDHeader == "" {
+		cfg.RequestIDHeader = DefaultConfig.RequestIDHeader
+	}
+	config = cfg
+}
+
+// GetConfig returns the current response configuration
+func GetConfig() Config {
+	return config
+}
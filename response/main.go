@@ -0,0 +1,74 @@
+package response
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// requestID resolves the request ID from locals or headers
+func requestID(c *fiber.Ctx) string {
+	if config.RequestIDLocal != "" {
+		if id, ok := c.Locals(config.RequestIDLocal).(string); ok && id != "" {
+			return id
+		}
+	}
+	if id := c.Get(config.RequestIDHeader); id != "" {
+		return id
+	}
+	return string(c.Response().Header.Peek(config.RequestIDHeader))
+}
+
+// JSON writes an envelope with the given HTTP status
+func JSON(c *fiber.Ctx, code int, env Envelope) error {
+	env.RequestID = requestID(c)
+	if config.Translate != nil {
+		env.Message = config.Translate(c, env.Message)
+	}
+	return c.Status(code).JSON(env)
+}
+
+// Success writes a 200 response with data and optional meta
+func Success(c *fiber.Ctx, data interface{}, meta interface{}) error {
+	return JSON(c, fiber.StatusOK, Envelope{
+		Status:  config.SuccessStatus,
+		Message: config.SuccessMessage,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
+// Created writes a 201 response with data
+func Created(c *fiber.Ctx, data interface{}) error {
+	return JSON(c, fiber.StatusCreated, Envelope{
+		Status:  config.SuccessStatus,
+		Message: config.SuccessMessage,
+		Data:    data,
+	})
+}
+
+// Error writes an error response with the given HTTP status
+func Error(c *fiber.Ctx, code int, err error) error {
+	message := fiber.ErrInternalServerError.Message
+	if err != nil {
+		message = err.Error()
+	}
+	if code >= fiber.StatusInternalServerError && config.HideInternalErrors {
+		message = fiber.NewError(code).Message
+	}
+	return JSON(c, code, Envelope{
+		Status:  config.ErrorStatus,
+		Message: message,
+	})
+}
+
+// ValidationFailed writes a 422 response with validation errors
+func ValidationFailed(c *fiber.Ctx, errs []validator.ValidatorError) error {
+	if config.TranslateErrors != nil {
+		errs = config.TranslateErrors(c, errs)
+	}
+	return JSON(c, fiber.StatusUnprocessableEntity, Envelope{
+		Status:  config.ErrorStatus,
+		Message: config.ValidationMessage,
+		Errors:  errs,
+	})
+}
@@ -0,0 +1,62 @@
+package routing
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Source of requested version
+type Source string
+
+const (
+	// SourcePath reads version from path prefix, e.g. /v2/users
+	SourcePath Source = "path"
+	// SourceHeader reads version from Config.Header, e.g. API-Version: 2
+	SourceHeader Source = "header"
+	// SourceAccept reads version from vendor media type or version parameter,
+	// e.g. application/vnd.acme.v2+json or application/json; version=2
+	SourceAccept Source = "accept"
+)
+
+// Version describes API version mounted under /<Name>
+type Version struct {
+	Name string
+	// Deprecated versions respond with Deprecation header, Deprecated at its date when set
+	Deprecated   bool
+	DeprecatedAt time.Time
+	// Sunset date is sent in Sunset header, requests after it get 410 when GoneAfterSunset is set
+	Sunset          time.Time
+	GoneAfterSunset bool
+	// Link documents the deprecation or migration
+	Link string
+	// Middleware runs for every route of the version
+	Middleware []fiber.Handler
+}
+
+// Config represents versioned API configuration
+type Config struct {
+	// Sources are checked in order, SourcePath always wins for versioned paths
+	Sources []Source
+	Header  string
+	// Vendor of Accept media types, e.g. acme for application/vnd.acme.v2+json
+	Vendor string
+	// Default version for requests without one, defaults to the latest mounted version
+	Default string
+	// Next skips negotiation, e.g. for unversioned endpoints sharing the router
+	Next func(c *fiber.Ctx) bool
+}
+
+// DefaultConfig is used for empty fields passed to New
+var DefaultConfig = Config{
+	Sources: []Source{SourcePath, SourceHeader, SourceAccept},
+	Header:  "API-Version",
+}
+
+// Info describes mounted version for route listings
+type Info struct {
+	Name       string
+	Prefix     string
+	Deprecated bool
+	Sunset     time.Time
+}
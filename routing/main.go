@@ -0,0 +1,200 @@
+package routing
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/apperror"
+	"github.com/rikiihsan/nest/requestctx"
+)
+
+// ErrGone is returned for versions past their sunset date
+var ErrGone = apperror.New("gone", http.StatusGone, "API version was retired")
+
+// versionKey holds version serving the request
+var versionKey = requestctx.NewKey[string]("api_version")
+
+var (
+	mu       sync.RWMutex
+	registry []Info
+)
+
+// API mounts versions of an API on a router
+type API struct {
+	router   fiber.Router
+	prefix   string
+	config   Config
+	versions map[string]*Version
+	order    []string
+}
+
+// New creates versioned API on router. Header and Accept negotiation rewrite unversioned paths
+// to the requested version, so mount it on its own group, e.g. app.Group("/api")
+func New(router fiber.Router, cfg Config) *API {
+	if len(cfg.Sources) == 0 {
+		cfg.Sources = DefaultConfig.Sources
+	}
+	if cfg.Header == "" {
+		cfg.Header = DefaultConfig.Header
+	}
+
+	prefix := ""
+	if group, ok := router.(*fiber.Group); ok {
+		prefix = strings.TrimSuffix(group.Prefix, "/")
+	}
+
+	a := &API{router: router, prefix: prefix, config: cfg, versions: make(map[string]*Version)}
+	router.Use(a.negotiate)
+	return a
+}
+
+// Version mounts version under /<name> and returns its router
+func (a *API) Version(v Version) fiber.Router {
+	version := v
+	a.versions[v.Name] = &version
+	a.order = append(a.order, v.Name)
+
+	mu.Lock()
+	registry = append(registry, Info{
+		Name:       v.Name,
+		Prefix:     a.prefix + "/" + v.Name,
+		Deprecated: v.Deprecated || !v.DeprecatedAt.IsZero(),
+		Sunset:     v.Sunset,
+	})
+	mu.Unlock()
+
+	handlers := append([]fiber.Handler{a.serve(&version)}, v.Middleware...)
+	return a.router.Group("/"+v.Name, handlers...)
+}
+
+// negotiate rewrites unversioned paths to the version requested by header or Accept
+func (a *API) negotiate(c *fiber.Ctx) error {
+	if a.config.Next != nil && a.config.Next(c) {
+		return c.Next()
+	}
+
+	rest := strings.TrimPrefix(c.Path(), a.prefix)
+	if name, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/"); a.versions[name] != nil && slices.Contains(a.config.Sources, SourcePath) {
+		return c.Next()
+	}
+
+	name := a.requested(c)
+	if name == "" {
+		name = a.config.Default
+	}
+	if name == "" && len(a.order) > 0 {
+		name = a.order[len(a.order)-1]
+	}
+	if a.versions[name] == nil {
+		return apperror.ErrBadRequest.WithMessage("unsupported API version " + name)
+	}
+
+	c.Path(a.prefix + "/" + name + rest)
+	return c.Next()
+}
+
+// requested returns version of header or Accept sources
+func (a *API) requested(c *fiber.Ctx) string {
+	for _, source := range a.config.Sources {
+		switch source {
+		case SourceHeader:
+			if value := c.Get(a.config.Header); value != "" {
+				return normalize(value)
+			}
+		case SourceAccept:
+			if value := acceptVersion(c.Get(fiber.HeaderAccept), a.config.Vendor); value != "" {
+				return normalize(value)
+			}
+		}
+	}
+	return ""
+}
+
+// serve marks request with version and sends deprecation headers
+func (a *API) serve(v *Version) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		versionKey.Set(c, v.Name)
+
+		if !v.DeprecatedAt.IsZero() {
+			c.Set("Deprecation", "@"+strconv.FormatInt(v.DeprecatedAt.Unix(), 10))
+		} else if v.Deprecated {
+			c.Set("Deprecation", "true")
+		}
+		if !v.Sunset.IsZero() {
+			c.Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			if v.GoneAfterSunset && !time.Now().Before(v.Sunset) {
+				return ErrGone.WithMessage("API version " + v.Name + " was retired")
+			}
+		}
+		if v.Link != "" {
+			c.Append(fiber.HeaderLink, "<"+v.Link+`>; rel="deprecation"`)
+		}
+		return c.Next()
+	}
+}
+
+// Current returns version serving the request
+func Current(c *fiber.Ctx) string {
+	name, _ := versionKey.From(c)
+	return name
+}
+
+// Versions returns mounted versions of all APIs
+func Versions() []Info {
+	mu.RLock()
+	defer mu.RUnlock()
+	return slices.Clone(registry)
+}
+
+// VersionOf returns version serving route path
+func VersionOf(path string) (Info, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, info := range registry {
+		if path == info.Prefix || strings.HasPrefix(path, info.Prefix+"/") {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+// acceptVersion extracts version of vendor media type or version parameter
+func acceptVersion(accept, vendor string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		for _, param := range strings.Split(params, ";") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(key, "version") {
+				return strings.Trim(value, `"`)
+			}
+		}
+
+		prefix := "application/vnd."
+		if vendor != "" {
+			prefix += vendor + "."
+		}
+		if rest, ok := strings.CutPrefix(strings.ToLower(mediaType), prefix); ok {
+			version, _, _ := strings.Cut(rest, "+")
+			if vendor == "" {
+				_, version, _ = strings.Cut(version, ".")
+			}
+			if version != "" {
+				return version
+			}
+		}
+	}
+	return ""
+}
+
+// normalize turns numeric versions into path names, e.g. 2 into v2
+func normalize(version string) string {
+	version = strings.TrimSpace(version)
+	if _, err := strconv.Atoi(version); err == nil {
+		return "v" + version
+	}
+	return version
+}
@@ -0,0 +1,76 @@
+package saga
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Status is state of saga instance
+type Status string
+
+// Saga statuses, completed, compensated and failed are final
+const (
+	StatusRunning      Status = "running"
+	StatusCompensating Status = "compensating"
+	StatusCompleted    Status = "completed"
+	StatusCompensated  Status = "compensated"
+	// StatusFailed means a compensation kept failing and the saga needs manual attention
+	StatusFailed Status = "failed"
+)
+
+// Instance represents persisted run of a workflow
+type Instance struct {
+	bun.BaseModel `bun:"table:saga_instances,alias:si"`
+
+	ID        string    `bun:",pk" json:"id"`
+	Workflow  string    `bun:",notnull" json:"workflow"`
+	Status    Status    `bun:",notnull" json:"status"`
+	Step      int       `bun:",notnull,default:0" json:"step"`
+	Data      string    `bun:",notnull" json:"data"`
+	Error     string    `bun:",nullzero" json:"error,omitempty"`
+	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+// Bind decodes saga data into dst
+func (i *Instance) Bind(dst interface{}) error {
+	return json.Unmarshal([]byte(i.Data), dst)
+}
+
+// Set replaces saga data, persisted with the step that set it
+func (i *Instance) Set(data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	i.Data = string(raw)
+	return nil
+}
+
+// Final reports whether saga stopped running
+func (i *Instance) Final() bool {
+	return i.Status == StatusCompleted || i.Status == StatusCompensated || i.Status == StatusFailed
+}
+
+// StepLog represents executed action or compensation of a saga step
+type StepLog struct {
+	bun.BaseModel `bun:"table:saga_steps,alias:ss"`
+
+	ID         int64     `bun:",pk,autoincrement" json:"id"`
+	SagaID     string    `bun:",notnull" json:"saga_id"`
+	Step       string    `bun:",notnull" json:"step"`
+	Index      int       `bun:",notnull" json:"index"`
+	Compensate bool      `bun:",notnull" json:"compensate"`
+	Error      string    `bun:",nullzero" json:"error,omitempty"`
+	CreatedAt  time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// Config represents saga configuration
+type Config struct {
+	// Session stores saga state and runs step transactions
+	Session string
+	// Queue runs steps, defaults to first configured queue
+	Queue string
+}
@@ -0,0 +1,346 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/queue"
+	"github.com/uptrace/bun"
+)
+
+// JobType is queue job type running saga steps
+const JobType = "saga:step"
+
+var (
+	ErrUnknownWorkflow = errors.New("saga : unknown workflow")
+	ErrNotFound        = errors.New("saga : instance not found")
+	// errStale rolls back step whose instance was advanced by another worker
+	errStale = errors.New("saga : instance changed concurrently")
+)
+
+// StepFunc runs action or compensation of a step. It runs in a transaction of Config.Session committed
+// together with the saga state, so database work through database.IDB commits exactly once while
+// external calls must be idempotent
+type StepFunc func(ctx context.Context, saga *Instance) error
+
+// Step is workflow step, Compensate undoes Action once a later step failed and may be nil
+type Step struct {
+	Name       string
+	Action     StepFunc
+	Compensate StepFunc
+}
+
+// Workflow is ordered steps run by a saga
+type Workflow struct {
+	Name  string
+	Steps []Step
+}
+
+var (
+	mu        sync.RWMutex
+	config    = Config{Session: "default"}
+	workflows = make(map[string]*Workflow)
+)
+
+func init() {
+	queue.Register(JobType, handle)
+}
+
+// Init sets saga configuration
+func Init(cfg Config) {
+	if cfg.Session == "" {
+		cfg.Session = "default"
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	config = cfg
+}
+
+// GetConfig returns current saga configuration
+func GetConfig() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config
+}
+
+// CreateTables creates saga instance and step log tables if they do not exist
+func CreateTables(ctx context.Context, db bun.IDB) error {
+	for _, model := range []interface{}{(*Instance)(nil), (*StepLog)(nil)} {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Define registers workflow, steps run in order and failed runs compensate completed steps in reverse
+func Define(name string, steps ...Step) *Workflow {
+	mu.Lock()
+	defer mu.Unlock()
+	wf := &Workflow{Name: name, Steps: steps}
+	workflows[name] = wf
+	return wf
+}
+
+func workflowOf(name string) (*Workflow, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	wf, ok := workflows[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWorkflow, name)
+	}
+	return wf, nil
+}
+
+// Start stores new saga of workflow with data and queues its first step. Inside a transaction of
+// Config.Session the saga starts only once the transaction commits
+func Start(ctx context.Context, workflow string, data interface{}) (*Instance, error) {
+	wf, err := workflowOf(workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	saga := &Instance{
+		ID:        uuid.NewString(),
+		Workflow:  wf.Name,
+		Status:    StatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if len(wf.Steps) == 0 {
+		saga.Status = StatusCompleted
+	}
+	if err := saga.Set(data); err != nil {
+		return nil, fmt.Errorf("failed to encode saga data: %w", err)
+	}
+
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.NewInsert().Model(saga).Exec(ctx); err != nil {
+		return nil, err
+	}
+	if saga.Status == StatusRunning {
+		database.AfterCommit(ctx, func(ctx context.Context) {
+			enqueue(ctx, saga)
+		})
+	}
+	return saga, nil
+}
+
+// Find returns saga by ID
+func Find(ctx context.Context, id string) (*Instance, error) {
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return nil, err
+	}
+	saga := &Instance{}
+	if err := db.NewSelect().Model(saga).Where("id = ?", id).Limit(1).Scan(ctx); err != nil {
+		if database.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return saga, nil
+}
+
+// History returns executed steps of saga, oldest first
+func History(ctx context.Context, id string) ([]StepLog, error) {
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return nil, err
+	}
+	var logs []StepLog
+	if err := db.NewSelect().Model(&logs).Where("saga_id = ?", id).Order("id").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// Resume queues sagas still running or compensating without progress for staleAfter, e.g. whose job
+// was lost in a crash. staleAfter should exceed the longest queue retry backoff, returns queued count
+func Resume(ctx context.Context, staleAfter time.Duration) (int, error) {
+	db, err := database.GetDB(GetConfig().Session)
+	if err != nil {
+		return 0, err
+	}
+	var sagas []Instance
+	err = db.NewSelect().Model(&sagas).
+		Where("status IN (?)", bun.In([]Status{StatusRunning, StatusCompensating})).
+		Where("updated_at < ?", time.Now().Add(-staleAfter)).
+		Scan(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range sagas {
+		enqueue(ctx, &sagas[i])
+	}
+	return len(sagas), nil
+}
+
+// enqueue queues current step of saga, the job ID dedupes repeated queuing of the same step
+func enqueue(ctx context.Context, saga *Instance) {
+	opts := []queue.Option{queue.WithID(fmt.Sprintf("%s:%s:%d", saga.ID, saga.Status, saga.Step))}
+	if queueName := GetConfig().Queue; queueName != "" {
+		opts = append(opts, queue.OnQueue(queueName))
+	}
+	if _, err := queue.Enqueue(ctx, JobType, saga.ID, opts...); err != nil {
+		slog.ErrorContext(ctx, "failed to queue saga step, Resume will retry", "saga_id", saga.ID, "error", err)
+	}
+}
+
+// handle runs next step or compensation of saga in job payload
+func handle(ctx context.Context, job *queue.Job) error {
+	var id string
+	if err := job.Bind(&id); err != nil {
+		return fmt.Errorf("failed to decode saga id: %w", err)
+	}
+	saga, err := Find(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		slog.WarnContext(ctx, "saga step skipped, saga not found", "saga_id", id)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	wf, err := workflowOf(saga.Workflow)
+	if err != nil {
+		return err
+	}
+
+	// queue retries failed steps, the last attempt moves the saga on instead
+	lastAttempt := job.Attempts > job.MaxRetries
+	switch saga.Status {
+	case StatusRunning:
+		return advance(ctx, wf, saga, lastAttempt)
+	case StatusCompensating:
+		return compensate(ctx, wf, saga, lastAttempt)
+	}
+	return nil
+}
+
+// advance runs action of current step, its last failed attempt starts compensation
+func advance(ctx context.Context, wf *Workflow, saga *Instance, lastAttempt bool) error {
+	index := saga.Step
+	if index >= len(wf.Steps) {
+		return ignoreStale(transition(ctx, saga, index, StatusRunning, StatusCompleted, nil))
+	}
+	step := wf.Steps[index]
+
+	err := database.RunInTx(ctx, GetConfig().Session, func(ctx context.Context) error {
+		if err := step.Action(ctx, saga); err != nil {
+			return err
+		}
+		next := *saga
+		next.Step = index + 1
+		if next.Step == len(wf.Steps) {
+			next.Status = StatusCompleted
+		}
+		return save(ctx, &next, index, StatusRunning, &StepLog{Step: step.Name, Index: index})
+	})
+	if err == nil || errors.Is(err, errStale) || !lastAttempt {
+		return ignoreStale(err)
+	}
+
+	slog.WarnContext(ctx, "saga step failed, compensating", "saga_id", saga.ID, "step", step.Name, "error", err)
+	return ignoreStale(transition(ctx, saga, index, StatusRunning, StatusCompensating, &StepLog{Step: step.Name, Index: index, Error: err.Error()}))
+}
+
+// compensate undoes last completed step, its last failed attempt marks saga failed
+func compensate(ctx context.Context, wf *Workflow, saga *Instance, lastAttempt bool) error {
+	if saga.Step <= 0 {
+		return ignoreStale(transition(ctx, saga, saga.Step, StatusCompensating, StatusCompensated, nil))
+	}
+	index := min(saga.Step, len(wf.Steps)) - 1
+	step := wf.Steps[index]
+
+	err := database.RunInTx(ctx, GetConfig().Session, func(ctx context.Context) error {
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx, saga); err != nil {
+				return err
+			}
+		}
+		next := *saga
+		next.Step = index
+		if index == 0 {
+			next.Status = StatusCompensated
+		}
+		return save(ctx, &next, saga.Step, StatusCompensating, &StepLog{Step: step.Name, Index: index, Compensate: true})
+	})
+	if err == nil || errors.Is(err, errStale) || !lastAttempt {
+		return ignoreStale(err)
+	}
+
+	slog.ErrorContext(ctx, "saga compensation failed", "saga_id", saga.ID, "step", step.Name, "error", err)
+	return ignoreStale(transition(ctx, saga, saga.Step, StatusCompensating, StatusFailed, &StepLog{Step: step.Name, Index: index, Compensate: true, Error: err.Error()}))
+}
+
+// transition changes status of saga without running a step, logging entry when given
+func transition(ctx context.Context, saga *Instance, step int, from, to Status, entry *StepLog) error {
+	return database.RunInTx(ctx, GetConfig().Session, func(ctx context.Context) error {
+		// data changes of failed steps are discarded
+		current, err := Find(ctx, saga.ID)
+		if err != nil {
+			return err
+		}
+		current.Status = to
+		if entry != nil {
+			current.Error = entry.Error
+		}
+		return save(ctx, current, step, from, entry)
+	})
+}
+
+// save stores saga if it is still at step and status, logs entry when given and queues the next step
+// after commit
+func save(ctx context.Context, saga *Instance, step int, status Status, entry *StepLog) error {
+	db, err := database.IDB(ctx, GetConfig().Session)
+	if err != nil {
+		return err
+	}
+
+	saga.UpdatedAt = time.Now()
+	result, err := db.NewUpdate().Model(saga).
+		Column("status", "step", "data", "error", "updated_at").
+		WherePK().
+		Where("step = ?", step).
+		Where("status = ?", status).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return errStale
+	}
+
+	if entry != nil {
+		entry.SagaID, entry.CreatedAt = saga.ID, time.Now()
+		if _, err := db.NewInsert().Model(entry).Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	if !saga.Final() {
+		database.AfterCommit(ctx, func(ctx context.Context) {
+			enqueue(ctx, saga)
+		})
+	}
+	return nil
+}
+
+func ignoreStale(err error) error {
+	if errors.Is(err, errStale) {
+		return nil
+	}
+	return err
+}
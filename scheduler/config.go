@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Func is scheduled job function
+type Func func(ctx context.Context) error
+
+// Run represents single job execution record
+type Run struct {
+	Job        string        `json:"job"`
+	StartedAt  time.Time     `json:"started_at"`
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
+	Skipped    bool          `json:"skipped,omitempty"`
+	InstanceID string        `json:"instance_id,omitempty"`
+}
+
+// HistoryStore persists job runs
+type HistoryStore interface {
+	Record(ctx context.Context, run Run) error
+	Recent(ctx context.Context, job string, limit int) ([]Run, error)
+}
+
+// Config represents scheduler configuration
+type Config struct {
+	// History stores job runs, defaults to Redis when available
+	History HistoryStore
+	// HistoryLimit is number of runs kept per job
+	HistoryLimit int
+	// InstanceID identifies this process in run history
+	InstanceID string
+	// OnError is called when a job fails or panics
+	OnError func(job string, err error)
+	// Session holds stored job definitions and DBHistory runs, defaults to "default"
+	Session string
+	// ReloadInterval reloads stored definitions in Listen when change notifications are missed, defaults to 1m
+	ReloadInterval time.Duration
+}
+
+// CatchUp is policy for runs of stored jobs missed while no instance was running
+type CatchUp string
+
+const (
+	// CatchUpSkip resumes on the next planned run
+	CatchUpSkip CatchUp = "skip"
+	// CatchUpOnce runs once immediately when any run was missed
+	CatchUpOnce CatchUp = "once"
+	// CatchUpAll runs every missed run, at most MaxCatchUp
+	CatchUpAll CatchUp = "all"
+)
+
+// MaxCatchUp limits runs replayed by CatchUpAll
+var MaxCatchUp = 100
+
+// JobDefinition is job persisted in the database and editable at runtime, running the handler
+// registered with Handle under Handler
+type JobDefinition struct {
+	bun.BaseModel `bun:"table:scheduled_jobs,alias:sj"`
+
+	Name    string `bun:",pk" json:"name"`
+	Spec    string `bun:",notnull" json:"spec"`
+	Handler string `bun:",notnull" json:"handler"`
+	// Payload is JSON passed to the handler
+	Payload string  `json:"payload,omitempty"`
+	Enabled bool    `bun:",notnull" json:"enabled"`
+	CatchUp CatchUp `bun:",notnull,default:'skip'" json:"catch_up"`
+	// TimeoutSeconds cancels job context, zero runs without timeout
+	TimeoutSeconds int `bun:",notnull,default:0" json:"timeout_seconds"`
+	// Singleton runs job on one instance at a time using Redis lock
+	Singleton bool         `bun:",notnull,default:false" json:"singleton"`
+	LastRunAt bun.NullTime `json:"last_run_at"`
+	UpdatedAt time.Time    `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+// JobRun is run recorded by DBHistory
+type JobRun struct {
+	bun.BaseModel `bun:"table:scheduled_job_runs,alias:sjr"`
+
+	ID         int64     `bun:",pk,autoincrement" json:"id"`
+	Job        string    `bun:",notnull" json:"job"`
+	StartedAt  time.Time `bun:",notnull" json:"started_at"`
+	DurationMs int64     `bun:",notnull" json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	Skipped    bool      `bun:",notnull" json:"skipped"`
+	InstanceID string    `json:"instance_id,omitempty"`
+}
+
+// Job represents registered scheduled job
+type Job struct {
+	name      string
+	schedule  Schedule
+	fn        Func
+	timeout   time.Duration
+	singleton bool
+	lockTTL   time.Duration
+	next      time.Time
+	running   bool
+
+	// stored jobs come from JobDefinition rows, version detects edits
+	stored  bool
+	version time.Time
+	missed  int
+}
+
+// Name returns job name
+func (j *Job) Name() string {
+	return j.name
+}
+
+// Next returns next planned run time
+func (j *Job) Next() time.Time {
+	return j.next
+}
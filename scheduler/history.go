@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/rikiihsan/nest/database"
+)
+
+// RedisHistory stores runs in capped Redis lists
+type RedisHistory struct {
+	Prefix string
+	Limit  int
+}
+
+// Record stores run and trims history
+func (h *RedisHistory) Record(ctx context.Context, run Run) error {
+	if database.RedisClient == nil {
+		return nil
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	key := h.Prefix + run.Job
+	pipe := database.RedisClient.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, int64(h.Limit-1))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Recent returns latest runs of job, newest first
+func (h *RedisHistory) Recent(ctx context.Context, job string, limit int) ([]Run, error) {
+	if database.RedisClient == nil {
+		return nil, nil
+	}
+	items, err := database.RedisClient.LRange(ctx, h.Prefix+job, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]Run, 0, len(items))
+	for _, item := range items {
+		var run Run
+		if err := json.Unmarshal([]byte(item), &run); err == nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// MemoryHistory keeps runs in process memory
+type MemoryHistory struct {
+	Limit int
+	mu    sync.Mutex
+	runs  map[string][]Run
+}
+
+// Record stores run and trims history
+func (h *MemoryHistory) Record(ctx context.Context, run Run) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.runs == nil {
+		h.runs = make(map[string][]Run)
+	}
+	runs := append([]Run{run}, h.runs[run.Job]...)
+	if len(runs) > h.Limit {
+		runs = runs[:h.Limit]
+	}
+	h.runs[run.Job] = runs
+	return nil
+}
+
+// Recent returns latest runs of job, newest first
+func (h *MemoryHistory) Recent(ctx context.Context, job string, limit int) ([]Run, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	runs := h.runs[job]
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return append([]Run(nil), runs...), nil
+}
@@ -0,0 +1,396 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/lock"
+)
+
+// ErrJobRunning is returned by RunNow while the job is running on this instance
+var ErrJobRunning = errors.New("scheduler : job is already running")
+
+// Scheduler runs registered jobs on their schedules
+type Scheduler struct {
+	config   Config
+	mu       sync.Mutex
+	jobs     []*Job
+	handlers map[string]HandlerFunc
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// Default is the scheduler used by package level helpers
+var Default = New(Config{})
+
+// New creates scheduler
+func New(cfg Config) *Scheduler {
+	if cfg.HistoryLimit <= 0 {
+		cfg.HistoryLimit = 100
+	}
+	if cfg.InstanceID == "" {
+		cfg.InstanceID, _ = os.Hostname()
+	}
+	if cfg.Session == "" {
+		cfg.Session = "default"
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = time.Minute
+	}
+	return &Scheduler{config: cfg, handlers: map[string]HandlerFunc{}}
+}
+
+// Builder configures job before registration
+type Builder struct {
+	scheduler *Scheduler
+	job       *Job
+	err       error
+}
+
+// Every schedules job at fixed interval, e.g. "5m"
+func Every(interval string) *Builder {
+	return Default.Every(interval)
+}
+
+// Cron schedules job with cron expression, e.g. "*/5 * * * *"
+func Cron(expr string) *Builder {
+	return Default.Cron(expr)
+}
+
+// Every schedules job at fixed interval on s
+func (s *Scheduler) Every(interval string) *Builder {
+	b := &Builder{scheduler: s, job: &Job{}}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		b.err = fmt.Errorf("invalid interval %q: %w", interval, err)
+	} else if d <= 0 {
+		b.err = fmt.Errorf("invalid interval %q: must be positive", interval)
+	}
+	b.job.schedule = intervalSchedule{every: d}
+	return b
+}
+
+// Cron schedules job with cron expression on s
+func (s *Scheduler) Cron(expr string) *Builder {
+	b := &Builder{scheduler: s, job: &Job{}}
+	b.job.schedule, b.err = ParseCron(expr)
+	return b
+}
+
+// Name sets job name used for locks and history, defaults to function name
+func (b *Builder) Name(name string) *Builder {
+	b.job.name = name
+	return b
+}
+
+// Timeout cancels job context after d
+func (b *Builder) Timeout(d time.Duration) *Builder {
+	b.job.timeout = d
+	return b
+}
+
+// Singleton runs each planned run of job once across instances and on a single instance at a time
+// using Redis locks. Planned runs stay claimed for lockTTL so instances with lagging clocks skip them,
+// the lock of a running job is renewed every third of lockTTL until it returns
+func (b *Builder) Singleton(lockTTL ...time.Duration) *Builder {
+	b.job.singleton = true
+	if len(lockTTL) > 0 {
+		b.job.lockTTL = lockTTL[0]
+	}
+	return b
+}
+
+// Do registers job function
+func (b *Builder) Do(fn Func) (*Job, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if fn == nil {
+		return nil, errors.New("scheduler : job function is nil")
+	}
+
+	job := b.job
+	job.fn = fn
+	if job.name == "" {
+		job.name = runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	}
+	if job.singleton && job.lockTTL <= 0 {
+		job.lockTTL = time.Minute
+		if job.timeout > 0 {
+			job.lockTTL = job.timeout
+		}
+	}
+	job.next = job.schedule.Next(time.Now())
+
+	b.scheduler.mu.Lock()
+	b.scheduler.jobs = append(b.scheduler.jobs, job)
+	b.scheduler.mu.Unlock()
+	return job, nil
+}
+
+// Jobs returns registered jobs
+func (s *Scheduler) Jobs() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Job(nil), s.jobs...)
+}
+
+// History returns configured history store
+func (s *Scheduler) History() HistoryStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config.History != nil {
+		return s.config.History
+	}
+	if database.RedisClient != nil {
+		return &RedisHistory{Prefix: "nest:scheduler:history:", Limit: s.config.HistoryLimit}
+	}
+	s.config.History = &MemoryHistory{Limit: s.config.HistoryLimit}
+	return s.config.History
+}
+
+// Start starts scheduling loop in background
+func (s *Scheduler) Start(ctx context.Context) error {
+	ctx, s.cancel = context.WithCancel(context.WithoutCancel(ctx))
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop(ctx)
+	}()
+	return nil
+}
+
+// Stop stops scheduling and waits for running jobs until ctx is done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduler did not drain: %w", ctx.Err())
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for _, job := range s.jobs {
+				if job.running || job.next.IsZero() || now.Before(job.next) {
+					continue
+				}
+				job.running = true
+				occurrence := slot(job.schedule, job.next)
+				if job.missed > 0 {
+					job.missed--
+					occurrence += fmt.Sprintf(":%d", job.missed)
+				} else {
+					job.next = job.schedule.Next(now)
+				}
+
+				s.wg.Add(1)
+				go func(job *Job) {
+					defer s.wg.Done()
+					s.execute(ctx, job, occurrence)
+
+					s.mu.Lock()
+					job.running = false
+					s.mu.Unlock()
+				}(job)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// RunNow executes job immediately, respecting singleton lock and timeout. Returns ErrJobRunning
+// while a scheduled run of job is in progress on this instance
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	for _, job := range s.Jobs() {
+		if job.name != name {
+			continue
+		}
+		s.mu.Lock()
+		if job.running {
+			s.mu.Unlock()
+			return ErrJobRunning
+		}
+		job.running = true
+		s.mu.Unlock()
+
+		defer func() {
+			s.mu.Lock()
+			job.running = false
+			s.mu.Unlock()
+		}()
+		return s.execute(ctx, job, "")
+	}
+	return fmt.Errorf("scheduler : job '%s' not found", name)
+}
+
+// slot identifies planned run at of schedule across instances, intervals are aligned to the epoch
+// since instances start them at different times
+func slot(schedule Schedule, at time.Time) string {
+	if interval, ok := schedule.(intervalSchedule); ok {
+		at = at.Truncate(interval.every)
+	}
+	return strconv.FormatInt(at.Truncate(time.Second).Unix(), 10)
+}
+
+// execute runs job, singleton jobs claim occurrence until their lock TTL expires, empty for manual runs
+func (s *Scheduler) execute(ctx context.Context, job *Job, occurrence string) error {
+	run := Run{Job: job.name, StartedAt: time.Now(), InstanceID: s.config.InstanceID}
+
+	if job.singleton && occurrence != "" {
+		// claims are never released, another instance reaching the same occurrence late must skip it
+		_, err := lock.Acquire(ctx, "scheduler:"+job.name+":"+occurrence, job.lockTTL)
+		if errors.Is(err, lock.ErrNotAcquired) {
+			return nil
+		}
+		if err != nil {
+			run.Error = err.Error()
+			run.Skipped = true
+			s.record(ctx, run, err)
+			return err
+		}
+	}
+	if job.singleton {
+		l, err := lock.Acquire(ctx, "scheduler:"+job.name, job.lockTTL)
+		if errors.Is(err, lock.ErrNotAcquired) {
+			// Another instance is still running job
+			return nil
+		}
+		if err != nil {
+			run.Error = err.Error()
+			run.Skipped = true
+			s.record(ctx, run, err)
+			return err
+		}
+		defer l.Release(context.WithoutCancel(ctx))
+
+		// Renewed while job runs so it doesn't expire and let another instance start job meanwhile
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		go s.keepLock(ctx, l, job.lockTTL, cancel)
+	}
+
+	jobCtx := ctx
+	if job.timeout > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, job.timeout)
+		defer cancel()
+	}
+
+	err := safeCall(jobCtx, job.fn)
+	run.Duration = time.Since(run.StartedAt)
+	if err != nil {
+		run.Error = err.Error()
+	}
+	s.record(ctx, run, err)
+	return err
+}
+
+// keepLock refreshes l every third of ttl until ctx is done, cancelling the job when the lock is lost
+func (s *Scheduler) keepLock(ctx context.Context, l *lock.Lock, ttl time.Duration, cancel context.CancelFunc) {
+	ticker := time.NewTicker(max(ttl/3, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := l.Refresh(ctx, ttl)
+			if errors.Is(err, lock.ErrNotHeld) {
+				slog.Warn("scheduled job lost its lock", "key", l.Key())
+				cancel()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("failed to refresh scheduled job lock", "key", l.Key(), "error", err)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) record(ctx context.Context, run Run, err error) {
+	if err != nil {
+		if s.config.OnError != nil {
+			s.config.OnError(run.Job, err)
+		} else {
+			slog.Error("scheduled job failed", "job", run.Job, "error", err)
+		}
+	}
+	if herr := s.History().Record(context.WithoutCancel(ctx), run); herr != nil {
+		slog.Warn("failed to record job run", "job", run.Job, "error", herr)
+	}
+}
+
+func safeCall(ctx context.Context, fn Func) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// MaterializedViews registers job running database.RefreshDue every interval, singleton when Redis is configured
+func (s *Scheduler) MaterializedViews(interval string) (*Job, error) {
+	b := s.Every(interval).Name("database:materialized-views")
+	if database.RedisClient != nil {
+		b = b.Singleton()
+	}
+	return b.Do(database.RefreshDue)
+}
+
+// MaterializedViews registers materialized view refresh job on Default scheduler
+func MaterializedViews(interval string) (*Job, error) {
+	return Default.MaterializedViews(interval)
+}
+
+// SQLiteBackup registers cron job backing up SQLite session into dir, keeping the newest keep backups
+func (s *Scheduler) SQLiteBackup(expr, sessionName, dir string, keep int) (*Job, error) {
+	return s.Cron(expr).Name("database:backup:" + sessionName).Do(database.BackupJob(sessionName, dir, keep))
+}
+
+// SQLiteBackup registers SQLite backup job on Default scheduler
+func SQLiteBackup(expr, sessionName, dir string, keep int) (*Job, error) {
+	return Default.SQLiteBackup(expr, sessionName, dir, keep)
+}
+
+// Start starts Default scheduler
+func Start(ctx context.Context) error {
+	return Default.Start(ctx)
+}
+
+// Stop stops Default scheduler
+func Stop(ctx context.Context) error {
+	return Default.Stop(ctx)
+}
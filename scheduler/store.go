@@ -0,0 +1,343 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// HandlerFunc runs stored job with payload of its definition
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+// ReloadChannel is Redis channel notifying instances of changed job definitions
+var ReloadChannel = "nest:scheduler:reload"
+
+// CreateTables creates tables of job definitions and DBHistory if they do not exist
+func CreateTables(ctx context.Context, db bun.IDB) error {
+	for _, model := range []interface{}{(*JobDefinition)(nil), (*JobRun)(nil)} {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handle registers handler run by stored jobs naming it
+func (s *Scheduler) Handle(name string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[name] = fn
+}
+
+// Handle registers handler on Default scheduler
+func Handle(name string, fn HandlerFunc) {
+	Default.Handle(name, fn)
+}
+
+// SaveJob creates or updates definition and notifies all instances to reload
+func (s *Scheduler) SaveJob(ctx context.Context, def *JobDefinition) error {
+	if _, err := ParseCron(def.Spec); err != nil {
+		return err
+	}
+	if def.CatchUp == "" {
+		def.CatchUp = CatchUpSkip
+	}
+	def.UpdatedAt = time.Now()
+
+	db, err := database.IDB(ctx, s.config.Session)
+	if err != nil {
+		return err
+	}
+	query := db.NewInsert().Model(def)
+	switch db.Dialect().Name() {
+	case dialect.MySQL:
+		query = query.On("DUPLICATE KEY UPDATE").
+			Set("spec = VALUES(spec), handler = VALUES(handler), payload = VALUES(payload), enabled = VALUES(enabled)").
+			Set("catch_up = VALUES(catch_up), timeout_seconds = VALUES(timeout_seconds), singleton = VALUES(singleton), updated_at = VALUES(updated_at)")
+	case dialect.MSSQL:
+		if _, err := db.NewDelete().Model(def).WherePK().Exec(ctx); err != nil {
+			return err
+		}
+	default:
+		query = query.On("CONFLICT (name) DO UPDATE").
+			Set("spec = EXCLUDED.spec, handler = EXCLUDED.handler, payload = EXCLUDED.payload, enabled = EXCLUDED.enabled").
+			Set("catch_up = EXCLUDED.catch_up, timeout_seconds = EXCLUDED.timeout_seconds, singleton = EXCLUDED.singleton, updated_at = EXCLUDED.updated_at")
+	}
+	if _, err := query.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store job %s: %w", def.Name, err)
+	}
+
+	database.AfterCommit(ctx, s.notify)
+	return nil
+}
+
+// SaveJob stores definition on Default scheduler session
+func SaveJob(ctx context.Context, def *JobDefinition) error {
+	return Default.SaveJob(ctx, def)
+}
+
+// DeleteJob removes definition and notifies all instances to reload
+func (s *Scheduler) DeleteJob(ctx context.Context, name string) error {
+	db, err := database.IDB(ctx, s.config.Session)
+	if err != nil {
+		return err
+	}
+	if _, err := db.NewDelete().Model((*JobDefinition)(nil)).Where("name = ?", name).Exec(ctx); err != nil {
+		return err
+	}
+
+	database.AfterCommit(ctx, s.notify)
+	return nil
+}
+
+// DeleteJob removes definition on Default scheduler session
+func DeleteJob(ctx context.Context, name string) error {
+	return Default.DeleteJob(ctx, name)
+}
+
+// StoredJobs returns all job definitions ordered by name
+func (s *Scheduler) StoredJobs(ctx context.Context) ([]JobDefinition, error) {
+	db, err := database.GetDB(s.config.Session)
+	if err != nil {
+		return nil, err
+	}
+	var defs []JobDefinition
+	if err := db.NewSelect().Model(&defs).Order("name").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+// notify reloads this instance and publishes reload to others
+func (s *Scheduler) notify(ctx context.Context) {
+	if err := s.Reload(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to reload scheduled jobs", "error", err)
+	}
+	if database.RedisClient == nil {
+		return
+	}
+	if err := database.RedisClient.Publish(ctx, ReloadChannel, s.config.InstanceID).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to publish scheduled jobs reload", "error", err)
+	}
+}
+
+// Reload syncs stored jobs with enabled definitions. New jobs replay runs missed since their
+// last run according to CatchUp, edited jobs are rescheduled and removed or disabled ones unscheduled
+func (s *Scheduler) Reload(ctx context.Context) error {
+	defs, err := s.StoredJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := map[string]*Job{}
+	jobs := s.jobs[:0:0]
+	for _, job := range s.jobs {
+		if job.stored {
+			existing[job.name] = job
+		} else {
+			jobs = append(jobs, job)
+		}
+	}
+
+	now := time.Now()
+	for _, def := range defs {
+		if !def.Enabled {
+			continue
+		}
+		if job, ok := existing[def.Name]; ok && job.version.Equal(def.UpdatedAt) {
+			jobs = append(jobs, job)
+			continue
+		}
+
+		job, err := s.storedJob(def, now)
+		if err != nil {
+			slog.WarnContext(ctx, "stored job skipped", "job", def.Name, "error", err)
+			continue
+		}
+		if old, ok := existing[def.Name]; ok {
+			// edited jobs are updated in place so a running execution still clears their running flag
+			old.schedule, old.fn, old.timeout = job.schedule, job.fn, job.timeout
+			old.singleton, old.lockTTL, old.version = job.singleton, job.lockTTL, job.version
+			old.next, old.missed = job.schedule.Next(now), 0
+			job = old
+		}
+		jobs = append(jobs, job)
+	}
+	s.jobs = jobs
+	return nil
+}
+
+// Reload syncs stored jobs of Default scheduler
+func Reload(ctx context.Context) error {
+	return Default.Reload(ctx)
+}
+
+// storedJob builds job of definition, planning catch-up runs missed since LastRunAt
+func (s *Scheduler) storedJob(def JobDefinition, now time.Time) (*Job, error) {
+	schedule, err := ParseCron(def.Spec)
+	if err != nil {
+		return nil, err
+	}
+	handler, ok := s.handlers[def.Handler]
+	if !ok {
+		return nil, fmt.Errorf("handler '%s' not registered", def.Handler)
+	}
+
+	name, payload := def.Name, json.RawMessage(def.Payload)
+	job := &Job{
+		name:      name,
+		schedule:  schedule,
+		timeout:   time.Duration(def.TimeoutSeconds) * time.Second,
+		singleton: def.Singleton,
+		stored:    true,
+		version:   def.UpdatedAt,
+		fn: func(ctx context.Context) error {
+			s.markRun(ctx, name)
+			return handler(ctx, payload)
+		},
+	}
+	if job.singleton {
+		job.lockTTL = time.Minute
+		if job.timeout > 0 {
+			job.lockTTL = job.timeout
+		}
+	}
+	job.next = schedule.Next(now)
+
+	if def.LastRunAt.IsZero() || def.CatchUp == CatchUpSkip {
+		return job, nil
+	}
+	missed := 0
+	for at := schedule.Next(def.LastRunAt.Time); !at.IsZero() && !at.After(now) && missed < MaxCatchUp; at = schedule.Next(at) {
+		missed++
+	}
+	if missed == 0 {
+		return job, nil
+	}
+	job.next = now
+	if def.CatchUp == CatchUpAll {
+		job.missed = missed - 1
+	}
+	return job, nil
+}
+
+// markRun records start of stored job run for catch-up after restarts
+func (s *Scheduler) markRun(ctx context.Context, name string) {
+	db, err := database.GetDB(s.config.Session)
+	if err == nil {
+		_, err = db.NewUpdate().Model((*JobDefinition)(nil)).Set("last_run_at = ?", time.Now()).Where("name = ?", name).Exec(ctx)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record stored job run", "job", name, "error", err)
+	}
+}
+
+// Listen loads stored jobs and reloads them on change notifications and every ReloadInterval,
+// blocks until ctx is done
+func (s *Scheduler) Listen(ctx context.Context) error {
+	if err := s.Reload(ctx); err != nil {
+		return err
+	}
+
+	var messages <-chan *redis.Message
+	if database.RedisClient != nil {
+		sub := database.RedisClient.Subscribe(ctx, ReloadChannel)
+		defer sub.Close()
+		messages = sub.Channel()
+	}
+
+	ticker := time.NewTicker(s.config.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			if message.Payload == s.config.InstanceID {
+				continue
+			}
+		case <-ticker.C:
+		}
+		if err := s.Reload(ctx); err != nil {
+			slog.WarnContext(ctx, "failed to reload scheduled jobs", "error", err)
+		}
+	}
+}
+
+// Listen runs Listen of Default scheduler
+func Listen(ctx context.Context) error {
+	return Default.Listen(ctx)
+}
+
+// DBHistory stores runs in the scheduled_job_runs table, see CreateTables
+type DBHistory struct {
+	Session string
+}
+
+// Record inserts run
+func (h *DBHistory) Record(ctx context.Context, run Run) error {
+	db, err := database.GetDB(h.Session)
+	if err != nil {
+		return err
+	}
+	_, err = db.NewInsert().Model(&JobRun{
+		Job:        run.Job,
+		StartedAt:  run.StartedAt,
+		DurationMs: run.Duration.Milliseconds(),
+		Error:      run.Error,
+		Skipped:    run.Skipped,
+		InstanceID: run.InstanceID,
+	}).Exec(ctx)
+	return err
+}
+
+// Recent returns latest runs of job, newest first
+func (h *DBHistory) Recent(ctx context.Context, job string, limit int) ([]Run, error) {
+	db, err := database.GetDB(h.Session)
+	if err != nil {
+		return nil, err
+	}
+	var rows []JobRun
+	if err := db.NewSelect().Model(&rows).Where("job = ?", job).Order("started_at DESC").Limit(limit).Scan(ctx); err != nil {
+		return nil, err
+	}
+	runs := make([]Run, len(rows))
+	for i, row := range rows {
+		runs[i] = Run{
+			Job:        row.Job,
+			StartedAt:  row.StartedAt,
+			Duration:   time.Duration(row.DurationMs) * time.Millisecond,
+			Error:      row.Error,
+			Skipped:    row.Skipped,
+			InstanceID: row.InstanceID,
+		}
+	}
+	return runs, nil
+}
+
+// Prune deletes runs started before cutoff
+func (h *DBHistory) Prune(ctx context.Context, before time.Time) (int64, error) {
+	db, err := database.GetDB(h.Session)
+	if err != nil {
+		return 0, err
+	}
+	result, err := db.NewDelete().Model((*JobRun)(nil)).Where("started_at < ?", before).Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,99 @@
+package secure
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// Redacted replaces sensitive values in strings, JSON and logs
+const Redacted = "[REDACTED]"
+
+// Sensitive holds secrets such as passwords and tokens that must not be logged or serialized.
+// Copies share the underlying bytes, so Zero wipes every copy
+type Sensitive struct {
+	b []byte
+}
+
+// New creates Sensitive from string, the string itself cannot be zeroed
+func New(s string) Sensitive {
+	return Sensitive{b: []byte(s)}
+}
+
+// FromBytes creates Sensitive owning b, b is zeroed by Zero
+func FromBytes(b []byte) Sensitive {
+	return Sensitive{b: b}
+}
+
+// Bytes returns underlying bytes, valid until Zero
+func (s Sensitive) Bytes() []byte {
+	return s.b
+}
+
+// Reveal returns secret as string, the copy is not zeroed by Zero
+func (s Sensitive) Reveal() string {
+	return string(s.b)
+}
+
+// Len returns secret length
+func (s Sensitive) Len() int {
+	return len(s.b)
+}
+
+// IsZero reports whether secret is empty
+func (s Sensitive) IsZero() bool {
+	return len(s.b) == 0
+}
+
+// Zero overwrites secret bytes, best effort since Go may have copied them
+func (s Sensitive) Zero() {
+	clear(s.b)
+}
+
+func (s Sensitive) String() string {
+	return Redacted
+}
+
+func (s Sensitive) GoString() string {
+	return "secure.Sensitive{" + Redacted + "}"
+}
+
+// Format redacts all fmt verbs including %x and %#v
+func (s Sensitive) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, s.GoString())
+		return
+	}
+	fmt.Fprint(f, Redacted)
+}
+
+// LogValue redacts secret in slog output
+func (s Sensitive) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+// MarshalJSON redacts secret in responses and logs
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// UnmarshalJSON reads secret from request bodies
+func (s *Sensitive) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	s.b = []byte(value)
+	return nil
+}
+
+// MarshalText redacts secret in text encoders
+func (s Sensitive) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+// UnmarshalText reads secret from form values and env
+func (s *Sensitive) UnmarshalText(text []byte) error {
+	s.b = append([]byte(nil), text...)
+	return nil
+}
@@ -0,0 +1,96 @@
+package security
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CSRFConfig represents CSRF middleware configuration
+type CSRFConfig struct {
+	// Next skips middleware when returning true
+	Next func(c *fiber.Ctx) bool
+	// Session returns ID of the authenticated session of request, tokens are stored per session in Redis
+	// when it is non-empty. Only return sessions the app has validated, each one creates a Redis key.
+	// Without it or without session the double-submit cookie is used
+	Session func(c *fiber.Ctx) string
+	// CookieName holds the token readable by JavaScript, sent back in HeaderName or FormField
+	CookieName     string
+	CookieDomain   string
+	CookiePath     string
+	CookieSecure   bool
+	CookieSameSite string
+	HeaderName     string
+	FormField      string
+	// TTL of tokens and cookie
+	TTL time.Duration
+	// Local is fiber locals key holding the token, see CSRFToken
+	Local string
+}
+
+// DefaultCSRFConfig is used for empty CSRFConfig fields
+var DefaultCSRFConfig = CSRFConfig{
+	CookieName:     "nest_csrf",
+	CookiePath:     "/",
+	CookieSameSite: fiber.CookieSameSiteLaxMode,
+	HeaderName:     "X-CSRF-Token",
+	FormField:      "_csrf",
+	TTL:            12 * time.Hour,
+	Local:          "csrf",
+}
+
+// CSRFPrefix is prepended to Redis keys of session tokens
+var CSRFPrefix = "nest:csrf:"
+
+// HeadersConfig represents security headers preset, empty fields are not sent
+type HeadersConfig struct {
+	// HSTS max age, sent on HTTPS requests only
+	HSTS                  time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	// CSP builds Content-Security-Policy, see NewCSP
+	CSP                     *CSP
+	CSPReportOnly           bool
+	FrameOptions            string
+	ReferrerPolicy          string
+	ContentTypeNosniff      bool
+	PermissionsPolicy       string
+	CrossOriginOpenerPolicy string
+}
+
+// Headers presets
+var (
+	// PresetWeb suits server rendered HTML, scripts need the per request nonce, see CSPNonce
+	PresetWeb = HeadersConfig{
+		HSTS:                    365 * 24 * time.Hour,
+		HSTSIncludeSubdomains:   true,
+		CSP:                     NewCSP().DefaultSrc(Self).ScriptSrc(Self, Nonce).StyleSrc(Self).ImgSrc(Self, "data:").ObjectSrc(None).BaseURI(Self).FrameAncestors(None),
+		FrameOptions:            "DENY",
+		ReferrerPolicy:          "strict-origin-when-cross-origin",
+		ContentTypeNosniff:      true,
+		PermissionsPolicy:       "camera=(), microphone=(), geolocation=()",
+		CrossOriginOpenerPolicy: "same-origin",
+	}
+	// PresetAPI suits JSON APIs which never render documents
+	PresetAPI = HeadersConfig{
+		HSTS:                  365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		CSP:                   NewCSP().DefaultSrc(None).FrameAncestors(None),
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+		ContentTypeNosniff:    true,
+	}
+)
+
+// CORSConfig represents CORS configuration, see CORSFromEnv
+type CORSConfig struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// EnvKey names the environment variable holding application environment, e.g. production
+var EnvKey = "APP_ENV"
@@ -0,0 +1,93 @@
+package security
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/rikiihsan/nest/env"
+)
+
+// CORS returns CORS middleware of cfg, CORSFromEnv when no config is given
+func CORS(configs ...CORSConfig) fiber.Handler {
+	cfg := CORSFromEnv()
+	if len(configs) > 0 {
+		cfg = configs[0]
+	}
+	// Without origins no CORS headers are sent, fiber would otherwise default to any origin
+	if len(cfg.AllowOrigins) == 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return cors.New(cors.Config{
+		AllowOrigins:     strings.Join(cfg.AllowOrigins, ","),
+		AllowMethods:     strings.Join(cfg.AllowMethods, ","),
+		AllowHeaders:     strings.Join(cfg.AllowHeaders, ","),
+		ExposeHeaders:    strings.Join(cfg.ExposeHeaders, ","),
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           int(cfg.MaxAge.Seconds()),
+	})
+}
+
+// CORSFromEnv reads CORS_ALLOW_ORIGINS, CORS_ALLOW_METHODS, CORS_ALLOW_HEADERS, CORS_EXPOSE_HEADERS,
+// CORS_ALLOW_CREDENTIALS and CORS_MAX_AGE (seconds). Origins default to any origin in a Development
+// environment and to none otherwise, including unset APP_ENV, so a forgotten variable fails closed
+func CORSFromEnv() CORSConfig {
+	origins := ""
+	if Development() {
+		origins = "*"
+	}
+
+	cfg := CORSConfig{
+		AllowOrigins:  list(env.Get("CORS_ALLOW_ORIGINS", origins)),
+		AllowMethods:  list(env.Get("CORS_ALLOW_METHODS", "GET,POST,HEAD,PUT,DELETE,PATCH")),
+		AllowHeaders:  list(env.Get("CORS_ALLOW_HEADERS")),
+		ExposeHeaders: list(env.Get("CORS_EXPOSE_HEADERS")),
+		MaxAge:        time.Duration(atoi(env.Get("CORS_MAX_AGE", "0"))) * time.Second,
+	}
+	cfg.AllowCredentials, _ = strconv.ParseBool(env.Get("CORS_ALLOW_CREDENTIALS", "false"))
+	// Credentials are never allowed for wildcard origins
+	if len(cfg.AllowOrigins) == 1 && cfg.AllowOrigins[0] == "*" {
+		cfg.AllowCredentials = false
+	}
+	return cfg
+}
+
+// Production reports whether EnvKey names a production environment
+func Production() bool {
+	switch strings.ToLower(env.Get(EnvKey)) {
+	case "prod", "production":
+		return true
+	}
+	return false
+}
+
+// Development reports whether EnvKey explicitly names a development environment, unset or unknown
+// environments such as staging are not
+func Development() bool {
+	switch strings.ToLower(env.Get(EnvKey)) {
+	case "dev", "development", "local", "test", "testing":
+		return true
+	}
+	return false
+}
+
+// list splits comma separated value dropping blanks
+func list(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func atoi(value string) int {
+	n, _ := strconv.Atoi(value)
+	return n
+}
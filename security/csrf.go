@@ -0,0 +1,169 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/apperror"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/logger"
+)
+
+// ErrCSRF is returned when unsafe request has missing or wrong token
+var ErrCSRF = apperror.ErrForbidden.WithMessage("invalid csrf token")
+
+// csrfLocal is locals key of csrfState read by CSRFToken and CSRFField
+const csrfLocal = "nest.csrf"
+
+type csrfState struct {
+	token string
+	field string
+}
+
+// CSRF returns middleware issuing tokens on every request and verifying them on unsafe methods.
+// Tokens are kept per session in Redis, otherwise compared with the double-submit cookie.
+// Safe methods pass without token while Redis fails
+func CSRF(configs ...CSRFConfig) fiber.Handler {
+	cfg := DefaultCSRFConfig
+	if len(configs) > 0 {
+		cfg = csrfDefaults(configs[0])
+	}
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		safe := false
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+			safe = true
+		}
+
+		token, err := csrfToken(c, cfg)
+		if err != nil {
+			if !safe {
+				return err
+			}
+			logger.Get().WarnContext(c.UserContext(), "csrf token lookup failed", "error", err)
+			return c.Next()
+		}
+		c.Locals(csrfLocal, csrfState{token: token, field: cfg.FormField})
+		c.Locals(cfg.Local, token)
+		c.Cookie(&fiber.Cookie{
+			Name:     cfg.CookieName,
+			Value:    token,
+			Path:     cfg.CookiePath,
+			Domain:   cfg.CookieDomain,
+			MaxAge:   int(cfg.TTL.Seconds()),
+			Secure:   cfg.CookieSecure,
+			SameSite: cfg.CookieSameSite,
+		})
+
+		if safe {
+			return c.Next()
+		}
+
+		submitted := c.Get(cfg.HeaderName)
+		if submitted == "" {
+			submitted = c.FormValue(cfg.FormField)
+		}
+		if submitted == "" || subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+			return ErrCSRF
+		}
+		return c.Next()
+	}
+}
+
+// csrfDefaults fills empty fields from DefaultCSRFConfig
+func csrfDefaults(cfg CSRFConfig) CSRFConfig {
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultCSRFConfig.CookieName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = DefaultCSRFConfig.CookiePath
+	}
+	if cfg.CookieSameSite == "" {
+		cfg.CookieSameSite = DefaultCSRFConfig.CookieSameSite
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = DefaultCSRFConfig.HeaderName
+	}
+	if cfg.FormField == "" {
+		cfg.FormField = DefaultCSRFConfig.FormField
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultCSRFConfig.TTL
+	}
+	if cfg.Local == "" {
+		cfg.Local = DefaultCSRFConfig.Local
+	}
+	return cfg
+}
+
+// csrfToken returns token of session stored in Redis, or of the double-submit cookie
+func csrfToken(c *fiber.Ctx, cfg CSRFConfig) (string, error) {
+	var session string
+	if cfg.Session != nil {
+		session = cfg.Session(c)
+	}
+	if session == "" || database.RedisClient == nil {
+		if token := c.Cookies(cfg.CookieName); token != "" {
+			return token, nil
+		}
+		return newToken()
+	}
+
+	ctx := c.UserContext()
+	key := CSRFPrefix + session
+	token, err := database.RedisClient.Get(ctx, key).Result()
+	if err == nil {
+		return token, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	if token, err = newToken(); err != nil {
+		return "", err
+	}
+	// Concurrent first requests of a session agree on the token stored first
+	if err := database.RedisClient.SetNX(ctx, key, token, cfg.TTL).Err(); err != nil {
+		return "", err
+	}
+	return database.RedisClient.Get(ctx, key).Result()
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CSRFToken returns token issued by CSRF middleware for request
+func CSRFToken(c *fiber.Ctx) string {
+	state, _ := c.Locals(csrfLocal).(csrfState)
+	return state.token
+}
+
+// CSRFField returns hidden form input holding the token, for html/template views
+func CSRFField(c *fiber.Ctx) template.HTML {
+	state, _ := c.Locals(csrfLocal).(csrfState)
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(state.field) +
+		`" value="` + template.HTMLEscapeString(state.token) + `">`)
+}
+
+// RotateCSRF removes token of session, e.g. after login, the next request issues a new one
+func RotateCSRF(c *fiber.Ctx, session string) error {
+	if database.RedisClient == nil {
+		return nil
+	}
+	return database.RedisClient.Del(c.UserContext(), CSRFPrefix+session).Err()
+}
@@ -0,0 +1,212 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CSP source keywords
+const (
+	Self          = "'self'"
+	None          = "'none'"
+	UnsafeInline  = "'unsafe-inline'"
+	StrictDynamic = "'strict-dynamic'"
+	// Nonce is replaced with the per request nonce, see CSPNonce
+	Nonce = "'nonce'"
+)
+
+// cspNonceLocal is locals key of the request nonce
+const cspNonceLocal = "nest.csp.nonce"
+
+// CSP builds Content-Security-Policy keeping directives in insertion order
+type CSP struct {
+	names   []string
+	sources map[string][]string
+}
+
+// NewCSP creates empty policy
+func NewCSP() *CSP {
+	return &CSP{sources: map[string][]string{}}
+}
+
+// Directive appends sources to directive, directives without sources are sent bare
+func (p *CSP) Directive(name string, sources ...string) *CSP {
+	if _, ok := p.sources[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.sources[name] = append(p.sources[name], sources...)
+	return p
+}
+
+// DefaultSrc appends sources to default-src
+func (p *CSP) DefaultSrc(sources ...string) *CSP {
+	return p.Directive("default-src", sources...)
+}
+
+// ScriptSrc appends sources to script-src
+func (p *CSP) ScriptSrc(sources ...string) *CSP {
+	return p.Directive("script-src", sources...)
+}
+
+// StyleSrc appends sources to style-src
+func (p *CSP) StyleSrc(sources ...string) *CSP {
+	return p.Directive("style-src", sources...)
+}
+
+// ImgSrc appends sources to img-src
+func (p *CSP) ImgSrc(sources ...string) *CSP {
+	return p.Directive("img-src", sources...)
+}
+
+// FontSrc appends sources to font-src
+func (p *CSP) FontSrc(sources ...string) *CSP {
+	return p.Directive("font-src", sources...)
+}
+
+// ConnectSrc appends sources to connect-src
+func (p *CSP) ConnectSrc(sources ...string) *CSP {
+	return p.Directive("connect-src", sources...)
+}
+
+// ObjectSrc appends sources to object-src
+func (p *CSP) ObjectSrc(sources ...string) *CSP {
+	return p.Directive("object-src", sources...)
+}
+
+// BaseURI appends sources to base-uri
+func (p *CSP) BaseURI(sources ...string) *CSP {
+	return p.Directive("base-uri", sources...)
+}
+
+// FormAction appends sources to form-action
+func (p *CSP) FormAction(sources ...string) *CSP {
+	return p.Directive("form-action", sources...)
+}
+
+// FrameAncestors appends sources to frame-ancestors
+func (p *CSP) FrameAncestors(sources ...string) *CSP {
+	return p.Directive("frame-ancestors", sources...)
+}
+
+// ReportURI sets report-uri
+func (p *CSP) ReportURI(uri string) *CSP {
+	return p.Directive("report-uri", uri)
+}
+
+// UpgradeInsecureRequests adds upgrade-insecure-requests
+func (p *CSP) UpgradeInsecureRequests() *CSP {
+	return p.Directive("upgrade-insecure-requests")
+}
+
+// Clone returns copy of policy, used to extend presets without changing them
+func (p *CSP) Clone() *CSP {
+	clone := NewCSP()
+	for _, name := range p.names {
+		clone.Directive(name, p.sources[name]...)
+	}
+	return clone
+}
+
+// usesNonce reports whether any directive contains Nonce
+func (p *CSP) usesNonce() bool {
+	for _, sources := range p.sources {
+		for _, source := range sources {
+			if source == Nonce {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String renders policy, Nonce sources become 'nonce-<nonce>' or are dropped when nonce is empty
+func (p *CSP) String(nonce string) string {
+	directives := make([]string, 0, len(p.names))
+	for _, name := range p.names {
+		parts := []string{name}
+		for _, source := range p.sources[name] {
+			if source == Nonce {
+				if nonce == "" {
+					continue
+				}
+				source = "'nonce-" + nonce + "'"
+			}
+			parts = append(parts, source)
+		}
+		directives = append(directives, strings.Join(parts, " "))
+	}
+	return strings.Join(directives, "; ")
+}
+
+// Headers returns middleware setting security headers of preset, e.g. PresetWeb or PresetAPI
+func Headers(preset HeadersConfig) fiber.Handler {
+	hsts := ""
+	if preset.HSTS > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(preset.HSTS.Seconds()))
+		if preset.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if preset.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	cspHeader := fiber.HeaderContentSecurityPolicy
+	if preset.CSPReportOnly {
+		cspHeader = fiber.HeaderContentSecurityPolicyReportOnly
+	}
+	nonce := preset.CSP != nil && preset.CSP.usesNonce()
+	csp := ""
+	if preset.CSP != nil && !nonce {
+		csp = preset.CSP.String("")
+	}
+
+	return func(c *fiber.Ctx) error {
+		if hsts != "" && c.Protocol() == "https" {
+			c.Set(fiber.HeaderStrictTransportSecurity, hsts)
+		}
+		if nonce {
+			value, err := newNonce()
+			if err != nil {
+				return err
+			}
+			c.Locals(cspNonceLocal, value)
+			c.Set(cspHeader, preset.CSP.String(value))
+		} else if csp != "" {
+			c.Set(cspHeader, csp)
+		}
+		if preset.FrameOptions != "" {
+			c.Set(fiber.HeaderXFrameOptions, preset.FrameOptions)
+		}
+		if preset.ReferrerPolicy != "" {
+			c.Set(fiber.HeaderReferrerPolicy, preset.ReferrerPolicy)
+		}
+		if preset.ContentTypeNosniff {
+			c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		}
+		if preset.PermissionsPolicy != "" {
+			c.Set(fiber.HeaderPermissionsPolicy, preset.PermissionsPolicy)
+		}
+		if preset.CrossOriginOpenerPolicy != "" {
+			c.Set("Cross-Origin-Opener-Policy", preset.CrossOriginOpenerPolicy)
+		}
+		return c.Next()
+	}
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// CSPNonce returns nonce of request for <script nonce="..."> when the policy uses Nonce
+func CSPNonce(c *fiber.Ctx) string {
+	nonce, _ := c.Locals(cspNonceLocal).(string)
+	return nonce
+}
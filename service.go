@@ -0,0 +1,135 @@
+package nest
+
+import (
+	"context"
+
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/database/repository"
+	"github.com/rikiihsan/nest/events"
+	"github.com/rikiihsan/nest/logger"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// Service provides validated transactional CRUD of T.
+// Writes publish "<Topic>.created", "<Topic>.updated" and "<Topic>.deleted" events after commit
+type Service[T any] struct {
+	Repo  *repository.Repository[T]
+	Topic string
+	// Source is struct tag used for validation error fields
+	Source string
+
+	// Hooks run inside the transaction, returning error rolls it back
+	BeforeCreate func(ctx context.Context, entity *T) error
+	BeforeUpdate func(ctx context.Context, entity *T) error
+	BeforeDelete func(ctx context.Context, entity *T) error
+
+	// OnEventError handles errors of event handlers, logged when nil
+	OnEventError func(err error)
+}
+
+// NewService creates service of T on session, empty topic disables events
+func NewService[T any](session, topic string) *Service[T] {
+	return &Service[T]{
+		Repo:   repository.New[T](session),
+		Topic:  topic,
+		Source: "json",
+	}
+}
+
+// Transaction runs fn in transaction of the service session, joining outer transaction of ctx
+func (s *Service[T]) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.RunInTx(ctx, s.Repo.Session, fn)
+}
+
+// Validate validates entity honoring validator.SkipFields of ctx, returns validator.Errors on failure
+func (s *Service[T]) Validate(ctx context.Context, entity *T) error {
+	if errs := validator.ValidateCtx(ctx, entity, s.Source); len(errs) > 0 {
+		return validator.Errors(errs)
+	}
+	return nil
+}
+
+// Find returns entity by primary key
+func (s *Service[T]) Find(ctx context.Context, id interface{}) (*T, error) {
+	return s.Repo.Find(ctx, id)
+}
+
+// List returns entities matching modifiers
+func (s *Service[T]) List(ctx context.Context, mods ...repository.QueryFunc) ([]T, error) {
+	return s.Repo.List(ctx, mods...)
+}
+
+// Create validates and inserts entity
+func (s *Service[T]) Create(ctx context.Context, entity *T) error {
+	if err := s.Validate(ctx, entity); err != nil {
+		return err
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		if s.BeforeCreate != nil {
+			if err := s.BeforeCreate(ctx, entity); err != nil {
+				return err
+			}
+		}
+		if err := s.Repo.Create(ctx, entity); err != nil {
+			return err
+		}
+		s.publish(ctx, "created", entity)
+		return nil
+	})
+}
+
+// Update validates and updates entity, only given columns when set
+func (s *Service[T]) Update(ctx context.Context, entity *T, columns ...string) error {
+	if err := s.Validate(ctx, entity); err != nil {
+		return err
+	}
+
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		if s.BeforeUpdate != nil {
+			if err := s.BeforeUpdate(ctx, entity); err != nil {
+				return err
+			}
+		}
+		if err := s.Repo.Update(ctx, entity, columns...); err != nil {
+			return err
+		}
+		s.publish(ctx, "updated", entity)
+		return nil
+	})
+}
+
+// Delete deletes entity by primary key, the deleted entity is the event payload
+func (s *Service[T]) Delete(ctx context.Context, id interface{}) error {
+	return s.Transaction(ctx, func(ctx context.Context) error {
+		entity, err := s.Repo.Find(ctx, id)
+		if err != nil {
+			return err
+		}
+		if s.BeforeDelete != nil {
+			if err := s.BeforeDelete(ctx, entity); err != nil {
+				return err
+			}
+		}
+		if err := s.Repo.Delete(ctx, entity); err != nil {
+			return err
+		}
+		s.publish(ctx, "deleted", entity)
+		return nil
+	})
+}
+
+// publish schedules event after commit of ctx transaction
+func (s *Service[T]) publish(ctx context.Context, action string, entity *T) {
+	if s.Topic == "" {
+		return
+	}
+
+	onError := s.OnEventError
+	if onError == nil {
+		onError = func(err error) {
+			logger.Get().ErrorContext(ctx, "service event handler failed", "topic", s.Topic+"."+action, "error", err)
+		}
+	}
+	events.PublishAfterCommit(ctx, s.Topic+"."+action, entity, onError)
+}
@@ -0,0 +1,54 @@
+package settings
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Setting represents persisted runtime setting with JSON encoded value
+type Setting struct {
+	bun.BaseModel `bun:"table:settings,alias:st"`
+
+	Key       string    `bun:",pk" json:"key"`
+	Value     string    `bun:",notnull" json:"value"`
+	UpdatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+// Config represents settings store configuration
+type Config struct {
+	Session string
+	// Prefix of Redis cache hash and invalidation channel
+	Prefix string
+	// TTL of local cache and Redis hash, a safety net when pub/sub messages are missed
+	TTL time.Duration
+}
+
+// DefaultConfig is used until Init is called
+var DefaultConfig = Config{
+	Session: "default",
+	Prefix:  "nest:settings",
+	TTL:     time.Minute,
+}
+
+var config = DefaultConfig
+
+// Init sets global settings configuration, empty fields fall back to DefaultConfig
+func Init(cfg Config) {
+	if cfg.Session == "" {
+		cfg.Session = DefaultConfig.Session
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultConfig.Prefix
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultConfig.TTL
+	}
+	config = cfg
+	invalidate()
+}
+
+// GetConfig returns current settings configuration
+func GetConfig() Config {
+	return config
+}
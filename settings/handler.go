@@ -0,0 +1,65 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/response"
+)
+
+// Register mounts admin endpoints listing and updating settings on router, which must be protected by the caller
+//
+//	GET    /settings
+//	GET    /settings/:key
+//	PUT    /settings/:key   body is the JSON value
+//	DELETE /settings/:key
+func Register(router fiber.Router) {
+	router.Get("/settings", listHandler)
+	router.Get("/settings/:key", showHandler)
+	router.Put("/settings/:key", updateHandler)
+	router.Delete("/settings/:key", deleteHandler)
+}
+
+func listHandler(c *fiber.Ctx) error {
+	all, err := All(c.UserContext())
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+
+	out := make(map[string]json.RawMessage, len(all))
+	for key, value := range all {
+		out[key] = json.RawMessage(value)
+	}
+	return response.Success(c, out, nil)
+}
+
+func showHandler(c *fiber.Ctx) error {
+	raw, err := Raw(c.UserContext(), c.Params("key"))
+	if errors.Is(err, ErrNotFound) {
+		return response.Error(c, fiber.StatusNotFound, err)
+	}
+	if err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, fiber.Map{"key": c.Params("key"), "value": json.RawMessage(raw)}, nil)
+}
+
+func updateHandler(c *fiber.Ctx) error {
+	body := c.Body()
+	if !json.Valid(body) {
+		return response.Error(c, fiber.StatusBadRequest, errors.New("settings : body must be a JSON value"))
+	}
+	value := json.RawMessage(append([]byte(nil), body...))
+	if err := Set(c.UserContext(), c.Params("key"), value); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, fiber.Map{"key": c.Params("key"), "value": value}, nil)
+}
+
+func deleteHandler(c *fiber.Ctx) error {
+	if err := Delete(c.UserContext(), c.Params("key")); err != nil {
+		return response.Error(c, fiber.StatusInternalServerError, err)
+	}
+	return response.Success(c, fiber.Map{"key": c.Params("key")}, nil)
+}
@@ -0,0 +1,313 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rikiihsan/nest/database"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+var ErrNotFound = errors.New("settings : setting not found")
+
+var (
+	mu       sync.RWMutex
+	local    map[string]string
+	loadedAt time.Time
+)
+
+// fillScript replaces hash with values loaded from database unless the version was bumped by a
+// change meanwhile, so a slow load never writes back values older than the change
+var fillScript = redis.NewScript(`
+if (redis.call('GET', KEYS[2]) or '') ~= ARGV[1] then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], unpack(ARGV, 3))
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+func cacheKey() string {
+	return config.Prefix + ":values"
+}
+
+func versionKey() string {
+	return config.Prefix + ":version"
+}
+
+func channel() string {
+	return config.Prefix + ":invalidate"
+}
+
+// CreateTable creates settings table if it does not exist
+func CreateTable(ctx context.Context, db bun.IDB) error {
+	_, err := db.NewCreateTable().Model((*Setting)(nil)).IfNotExists().Exec(ctx)
+	return err
+}
+
+// invalidate drops local cache
+func invalidate() {
+	mu.Lock()
+	defer mu.Unlock()
+	local = nil
+}
+
+// values returns all settings from local cache, Redis hash or database in that order
+func values(ctx context.Context) (map[string]string, error) {
+	mu.RLock()
+	if local != nil && time.Since(loadedAt) < config.TTL {
+		defer mu.RUnlock()
+		return local, nil
+	}
+	mu.RUnlock()
+
+	loaded, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	local, loadedAt = loaded, time.Now()
+	return local, nil
+}
+
+// load reads settings from Redis hash, filling it from database when empty
+func load(ctx context.Context) (map[string]string, error) {
+	version, fill := "", false
+	if database.RedisClient != nil {
+		cached, err := database.RedisClient.HGetAll(ctx, cacheKey()).Result()
+		if err == nil && len(cached) > 0 {
+			return cached, nil
+		}
+		// read before the database so changes committed during the load are detected
+		version, err = database.RedisClient.Get(ctx, versionKey()).Result()
+		fill = err == nil || errors.Is(err, redis.Nil)
+	}
+
+	db, err := database.GetDB(config.Session)
+	if err != nil {
+		return nil, err
+	}
+	var rows []Setting
+	if err := db.NewSelect().Model(&rows).Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	loaded := make(map[string]string, len(rows))
+	for _, row := range rows {
+		loaded[row.Key] = row.Value
+	}
+	if fill && len(loaded) > 0 {
+		args := []interface{}{version, config.TTL.Milliseconds()}
+		for key, value := range loaded {
+			args = append(args, key, value)
+		}
+		if err := fillScript.Run(ctx, database.RedisClient, []string{cacheKey(), versionKey()}, args...).Err(); err != nil {
+			slog.WarnContext(ctx, "failed to cache settings", "error", err)
+		}
+	}
+	return loaded, nil
+}
+
+// Raw returns JSON encoded value of key, ErrNotFound when unset
+func Raw(ctx context.Context, key string) (string, error) {
+	all, err := values(ctx)
+	if err != nil {
+		return "", err
+	}
+	value, ok := all[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// All returns JSON encoded values of all settings
+func All(ctx context.Context) (map[string]string, error) {
+	all, err := values(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(all))
+	for key, value := range all {
+		out[key] = value
+	}
+	return out, nil
+}
+
+// Bind decodes value of key into dst, ErrNotFound when unset
+func Bind(ctx context.Context, key string, dst interface{}) error {
+	raw, err := Raw(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(raw), dst)
+}
+
+// Get returns typed value of key, def when unset or not decodable as T
+func Get[T any](ctx context.Context, key string, def T) T {
+	var value T
+	if err := Bind(ctx, key, &value); err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.WarnContext(ctx, "setting unavailable, using default", "key", key, "error", err)
+		}
+		return def
+	}
+	return value
+}
+
+// String returns string setting or def
+func String(ctx context.Context, key, def string) string {
+	return Get(ctx, key, def)
+}
+
+// Int returns int setting or def, numeric strings are accepted
+func Int(ctx context.Context, key string, def int) int {
+	raw, err := Raw(ctx, key)
+	if err != nil {
+		return def
+	}
+	var n int
+	if json.Unmarshal([]byte(raw), &n) == nil {
+		return n
+	}
+	var s string
+	if json.Unmarshal([]byte(raw), &s) == nil {
+		if n, err := strconv.Atoi(s); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+// Bool returns bool setting or def
+func Bool(ctx context.Context, key string, def bool) bool {
+	return Get(ctx, key, def)
+}
+
+// Float returns float setting or def
+func Float(ctx context.Context, key string, def float64) float64 {
+	return Get(ctx, key, def)
+}
+
+// Duration returns duration setting stored as string like "5m" or def
+func Duration(ctx context.Context, key string, def time.Duration) time.Duration {
+	s := Get(ctx, key, "")
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	return def
+}
+
+// Set stores JSON encoded value and invalidates caches of all instances
+func Set(ctx context.Context, key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode setting %s: %w", key, err)
+	}
+
+	setting := &Setting{Key: key, Value: string(raw), UpdatedAt: time.Now()}
+	if err := store(ctx, setting); err != nil {
+		return fmt.Errorf("failed to store setting %s: %w", key, err)
+	}
+
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		publish(ctx, key)
+	})
+	return nil
+}
+
+// store upserts setting, on MSSQL the row is replaced in a transaction as it has no upsert
+func store(ctx context.Context, setting *Setting) error {
+	db, err := database.IDB(ctx, config.Session)
+	if err != nil {
+		return err
+	}
+
+	query := db.NewInsert().Model(setting)
+	switch db.Dialect().Name() {
+	case dialect.MySQL:
+		query = query.On("DUPLICATE KEY UPDATE").Set("value = VALUES(value), updated_at = VALUES(updated_at)")
+	case dialect.MSSQL:
+		return database.RunInTx(ctx, config.Session, func(ctx context.Context) error {
+			tx, err := database.IDB(ctx, config.Session)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.NewDelete().Model(setting).WherePK().Exec(ctx); err != nil {
+				return err
+			}
+			_, err = tx.NewInsert().Model(setting).Exec(ctx)
+			return err
+		})
+	default:
+		query = query.On("CONFLICT (?) DO UPDATE", bun.Ident("key")).Set("value = EXCLUDED.value, updated_at = EXCLUDED.updated_at")
+	}
+	_, err = query.Exec(ctx)
+	return err
+}
+
+// Delete removes setting and invalidates caches of all instances
+func Delete(ctx context.Context, key string) error {
+	db, err := database.IDB(ctx, config.Session)
+	if err != nil {
+		return err
+	}
+	if _, err := db.NewDelete().Model((*Setting)(nil)).Where("? = ?", bun.Ident("key"), key).Exec(ctx); err != nil {
+		return err
+	}
+
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		publish(ctx, key)
+	})
+	return nil
+}
+
+// publish bumps the version, drops Redis hash and notifies instances to drop local caches
+func publish(ctx context.Context, key string) {
+	invalidate()
+	if database.RedisClient == nil {
+		return
+	}
+	pipe := database.RedisClient.TxPipeline()
+	pipe.Incr(ctx, versionKey())
+	pipe.Del(ctx, cacheKey())
+	pipe.Publish(ctx, channel(), key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to invalidate settings cache", "key", key, "error", err)
+	}
+}
+
+// Listen drops local cache whenever another instance changes a setting, blocks until ctx is done
+func Listen(ctx context.Context) error {
+	if database.RedisClient == nil {
+		return nil
+	}
+
+	sub := database.RedisClient.Subscribe(ctx, channel())
+	defer sub.Close()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			invalidate()
+			slog.DebugContext(ctx, "settings invalidated", "key", message.Payload)
+		}
+	}
+}
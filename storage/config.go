@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Driver represents object storage backend
+type Driver interface {
+	Put(ctx context.Context, key string, r io.Reader, opts ...PutOption) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// PutOptions represents object write options
+type PutOptions struct {
+	ContentType string
+	// Size of content, -1 when unknown
+	Size int64
+}
+
+// PutOption customizes Put
+type PutOption func(o *PutOptions)
+
+// WithContentType sets object content type
+func WithContentType(contentType string) PutOption {
+	return func(o *PutOptions) {
+		o.ContentType = contentType
+	}
+}
+
+// WithSize sets content length allowing single request uploads
+func WithSize(size int64) PutOption {
+	return func(o *PutOptions) {
+		o.Size = size
+	}
+}
+
+func newPutOptions(opts []PutOption) PutOptions {
+	o := PutOptions{Size: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ContentType == "" {
+		o.ContentType = "application/octet-stream"
+	}
+	return o
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocalDriver stores objects on local filesystem
+type LocalDriver struct {
+	Root string
+	// BaseURL is public URL where Handler is mounted, used by SignedURL
+	BaseURL string
+	// Secret signs URLs produced by SignedURL
+	Secret []byte
+}
+
+// path resolves key inside root rejecting traversal
+func (d *LocalDriver) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	full := filepath.Join(d.Root, clean)
+	if !strings.HasPrefix(full, filepath.Clean(d.Root)) {
+		return "", fmt.Errorf("storage : invalid key %q", key)
+	}
+	return full, nil
+}
+
+// Put writes object atomically via temporary file
+func (d *LocalDriver) Put(ctx context.Context, key string, r io.Reader, opts ...PutOption) error {
+	full, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), full)
+}
+
+// Get opens object for reading
+func (d *LocalDriver) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	full, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(full)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes object, missing objects are ignored
+func (d *LocalDriver) Delete(ctx context.Context, key string) error {
+	full, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Exists reports whether object exists
+func (d *LocalDriver) Exists(ctx context.Context, key string) (bool, error) {
+	full, err := d.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(full)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (d *LocalDriver) sign(key string, expires int64) string {
+	h := hmac.New(sha256.New, d.Secret)
+	fmt.Fprintf(h, "%s:%d", key, expires)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SignedURL returns URL served by Handler valid until expiry
+func (d *LocalDriver) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if len(d.Secret) == 0 {
+		return "", errors.New("storage : local driver secret is not configured")
+	}
+	key = strings.TrimPrefix(key, "/")
+	exp := time.Now().Add(expires).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(exp, 10))
+	query.Set("signature", d.sign(key, exp))
+	return strings.TrimSuffix(d.BaseURL, "/") + "/" + key + "?" + query.Encode(), nil
+}
+
+// Handler serves objects requested with URLs from SignedURL, mount as router.Get(prefix+"/*", h)
+func (d *LocalDriver) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key, err := url.PathUnescape(c.Params("*"))
+		if err != nil {
+			return fiber.ErrBadRequest
+		}
+		exp, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+		if err != nil || time.Now().Unix() > exp {
+			return fiber.ErrForbidden
+		}
+		if !hmac.Equal([]byte(c.Query("signature")), []byte(d.sign(key, exp))) {
+			return fiber.ErrForbidden
+		}
+
+		full, err := d.path(key)
+		if err != nil {
+			return fiber.ErrBadRequest
+		}
+		if _, err := os.Stat(full); err != nil {
+			return fiber.ErrNotFound
+		}
+		return c.SendFile(full)
+	}
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("storage : object not found")
+	ErrDiskNotFound = errors.New("storage : disk not found")
+)
+
+var (
+	mu    sync.RWMutex
+	disks = make(map[string]Driver)
+)
+
+// Register registers storage disk by name, "default" is used by package helpers
+func Register(name string, driver Driver) {
+	mu.Lock()
+	defer mu.Unlock()
+	disks[name] = driver
+}
+
+// Disk returns registered disk by name
+func Disk(name string) (Driver, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	driver, exists := disks[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrDiskNotFound, name)
+	}
+	return driver, nil
+}
+
+// Put writes object to default disk
+func Put(ctx context.Context, key string, r io.Reader, opts ...PutOption) error {
+	disk, err := Disk("default")
+	if err != nil {
+		return err
+	}
+	return disk.Put(ctx, key, r, opts...)
+}
+
+// Get opens object from default disk
+func Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	disk, err := Disk("default")
+	if err != nil {
+		return nil, err
+	}
+	return disk.Get(ctx, key)
+}
+
+// Delete removes object from default disk
+func Delete(ctx context.Context, key string) error {
+	disk, err := Disk("default")
+	if err != nil {
+		return err
+	}
+	return disk.Delete(ctx, key)
+}
+
+// SignedURL returns temporary URL of object on default disk
+func SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	disk, err := Disk("default")
+	if err != nil {
+		return "", err
+	}
+	return disk.SignedURL(ctx, key, expires)
+}
+
+// Stream copies object from disk into w
+func Stream(ctx context.Context, disk Driver, key string, w io.Writer) (int64, error) {
+	r, err := disk.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return io.Copy(w, r)
+}
@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rikiihsan/nest/internal/awsv4"
+)
+
+// MinPartSize is the smallest part size accepted by S3 multipart uploads
+const MinPartSize = 5 << 20
+
+// S3Driver stores objects in S3-compatible storage (AWS, MinIO, R2, GCS XML API)
+type S3Driver struct {
+	Bucket       string
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// Endpoint defaults to https://s3.{region}.amazonaws.com
+	Endpoint string
+	// PathStyle uses endpoint/bucket/key instead of bucket.endpoint/key
+	PathStyle bool
+	// PartSize used for multipart uploads, defaults to 8MiB
+	PartSize int64
+	Client   *http.Client
+}
+
+// NewGCS creates driver for Google Cloud Storage using HMAC keys and the XML API
+func NewGCS(bucket, accessKey, secretKey string) *S3Driver {
+	return &S3Driver{
+		Bucket:    bucket,
+		Region:    "auto",
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Endpoint:  "https://storage.googleapis.com",
+		PathStyle: true,
+	}
+}
+
+func (d *S3Driver) client() *http.Client {
+	if d.Client != nil {
+		return d.Client
+	}
+	return http.DefaultClient
+}
+
+func (d *S3Driver) credentials() awsv4.Credentials {
+	return awsv4.Credentials{
+		AccessKey:    d.AccessKey,
+		SecretKey:    d.SecretKey,
+		SessionToken: d.SessionToken,
+	}
+}
+
+// objectURL builds object URL with optional query
+func (d *S3Driver) objectURL(key string, query url.Values) (*url.URL, error) {
+	endpoint := d.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", d.Region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	key = strings.TrimPrefix(key, "/")
+	if d.PathStyle {
+		u.Path = "/" + d.Bucket + "/" + key
+	} else {
+		u.Host = d.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = awsv4.EscapePath(u.Path)
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+	return u, nil
+}
+
+// do signs and executes request, non-2xx responses are returned as errors
+func (d *S3Driver) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	u, err := d.objectURL(key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	awsv4.Sign(req, awsv4.UnsignedPayload, d.credentials(), d.Region, "s3", time.Now())
+
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("storage : %s %s returned %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// Put uploads object, unknown or large sizes use multipart upload
+func (d *S3Driver) Put(ctx context.Context, key string, r io.Reader, opts ...PutOption) error {
+	o := newPutOptions(opts)
+	if o.Size < 0 || o.Size > d.partSize() {
+		return d.PutMultipart(ctx, key, r, opts...)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", o.ContentType)
+	resp, err := d.do(ctx, http.MethodPut, key, nil, r, o.Size, header)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads object as stream
+func (d *S3Driver) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := d.do(ctx, http.MethodGet, key, nil, nil, -1, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes object
+func (d *S3Driver) Delete(ctx context.Context, key string) error {
+	resp, err := d.do(ctx, http.MethodDelete, key, nil, nil, -1, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Exists reports whether object exists
+func (d *S3Driver) Exists(ctx context.Context, key string) (bool, error) {
+	resp, err := d.do(ctx, http.MethodHead, key, nil, nil, -1, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
+
+// SignedURL returns presigned GET URL
+func (d *S3Driver) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	u, err := d.objectURL(key, nil)
+	if err != nil {
+		return "", err
+	}
+	return awsv4.Presign(http.MethodGet, u, d.credentials(), d.Region, "s3", expires, time.Now()), nil
+}
+
+func (d *S3Driver) partSize() int64 {
+	if d.PartSize < MinPartSize {
+		return 8 << 20
+	}
+	return d.PartSize
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// PutMultipart uploads r in parts without buffering the whole object
+func (d *S3Driver) PutMultipart(ctx context.Context, key string, r io.Reader, opts ...PutOption) error {
+	o := newPutOptions(opts)
+
+	uploadID, err := d.CreateMultipartUpload(ctx, key, o.ContentType)
+	if err != nil {
+		return err
+	}
+
+	var parts []completedPart
+	buf := make([]byte, d.partSize())
+	for number := 1; ; number++ {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 || number == 1 {
+			etag, err := d.UploadPart(ctx, key, uploadID, number, bytes.NewReader(buf[:n]), int64(n))
+			if err != nil {
+				d.AbortMultipartUpload(context.WithoutCancel(ctx), key, uploadID)
+				return err
+			}
+			parts = append(parts, completedPart{PartNumber: number, ETag: etag})
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			d.AbortMultipartUpload(context.WithoutCancel(ctx), key, uploadID)
+			return readErr
+		}
+	}
+
+	if err := d.CompleteMultipartUpload(ctx, key, uploadID, parts); err != nil {
+		d.AbortMultipartUpload(context.WithoutCancel(ctx), key, uploadID)
+		return err
+	}
+	return nil
+}
+
+// CreateMultipartUpload starts multipart upload and returns upload ID
+func (d *S3Driver) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	resp, err := d.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0, header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("storage : invalid multipart response: %w", err)
+	}
+	return result.UploadID, nil
+}
+
+// UploadPart uploads single part and returns its ETag
+func (d *S3Driver) UploadPart(ctx context.Context, key, uploadID string, number int, r io.Reader, size int64) (string, error) {
+	query := url.Values{
+		"partNumber": {strconv.Itoa(number)},
+		"uploadId":   {uploadID},
+	}
+	resp, err := d.do(ctx, http.MethodPut, key, query, r, size, nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+// CompleteMultipartUpload assembles uploaded parts
+func (d *S3Driver) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []completedPart) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(body), int64(len(body)), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 may report errors with 200 status in the body
+	data, _ := io.ReadAll(resp.Body)
+	if bytes.Contains(data, []byte("<Error>")) {
+		return fmt.Errorf("storage : complete multipart upload failed: %s", data)
+	}
+	return nil
+}
+
+// AbortMultipartUpload discards uploaded parts
+func (d *S3Driver) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	resp, err := d.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, -1, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
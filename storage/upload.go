@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/validator"
+	"github.com/valyala/fasthttp"
+)
+
+// UploadResult describes stored upload
+type UploadResult struct {
+	Key         string `json:"key"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// Upload validates multipart file from field and streams it to disk under key
+//
+// Validation failures are returned as validator.Errors.
+func Upload(c *fiber.Ctx, field string, disk Driver, key string, rules validator.FileRules) (*UploadResult, error) {
+	fh, err := c.FormFile(field)
+	if err != nil && !errors.Is(err, fasthttp.ErrMissingFile) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if errs := validator.ValidateFile(fh, field, rules); len(errs) > 0 {
+		return nil, validator.Errors(errs)
+	}
+	if fh == nil {
+		return nil, nil
+	}
+
+	contentType, err := validator.DetectFileType(fh)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := disk.Put(c.UserContext(), key, file, WithContentType(contentType), WithSize(fh.Size)); err != nil {
+		return nil, err
+	}
+
+	return &UploadResult{
+		Key:         key,
+		Filename:    fh.Filename,
+		Size:        fh.Size,
+		ContentType: contentType,
+	}, nil
+}
@@ -0,0 +1,39 @@
+package tasks
+
+import "time"
+
+// Config represents background task configuration
+type Config struct {
+	// MaxConcurrent caps running tasks of the process, zero is unlimited
+	MaxConcurrent int
+	// Timeout of each task, zero runs until the task or shutdown ends it
+	Timeout time.Duration
+}
+
+// DefaultConfig is used until Init is called
+var DefaultConfig = Config{
+	MaxConcurrent: 1000,
+	Timeout:       5 * time.Minute,
+}
+
+// Option configures single task
+type Option func(*options)
+
+type options struct {
+	timeout time.Duration
+	limit   int
+}
+
+// WithTimeout overrides Config.Timeout for task, zero disables it
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithLimit caps concurrently running tasks sharing the name
+func WithLimit(n int) Option {
+	return func(o *options) {
+		o.limit = n
+	}
+}
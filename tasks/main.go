@@ -0,0 +1,145 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/rikiihsan/nest/logger"
+)
+
+// Errors returned by Go
+var (
+	ErrLimit   = errors.New("tasks : concurrency limit reached")
+	ErrStopped = errors.New("tasks : runner is shutting down")
+)
+
+// runner tracks running tasks
+type runner struct {
+	mu       sync.Mutex
+	config   Config
+	running  int
+	byName   map[string]int
+	stopping bool
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+var tasks = newRunner(DefaultConfig)
+
+func newRunner(cfg Config) *runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &runner{config: cfg, byName: map[string]int{}, ctx: ctx, cancel: cancel}
+}
+
+// Init sets task configuration
+func Init(cfg Config) {
+	tasks.mu.Lock()
+	defer tasks.mu.Unlock()
+	tasks.config = cfg
+}
+
+// Go runs fn in background goroutine. Panics are recovered and logged with stack, errors are logged.
+// The task context keeps values of ctx but not its cancellation, so it outlives the request.
+// Returns ErrLimit when a concurrency cap is reached and ErrStopped after Drain started
+func Go(ctx context.Context, name string, fn func(ctx context.Context) error, opts ...Option) error {
+	o := options{timeout: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	tasks.mu.Lock()
+	if tasks.stopping {
+		tasks.mu.Unlock()
+		return ErrStopped
+	}
+	if tasks.config.MaxConcurrent > 0 && tasks.running >= tasks.config.MaxConcurrent {
+		tasks.mu.Unlock()
+		return ErrLimit
+	}
+	if o.limit > 0 && tasks.byName[name] >= o.limit {
+		tasks.mu.Unlock()
+		return fmt.Errorf("%w for %s", ErrLimit, name)
+	}
+	if o.timeout < 0 {
+		o.timeout = tasks.config.Timeout
+	}
+	tasks.running++
+	tasks.byName[name]++
+	tasks.wg.Add(1)
+	tasks.mu.Unlock()
+
+	base := context.WithoutCancel(ctx)
+	taskCtx, cancel := context.WithCancel(base)
+	if o.timeout > 0 {
+		taskCtx, cancel = context.WithTimeout(base, o.timeout)
+	}
+	// Drain cancels tasks still running when its deadline passes
+	stop := context.AfterFunc(tasks.ctx, cancel)
+
+	go func() {
+		defer func() {
+			stop()
+			cancel()
+			tasks.mu.Lock()
+			tasks.running--
+			if tasks.byName[name]--; tasks.byName[name] <= 0 {
+				delete(tasks.byName, name)
+			}
+			tasks.mu.Unlock()
+			tasks.wg.Done()
+		}()
+		run(taskCtx, name, fn)
+	}()
+	return nil
+}
+
+// run executes task logging errors and panics
+func run(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Get().ErrorContext(ctx, "background task panicked", "task", name, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		logger.Get().ErrorContext(ctx, "background task failed", "task", name, "duration", time.Since(start), "error", err)
+		return
+	}
+	logger.Get().DebugContext(ctx, "background task finished", "task", name, "duration", time.Since(start))
+}
+
+// Running returns number of running tasks
+func Running() int {
+	tasks.mu.Lock()
+	defer tasks.mu.Unlock()
+	return tasks.running
+}
+
+// Drain stops accepting tasks and waits for running ones, cancelling them when ctx is done.
+// App.Shutdown calls it before stop hooks, call it yourself before closing resources tasks use otherwise
+func Drain(ctx context.Context) error {
+	tasks.mu.Lock()
+	tasks.stopping = true
+	tasks.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		tasks.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// Tasks ignoring their context are abandoned
+		tasks.cancel()
+		return fmt.Errorf("tasks : %d task(s) cancelled on shutdown: %w", Running(), ctx.Err())
+	}
+}
@@ -0,0 +1,61 @@
+package unique
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rikiihsan/nest/database"
+)
+
+var ErrNoRedis = errors.New("unique : redis client is not initialized")
+
+// Prefix is prepended to all HyperLogLog keys
+var Prefix = "nest:unique:"
+
+// Add adds members to HyperLogLog of key, reports whether the estimate changed
+func Add(ctx context.Context, key string, members ...string) (bool, error) {
+	if database.RedisClient == nil {
+		return false, ErrNoRedis
+	}
+
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+	n, err := database.RedisClient.PFAdd(ctx, Prefix+key, values...).Result()
+	return n == 1, err
+}
+
+// AddWithExpiry adds members and expires key after ttl, e.g. daily unique visitors
+func AddWithExpiry(ctx context.Context, key string, ttl time.Duration, members ...string) (bool, error) {
+	changed, err := Add(ctx, key, members...)
+	if err != nil {
+		return changed, err
+	}
+	return changed, database.RedisClient.ExpireNX(ctx, Prefix+key, ttl).Err()
+}
+
+// EstimateCount returns approximate count of distinct members across keys, standard error is 0.81%
+func EstimateCount(ctx context.Context, keys ...string) (int64, error) {
+	if database.RedisClient == nil {
+		return 0, ErrNoRedis
+	}
+	return database.RedisClient.PFCount(ctx, prefixed(keys)...).Result()
+}
+
+// Merge stores union of keys in dest
+func Merge(ctx context.Context, dest string, keys ...string) error {
+	if database.RedisClient == nil {
+		return ErrNoRedis
+	}
+	return database.RedisClient.PFMerge(ctx, Prefix+dest, prefixed(keys)...).Err()
+}
+
+func prefixed(keys []string) []string {
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = Prefix + key
+	}
+	return out
+}
@@ -0,0 +1,273 @@
+package validator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/logger"
+)
+
+// Prefix is prefix of cached async rule results in Redis
+var Prefix = "nest:validator:"
+
+// Fallback decides the outcome of async rules whose dependency failed or timed out
+type Fallback int
+
+const (
+	// FallbackSkip treats the value as valid
+	FallbackSkip Fallback = iota
+	// FallbackWarn treats the value as valid and logs a warning
+	FallbackWarn
+	// FallbackFail reports the field with UnavailableMessage
+	FallbackFail
+)
+
+// AsyncFunc checks value with an external call, e.g. a VAT number API. Errors mean the dependency is unavailable
+type AsyncFunc func(ctx context.Context, value interface{}, param string) (bool, error)
+
+// AsyncOptions configures async rule, messages may use {0} for field name and {1} for tag parameter
+type AsyncOptions struct {
+	Message            string
+	UnavailableMessage string
+	// Timeout bounds each call of the rule, defaults to 5s
+	Timeout time.Duration
+	// CacheTTL keeps results in Redis, zero disables caching
+	CacheTTL time.Duration
+	Fallback Fallback
+}
+
+// DefaultAsyncOptions holds default messages and timeout of async rules
+var DefaultAsyncOptions = AsyncOptions{
+	Message:            "{0} is invalid",
+	UnavailableMessage: "{0} could not be verified, try again later",
+	Timeout:            5 * time.Second,
+}
+
+type asyncRule struct {
+	tag  string
+	fn   AsyncFunc
+	opts AsyncOptions
+}
+
+var (
+	asyncMu    sync.RWMutex
+	asyncRules = map[string]*asyncRule{}
+)
+
+// RegisterAsyncValidation adds rule calling fn, checks of a ValidateCtx call run concurrently
+// once the other rules passed. Zero values pass, combine with required when the field is mandatory.
+// List async rules last in the tag, rules after them on the same field are not checked by ValidateCtx
+func RegisterAsyncValidation(tag string, fn AsyncFunc, opts ...AsyncOptions) error {
+	if err := checkFrozen(tag); err != nil {
+		return err
+	}
+	rule := &asyncRule{tag: tag, fn: fn, opts: DefaultAsyncOptions}
+	if len(opts) > 0 {
+		rule.opts = asyncDefaults(opts[0])
+	}
+
+	err := validate.RegisterValidationCtx(tag, rule.validate)
+	if err != nil {
+		return fmt.Errorf("failed to register validation function: %w", err)
+	}
+
+	err = validate.RegisterTranslation(tag, trans,
+		func(ut ut.Translator) error {
+			if err := ut.Add(tag, rule.opts.Message, true); err != nil {
+				return err
+			}
+			return ut.Add(unavailableKey(tag), rule.opts.UnavailableMessage, true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			return translateParams(ut, tag, fe)
+		})
+	if err != nil {
+		return fmt.Errorf("failed to register validation translation: %w", err)
+	}
+
+	asyncMu.Lock()
+	asyncRules[tag] = rule
+	asyncMu.Unlock()
+	return nil
+}
+
+// asyncDefaults fills empty fields from DefaultAsyncOptions
+func asyncDefaults(opts AsyncOptions) AsyncOptions {
+	if opts.Message == "" {
+		opts.Message = DefaultAsyncOptions.Message
+	}
+	if opts.UnavailableMessage == "" {
+		opts.UnavailableMessage = DefaultAsyncOptions.UnavailableMessage
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultAsyncOptions.Timeout
+	}
+	return opts
+}
+
+func unavailableKey(tag string) string {
+	return tag + ".unavailable"
+}
+
+func hasAsyncRules() bool {
+	asyncMu.RLock()
+	defer asyncMu.RUnlock()
+	return len(asyncRules) > 0
+}
+
+// asyncResult is outcome of one rule call
+type asyncResult struct {
+	valid       bool
+	unavailable bool
+}
+
+// passed applies fallback of rule to result
+func (r asyncResult) passed(rule *asyncRule) bool {
+	if r.unavailable {
+		return rule.opts.Fallback != FallbackFail
+	}
+	return r.valid
+}
+
+type asyncKey struct {
+	tag   string
+	param string
+	value string
+}
+
+type asyncCheck struct {
+	rule   *asyncRule
+	value  interface{}
+	param  string
+	result asyncResult
+}
+
+// asyncRun collects checks of a validation, their fields are reported as pending errors until resolved
+type asyncRun struct {
+	mu     sync.Mutex
+	checks map[asyncKey]*asyncCheck
+}
+
+type asyncRunKey struct{}
+
+// validate is the validator.FuncCtx of rule. Without asyncRun in ctx, e.g. in SliceValidate, the rule is called inline
+func (rule *asyncRule) validate(ctx context.Context, fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if !field.IsValid() || field.IsZero() {
+		return true
+	}
+	value := field.Interface()
+
+	run, _ := ctx.Value(asyncRunKey{}).(*asyncRun)
+	if run == nil {
+		return rule.check(ctx, value, fl.Param()).passed(rule)
+	}
+
+	key := asyncKey{tag: rule.tag, param: fl.Param(), value: fmt.Sprint(value)}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if _, ok := run.checks[key]; !ok {
+		run.checks[key] = &asyncCheck{rule: rule, value: value, param: fl.Param()}
+	}
+	// pending, apply drops the error when the check passes
+	return false
+}
+
+// pending returns check of fe when it was reported by an async rule
+func (run *asyncRun) pending(fe validator.FieldError) (*asyncCheck, bool) {
+	check, ok := run.checks[asyncKey{tag: fe.Tag(), param: fe.Param(), value: fmt.Sprint(fe.Value())}]
+	return check, ok
+}
+
+// apply returns errors of the other rules when there are any, without calling async rules.
+// Otherwise pending checks are resolved and errors of failed ones are returned
+func (run *asyncRun) apply(ctx context.Context, errs validator.ValidationErrors) validator.ValidationErrors {
+	var others, async validator.ValidationErrors
+	for _, fe := range errs {
+		if _, ok := run.pending(fe); ok {
+			async = append(async, fe)
+		} else {
+			others = append(others, fe)
+		}
+	}
+	if len(others) > 0 {
+		return others
+	}
+
+	run.resolve(ctx)
+	failed := async[:0]
+	for _, fe := range async {
+		if check, _ := run.pending(fe); !check.result.passed(check.rule) {
+			failed = append(failed, fe)
+		}
+	}
+	return failed
+}
+
+// resolve calls pending checks concurrently
+func (run *asyncRun) resolve(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, check := range run.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			check.result = check.rule.check(ctx, check.value, check.param)
+		}()
+	}
+	wg.Wait()
+}
+
+// message translates fe, using the unavailable message when an async rule could not be checked
+func (run *asyncRun) message(data interface{}, fe validator.FieldError) string {
+	if run != nil {
+		if check, ok := run.pending(fe); ok && check.result.unavailable {
+			return translateParams(trans, unavailableKey(fe.Tag()), fe)
+		}
+	}
+	return messageOf(data, fe)
+}
+
+// check calls rule with its timeout, reading and storing results in Redis when CacheTTL is set
+func (rule *asyncRule) check(ctx context.Context, value interface{}, param string) asyncResult {
+	key := rule.cacheKey(value, param)
+	if rule.opts.CacheTTL > 0 && database.RedisClient != nil {
+		if cached, err := database.RedisClient.Get(ctx, key).Result(); err == nil {
+			return asyncResult{valid: cached == "1"}
+		}
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, rule.opts.Timeout)
+	defer cancel()
+	valid, err := rule.fn(callCtx, value, param)
+	if err != nil {
+		if rule.opts.Fallback == FallbackWarn {
+			logger.Get().WarnContext(ctx, "async validation unavailable", "tag", rule.tag, "error", err)
+		}
+		return asyncResult{unavailable: true}
+	}
+
+	if rule.opts.CacheTTL > 0 && database.RedisClient != nil {
+		cached := "0"
+		if valid {
+			cached = "1"
+		}
+		if err := database.RedisClient.Set(ctx, key, cached, rule.opts.CacheTTL).Err(); err != nil {
+			logger.Get().WarnContext(ctx, "async validation cache failed", "tag", rule.tag, "error", err)
+		}
+	}
+	return asyncResult{valid: valid}
+}
+
+// cacheKey hashes value so raw input, e.g. tax numbers, is not stored in key names
+func (rule *asyncRule) cacheKey(value interface{}, param string) string {
+	sum := sha256.Sum256([]byte(param + "\x00" + reflect.TypeOf(value).String() + "\x00" + fmt.Sprint(value)))
+	return Prefix + "async:" + rule.tag + ":" + hex.EncodeToString(sum[:])
+}
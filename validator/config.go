@@ -1,10 +1,13 @@
 package validator
 
 import (
+	"fmt"
+
 	"github.com/gofiber/fiber/v2"
 )
 
-// Translator represents custom validation message translator
+// Translator represents custom validation message translator,
+// Message may use {0} for field name and {1} for tag parameter
 type Translator struct {
 	Tag     string
 	Message string
@@ -15,7 +18,23 @@ type ValidatorError struct {
 	FailedField string `json:"failed_field"`
 	Tag         string `json:"tag"`
 	Message     string `json:"message"`
+	Param       string `json:"param,omitempty"` // Tag parameter, e.g. 8 of min=8
 	Index       *int   `json:"index,omitempty"` // For slice validation
+	// Row and Column locate the source cell of imported data, see SliceValidateWithOrigin
+	Row    *int   `json:"row,omitempty"`
+	Column string `json:"column,omitempty"`
+	// Phase is PhaseSyntax or PhaseSemantic for errors collected by Validators.Run
+	Phase string `json:"phase,omitempty"`
+}
+
+// Origin maps slice elements back to rows and columns of imported files
+type Origin struct {
+	// FirstRow is 1-based row number of the first element, defaults to 2 for files with header row
+	FirstRow int
+	// Rows sets row number per element, overriding FirstRow when set
+	Rows []int
+	// Columns maps field path to column name or letter, defaults to the field path
+	Columns map[string]string
 }
 
 // Validators holds validation context and results
@@ -24,6 +43,9 @@ type Validators struct {
 	Data           interface{}
 	Error          bool
 	ValidationsErr []ValidatorError
+
+	semantic []SemanticFunc
+	phase    string
 }
 
 // HasErrors checks if there are validation errors
@@ -45,6 +67,7 @@ func (v *Validators) AddError(field, tag, message string) {
 		FailedField: field,
 		Tag:         tag,
 		Message:     message,
+		Phase:       v.phase,
 	})
 	v.Error = true
 }
@@ -56,6 +79,7 @@ func (v *Validators) AddErrorWithIndex(field, tag, message string, index int) {
 		Tag:         tag,
 		Message:     message,
 		Index:       &index,
+		Phase:       v.phase,
 	})
 	v.Error = true
 }
@@ -76,3 +100,25 @@ func (v *Validators) Clear() {
 	v.ValidationsErr = []ValidatorError{}
 	v.Error = false
 }
+
+// Errors wraps validation errors so they can be returned as error
+type Errors []ValidatorError
+
+// Error returns the first validation message with the total error count
+func (e Errors) Error() string {
+	if len(e) == 0 {
+		return "validation failed"
+	}
+	if len(e) == 1 {
+		return e[0].Message
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0].Message, len(e)-1)
+}
+
+// Err returns validation errors as error, nil when there are none
+func (v *Validators) Err() error {
+	if len(v.ValidationsErr) == 0 {
+		return nil
+	}
+	return Errors(v.ValidationsErr)
+}
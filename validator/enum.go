@@ -0,0 +1,73 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnumValue is underlying type of enums
+type EnumValue interface {
+	~string | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Enum holds allowed values of T, registered as validation tag aliasing oneof
+type Enum[T EnumValue] struct {
+	tag    string
+	values []T
+}
+
+// NewEnum registers tag as alias of oneof with values, e.g. NewEnum("order_status", Pending, Paid)
+// lets fields use validate:"order_status" while the value list lives next to the type
+func NewEnum[T EnumValue](tag string, values ...T) (*Enum[T], error) {
+	if err := checkFrozen(tag); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("validator : enum %s has no values", tag)
+	}
+
+	params := make([]string, len(values))
+	for i, value := range values {
+		params[i] = fmt.Sprint(value)
+		if params[i] == "" || strings.ContainsAny(params[i], " ,|'") {
+			return nil, fmt.Errorf("validator : enum %s value %q cannot be used with oneof", tag, params[i])
+		}
+	}
+
+	validate.RegisterAlias(tag, "oneof="+strings.Join(params, " "))
+	if err := Init(Translator{Tag: tag, Message: "{0} must be one of [{1}]"}); err != nil {
+		return nil, err
+	}
+	return &Enum[T]{tag: tag, values: values}, nil
+}
+
+// Tag returns validation tag of enum
+func (e *Enum[T]) Tag() string {
+	return e.tag
+}
+
+// Values returns allowed values in definition order
+func (e *Enum[T]) Values() []T {
+	return append([]T(nil), e.values...)
+}
+
+// Contains reports whether v is allowed
+func (e *Enum[T]) Contains(v T) bool {
+	for _, value := range e.values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse returns value whose text is s, e.g. from query strings
+func (e *Enum[T]) Parse(s string) (T, error) {
+	for _, value := range e.values {
+		if fmt.Sprint(value) == s {
+			return value, nil
+		}
+	}
+	var zero T
+	return zero, fmt.Errorf("validator : %q is not a valid %s", s, e.tag)
+}
@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// FileRules represents validation rules for uploaded files
+type FileRules struct {
+	Required bool
+	MinSize  int64
+	MaxSize  int64
+	// MimeTypes allowed, supports wildcards like image/*
+	MimeTypes []string
+	// Extensions allowed including dot, e.g. .jpg
+	Extensions []string
+}
+
+// DetectFileType sniffs content type from the first 512 bytes of uploaded file
+func DetectFileType(fh *multipart.FileHeader) (string, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// ValidateFile validates uploaded file against rules
+func ValidateFile(fh *multipart.FileHeader, field string, rules FileRules) []ValidatorError {
+	validationErrors := []ValidatorError{}
+
+	if fh == nil {
+		if rules.Required {
+			validationErrors = append(validationErrors, ValidatorError{
+				FailedField: field,
+				Tag:         "required",
+				Message:     fmt.Sprintf("%s is a required field", field),
+			})
+		}
+		return validationErrors
+	}
+
+	if rules.MaxSize > 0 && fh.Size > rules.MaxSize {
+		validationErrors = append(validationErrors, ValidatorError{
+			FailedField: field,
+			Tag:         "max_size",
+			Message:     fmt.Sprintf("%s must not be larger than %d bytes", field, rules.MaxSize),
+		})
+	}
+	if rules.MinSize > 0 && fh.Size < rules.MinSize {
+		validationErrors = append(validationErrors, ValidatorError{
+			FailedField: field,
+			Tag:         "min_size",
+			Message:     fmt.Sprintf("%s must be at least %d bytes", field, rules.MinSize),
+		})
+	}
+
+	if len(rules.Extensions) > 0 {
+		ext := strings.ToLower(filepath.Ext(fh.Filename))
+		if !containsFold(rules.Extensions, ext) {
+			validationErrors = append(validationErrors, ValidatorError{
+				FailedField: field,
+				Tag:         "extension",
+				Message:     fmt.Sprintf("%s must have one of the extensions: %s", field, strings.Join(rules.Extensions, ", ")),
+			})
+		}
+	}
+
+	if len(rules.MimeTypes) > 0 {
+		detected, err := DetectFileType(fh)
+		if err != nil || !mimeAllowed(rules.MimeTypes, detected) {
+			validationErrors = append(validationErrors, ValidatorError{
+				FailedField: field,
+				Tag:         "mime",
+				Message:     fmt.Sprintf("%s must be of type: %s", field, strings.Join(rules.MimeTypes, ", ")),
+			})
+		}
+	}
+
+	return validationErrors
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func mimeAllowed(allowed []string, detected string) bool {
+	// Strip parameters such as charset
+	detected = strings.TrimSpace(strings.SplitN(detected, ";", 2)[0])
+	for _, pattern := range allowed {
+		if strings.HasSuffix(pattern, "/*") {
+			if strings.HasPrefix(detected, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(pattern, detected) {
+			return true
+		}
+	}
+	return false
+}
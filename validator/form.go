@@ -0,0 +1,335 @@
+package validator
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MaxFormIndex bounds slice indexes of form keys so items[1000000] cannot allocate huge slices
+var MaxFormIndex = 1000
+
+var (
+	fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// ParseForm decodes urlencoded or multipart body of request into out using the form tag,
+// see DecodeForm for key syntax. Multipart files are set on *multipart.FileHeader fields
+func ParseForm(c *fiber.Ctx, out interface{}) error {
+	values := map[string][]string{}
+	var files map[string][]*multipart.FileHeader
+
+	if strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		form, err := c.MultipartForm()
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		values, files = form.Value, form.File
+	} else {
+		c.Request().PostArgs().VisitAll(func(key, value []byte) {
+			values[string(key)] = append(values[string(key)], string(value))
+		})
+	}
+
+	if err := DecodeForm(values, out, "form"); err != nil {
+		return err
+	}
+	return decodeFiles(files, out, "form")
+}
+
+// DecodeForm sets fields of out from values keyed like items[0][name], items.0.name,
+// settings[theme] or tags[], matching struct fields by tag, json tag or field name.
+// Unknown keys are ignored, values that cannot be converted are returned as Errors with indexed
+// paths like items[0].qty, the same paths Validate reports for JSON bodies
+func DecodeForm(values map[string][]string, out interface{}, tag string) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("validator : form target must be a non-nil pointer")
+	}
+
+	// Sorted keys keep errors in a stable order
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs Errors
+	for _, key := range keys {
+		path := splitFormKey(key)
+		if len(path) == 0 {
+			continue
+		}
+		err := setFormPath(v.Elem(), path, "", tag, func(field reflect.Value) error {
+			return setFormValues(field, values[key])
+		})
+		var fieldErr *formFieldError
+		if errors.As(err, &fieldErr) {
+			errs = append(errs, ValidatorError{
+				FailedField: fieldErr.path,
+				Tag:         "type",
+				Message:     fmt.Sprintf("%s has invalid value", fieldErr.path),
+			})
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// decodeFiles sets *multipart.FileHeader and []*multipart.FileHeader fields from files
+func decodeFiles(files map[string][]*multipart.FileHeader, out interface{}, tag string) error {
+	for key, headers := range files {
+		path := splitFormKey(key)
+		if len(path) == 0 {
+			continue
+		}
+		err := setFormPath(reflect.ValueOf(out).Elem(), path, "", tag, func(field reflect.Value) error {
+			switch {
+			case field.Type() == fileHeaderType && len(headers) > 0:
+				field.Set(reflect.ValueOf(headers[0]))
+			case field.Kind() == reflect.Slice && field.Type().Elem() == fileHeaderType:
+				field.Set(reflect.AppendSlice(field, reflect.ValueOf(headers)))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formFieldError locates value that could not be converted
+type formFieldError struct {
+	path string
+	err  error
+}
+
+func (e *formFieldError) Error() string {
+	return e.path + ": " + e.err.Error()
+}
+
+// splitFormKey splits items[0][name] and items.0.name into [items 0 name], tags[] ends with ""
+func splitFormKey(key string) []string {
+	var parts []string
+	for len(key) > 0 {
+		switch key[0] {
+		case '[':
+			end := strings.IndexByte(key, ']')
+			if end < 0 {
+				return append(parts, key[1:])
+			}
+			parts = append(parts, key[1:end])
+			key = key[end+1:]
+		case '.':
+			key = key[1:]
+		default:
+			end := strings.IndexAny(key, "[.")
+			if end < 0 {
+				end = len(key)
+			}
+			parts = append(parts, key[:end])
+			key = key[end:]
+		}
+	}
+	return parts
+}
+
+// setFormPath walks v along keys, allocating pointers, slices and maps, and calls set on the target.
+// path accumulates the error path in DefaultFieldPath format
+func setFormPath(v reflect.Value, keys []string, path, tag string, set func(reflect.Value) error) error {
+	for v.Kind() == reflect.Ptr && v.Type() != fileHeaderType {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if len(keys) == 0 {
+		if err := set(v); err != nil {
+			return &formFieldError{path: path, err: err}
+		}
+		return nil
+	}
+
+	key, rest := keys[0], keys[1:]
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return nil
+		}
+		field, ok := formField(v.Type(), key, tag)
+		if !ok {
+			return nil
+		}
+		name := tagName(field, tag)
+		if path != "" {
+			name = path + "." + name
+		}
+		return setFormPath(v.FieldByIndex(field.Index), rest, name, tag, set)
+
+	case reflect.Slice:
+		// tags[] appends the values, scalars are set by setFormValues
+		if key == "" {
+			return setFormPath(v, rest, path, tag, set)
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= MaxFormIndex {
+			return nil
+		}
+		if i >= v.Len() {
+			grown := reflect.MakeSlice(v.Type(), i+1, i+1)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+		return setFormPath(v.Index(i), rest, path+"["+key+"]", tag, set)
+
+	case reflect.Array:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= v.Len() {
+			return nil
+		}
+		return setFormPath(v.Index(i), rest, path+"["+key+"]", tag, set)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		mapKey := reflect.ValueOf(key).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(mapKey); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setFormPath(elem, rest, path+"["+key+"]", tag, set); err != nil {
+			return err
+		}
+		v.SetMapIndex(mapKey, elem)
+		return nil
+	}
+	return nil
+}
+
+// formField finds exported field of t named key by tag, json tag or Go name. Fields tagged "-"
+// are never bound and the Go name only matches fields without a name in either tag
+func formField(t reflect.Type, key, tag string) (reflect.StructField, bool) {
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		if name, ok := formName(field, tag); ok && name == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// formName returns form key of field, false when its source or json tag is "-"
+func formName(field reflect.StructField, source string) (string, bool) {
+	for _, tag := range []string{source, "json"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			return name, true
+		}
+	}
+	return field.Name, true
+}
+
+// setFormValues converts values into field, slices of scalars take every value, others the last
+func setFormValues(field reflect.Value, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 && field.Type() != fileHeaderType {
+		for _, value := range values {
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setFormScalar(elem, value); err != nil {
+				return err
+			}
+			field.Set(reflect.Append(field, elem))
+		}
+		return nil
+	}
+	return setFormScalar(field, values[len(values)-1])
+}
+
+// setFormScalar converts raw into field, empty values leave field unset
+func setFormScalar(field reflect.Value, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		value := reflect.New(field.Type().Elem())
+		if err := setFormScalar(value.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	if field.Type() == timeType {
+		// datetime-local inputs send minutes without seconds
+		for _, layout := range []string{time.RFC3339, time.DateTime, "2006-01-02T15:04", time.DateOnly} {
+			if t, err := time.Parse(layout, raw); err == nil {
+				field.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q", raw)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		// Checkboxes send "on" when checked
+		if raw == "on" {
+			raw = "true"
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrFrozen is returned by registration APIs after Freeze
+var ErrFrozen = errors.New("validator : configuration is frozen")
+
+var frozen atomic.Bool
+
+// Freeze locks configuration, later Init, AddCustomValidation, RegisterAsyncValidation, NewEnum,
+// RegisterMessage and RegisterSemantic calls return ErrFrozen instead of racing validations on the
+// shared validator instance. App.Run freezes before serving when Config.FreezeValidator is set
+func Freeze() {
+	frozen.Store(true)
+}
+
+// Frozen reports whether Freeze was called
+func Frozen() bool {
+	return frozen.Load()
+}
+
+// checkFrozen returns ErrFrozen naming registration of what when configuration is locked
+func checkFrozen(what string) error {
+	if !frozen.Load() {
+		return nil
+	}
+	return fmt.Errorf("%w: cannot register %s%s", ErrFrozen, what, frozenDetail())
+}
@@ -0,0 +1,38 @@
+//go:build validatorcheck
+
+package validator
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+var (
+	startOnce sync.Once
+	startedAt string
+)
+
+// started freezes configuration on the first validation, so registrations that would race with
+// serving fail in tests and staging instead of corrupting the validator
+func started() {
+	if frozen.Load() {
+		return
+	}
+	// Caller of the validation function, e.g. the handler
+	_, file, line, ok := runtime.Caller(2)
+	startOnce.Do(func() {
+		if ok {
+			startedAt = fmt.Sprintf("%s:%d", file, line)
+		}
+		Freeze()
+	})
+}
+
+// frozenDetail locates the validation that froze configuration
+func frozenDetail() string {
+	if startedAt == "" {
+		return ""
+	}
+	return ", validation started at " + startedAt
+}
@@ -0,0 +1,10 @@
+//go:build !validatorcheck
+
+package validator
+
+// started is a no-op, build with -tags validatorcheck to freeze configuration on the first validation
+func started() {}
+
+func frozenDetail() string {
+	return ""
+}
@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// fieldStructs caches single field struct types used by FieldError
+var fieldStructs sync.Map
+
+// FieldError returns error of value failing tag for field named by its source tag, used by code generated
+// with `nest gen validators` on the failure path so messages match reflection based validation
+func FieldError(field string, value interface{}, tag string) ValidatorError {
+	if err := ValidateField(field, value, tag); err != nil {
+		return *err
+	}
+	tagName, param, _ := strings.Cut(tag, "=")
+	return ValidatorError{FailedField: field, Tag: tagName, Message: field + " is invalid", Param: param}
+}
+
+// ValidateField validates value against tag as struct field named field, nil when valid
+func ValidateField(field string, value interface{}, tag string) *ValidatorError {
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		return nil
+	}
+
+	key := typ.String() + "\x00" + field + "\x00" + tag
+	cached, ok := fieldStructs.Load(key)
+	if !ok {
+		cached, _ = fieldStructs.LoadOrStore(key, reflect.StructOf([]reflect.StructField{{
+			Name: "Value",
+			Type: typ,
+			Tag:  reflect.StructTag(`json:"` + field + `" validate:"` + strings.ReplaceAll(tag, `"`, `\"`) + `"`),
+		}}))
+	}
+
+	started()
+	holder := reflect.New(cached.(reflect.Type))
+	holder.Elem().Field(0).Set(reflect.ValueOf(value))
+	errs, ok := validate.Struct(holder.Interface()).(validator.ValidationErrors)
+	if !ok || len(errs) == 0 {
+		return nil
+	}
+	return &ValidatorError{
+		FailedField: field,
+		Tag:         errs[0].Tag(),
+		Message:     messageOf(nil, errs[0]),
+		Param:       errs[0].Param(),
+	}
+}
@@ -58,28 +58,15 @@ func GetFieldTag(data interface{}, fieldName string, sourceTag string) string {
 		return fieldName
 	}
 
-	tagValue := field.Tag.Get(sourceTag)
-	if tagValue == "" || tagValue == "-" {
-		// Fallback to json tag if source tag not found
-		if sourceTag != "json" {
-			jsonTag := field.Tag.Get("json")
-			if jsonTag != "" && jsonTag != "-" {
-				return strings.Split(jsonTag, ",")[0]
-			}
-		}
-		return fieldName
-	}
-
-	// Return first part of tag (before comma)
-	return strings.Split(tagValue, ",")[0]
+	return tagName(field, sourceTag)
 }
 
 // Init initializes validator with custom translators
 func Init(translators ...Translator) error {
-	// Re-register default translations
-	if err := en_translations.RegisterDefaultTranslations(validate, trans); err != nil {
-		return fmt.Errorf("failed to register default translations: %w", err)
+	if err := checkFrozen("translators"); err != nil {
+		return err
 	}
+	// Default translations are registered on package load
 
 	// Register custom translations
 	for _, item := range translators {
@@ -88,8 +75,7 @@ func Init(translators ...Translator) error {
 				return ut.Add(item.Tag, item.Message, true)
 			},
 			func(ut ut.Translator, fe validator.FieldError) string {
-				t, _ := ut.T(item.Tag, fe.Field())
-				return t
+				return translateParams(ut, item.Tag, fe)
 			})
 		if err != nil {
 			return fmt.Errorf("failed to register translation for tag %s: %w", item.Tag, err)
@@ -99,23 +85,60 @@ func Init(translators ...Translator) error {
 	return nil
 }
 
+// translateParams renders {0} as field and {1} as tag parameter, oneof values are comma separated
+func translateParams(ut ut.Translator, tag string, fe validator.FieldError) string {
+	param := fe.Param()
+	// Enums registered with NewEnum alias oneof
+	if fe.ActualTag() == "oneof" {
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+
+	t, err := ut.T(tag, fe.Field(), param)
+	if err != nil {
+		return fe.Error()
+	}
+	return t
+}
+
 // Validate validates a struct and returns validation errors
 func Validate(data interface{}, source string) []ValidatorError {
+	return ValidateCtx(context.Background(), data, source)
+}
+
+// ValidateCtx validates a struct with context, fields set by SkipFields are not validated.
+// Async rules are collected while validating and checked concurrently only when all other rules passed
+func ValidateCtx(ctx context.Context, data interface{}, source string) []ValidatorError {
 	if data == nil {
 		return []ValidatorError{}
 	}
+	started()
 
-	validationErrors := []ValidatorError{}
-	errs := validate.Struct(data)
+	var run *asyncRun
+	if hasAsyncRules() {
+		run = &asyncRun{checks: map[asyncKey]*asyncCheck{}}
+		ctx = context.WithValue(ctx, asyncRunKey{}, run)
+	}
 
+	var errs error
+	if skip := SkippedFields(ctx); len(skip) > 0 {
+		errs = validate.StructExceptCtx(ctx, data, skip...)
+	} else {
+		errs = validate.StructCtx(ctx, data)
+	}
+
+	validationErrors := []ValidatorError{}
 	if errs != nil {
 		// Type assertion with safety check
 		if validationErrs, ok := errs.(validator.ValidationErrors); ok {
+			if run != nil && len(run.checks) > 0 {
+				validationErrs = run.apply(ctx, validationErrs)
+			}
 			for _, err := range validationErrs {
 				elem := ValidatorError{
-					FailedField: GetFieldTag(data, err.Field(), source),
+					FailedField: pathResolver.ResolvePath(data, err, source),
 					Tag:         err.Tag(),
-					Message:     err.Translate(trans),
+					Message:     run.message(data, err),
+					Param:       err.Param(),
 				}
 				validationErrors = append(validationErrors, elem)
 			}
@@ -143,7 +166,7 @@ func ValidateWithContext(ctx context.Context, data interface{}, source string, t
 
 	// Run validation in goroutine
 	go func() {
-		result := Validate(data, source)
+		result := ValidateCtx(ctx, data, source)
 		select {
 		case resultChan <- result:
 		case <-ctx.Done():
@@ -169,6 +192,7 @@ func SliceValidate(data interface{}, source string) []ValidatorError {
 		return []ValidatorError{}
 	}
 
+	started()
 	validationErrors := []ValidatorError{}
 	v := reflect.ValueOf(data)
 
@@ -194,20 +218,62 @@ func SliceValidate(data interface{}, source string) []ValidatorError {
 			continue
 		}
 
-		elemData := elem.Interface()
-		errs := validate.Struct(elemData)
-
-		if errs != nil {
-			if validationErrs, ok := errs.(validator.ValidationErrors); ok {
-				for _, err := range validationErrs {
-					validationError := ValidatorError{
-						FailedField: fmt.Sprintf("[%d].%s", i, GetFieldTag(elemData, err.Field(), source)),
-						Tag:         err.Tag(),
-						Message:     fmt.Sprintf("Index %d: %s", i, err.Translate(trans)),
-						Index:       &i,
-					}
-					validationErrors = append(validationErrors, validationError)
+		validationErrors = append(validationErrors, validateElement(elem.Interface(), i, source)...)
+	}
+
+	return validationErrors
+}
+
+// SliceValidateWithOrigin validates a slice of structs parsed from CSV/XLSX, setting Row and Column of errors
+func SliceValidateWithOrigin(data interface{}, source string, origin Origin) []ValidatorError {
+	if origin.FirstRow <= 0 {
+		origin.FirstRow = 2
+	}
+
+	validationErrors := SliceValidate(data, source)
+	for i, err := range validationErrors {
+		if err.Index == nil {
+			continue
+		}
+
+		row := origin.FirstRow + *err.Index
+		if *err.Index < len(origin.Rows) {
+			row = origin.Rows[*err.Index]
+		}
+
+		// Strip "[i]." prefix so paths match Columns keys
+		field := err.FailedField
+		if _, after, ok := strings.Cut(field, "]."); ok {
+			field = after
+		}
+		column := field
+		if mapped, ok := origin.Columns[field]; ok {
+			column = mapped
+		}
+
+		validationErrors[i].Row = &row
+		validationErrors[i].Column = column
+	}
+
+	return validationErrors
+}
+
+// validateElement validates slice element with errors prefixed by its index
+func validateElement(elemData interface{}, i int, source string) []ValidatorError {
+	validationErrors := []ValidatorError{}
+	errs := validate.Struct(elemData)
+
+	if errs != nil {
+		if validationErrs, ok := errs.(validator.ValidationErrors); ok {
+			for _, err := range validationErrs {
+				validationError := ValidatorError{
+					FailedField: fmt.Sprintf("[%d].%s", i, pathResolver.ResolvePath(elemData, err, source)),
+					Tag:         err.Tag(),
+					Message:     fmt.Sprintf("Index %d: %s", i, messageOf(elemData, err)),
+					Param:       err.Param(),
+					Index:       &i,
 				}
+				validationErrors = append(validationErrors, validationError)
 			}
 		}
 	}
@@ -217,6 +283,7 @@ func SliceValidate(data interface{}, source string) []ValidatorError {
 
 // ValidateVar validates a single variable with validation tags
 func ValidateVar(field interface{}, tag string, fieldName string) []ValidatorError {
+	started()
 	validationErrors := []ValidatorError{}
 	err := validate.Var(field, tag)
 
@@ -226,7 +293,8 @@ func ValidateVar(field interface{}, tag string, fieldName string) []ValidatorErr
 				elem := ValidatorError{
 					FailedField: fieldName,
 					Tag:         verr.Tag(),
-					Message:     verr.Translate(trans),
+					Message:     messageOf(nil, verr),
+					Param:       verr.Param(),
 				}
 				validationErrors = append(validationErrors, elem)
 			}
@@ -238,6 +306,9 @@ func ValidateVar(field interface{}, tag string, fieldName string) []ValidatorErr
 
 // AddCustomValidation adds custom validation rule
 func AddCustomValidation(tag string, fn validator.Func, message string) error {
+	if err := checkFrozen(tag); err != nil {
+		return err
+	}
 	// Register validation function
 	err := validate.RegisterValidation(tag, fn)
 	if err != nil {
@@ -250,8 +321,7 @@ func AddCustomValidation(tag string, fn validator.Func, message string) error {
 			return ut.Add(tag, message, true)
 		},
 		func(ut ut.Translator, fe validator.FieldError) string {
-			t, _ := ut.T(tag, fe.Field())
-			return t
+			return translateParams(ut, tag, fe)
 		})
 	if err != nil {
 		return fmt.Errorf("failed to register validation translation: %w", err)
@@ -282,7 +352,11 @@ func NewValidators(ctx *fiber.Ctx, data interface{}) *Validators {
 
 // ValidateStruct is a convenience method for Validators struct
 func (v *Validators) ValidateStruct(source string) {
-	errors := Validate(v.Data, source)
+	ctx := context.Background()
+	if v.Ctx != nil {
+		ctx = v.Ctx.UserContext()
+	}
+	errors := ValidateCtx(ctx, v.Data, source)
 	v.ValidationsErr = append(v.ValidationsErr, errors...)
 	if len(errors) > 0 {
 		v.Error = true
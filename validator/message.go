@@ -0,0 +1,165 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rikiihsan/nest/secure"
+)
+
+var (
+	messagesMu sync.RWMutex
+	messages   = map[string]string{}
+)
+
+// RegisterMessage sets message template of tag, replacing its translation. Templates may use
+// {field}, {tag}, {param}, {value} and {.Sibling} for values of fields next to the failed one, e.g.
+// "{field} must be after {.StartDate} (got {value})". Values of fields tagged secret:"true" render as secure.Redacted,
+// as do values validated without their struct, e.g. by ValidateField and ValidateVar.
+// Returns ErrFrozen after Freeze
+func RegisterMessage(tag, template string) error {
+	if err := checkFrozen("message of " + tag); err != nil {
+		return err
+	}
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	messages[tag] = template
+	return nil
+}
+
+// messageOf renders registered template of fe against data, falling back to the translation
+func messageOf(data interface{}, fe validator.FieldError) string {
+	messagesMu.RLock()
+	template, ok := messages[fe.Tag()]
+	messagesMu.RUnlock()
+	if !ok {
+		return fe.Translate(trans)
+	}
+	return renderMessage(template, data, fe)
+}
+
+// renderMessage replaces placeholders of template, unknown placeholders are kept
+func renderMessage(template string, data interface{}, fe validator.FieldError) string {
+	parent, field, found := locateField(data, fe)
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(template[:start])
+		name := template[start+1 : start+end]
+		template = template[start+end+1:]
+
+		switch {
+		case name == "field":
+			b.WriteString(fe.Field())
+		case name == "tag":
+			b.WriteString(fe.Tag())
+		case name == "param":
+			b.WriteString(fe.Param())
+		case name == "value":
+			// fields that can't be located, e.g. of ValidateField and ValidateVar, may be secret
+			if !found || secret(field) {
+				b.WriteString(secure.Redacted)
+			} else {
+				b.WriteString(formatValue(fe.Value()))
+			}
+		case strings.HasPrefix(name, ".") && found:
+			b.WriteString(siblingValue(parent, name[1:]))
+		default:
+			b.WriteString("{" + name + "}")
+		}
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+// locateField walks data along the struct namespace of fe, returning the struct holding the failed field
+func locateField(data interface{}, fe validator.FieldError) (reflect.Value, reflect.StructField, bool) {
+	v := reflect.ValueOf(data)
+	segments := splitNamespace(fe.StructNamespace())
+	// First segment is the root struct type name
+	if len(segments) > 1 && !strings.HasPrefix(segments[0].name, "[") {
+		segments = segments[1:]
+	}
+
+	for i, seg := range segments {
+		v = indirect(v)
+		if v.Kind() != reflect.Struct {
+			break
+		}
+		field, ok := v.Type().FieldByName(seg.name)
+		if !ok {
+			break
+		}
+		if i == len(segments)-1 {
+			return v, field, true
+		}
+
+		v = v.FieldByIndex(field.Index)
+		for _, key := range seg.keys {
+			v = indirect(v)
+			switch v.Kind() {
+			case reflect.Slice, reflect.Array:
+				n, err := strconv.Atoi(key)
+				if err != nil || n < 0 || n >= v.Len() {
+					return reflect.Value{}, reflect.StructField{}, false
+				}
+				v = v.Index(n)
+			case reflect.Map:
+				if v.Type().Key().Kind() != reflect.String {
+					return reflect.Value{}, reflect.StructField{}, false
+				}
+				v = v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			default:
+				return reflect.Value{}, reflect.StructField{}, false
+			}
+		}
+	}
+	return reflect.Value{}, reflect.StructField{}, false
+}
+
+// siblingValue formats field of parent named by Go name or json tag
+func siblingValue(parent reflect.Value, name string) string {
+	for _, field := range reflect.VisibleFields(parent.Type()) {
+		if !field.IsExported() || (field.Name != name && tagName(field, "json") != name) {
+			continue
+		}
+		if secret(field) {
+			return secure.Redacted
+		}
+		return formatValue(parent.FieldByIndex(field.Index).Interface())
+	}
+	return "{." + name + "}"
+}
+
+// secret reports whether field is tagged secret:"true"
+func secret(field reflect.StructField) bool {
+	s, _ := strconv.ParseBool(field.Tag.Get("secret"))
+	return s
+}
+
+func formatValue(value interface{}) string {
+	v := indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldPathResolver formats failed field path of validation errors, e.g. settings[theme] or items[0].name
+type FieldPathResolver interface {
+	ResolvePath(data interface{}, fe validator.FieldError, source string) string
+}
+
+// FieldPathResolverFunc adapts function to FieldPathResolver
+type FieldPathResolverFunc func(data interface{}, fe validator.FieldError, source string) string
+
+// ResolvePath calls f
+func (f FieldPathResolverFunc) ResolvePath(data interface{}, fe validator.FieldError, source string) string {
+	return f(data, fe, source)
+}
+
+var pathResolver FieldPathResolver = FieldPathResolverFunc(DefaultFieldPath)
+
+// SetFieldPathResolver replaces field path resolver, nil restores DefaultFieldPath
+func SetFieldPathResolver(resolver FieldPathResolver) {
+	if resolver == nil {
+		resolver = FieldPathResolverFunc(DefaultFieldPath)
+	}
+	pathResolver = resolver
+}
+
+// DefaultFieldPath maps struct namespace of error to source tag names, keeping slice indexes and map keys
+func DefaultFieldPath(data interface{}, fe validator.FieldError, source string) string {
+	namespace := fe.StructNamespace()
+	t := reflect.TypeOf(data)
+	if namespace == "" || t == nil {
+		return GetFieldTag(data, fe.Field(), source)
+	}
+
+	segments := splitNamespace(namespace)
+	// First segment is the root struct type name
+	if len(segments) > 1 && !strings.HasPrefix(segments[0].name, "[") {
+		segments = segments[1:]
+	}
+
+	var b strings.Builder
+	for i, seg := range segments {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		name := seg.name
+		if t != nil && t.Kind() == reflect.Struct && name != "" {
+			if field, ok := t.FieldByName(name); ok {
+				name = tagName(field, source)
+				t = field.Type
+			} else {
+				t = nil
+			}
+		}
+
+		if i > 0 && name != "" {
+			b.WriteByte('.')
+		}
+		b.WriteString(name)
+
+		for _, key := range seg.keys {
+			b.WriteString("[" + key + "]")
+			for t != nil && t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+				t = t.Elem()
+			} else {
+				t = nil
+			}
+		}
+	}
+	return b.String()
+}
+
+// namespaceSegment is field name followed by its index or key brackets
+type namespaceSegment struct {
+	name string
+	keys []string
+}
+
+// splitNamespace splits Root.Field[key].Sub keeping dots inside brackets
+func splitNamespace(namespace string) []namespaceSegment {
+	var segments []namespaceSegment
+	var name strings.Builder
+	var keys []string
+
+	for i := 0; i < len(namespace); i++ {
+		switch namespace[i] {
+		case '.':
+			segments = append(segments, namespaceSegment{name: name.String(), keys: keys})
+			name.Reset()
+			keys = nil
+		case '[':
+			end := strings.IndexByte(namespace[i:], ']')
+			if end < 0 {
+				name.WriteString(namespace[i:])
+				i = len(namespace)
+				continue
+			}
+			keys = append(keys, namespace[i+1:i+end])
+			i += end
+		default:
+			name.WriteByte(namespace[i])
+		}
+	}
+	return append(segments, namespaceSegment{name: name.String(), keys: keys})
+}
+
+// tagName returns field name from source tag, falling back to json tag then Go name
+func tagName(field reflect.StructField, source string) string {
+	for _, tag := range []string{source, "json"} {
+		if value := strings.Split(field.Tag.Get(tag), ",")[0]; value != "" && value != "-" {
+			return value
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Validation phases of Validators.Run
+const (
+	PhaseSyntax   = "syntax"
+	PhaseSemantic = "semantic"
+)
+
+// SemanticFunc checks business rules, e.g. uniqueness with database access, adding failures with
+// Validators.AddError. Returned errors abort Run, use them for failures of the check itself
+type SemanticFunc func(ctx context.Context, v *Validators) error
+
+var (
+	semanticMu    sync.RWMutex
+	semanticRules = map[reflect.Type][]SemanticFunc{}
+)
+
+// RegisterSemantic registers fn run by Validators.Run for data of type T or *T, ErrFrozen after Freeze
+func RegisterSemantic[T any](fn func(ctx context.Context, data *T, v *Validators) error) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if err := checkFrozen("semantic rule of " + typ.String()); err != nil {
+		return err
+	}
+	semanticMu.Lock()
+	defer semanticMu.Unlock()
+	semanticRules[typ] = append(semanticRules[typ], func(ctx context.Context, v *Validators) error {
+		switch data := v.Data.(type) {
+		case *T:
+			return fn(ctx, data, v)
+		case T:
+			return fn(ctx, &data, v)
+		}
+		return nil
+	})
+	return nil
+}
+
+// registeredSemantic returns rules registered for type of data
+func registeredSemantic(data interface{}) []SemanticFunc {
+	typ := reflect.TypeOf(data)
+	if typ == nil {
+		return nil
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	semanticMu.RLock()
+	defer semanticMu.RUnlock()
+	return append([]SemanticFunc(nil), semanticRules[typ]...)
+}
+
+// Semantic adds rules run by Run after the rules registered for the data type
+func (v *Validators) Semantic(fns ...SemanticFunc) *Validators {
+	v.semantic = append(v.semantic, fns...)
+	return v
+}
+
+// Run validates struct tags, then semantic rules only when the syntax phase passed.
+// Errors of both phases are collected with their Phase, failures are returned as Errors
+func (v *Validators) Run(source string) error {
+	ctx := context.Background()
+	if v.Ctx != nil {
+		ctx = v.Ctx.UserContext()
+	}
+
+	v.phase = PhaseSyntax
+	errs := ValidateCtx(ctx, v.Data, source)
+	for i := range errs {
+		errs[i].Phase = PhaseSyntax
+	}
+	v.ValidationsErr = append(v.ValidationsErr, errs...)
+	if len(errs) > 0 {
+		v.Error = true
+	}
+	if v.HasErrors() {
+		return v.Err()
+	}
+
+	v.phase = PhaseSemantic
+	defer func() { v.phase = "" }()
+	for _, fn := range append(registeredSemantic(v.Data), v.semantic...) {
+		if err := fn(ctx, v); err != nil {
+			return err
+		}
+	}
+	return v.Err()
+}
+
+// ErrorsByPhase returns errors of phase
+func (v *Validators) ErrorsByPhase(phase string) []ValidatorError {
+	var errors []ValidatorError
+	for _, err := range v.ValidationsErr {
+		if err.Phase == phase {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
@@ -0,0 +1,22 @@
+package validator
+
+import "context"
+
+type skipKey struct{}
+
+// SkipFields returns context disabling validation of fields, e.g. "Password" or "Profile.Secret".
+// Names are Go field names relative to the validated struct, added to fields skipped by parent context
+func SkipFields(ctx context.Context, fields ...string) context.Context {
+	skip := append(SkippedFields(ctx), fields...)
+	return context.WithValue(ctx, skipKey{}, skip)
+}
+
+// SkippedFields returns fields skipped by ctx
+func SkippedFields(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	skip, _ := ctx.Value(skipKey{}).([]string)
+	// Copy so appends in SkipFields never share backing array
+	return append([]string(nil), skip...)
+}
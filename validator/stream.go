@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StreamResult represents validation result of one decoded array element
+type StreamResult struct {
+	Index  int
+	Elem   interface{}
+	Errors []ValidatorError
+	// Err is set when the stream could not be decoded, no further results follow
+	Err error
+}
+
+// ValidateJSONStream decodes JSON array from r element by element and calls fn with each result,
+// so large bodies are validated without loading them into memory. Returning error from fn stops decoding.
+// Returns number of decoded elements
+func ValidateJSONStream(r io.Reader, newElem func() interface{}, source string, fn func(StreamResult) error) (int, error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read json array: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return 0, fmt.Errorf("expected json array, got %v", token)
+	}
+
+	count := 0
+	for decoder.More() {
+		elem := newElem()
+		if err := decoder.Decode(elem); err != nil {
+			return count, fmt.Errorf("failed to decode element %d: %w", count, err)
+		}
+
+		result := StreamResult{Index: count, Elem: elem, Errors: validateElement(elem, count, source)}
+		count++
+		if err := fn(result); err != nil {
+			return count, err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return count, fmt.Errorf("failed to read end of json array: %w", err)
+	}
+	return count, nil
+}
+
+// ValidateJSONStreamChan is ValidateJSONStream emitting results on channel closed when done or ctx is cancelled
+func ValidateJSONStreamChan(ctx context.Context, r io.Reader, newElem func() interface{}, source string) <-chan StreamResult {
+	results := make(chan StreamResult)
+
+	go func() {
+		defer close(results)
+
+		_, err := ValidateJSONStream(r, newElem, source, func(result StreamResult) error {
+			select {
+			case results <- result:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			select {
+			case results <- StreamResult{Index: -1, Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return results
+}
+
+// RequestBodyReader returns streamed request body when fiber StreamRequestBody is enabled, otherwise buffered body
+func RequestBodyReader(c *fiber.Ctx) io.Reader {
+	if stream := c.Context().RequestBodyStream(); stream != nil {
+		return stream
+	}
+	return bytes.NewReader(c.Body())
+}
@@ -0,0 +1,122 @@
+package testkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rikiihsan/nest/i18n"
+	"github.com/rikiihsan/nest/validator"
+)
+
+// UpdateEnv regenerates golden files when set to 1
+const UpdateEnv = "NEST_UPDATE_GOLDEN"
+
+// GoldenDir is directory of golden files relative to the test package
+var GoldenDir = "testdata"
+
+// Case represents table-driven validation case, empty Field means model must be valid
+type Case struct {
+	Name  string
+	Model interface{}
+	Field string
+	Tag   string
+	// Source is struct tag of error fields, json when empty
+	Source string
+}
+
+// Run runs cases as subtests
+func Run(t *testing.T, cases []Case) {
+	t.Helper()
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if c.Field == "" {
+				AssertValid(t, c.Model, c.Source)
+				return
+			}
+			assertInvalid(t, c.Model, c.Field, c.Tag, c.Source)
+		})
+	}
+}
+
+// AssertValid fails test when model has validation errors
+func AssertValid(t testing.TB, model interface{}, source ...string) {
+	t.Helper()
+	if errs := validate(model, first(source)); len(errs) > 0 {
+		t.Errorf("expected valid model, got errors:\n%s", render(errs))
+	}
+}
+
+// AssertInvalid fails test unless field fails tag, field is error path like items[0].name
+func AssertInvalid(t testing.TB, model interface{}, field, tag string) {
+	t.Helper()
+	assertInvalid(t, model, field, tag, "")
+}
+
+func assertInvalid(t testing.TB, model interface{}, field, tag, source string) {
+	t.Helper()
+	errs := validate(model, first([]string{source}))
+	for _, err := range errs {
+		if err.FailedField == field && (tag == "" || err.Tag == tag) {
+			return
+		}
+	}
+	if len(errs) == 0 {
+		t.Errorf("expected %s to fail %q, model is valid", field, tag)
+		return
+	}
+	t.Errorf("expected %s to fail %q, got errors:\n%s", field, tag, render(errs))
+}
+
+// AssertGolden compares messages rendered in locale with testdata/<name>.<locale>.golden,
+// run tests with NEST_UPDATE_GOLDEN=1 to write the files
+func AssertGolden(t testing.TB, name, locale string, model interface{}) {
+	t.Helper()
+
+	got := render(i18n.TranslateErrors(locale, validate(model, "json")))
+	path := filepath.Join(GoldenDir, name+"."+locale+".golden")
+
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with %s=1 to create it: %v", UpdateEnv, err)
+	}
+	if got != string(want) {
+		t.Errorf("messages of %s differ from %s\ngot:\n%s\nwant:\n%s", name, path, got, want)
+	}
+}
+
+// validate validates slices element by element and structs directly
+func validate(model interface{}, source string) []validator.ValidatorError {
+	if errs := validator.SliceValidate(model, source); len(errs) != 1 || errs[0].Tag != "slice" {
+		return errs
+	}
+	return validator.Validate(model, source)
+}
+
+// render formats errors one per line as field, tag and message
+func render(errs []validator.ValidatorError) string {
+	var b strings.Builder
+	for _, err := range errs {
+		fmt.Fprintf(&b, "%s\t%s\t%s\n", err.FailedField, err.Tag, err.Message)
+	}
+	return b.String()
+}
+
+func first(source []string) string {
+	if len(source) > 0 && source[0] != "" {
+		return source[0]
+	}
+	return "json"
+}
@@ -0,0 +1,103 @@
+package testkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type user struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
+}
+
+// recorder captures failures reported by assertions instead of failing the test
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestRun(t *testing.T) {
+	Run(t, []Case{
+		{Name: "valid", Model: user{Name: "Ann", Email: "ann@example.com"}},
+		{Name: "missing name", Model: user{Email: "ann@example.com"}, Field: "name", Tag: "required"},
+		{Name: "bad email", Model: user{Name: "Ann", Email: "ann"}, Field: "email", Tag: "email"},
+		{Name: "slice element", Model: []user{{Name: "Ann", Email: "ann@example.com"}, {Email: "bob@example.com"}}, Field: "[1].name", Tag: "required"},
+	})
+}
+
+func TestAssertValidFails(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertValid(r, user{Email: "ann"})
+
+	if len(r.failures) != 1 {
+		t.Fatalf("expected one failure, got %d", len(r.failures))
+	}
+	if !strings.Contains(r.failures[0], "name\trequired\t") || !strings.Contains(r.failures[0], "email\temail\t") {
+		t.Errorf("failure does not list errors:\n%s", r.failures[0])
+	}
+}
+
+func TestAssertInvalidFails(t *testing.T) {
+	r := &recorder{TB: t}
+	AssertInvalid(r, user{Name: "Ann", Email: "ann@example.com"}, "name", "required")
+	AssertInvalid(r, user{Email: "ann@example.com"}, "name", "min")
+	AssertInvalid(r, user{Email: "ann@example.com"}, "name", "")
+
+	if len(r.failures) != 2 {
+		t.Fatalf("expected two failures, got %q", r.failures)
+	}
+	if !strings.Contains(r.failures[0], "model is valid") {
+		t.Errorf("unexpected failure for valid model: %s", r.failures[0])
+	}
+	if !strings.Contains(r.failures[1], "name\trequired\t") {
+		t.Errorf("unexpected failure for other tag: %s", r.failures[1])
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	dir := GoldenDir
+	GoldenDir = t.TempDir()
+	t.Cleanup(func() { GoldenDir = dir })
+
+	model := user{Email: "ann"}
+
+	t.Setenv(UpdateEnv, "1")
+	AssertGolden(t, "user", "en", model)
+
+	path := filepath.Join(GoldenDir, "user.en.golden")
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+	if !strings.HasPrefix(string(written), "name\trequired\t") {
+		t.Errorf("unexpected golden file:\n%s", written)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	AssertGolden(t, "user", "en", model)
+
+	r := &recorder{TB: t}
+	AssertGolden(r, "user", "en", user{Name: "Ann", Email: "ann"})
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "differ from") {
+		t.Errorf("expected mismatch failure, got %q", r.failures)
+	}
+
+	r = &recorder{TB: t}
+	AssertGolden(r, "missing", "en", model)
+	if len(r.failures) == 0 || !strings.Contains(r.failures[0], UpdateEnv) {
+		t.Errorf("expected missing file failure, got %q", r.failures)
+	}
+}
@@ -0,0 +1,37 @@
+package views
+
+import (
+	"html/template"
+	"io/fs"
+)
+
+// Config represents view engine configuration
+type Config struct {
+	// FS holds templates, defaults to os.DirFS(Dir), use embed.FS for single binary builds
+	FS fs.FS
+	// Dir is template root when FS is nil
+	Dir string
+	// Extension of template files, names passed to Render omit it
+	Extension string
+	// Layout wraps pages unless Render is given another one, e.g. "layouts/main".
+	// Layouts include the page with {{ template "content" . }}, an empty layout renders pages alone
+	Layout string
+	// Partials directory is parsed into every page, partials are included by path, e.g. {{ template "partials/nav" . }}.
+	// A missing directory is ignored
+	Partials string
+	// Reload reparses templates and rehashes assets on every render, for development
+	Reload bool
+	// Funcs are added to the built-in functions, overriding them on name clash
+	Funcs template.FuncMap
+	// Assets holds static files hashed by the asset function, AssetsURL is where they are served
+	Assets    fs.FS
+	AssetsURL string
+}
+
+// DefaultConfig is used for empty fields passed to New
+var DefaultConfig = Config{
+	Dir:       "views",
+	Extension: ".html",
+	Partials:  "partials",
+	AssetsURL: "/assets",
+}
@@ -0,0 +1,107 @@
+package views
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rikiihsan/nest/i18n"
+	"github.com/rikiihsan/nest/security"
+)
+
+// builtinFuncs returns functions of every template, request functions are placeholders
+// replaced by requestFuncs when rendering through RenderCtx
+func (e *Engine) builtinFuncs() template.FuncMap {
+	return template.FuncMap{
+		"asset": e.asset,
+		"dict":  dict,
+		"t": func(key string, pairs ...interface{}) string {
+			return i18n.T(i18n.GetConfig().DefaultLocale, key, params(pairs))
+		},
+		"tp": func(key string, count int, pairs ...interface{}) string {
+			return i18n.Plural(i18n.GetConfig().DefaultLocale, key, count, params(pairs))
+		},
+		"locale":    func() string { return i18n.GetConfig().DefaultLocale },
+		"csrfToken": func() string { return "" },
+		"csrfField": func() template.HTML { return "" },
+		"nonce":     func() string { return "" },
+	}
+}
+
+// requestFuncs binds locale, CSRF and CSP nonce functions to request
+func requestFuncs(c *fiber.Ctx) template.FuncMap {
+	return template.FuncMap{
+		"t": func(key string, pairs ...interface{}) string {
+			return i18n.Translate(c, key, params(pairs))
+		},
+		"tp": func(key string, count int, pairs ...interface{}) string {
+			return i18n.TranslatePlural(c, key, count, params(pairs))
+		},
+		"locale":    func() string { return i18n.Locale(c) },
+		"csrfToken": func() string { return security.CSRFToken(c) },
+		"csrfField": func() template.HTML { return security.CSRFField(c) },
+		"nonce":     func() string { return security.CSPNonce(c) },
+	}
+}
+
+// asset returns URL of static file with content hash query for cache busting, e.g. /assets/app.css?v=1a2b3c4d5e
+func (e *Engine) asset(name string) (string, error) {
+	name = strings.TrimPrefix(name, "/")
+	url := strings.TrimSuffix(e.config.AssetsURL, "/") + "/" + name
+	if e.config.Assets == nil {
+		return url, nil
+	}
+
+	if !e.config.Reload {
+		e.mu.RLock()
+		hash, ok := e.assets[name]
+		e.mu.RUnlock()
+		if ok {
+			return url + "?v=" + hash, nil
+		}
+	}
+
+	data, err := fs.ReadFile(e.config.Assets, name)
+	if err != nil {
+		return "", fmt.Errorf("views : asset %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:5])
+
+	e.mu.Lock()
+	e.assets[name] = hash
+	e.mu.Unlock()
+	return url + "?v=" + hash, nil
+}
+
+// dict builds map from key value pairs, for passing several values to partials
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("views : dict expects key value pairs")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("views : dict key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// params converts key value pairs into translation params, odd trailing values are ignored
+func params(pairs []interface{}) i18n.Params {
+	p := i18n.Params{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if key, ok := pairs[i].(string); ok {
+			p[key] = pairs[i+1]
+		}
+	}
+	return p
+}
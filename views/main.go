@@ -0,0 +1,235 @@
+package views
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrNotInitialized = errors.New("views : engine is not initialized")
+
+// Engine renders html/template pages with layouts and partials, it implements fiber.Views
+type Engine struct {
+	config Config
+	funcs  template.FuncMap
+
+	mu     sync.RWMutex
+	cache  map[string]*template.Template
+	assets map[string]string
+}
+
+var engine *Engine
+
+// New creates engine, empty fields fall back to DefaultConfig
+func New(cfg Config) *Engine {
+	if cfg.FS == nil {
+		if cfg.Dir == "" {
+			cfg.Dir = DefaultConfig.Dir
+		}
+		cfg.FS = os.DirFS(cfg.Dir)
+	}
+	if cfg.Extension == "" {
+		cfg.Extension = DefaultConfig.Extension
+	}
+	if cfg.Partials == "" {
+		cfg.Partials = DefaultConfig.Partials
+	}
+	if cfg.AssetsURL == "" {
+		cfg.AssetsURL = DefaultConfig.AssetsURL
+	}
+
+	e := &Engine{
+		config: cfg,
+		cache:  make(map[string]*template.Template),
+		assets: make(map[string]string),
+	}
+	e.funcs = e.builtinFuncs()
+	for name, fn := range cfg.Funcs {
+		e.funcs[name] = fn
+	}
+	return e
+}
+
+// Init creates global engine used by Render
+func Init(cfg Config) *Engine {
+	engine = New(cfg)
+	return engine
+}
+
+// Get returns global engine
+func Get() *Engine {
+	return engine
+}
+
+// Load parses every page up front so template errors surface at startup, it is a no-op with Reload
+func (e *Engine) Load() error {
+	if e.config.Reload {
+		return nil
+	}
+	return fs.WalkDir(e.config.FS, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, e.config.Extension) {
+			return err
+		}
+		name := strings.TrimSuffix(file, e.config.Extension)
+		if e.isLayoutOrPartial(name) {
+			return nil
+		}
+		_, err = e.template(name, e.config.Layout)
+		return err
+	})
+}
+
+// Render renders page with layout into w, implementing fiber.Views. Request functions such as
+// csrfField render empty, use the package Render to bind them to the request
+func (e *Engine) Render(w io.Writer, name string, data interface{}, layout ...string) error {
+	t, err := e.clone(name, e.layout(layout))
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, data)
+}
+
+// RenderCtx renders page with functions bound to the request, e.g. locale, csrfField and nonce
+func (e *Engine) RenderCtx(c *fiber.Ctx, w io.Writer, name string, data interface{}, layout ...string) error {
+	t, err := e.clone(name, e.layout(layout))
+	if err != nil {
+		return err
+	}
+	return t.Funcs(requestFuncs(c)).Execute(w, data)
+}
+
+// clone returns copy of parsed set for execution, cached sets are never executed so they stay cloneable
+func (e *Engine) clone(name, layout string) (*template.Template, error) {
+	t, err := e.template(name, layout)
+	if err != nil {
+		return nil, err
+	}
+	return t.Clone()
+}
+
+// Render writes page rendered by the global engine as text/html
+func Render(c *fiber.Ctx, name string, data interface{}, layout ...string) error {
+	if engine == nil {
+		return ErrNotInitialized
+	}
+
+	var buf bytes.Buffer
+	if err := engine.RenderCtx(c, &buf, name, data, layout...); err != nil {
+		return err
+	}
+	c.Type("html", "utf-8")
+	return c.Send(buf.Bytes())
+}
+
+func (e *Engine) layout(layout []string) string {
+	if len(layout) > 0 {
+		return layout[0]
+	}
+	return e.config.Layout
+}
+
+func (e *Engine) isLayoutOrPartial(name string) bool {
+	if e.config.Layout != "" && path.Dir(name) == path.Dir(e.config.Layout) {
+		return true
+	}
+	return e.config.Partials != "" && strings.HasPrefix(name, e.config.Partials+"/")
+}
+
+// template returns parsed set of page within layout, cached unless Reload is set
+func (e *Engine) template(name, layout string) (*template.Template, error) {
+	key := layout + "|" + name
+	if !e.config.Reload {
+		e.mu.RLock()
+		t, ok := e.cache[key]
+		e.mu.RUnlock()
+		if ok {
+			return t, nil
+		}
+	}
+
+	t, err := e.parse(name, layout)
+	if err != nil {
+		return nil, err
+	}
+	if !e.config.Reload {
+		e.mu.Lock()
+		e.cache[key] = t
+		e.mu.Unlock()
+	}
+	return t, nil
+}
+
+// parse builds set rooted at layout, or at page without layout. The page body becomes "content"
+// so pages only need {{ define }} for extra blocks such as "title"
+func (e *Engine) parse(name, layout string) (*template.Template, error) {
+	page, err := e.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	root := name
+	if layout != "" {
+		root = layout
+	}
+	t := template.New(root).Funcs(e.funcs)
+
+	if layout != "" {
+		src, err := e.read(layout)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := t.Parse(src); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.parsePartials(t); err != nil {
+		return nil, err
+	}
+
+	target := t
+	if layout != "" {
+		target = t.New("content")
+	}
+	if _, err := target.Parse(page); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (e *Engine) parsePartials(t *template.Template) error {
+	if e.config.Partials == "" {
+		return nil
+	}
+	err := fs.WalkDir(e.config.FS, e.config.Partials, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(file, e.config.Extension) {
+			return err
+		}
+		src, err := fs.ReadFile(e.config.FS, file)
+		if err != nil {
+			return err
+		}
+		_, err = t.New(strings.TrimSuffix(file, e.config.Extension)).Parse(string(src))
+		return err
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (e *Engine) read(name string) (string, error) {
+	src, err := fs.ReadFile(e.config.FS, name+e.config.Extension)
+	if err != nil {
+		return "", fmt.Errorf("views : failed to read template %s: %w", name, err)
+	}
+	return string(src), nil
+}
@@ -0,0 +1,87 @@
+package webhooks
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Endpoint represents registered webhook receiver
+type Endpoint struct {
+	bun.BaseModel `bun:"table:webhook_endpoints,alias:we"`
+
+	ID     int64  `bun:",pk,autoincrement" json:"id"`
+	URL    string `bun:",notnull" json:"url"`
+	Secret string `bun:",notnull" json:"-"`
+	// Events are topic patterns matched with events.Match, empty receives all events
+	Events []string `bun:",notnull" json:"events"`
+	// RateLimit is deliveries per minute, zero is unlimited
+	RateLimit int       `bun:",notnull,default:0" json:"rate_limit"`
+	Active    bool      `bun:",notnull,default:true" json:"active"`
+	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// Delivery represents delivery log row of an event to an endpoint
+type Delivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries,alias:wd"`
+
+	ID         int64  `bun:",pk,autoincrement" json:"id"`
+	EndpointID int64  `bun:",notnull,unique:webhook_delivery_event" json:"endpoint_id"`
+	EventID    string `bun:",notnull,unique:webhook_delivery_event" json:"event_id"`
+	Event      string `bun:",notnull" json:"event"`
+	Payload    string `bun:",notnull" json:"payload"`
+	Status     string `bun:",notnull" json:"status"`
+	Attempts   int    `bun:",notnull,default:0" json:"attempts"`
+	// ResponseCode is HTTP status of the last attempt
+	ResponseCode int          `bun:",notnull,default:0" json:"response_code"`
+	LastError    string       `bun:",nullzero" json:"last_error,omitempty"`
+	CreatedAt    time.Time    `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	AvailableAt  time.Time    `bun:",nullzero,notnull,default:current_timestamp" json:"available_at"`
+	DeliveredAt  bun.NullTime `json:"delivered_at"`
+}
+
+// Config represents dispatcher configuration
+type Config struct {
+	Session      string
+	Client       *http.Client
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	Backoff      func(attempt int) time.Duration
+	// SignatureHeader carries "t=<unix>,v1=<hex hmac-sha256 of t.body>"
+	SignatureHeader string
+	// ClaimTTL postpones deliveries while a dispatcher sends them so other instances skip them, deliveries
+	// of crashed dispatchers are retried after it. Should exceed the Client timeout
+	ClaimTTL time.Duration
+}
+
+// DefaultConfig fills zero fields of NewDispatcher config
+var DefaultConfig = Config{
+	Session:         "default",
+	PollInterval:    time.Second,
+	BatchSize:       100,
+	MaxAttempts:     8,
+	SignatureHeader: "X-Webhook-Signature",
+	ClaimTTL:        time.Minute,
+	Backoff: func(attempt int) time.Duration {
+		return time.Duration(1<<min(attempt, 12)) * 10 * time.Second
+	},
+}
+
+// Option customizes dispatched deliveries
+type Option func(d *Delivery)
+
+// WithEventID sets event ID, deliveries of the same event ID to an endpoint are deduplicated
+func WithEventID(id string) Option {
+	return func(d *Delivery) {
+		d.EventID = id
+	}
+}
@@ -0,0 +1,446 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rikiihsan/nest/database"
+	"github.com/rikiihsan/nest/events"
+	"github.com/rikiihsan/nest/ratelimit"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhooks : invalid signature")
+	ErrExpiredSignature = errors.New("webhooks : signature timestamp outside tolerance")
+)
+
+// CreateTables creates endpoint and delivery tables if they do not exist
+func CreateTables(ctx context.Context, db bun.IDB) error {
+	for _, model := range []interface{}{(*Endpoint)(nil), (*Delivery)(nil)} {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterEndpoint stores endpoint receiving events matching patterns, secret is generated when empty
+func RegisterEndpoint(ctx context.Context, db bun.IDB, url, secret string, patterns ...string) (*Endpoint, error) {
+	if secret == "" {
+		secret = strings.ReplaceAll(uuid.NewString(), "-", "") + strings.ReplaceAll(uuid.NewString(), "-", "")
+	}
+	endpoint := &Endpoint{
+		URL:       url,
+		Secret:    secret,
+		Events:    patterns,
+		Active:    true,
+		CreatedAt: time.Now(),
+	}
+	if endpoint.Events == nil {
+		endpoint.Events = []string{}
+	}
+	if _, err := db.NewInsert().Model(endpoint).Exec(ctx); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// Dispatch writes a pending delivery for every active endpoint subscribed to event, using tx
+// commits deliveries atomically with business data. Returns number of deliveries created
+func Dispatch(ctx context.Context, db bun.IDB, event string, payload interface{}, opts ...Option) (int, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	var endpoints []*Endpoint
+	if err := db.NewSelect().Model(&endpoints).Where("active = ?", true).Scan(ctx); err != nil {
+		return 0, fmt.Errorf("failed to load webhook endpoints: %w", err)
+	}
+
+	template := Delivery{EventID: uuid.NewString()}
+	for _, opt := range opts {
+		opt(&template)
+	}
+
+	created := 0
+	for _, endpoint := range endpoints {
+		if !subscribed(endpoint, event) {
+			continue
+		}
+
+		delivery := &Delivery{
+			EndpointID:  endpoint.ID,
+			EventID:     template.EventID,
+			Event:       event,
+			Payload:     string(raw),
+			Status:      StatusPending,
+			CreatedAt:   time.Now(),
+			AvailableAt: time.Now(),
+		}
+
+		// Duplicate event IDs are ignored without aborting the transaction
+		query := db.NewInsert().Model(delivery)
+		switch db.Dialect().Name() {
+		case dialect.PG, dialect.SQLite:
+			query = query.On("CONFLICT (endpoint_id, event_id) DO NOTHING")
+		case dialect.MySQL:
+			query = query.Ignore()
+		}
+
+		res, err := query.Exec(ctx)
+		if database.IsUniqueViolation(err) {
+			continue
+		}
+		if err != nil {
+			return created, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// subscribed reports whether endpoint receives event
+func subscribed(endpoint *Endpoint, event string) bool {
+	if len(endpoint.Events) == 0 {
+		return true
+	}
+	for _, pattern := range endpoint.Events {
+		if events.Match(pattern, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe dispatches events of bus matching pattern to endpoints on session, e.g. Subscribe(events.Default, "default", "order.>")
+func Subscribe(bus *events.Bus, session, pattern string) func() {
+	return bus.Subscribe(pattern, func(ctx context.Context, event events.Event) error {
+		db, err := database.IDB(ctx, session)
+		if err != nil {
+			return err
+		}
+		_, err = Dispatch(ctx, db, event.Topic, event.Payload)
+		return err
+	})
+}
+
+// Sign returns signature header value of body at t
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature header value of body, rejecting timestamps older than tolerance
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+	t := time.Unix(unix, 0)
+	if tolerance > 0 && time.Since(t).Abs() > tolerance {
+		return ErrExpiredSignature
+	}
+
+	expected := Sign(secret, t, body)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte("t="+timestamp+",v1="+signature)) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Redeliver resets delivery to pending so dispatcher sends it again
+func Redeliver(ctx context.Context, db bun.IDB, id int64) error {
+	res, err := db.NewUpdate().
+		Model((*Delivery)(nil)).
+		Set("status = ?", StatusPending).
+		Set("attempts = 0").
+		Set("available_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook delivery %d: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// Deliveries returns latest deliveries of endpoint, newest first
+func Deliveries(ctx context.Context, db bun.IDB, endpointID int64, limit int) ([]*Delivery, error) {
+	var deliveries []*Delivery
+	err := db.NewSelect().
+		Model(&deliveries).
+		Where("endpoint_id = ?", endpointID).
+		Order("id DESC").
+		Limit(limit).
+		Scan(ctx)
+	return deliveries, err
+}
+
+// Dispatcher polls delivery table and sends pending deliveries
+type Dispatcher struct {
+	config Config
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates dispatcher, empty fields fall back to DefaultConfig
+func NewDispatcher(cfg Config) *Dispatcher {
+	if cfg.Session == "" {
+		cfg.Session = DefaultConfig.Session
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultConfig.PollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultConfig.BatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultConfig.MaxAttempts
+	}
+	if cfg.Backoff == nil {
+		cfg.Backoff = DefaultConfig.Backoff
+	}
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = DefaultConfig.SignatureHeader
+	}
+	if cfg.ClaimTTL <= 0 {
+		cfg.ClaimTTL = DefaultConfig.ClaimTTL
+	}
+	return &Dispatcher{config: cfg}
+}
+
+// Start starts polling in background
+func (d *Dispatcher) Start(ctx context.Context) error {
+	ctx, d.cancel = context.WithCancel(context.WithoutCancel(ctx))
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := d.Flush(ctx); err != nil {
+					slog.Error("webhook dispatcher failed", "error", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops polling and waits for the current batch
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	if d.cancel == nil {
+		return nil
+	}
+	d.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("webhook dispatcher did not drain: %w", ctx.Err())
+	}
+}
+
+// Flush sends one batch of due deliveries and returns delivered count. Every delivery is claimed
+// before it is sent, so concurrent dispatchers never send the same delivery twice
+func (d *Dispatcher) Flush(ctx context.Context) (int, error) {
+	db, err := database.GetDB(d.config.Session)
+	if err != nil {
+		return 0, err
+	}
+
+	var deliveries []*Delivery
+	err = db.NewSelect().
+		Model(&deliveries).
+		Where("status = ?", StatusPending).
+		Where("available_at <= ?", time.Now()).
+		Order("id ASC").
+		Limit(d.config.BatchSize).
+		Scan(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load webhook deliveries: %w", err)
+	}
+
+	endpoints := make(map[int64]*Endpoint)
+	delivered := 0
+	for _, delivery := range deliveries {
+		endpoint, ok := endpoints[delivery.EndpointID]
+		if !ok {
+			endpoint = new(Endpoint)
+			if err := db.NewSelect().Model(endpoint).Where("id = ?", delivery.EndpointID).Scan(ctx); err != nil {
+				return delivered, fmt.Errorf("failed to load webhook endpoint %d: %w", delivery.EndpointID, err)
+			}
+			endpoints[endpoint.ID] = endpoint
+		}
+
+		// Rate limited deliveries are postponed without counting an attempt
+		if endpoint.RateLimit > 0 && database.RedisClient != nil {
+			result, err := ratelimit.Allow(ctx, "webhooks:"+strconv.FormatInt(endpoint.ID, 10), endpoint.RateLimit, time.Minute)
+			if err != nil {
+				return delivered, err
+			}
+			if !result.Allowed {
+				_, err := db.NewUpdate().
+					Model(delivery).
+					Set("available_at = ?", time.Now().Add(result.RetryAfter)).
+					WherePK().
+					Exec(ctx)
+				if err != nil {
+					return delivered, err
+				}
+				continue
+			}
+		}
+
+		claimed, err := d.claim(ctx, db, delivery)
+		if err != nil {
+			return delivered, err
+		}
+		if !claimed {
+			continue
+		}
+		if err := d.send(ctx, db, endpoint, delivery); err != nil {
+			return delivered, err
+		}
+		if delivery.Status == StatusDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// claim postpones delivery by ClaimTTL unless another dispatcher claimed or sent it since it was loaded
+func (d *Dispatcher) claim(ctx context.Context, db bun.IDB, delivery *Delivery) (bool, error) {
+	now := time.Now()
+	res, err := db.NewUpdate().
+		Model((*Delivery)(nil)).
+		Set("available_at = ?", now.Add(d.config.ClaimTTL)).
+		Where("id = ?", delivery.ID).
+		Where("status = ?", StatusPending).
+		Where("attempts = ?", delivery.Attempts).
+		Where("available_at <= ?", now).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery %d: %w", delivery.ID, err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// send posts delivery to endpoint and records the attempt
+func (d *Dispatcher) send(ctx context.Context, db bun.IDB, endpoint *Endpoint, delivery *Delivery) error {
+	delivery.Attempts++
+	code, err := d.post(ctx, endpoint, delivery)
+	delivery.ResponseCode = code
+
+	query := db.NewUpdate().
+		Model(delivery).
+		Set("attempts = ?", delivery.Attempts).
+		Set("response_code = ?", code)
+	switch {
+	case err == nil:
+		delivery.Status = StatusDelivered
+		query = query.
+			Set("status = ?", StatusDelivered).
+			Set("last_error = NULL").
+			Set("delivered_at = ?", time.Now())
+	case delivery.Attempts >= d.config.MaxAttempts || !endpoint.Active:
+		delivery.Status = StatusFailed
+		query = query.
+			Set("status = ?", StatusFailed).
+			Set("last_error = ?", err.Error())
+	default:
+		query = query.
+			Set("last_error = ?", err.Error()).
+			Set("available_at = ?", time.Now().Add(d.config.Backoff(delivery.Attempts)))
+	}
+
+	if _, err := query.WherePK().Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update webhook delivery %d: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// post sends signed payload, non-2xx responses are errors
+func (d *Dispatcher) post(ctx context.Context, endpoint *Endpoint, delivery *Delivery) (int, error) {
+	if !endpoint.Active {
+		return 0, errors.New("endpoint is disabled")
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Id", delivery.EventID)
+	req.Header.Set(d.config.SignatureHeader, Sign(endpoint.Secret, time.Now(), body))
+
+	resp, err := d.config.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}